@@ -0,0 +1,140 @@
+package graphql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// QueryHash gets the SHA-256 hash of a query document in hex, as used to
+// identify persisted queries.
+func QueryHash(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+// AllowList is a set of query documents, identified by QueryHash, that a
+// client may send.
+type AllowList struct {
+	hashes map[string]bool
+}
+
+// NewAllowList makes an AllowList of the hashes.
+func NewAllowList(hashes ...string) *AllowList {
+	l := &AllowList{hashes: make(map[string]bool, len(hashes))}
+	for _, h := range hashes {
+		l.hashes[h] = true
+	}
+	return l
+}
+
+// LoadAllowList reads an AllowList from a persisted query manifest. Both
+// the Apollo manifest format and the map of hash to query document used
+// by Relay and others are accepted:
+//  {"operations": [{"id": "<hash>", "name": "GetUser", "body": "query GetUser ..."}]}
+//  {"<hash>": "query GetUser ..."}
+// When a query document is given, its hash is checked against the key.
+func LoadAllowList(r io.Reader) (*AllowList, error) {
+	var raw map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, errors.Wrap(err, "graphql: decoding allow-list")
+	}
+	l := NewAllowList()
+	if ops, ok := raw["operations"]; ok {
+		var manifest []struct {
+			ID   string `json:"id"`
+			Body string `json:"body"`
+		}
+		if err := json.Unmarshal(ops, &manifest); err != nil {
+			return nil, errors.Wrap(err, "graphql: decoding allow-list")
+		}
+		for _, op := range manifest {
+			if err := l.add(op.ID, op.Body); err != nil {
+				return nil, err
+			}
+		}
+		return l, nil
+	}
+	for hash, v := range raw {
+		var body string
+		if err := json.Unmarshal(v, &body); err != nil {
+			return nil, errors.Wrapf(err, "graphql: decoding allow-list entry %q", hash)
+		}
+		if err := l.add(hash, body); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+func (l *AllowList) add(hash, body string) error {
+	if body != "" && QueryHash(body) != hash {
+		return errors.Errorf("graphql: allow-list entry %q does not match its query", hash)
+	}
+	l.hashes[hash] = true
+	return nil
+}
+
+// Allows reports whether the query document is in the list.
+func (l *AllowList) Allows(query string) bool {
+	return l.hashes[QueryHash(query)]
+}
+
+// NotAllowedError is returned by a client with an allow-list when asked
+// to send a query that is not in it.
+type NotAllowedError struct {
+	// Hash is the QueryHash of the query.
+	Hash string
+	// OperationName is the name of the operation, if known.
+	OperationName string
+}
+
+func (e *NotAllowedError) Error() string {
+	name := e.OperationName
+	if name == "" {
+		name = "anonymous operation"
+	}
+	return fmt.Sprintf("graphql: %s (%s) is not in the allow-list", name, e.Hash)
+}
+
+// WithAllowList makes the client refuse to send queries that are not in
+// the allow-list, returning a *NotAllowedError instead. Use it in
+// services that must never send ad-hoc queries. Documents named by
+// NamingAuto are checked as the caller wrote them, before naming.
+//  f, err := os.Open("persisted-queries.json")
+//  ...
+//  allowed, err := graphql.LoadAllowList(f)
+//  ...
+//  NewClient(endpoint, WithAllowList(allowed))
+func WithAllowList(l *AllowList) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.allowList = l
+	})
+}
+
+// checkAllowed checks the request against the client's allow-list.
+func (c *Client) checkAllowed(req *Request) error {
+	if c.allowList == nil {
+		return nil
+	}
+	query := req.Query
+	if req.namedQuery != "" && req.namedQuery == query {
+		// documents the client named are allowed by the caller's document
+		query = req.unnamedQuery
+	}
+	hash := documentInfo(query).hash
+	if c.allowList.hashes[hash] {
+		return nil
+	}
+	name := req.OperationName
+	if name == "" {
+		if op, err := req.Operation(); err == nil {
+			name = op.Name
+		}
+	}
+	return &NotAllowedError{Hash: hash, OperationName: name}
+}
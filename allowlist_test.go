@@ -0,0 +1,58 @@
+package graphql_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestAllowList(t *testing.T) {
+	RegisterTestingT(t)
+	const getUser = `query GetUser { user(id: 1) { name } }`
+	hash := graphql.QueryHash(getUser)
+	Expect(hash).Should(HaveLen(64))
+
+	apollo := `{"format":"apollo-persisted-query-manifest","version":1,"operations":[
+		{"id":"` + hash + `","name":"GetUser","type":"query","body":"query GetUser { user(id: 1) { name } }"}
+	]}`
+	l, err := graphql.LoadAllowList(strings.NewReader(apollo))
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(l.Allows(getUser)).Should(BeTrue())
+	Expect(l.Allows(`{ user(id: 1) { name } }`)).Should(BeFalse())
+
+	l, err = graphql.LoadAllowList(strings.NewReader(`{"` + hash + `":"query GetUser { user(id: 1) { name } }"}`))
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(l.Allows(getUser)).Should(BeTrue())
+
+	_, err = graphql.LoadAllowList(strings.NewReader(`{"abc":"{ user }"}`))
+	Expect(err).Should(MatchError(`graphql: allow-list entry "abc" does not match its query`))
+
+	client := bodyClient(`{"data":{}}`, graphql.WithAllowList(l))
+	err = client.Run(context.Background(), graphql.NewRequest(getUser), nil)
+	Expect(err).ShouldNot(HaveOccurred())
+
+	err = client.Run(context.Background(), graphql.NewRequest(`query Adhoc { users { id } }`), nil)
+	Expect(err).Should(BeAssignableToTypeOf(&graphql.NotAllowedError{}))
+	Expect(err.(*graphql.NotAllowedError).OperationName).Should(Equal("Adhoc"))
+	Expect(err.Error()).Should(HavePrefix("graphql: Adhoc ("))
+}
+
+func TestAllowListNamedOperations(t *testing.T) {
+	RegisterTestingT(t)
+	const anonymous = `{ user(id: 1) { name } }`
+	client := bodyClient(`{"data":{}}`,
+		graphql.WithAllowList(graphql.NewAllowList(graphql.QueryHash(anonymous))),
+		graphql.WithOperationNaming(graphql.NamingAuto))
+
+	// documents are checked as the caller wrote them, not as named
+	req := graphql.NewRequest(anonymous)
+	req.SetMetricsName("GetUser")
+	Expect(client.Run(context.Background(), req, nil)).Should(Succeed())
+
+	req = graphql.NewRequest(`query GetUser { user(id: 1) { name } }`)
+	err := client.Run(context.Background(), req, nil)
+	Expect(err).Should(BeAssignableToTypeOf(&graphql.NotAllowedError{}))
+}
@@ -0,0 +1,86 @@
+package graphql
+
+import (
+	"context"
+	"time"
+)
+
+// AuditRecord is a record of a mutation sent by the client, for audit
+// logs of state-changing calls.
+type AuditRecord struct {
+	// Operation is the request's MetricsName.
+	Operation string
+	// QueryHash is the QueryHash of the query, and VariablesHash the
+	// SHA-256 hash of its variables in canonical JSON, so that the
+	// record identifies the call without holding its data.
+	QueryHash     string
+	VariablesHash string
+	// Caller is the identity set with WithAuditCaller, if any.
+	Caller string
+	// Time is when the mutation was sent.
+	Time time.Time
+	// Error is the error the call failed with, or the first GraphQL
+	// error in the response. It is empty if the mutation succeeded.
+	Error string
+}
+
+// WithAudit passes a record of every mutation the client sends to sink,
+// once the call has completed, including mutations that fail or are sent
+// in a batch. Mutations denied by a policy are never sent, so aren't
+// recorded. The sink is called synchronously, so that a mutation isn't
+// reported as done before it is recorded.
+//  NewClient(endpoint, WithAudit(func(r graphql.AuditRecord) {
+//      auditLog.Write(r)
+//  }))
+func WithAudit(sink func(AuditRecord)) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.auditSink = sink
+	})
+}
+
+// WithAuditCaller gets a context whose mutations are recorded as made
+// by caller, such as the user or service on whose behalf they're made.
+//  ctx = graphql.WithAuditCaller(ctx, "user:1234")
+func WithAuditCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, auditCallerKey{}, caller)
+}
+
+type auditCallerKey struct{}
+
+// startAudit begins the audit record of a prepared request, or returns
+// nil if the client doesn't audit or the request isn't a mutation.
+func (c *Client) startAudit(ctx context.Context, req *Request) *AuditRecord {
+	if c.auditSink == nil {
+		return nil
+	}
+	if op, err := req.Operation(); err != nil || op.Type != "mutation" {
+		return nil
+	}
+	caller, _ := ctx.Value(auditCallerKey{}).(string)
+	// the request has been encoded, so its variables can be hashed
+	// without error
+	hash, _ := variablesHash(req.Variables)
+	return &AuditRecord{
+		Operation:     req.MetricsName(),
+		QueryHash:     documentInfo(req.Query).hash,
+		VariablesHash: hash,
+		Caller:        caller,
+		Time:          c.now(),
+	}
+}
+
+// finishAudit completes an audit record with the outcome of the call,
+// and passes it to the sink.
+func (c *Client) finishAudit(record *AuditRecord, errs Errors, err error) {
+	if record == nil {
+		return
+	}
+	switch {
+	case err != nil:
+		record.Error = err.Error()
+	case len(errs) > 0:
+		record.Error = errs[0].Error()
+	}
+	defer c.recoverHook("audit sink", nil)
+	c.auditSink(*record)
+}
@@ -0,0 +1,61 @@
+package graphql_test
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestAudit(t *testing.T) {
+	RegisterTestingT(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		switch {
+		case b[0] == '[':
+			io.WriteString(w, `[{"data":{"ok":true}},{"errors":[{"message":"not found"}]}]`)
+		default:
+			io.WriteString(w, `{"data":{"ok":true}}`)
+		}
+	}))
+	defer srv.Close()
+	var records []graphql.AuditRecord
+	client := graphql.NewClient(srv.URL, graphql.WithAudit(func(r graphql.AuditRecord) {
+		records = append(records, r)
+	}))
+	ctx := graphql.WithAuditCaller(context.Background(), "user:1")
+
+	var resp map[string]interface{}
+	Expect(client.Run(ctx, graphql.NewRequest(`query User { ok }`), &resp)).Should(Succeed())
+	Expect(records).Should(BeEmpty())
+
+	req := graphql.NewRequest(`mutation DeleteUser($id: ID!) { ok }`)
+	req.Var("id", "1")
+	Expect(client.Run(ctx, req, &resp)).Should(Succeed())
+	Expect(records).Should(HaveLen(1))
+	Expect(records[0].Operation).Should(Equal("DeleteUser"))
+	Expect(records[0].Caller).Should(Equal("user:1"))
+	Expect(records[0].QueryHash).Should(Equal(graphql.QueryHash(req.Query)))
+	fingerprint, err := graphql.Fingerprint(req)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(graphql.QueryHash(req.Query) + "." + records[0].VariablesHash).Should(Equal(fingerprint))
+	Expect(records[0].Time).ShouldNot(BeZero())
+	Expect(records[0].Error).Should(BeEmpty())
+
+	records = nil
+	_, err = client.RunBatch(context.Background(), []*graphql.Request{
+		graphql.NewRequest(`mutation A { ok }`),
+		graphql.NewRequest(`mutation B { ok }`),
+	}, nil)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(records).Should(HaveLen(2))
+	Expect(records[0].Error).Should(BeEmpty())
+	Expect(records[1].Operation).Should(Equal("B"))
+	Expect(records[1].Caller).Should(BeEmpty())
+	Expect(records[1].Error).Should(Equal("graphql: not found"))
+}
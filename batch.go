@@ -0,0 +1,340 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// BatchStatus is the outcome of one request in a batch.
+type BatchStatus int
+
+// Batch statuses.
+const (
+	// BatchOK requests succeeded without errors.
+	BatchOK BatchStatus = iota
+	// BatchPartial requests returned data and errors; the data is
+	// decoded.
+	BatchPartial
+	// BatchFailed requests returned errors and no data, or no response.
+	BatchFailed
+)
+
+func (s BatchStatus) String() string {
+	switch s {
+	case BatchOK:
+		return "ok"
+	case BatchPartial:
+		return "partial"
+	case BatchFailed:
+		return "failed"
+	}
+	return "unknown"
+}
+
+// BatchResult is the result of one request in a batch.
+type BatchResult struct {
+	Status BatchStatus
+	// Errors are the GraphQL errors returned for the request.
+	Errors Errors
+	// Err is the first of Errors, or the reason the request has no
+	// result; nil if Status is BatchOK.
+	Err error
+	// Data is the value the data was decoded into by RunBatchDecoders,
+	// or nil if it wasn't decoded.
+	Data interface{}
+}
+
+// WithBatchSplitting makes RunBatch resend batches that the server
+// rejects with 413 Payload Too Large in two halves, splitting them again
+// if needed, up to depth times. A depth of 3 sends batches of down to an
+// eighth of the original size.
+//  NewClient(endpoint, WithBatchSplitting(3))
+func WithBatchSplitting(depth int) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.batchSplitDepth = depth
+	})
+}
+
+// RunBatch sends the requests to the server together, as a JSON array,
+// and decodes the data of each response into the value at the same index
+// in resps, which may be nil or have nil values to skip decoding.
+//
+// The error is only for failures of the whole batch, such as network
+// errors. Each request's result, including its errors, is in the
+// BatchResult at the same index, in the order of reqs. In dry-run mode,
+// mutations are left out of the batch and fail as described for
+// WithDryRun.
+//
+// Each request in the batch is otherwise handled as a request sent on
+// its own is, as far as a shared HTTP request allows: the batch is sent
+// with the headers of all of its requests, and the fingerprint header,
+// if the client sends them, has the fingerprint of each request in turn.
+//  results, err := client.RunBatch(ctx, []*graphql.Request{userReq, orgReq}, []interface{}{&user, &org})
+func (c *Client) RunBatch(ctx context.Context, reqs []*Request, resps []interface{}) ([]BatchResult, error) {
+	if resps != nil && len(resps) != len(reqs) {
+		return nil, errors.Errorf("graphql: %d requests but %d responses", len(reqs), len(resps))
+	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	atomic.AddInt32(&c.inFlight, int32(len(reqs)))
+	defer atomic.AddInt32(&c.inFlight, -int32(len(reqs)))
+	c.refreshSchema(ctx)
+	prepared := make([]*Request, len(reqs))
+	for i, req := range reqs {
+		var err error
+		if prepared[i], err = c.prepare(req); err != nil {
+			return nil, errors.Wrapf(err, "batch request %d", i)
+		}
+	}
+	ctx, err := c.checkPolicies(ctx, prepared)
+	if err != nil {
+		return nil, err
+	}
+	ctx = c.routeContext(ctx, prepared...)
+	for i, req := range prepared {
+		if prepared[i], err = c.withIdempotencyKey(reqs[i], req); err != nil {
+			return nil, errors.Wrapf(err, "batch request %d", i)
+		}
+	}
+	results := make([]BatchResult, len(reqs))
+	// in dry-run mode, mutations are left out of the batch
+	var sent []int
+	for i, req := range prepared {
+		if c.dryRuns(req) {
+			results[i] = BatchResult{Status: BatchFailed, Err: c.dryRunMutation(ctx, reqs[i], req)}
+			continue
+		}
+		sent = append(sent, i)
+	}
+	if len(sent) == 0 {
+		return results, nil
+	}
+	batch := make([]*Request, len(sent))
+	fingerprints := make([]string, len(sent))
+	audits := make([]*AuditRecord, len(sent))
+	for j, i := range sent {
+		// fingerprints and audit records are of the variables as given
+		if c.fingerprintHeader != "" {
+			if fingerprints[j], err = Fingerprint(prepared[i]); err != nil {
+				return nil, errors.Wrapf(err, "batch request %d", i)
+			}
+		}
+		if batch[j], err = c.encryptVariables(ctx, prepared[i]); err != nil {
+			return nil, errors.Wrapf(err, "batch request %d", i)
+		}
+	}
+	for j, i := range sent {
+		audits[j] = c.startAudit(ctx, prepared[i])
+	}
+	start := c.now()
+	items, err := c.sendBatch(ctx, batch, fingerprints, 0)
+	if err != nil {
+		for j, i := range sent {
+			c.finishAudit(audits[j], nil, err)
+			c.finishCapture(c.startCapture(batch[j], nil), start, nil, err)
+			c.recordHealth(prepared[i], start, nil, err)
+		}
+		return nil, err
+	}
+	for j, i := range sent {
+		var resp interface{}
+		if resps != nil {
+			resp = resps[i]
+		}
+		item := items[j]
+		capture := c.startCapture(batch[j], item.header)
+		c.captureResponse(capture, item.res, item.body)
+		if item.body == nil {
+			results[i] = BatchResult{
+				Status: BatchFailed,
+				Err:    withFingerprint(errors.Errorf("graphql: no response for batch request %d", i), item.fingerprint),
+			}
+		} else {
+			results[i] = c.batchResult(ctx, prepared[i], item, resp, start, c.batchStats(batch[j], item))
+		}
+		c.finishAudit(audits[j], results[i].Errors, results[i].Err)
+		c.finishCapture(capture, start, results[i].Errors, results[i].Err)
+		c.recordHealth(prepared[i], start, results[i].Errors, results[i].Err)
+	}
+	return results, nil
+}
+
+// batchItem is the response to one request in a batch.
+type batchItem struct {
+	// body is the request's own response, or nil if it has none.
+	body json.RawMessage
+	// header is the header the batch was sent with, and res the HTTP
+	// response it came in.
+	header http.Header
+	res    *http.Response
+	// budget is the latency budget sent with the batch.
+	budget time.Duration
+	// fingerprint is the request's fingerprint, if the client sends
+	// them.
+	fingerprint string
+}
+
+// sendBatch posts the prepared requests as a batch, returning the
+// responses. If the server rejects the batch as too large, and the client
+// splits batches, it is sent in halves, which may be split in turn up to
+// the client's split depth. fingerprints are those of reqs, sent if the
+// client sends them.
+func (c *Client) sendBatch(ctx context.Context, reqs []*Request, fingerprints []string, depth int) ([]batchItem, error) {
+	header := make(http.Header)
+	for _, req := range reqs {
+		for name, values := range req.Header {
+			for _, value := range values {
+				if !containsString(header[name], value) {
+					header[name] = append(header[name], value)
+				}
+			}
+		}
+	}
+	if key := batchIdempotencyKey(reqs); key != "" {
+		header.Set(c.idempotencyHeader, key)
+	}
+	c.sendConsistencyToken(ctx, header)
+	budget := c.sendLatencyBudget(ctx, header)
+	if c.fingerprintHeader != "" {
+		for _, fp := range fingerprints {
+			header.Add(c.fingerprintHeader, fp)
+		}
+	}
+	b, err := json.Marshal(reqs)
+	if err != nil {
+		return nil, err
+	}
+	res, _, body, err := c.post(ctx, b, header, &RequestStats{})
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusRequestEntityTooLarge && len(reqs) > 1 && depth < c.batchSplitDepth {
+		half := len(reqs) / 2
+		first, err := c.sendBatch(ctx, reqs[:half], fingerprints[:half], depth+1)
+		if err != nil {
+			return nil, err
+		}
+		second, err := c.sendBatch(ctx, reqs[half:], fingerprints[half:], depth+1)
+		if err != nil {
+			return nil, err
+		}
+		return append(first, second...), nil
+	}
+	var bodies []json.RawMessage
+	if err := c.decode(body, &bodies); err != nil {
+		// servers that don't support batching reply with one response
+		var single struct {
+			Errors Errors
+		}
+		if json.Unmarshal(body, &single) == nil && len(single.Errors) > 0 {
+			return nil, single.Errors
+		}
+		if res.StatusCode < 200 || res.StatusCode > 299 {
+			return nil, &StatusError{StatusCode: res.StatusCode, Status: res.Status, Body: body}
+		}
+		return nil, err
+	}
+	for _, req := range reqs {
+		c.keepConsistencyToken(ctx, req, res)
+	}
+	items := make([]batchItem, len(reqs))
+	for i := range items {
+		items[i] = batchItem{header: header, res: res, budget: budget, fingerprint: fingerprints[i]}
+		if i < len(bodies) {
+			items[i].body = bodies[i]
+		}
+	}
+	return items, nil
+}
+
+// batchStats gets the stats of one request in a batch: the sizes of its
+// own parts of the batch.
+func (c *Client) batchStats(req *Request, item batchItem) RequestStats {
+	stats := RequestStats{
+		Operation:     req.MetricsName(),
+		ResponseBytes: len(item.body),
+		LatencyBudget: item.budget,
+		BudgetOutcome: c.budgetOutcome(item.budget, item.res),
+		FromCache:     fromCache(item.res.Header),
+	}
+	if c.statsReport == nil {
+		return stats
+	}
+	stats.RootFields = req.RootFields()
+	if b, err := json.Marshal(req); err == nil {
+		stats.RequestBytes = len(b)
+	}
+	return stats
+}
+
+// batchResult decodes one response of a batch, handling it as responses
+// to single requests are, as Do handles them. start is when the batch
+// was sent.
+func (c *Client) batchResult(ctx context.Context, req *Request, item batchItem, resp interface{}, start time.Time, stats RequestStats) BatchResult {
+	if c.specCompliance {
+		if err := checkResponseShape(item.body); err != nil {
+			return BatchResult{Status: BatchFailed, Err: withFingerprint(err, item.fingerprint)}
+		}
+	}
+	var graphResponse struct {
+		Data       json.RawMessage
+		Errors     Errors
+		Extensions json.RawMessage
+	}
+	if err := json.Unmarshal(item.body, &graphResponse); err != nil {
+		return BatchResult{Status: BatchFailed, Err: withFingerprint(errors.Wrap(err, "decoding batch response"), item.fingerprint)}
+	}
+	result := BatchResult{Errors: graphResponse.Errors}
+	hasData := len(graphResponse.Data) > 0 && !bytes.Equal(graphResponse.Data, []byte("null"))
+	switch {
+	case len(result.Errors) == 0:
+		result.Status = BatchOK
+	case hasData:
+		result.Status = BatchPartial
+		result.Err = withFingerprint(result.Errors[0], item.fingerprint)
+	default:
+		result.Status = BatchFailed
+		result.Err = withFingerprint(result.Errors[0], item.fingerprint)
+	}
+	res := &response{
+		data:        graphResponse.Data,
+		errors:      graphResponse.Errors,
+		extensions:  graphResponse.Extensions,
+		status:      item.res.StatusCode,
+		header:      item.res.Header,
+		duration:    c.since(start),
+		attempts:    1,
+		req:         req,
+		fingerprint: item.fingerprint,
+		stats:       stats,
+	}
+	var decode func(json.RawMessage) ([]Warning, error)
+	if hasData {
+		var err error
+		if res.data, err = c.decryptData(ctx, req, res.data); err == nil {
+			res.data, res.truncations, err = c.truncateLists(res.data)
+		}
+		if err != nil {
+			result.Status = BatchFailed
+			result.Err = withFingerprint(err, item.fingerprint)
+			return result
+		}
+		decode = func(data json.RawMessage) ([]Warning, error) {
+			return c.decodeResult(req, data, resp)
+		}
+	}
+	if _, err := c.handleResponse(ctx, req, res, resp, true, decode); err != nil {
+		result.Status = BatchFailed
+		result.Err = err
+	}
+	return result
+}
@@ -0,0 +1,225 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestRunBatch(t *testing.T) {
+	RegisterTestingT(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqs []graphql.Request
+		err := json.NewDecoder(r.Body).Decode(&reqs)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(reqs).Should(HaveLen(4))
+		Expect(reqs[1].Variables).Should(Equal(map[string]interface{}{"id": float64(2)}))
+		io.WriteString(w, `[
+			{"data": {"user": {"name": "Mat"}}},
+			{"data": {"user": {"name": "David"}}, "errors": [{"message": "email unavailable", "path": ["user", "email"]}]},
+			{"data": null, "errors": [{"message": "not found"}]}
+		]`)
+	}))
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL)
+
+	var reqs []*graphql.Request
+	for id := 1; id <= 4; id++ {
+		req := graphql.NewRequest(`query ($id: ID!) { user(id: $id) { name email } }`)
+		req.Var("id", id)
+		reqs = append(reqs, req)
+	}
+	type user struct {
+		User struct {
+			Name string
+		}
+	}
+	var a, b, c user
+	results, err := client.RunBatch(context.Background(), reqs, []interface{}{&a, &b, &c, nil})
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(results).Should(HaveLen(4))
+
+	Expect(results[0].Status).Should(Equal(graphql.BatchOK))
+	Expect(results[0].Err).ShouldNot(HaveOccurred())
+	Expect(a.User.Name).Should(Equal("Mat"))
+
+	Expect(results[1].Status).Should(Equal(graphql.BatchPartial))
+	Expect(results[1].Err).Should(MatchError("graphql: email unavailable"))
+	Expect(b.User.Name).Should(Equal("David"))
+
+	Expect(results[2].Status).Should(Equal(graphql.BatchFailed))
+	Expect(results[2].Errors).Should(HaveLen(1))
+	Expect(results[2].Err).Should(MatchError("graphql: not found"))
+
+	Expect(results[3].Status.String()).Should(Equal("failed"))
+	Expect(results[3].Err).Should(MatchError("graphql: no response for batch request 3"))
+}
+
+func TestRunBatchUnsupported(t *testing.T) {
+	RegisterTestingT(t)
+	client := bodyClient(`{"errors":[{"message":"batching is not supported"}]}`)
+	_, err := client.RunBatch(context.Background(), []*graphql.Request{graphql.NewRequest(`{ a }`)}, nil)
+	Expect(err).Should(MatchError("graphql: batching is not supported"))
+
+	_, err = client.RunBatch(context.Background(), []*graphql.Request{graphql.NewRequest(`{ a }`)}, []interface{}{})
+	Expect(err).Should(MatchError("graphql: 1 requests but 0 responses"))
+}
+
+func TestRunBatchSplitting(t *testing.T) {
+	RegisterTestingT(t)
+	var sizes []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqs []graphql.Request
+		json.NewDecoder(r.Body).Decode(&reqs)
+		sizes = append(sizes, len(reqs))
+		if len(reqs) > 2 {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		items := make([]map[string]interface{}, len(reqs))
+		for i, req := range reqs {
+			items[i] = map[string]interface{}{"data": map[string]interface{}{"id": req.Variables["id"]}}
+		}
+		json.NewEncoder(w).Encode(items)
+	}))
+	defer srv.Close()
+
+	var reqs []*graphql.Request
+	var resps []interface{}
+	for id := 1; id <= 5; id++ {
+		req := graphql.NewRequest(`query ($id: Int) { id }`)
+		req.Var("id", id)
+		reqs = append(reqs, req)
+		resps = append(resps, &struct{ ID int }{})
+	}
+
+	_, err := graphql.NewClient(srv.URL).RunBatch(context.Background(), reqs, resps)
+	Expect(err).Should(BeAssignableToTypeOf(&graphql.StatusError{}))
+
+	sizes = nil
+	results, err := graphql.NewClient(srv.URL, graphql.WithBatchSplitting(1)).RunBatch(context.Background(), reqs, resps)
+	Expect(err).Should(BeAssignableToTypeOf(&graphql.StatusError{}))
+	Expect(results).Should(BeNil())
+	Expect(sizes).Should(Equal([]int{5, 2, 3}))
+
+	sizes = nil
+	results, err = graphql.NewClient(srv.URL, graphql.WithBatchSplitting(2)).RunBatch(context.Background(), reqs, resps)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(sizes).Should(Equal([]int{5, 2, 3, 1, 2}))
+	for i, result := range results {
+		Expect(result.Status).Should(Equal(graphql.BatchOK))
+		Expect(resps[i]).Should(Equal(&struct{ ID int }{i + 1}))
+	}
+}
+
+func TestRunBatchReports(t *testing.T) {
+	RegisterTestingT(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `[
+			{"data": {"user": {"name": "Mat"}}},
+			{"data": null, "errors": [{"message": "not found"}]}
+		]`)
+	}))
+	defer srv.Close()
+	var stats []graphql.RequestStats
+	var shapes []graphql.ShapeReport
+	health := graphql.NewHealthTracker(time.Minute)
+	client := graphql.NewClient(srv.URL,
+		graphql.WithStatsReport(func(s graphql.RequestStats) { stats = append(stats, s) }),
+		graphql.WithShapeReport(func(r graphql.ShapeReport) { shapes = append(shapes, r) }),
+		graphql.WithHealthTracker(health))
+
+	reqs := []*graphql.Request{
+		graphql.NewRequest(`query User { user { name } }`),
+		graphql.NewRequest(`query Missing { user { name } }`),
+	}
+	_, err := client.RunBatch(context.Background(), reqs, nil)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(stats).Should(HaveLen(2))
+	Expect(stats[0].Operation).Should(Equal("User"))
+	Expect(stats[0].RootFields).Should(Equal([]string{"user"}))
+	Expect(stats[0].ResponseBytes).Should(Equal(len(`{"data": {"user": {"name": "Mat"}}}`)))
+	Expect(stats[1].Operation).Should(Equal("Missing"))
+	Expect(shapes).Should(HaveLen(1))
+	Expect(shapes[0].Operation).Should(Equal("User"))
+	Expect(health.Health("User").Calls).Should(Equal(1))
+	Expect(health.Health("User").Failures).Should(Equal(0))
+	Expect(health.Health("Missing").Failures).Should(Equal(1))
+}
+
+func TestRunBatchHandlesEachRequest(t *testing.T) {
+	RegisterTestingT(t)
+	var headers []http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header)
+		w.Header().Set("X-Consistency-Token", "after-write")
+		w.Header().Set("X-Budget-Honored", "true")
+		io.WriteString(w, `[
+			{"data": {"ids": [1, 2, 3]}},
+			{"data": {"setName": true}}
+		]`)
+	}))
+	defer srv.Close()
+	var captures []graphql.Capture
+	client := graphql.NewClient(srv.URL,
+		graphql.WithConsistencyTokens("X-Consistency-Token"),
+		graphql.WithLatencyBudget(graphql.LatencyBudget{
+			Header:        "X-Budget",
+			Budget:        time.Second,
+			HonoredHeader: "X-Budget-Honored",
+		}),
+		graphql.WithListLimit(2, 0),
+		graphql.WithCapture(graphql.CapturePolicy{Rate: 1}, func(c graphql.Capture) { captures = append(captures, c) }))
+
+	query := graphql.NewRequest(`query Ids { ids }`)
+	query.Header = http.Header{"X-Tag": {"a"}}
+	mutation := graphql.NewRequest(`mutation SetName { setName }`)
+	mutation.Header = http.Header{"X-Tag": {"b"}}
+	var ids struct{ IDs []int }
+	results, err := client.RunBatch(context.Background(), []*graphql.Request{query, mutation}, []interface{}{&ids, nil})
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(results[0].Err).ShouldNot(HaveOccurred())
+	Expect(results[1].Err).ShouldNot(HaveOccurred())
+	Expect(ids.IDs).Should(Equal([]int{1, 2}))
+	Expect(headers[0]["X-Tag"]).Should(Equal([]string{"a", "b"}))
+	Expect(headers[0].Get("X-Budget")).Should(Equal("1000"))
+	Expect(headers[0].Get("X-Consistency-Token")).Should(BeEmpty())
+	Expect(captures).Should(HaveLen(2))
+	Expect(string(captures[0].ResponseBody)).Should(Equal(`{"data": {"ids": [1, 2, 3]}}`))
+	Expect(captures[1].Operation).Should(Equal("SetName"))
+	Expect(captures[1].Query).Should(Equal(`mutation SetName { setName }`))
+
+	// the mutation's consistency token is sent with later batches
+	_, err = client.RunBatch(context.Background(), []*graphql.Request{graphql.NewRequest(`query Ids { ids }`)}, nil)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(headers[1].Get("X-Consistency-Token")).Should(Equal("after-write"))
+}
+
+func TestRunBatchFingerprints(t *testing.T) {
+	RegisterTestingT(t)
+	var sent []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = r.Header["X-Request-Fingerprint"]
+		io.WriteString(w, `[{"data": null, "errors": [{"message": "not found"}]}, {"data": {"b": 1}}]`)
+	}))
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL, graphql.WithFingerprints("X-Request-Fingerprint"))
+
+	reqs := []*graphql.Request{graphql.NewRequest(`{ a }`), graphql.NewRequest(`{ b }`)}
+	results, err := client.RunBatch(context.Background(), reqs, nil)
+	Expect(err).ShouldNot(HaveOccurred())
+	a, err := graphql.Fingerprint(reqs[0])
+	Expect(err).ShouldNot(HaveOccurred())
+	b, err := graphql.Fingerprint(reqs[1])
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(sent).Should(Equal([]string{a, b}))
+	Expect(results[0].Err).Should(BeAssignableToTypeOf(&graphql.FingerprintError{}))
+	Expect(results[0].Err.(*graphql.FingerprintError).Fingerprint).Should(Equal(a))
+}
@@ -0,0 +1,87 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// LatencyBudget configures the latency budget sent with requests, for
+// gateways that shed load adaptively by dropping work that can't finish
+// in time.
+type LatencyBudget struct {
+	// Header carries the budget on requests, in whole milliseconds.
+	Header string
+	// Budget is sent when the context has no deadline, or a later one;
+	// otherwise the time left until the deadline is sent. If it is zero
+	// only requests with deadlines carry a budget.
+	Budget time.Duration
+	// HonoredHeader is the response header in which the server says
+	// whether it honored the budget, as true or false.
+	HonoredHeader string
+}
+
+// BudgetOutcome is whether the server honored the latency budget of a
+// request.
+type BudgetOutcome string
+
+const (
+	// BudgetUnanswered means no budget was sent, or the server didn't
+	// say whether it honored it.
+	BudgetUnanswered BudgetOutcome = ""
+	// BudgetHonored means the server honored the budget.
+	BudgetHonored BudgetOutcome = "honored"
+	// BudgetIgnored means the server said it didn't honor the budget.
+	BudgetIgnored BudgetOutcome = "ignored"
+)
+
+// WithLatencyBudget sends a latency budget with requests, and records
+// in their RequestStats the budget sent and whether the server honored
+// it.
+//  NewClient(endpoint, WithLatencyBudget(LatencyBudget{
+//      Header:        "X-Latency-Budget-Ms",
+//      Budget:        500 * time.Millisecond,
+//      HonoredHeader: "X-Latency-Budget-Honored",
+//  }))
+func WithLatencyBudget(budget LatencyBudget) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.latencyBudget = budget
+	})
+}
+
+// sendLatencyBudget adds the latency budget of a request made with ctx
+// to header, and gets it, or zero if none is sent.
+func (c *Client) sendLatencyBudget(ctx context.Context, header http.Header) time.Duration {
+	if c.latencyBudget.Header == "" {
+		return 0
+	}
+	budget := c.latencyBudget.Budget
+	// deadlines are in real time, whatever the client's clock
+	if deadline, ok := ctx.Deadline(); ok {
+		if left := time.Until(deadline); budget == 0 || left < budget {
+			budget = left
+		}
+	}
+	if budget <= 0 {
+		return 0
+	}
+	header.Set(c.latencyBudget.Header, strconv.FormatInt(int64(budget/time.Millisecond), 10))
+	return budget
+}
+
+// budgetOutcome reads whether the server honored the latency budget
+// sent with the request from its response.
+func (c *Client) budgetOutcome(budget time.Duration, res *http.Response) BudgetOutcome {
+	if budget == 0 || c.latencyBudget.HonoredHeader == "" {
+		return BudgetUnanswered
+	}
+	honored, err := strconv.ParseBool(res.Header.Get(c.latencyBudget.HonoredHeader))
+	switch {
+	case err != nil:
+		return BudgetUnanswered
+	case honored:
+		return BudgetHonored
+	}
+	return BudgetIgnored
+}
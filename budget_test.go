@@ -0,0 +1,67 @@
+package graphql_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/joefitzgerald/graphql"
+	"github.com/joefitzgerald/graphql/graphqltest"
+	. "github.com/onsi/gomega"
+)
+
+func TestWithLatencyBudget(t *testing.T) {
+	RegisterTestingT(t)
+	var budgets []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		budget := r.Header.Get("X-Latency-Budget-Ms")
+		budgets = append(budgets, budget)
+		if ms, err := strconv.Atoi(budget); err == nil {
+			w.Header().Set("X-Latency-Budget-Honored", strconv.FormatBool(ms >= 100))
+		}
+		io.WriteString(w, `{"data":{"ok":true}}`)
+	}))
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL, graphql.WithLatencyBudget(graphql.LatencyBudget{
+		Header:        "X-Latency-Budget-Ms",
+		Budget:        500 * time.Millisecond,
+		HonoredHeader: "X-Latency-Budget-Honored",
+	}))
+	req := graphql.NewRequest(`{ ok }`)
+
+	result, err := graphql.Do[map[string]interface{}](context.Background(), client, req)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(budgets).Should(Equal([]string{"500"}))
+	Expect(result.Stats.LatencyBudget).Should(Equal(500 * time.Millisecond))
+	Expect(result.Stats.BudgetOutcome).Should(Equal(graphql.BudgetHonored))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	result, err = graphql.Do[map[string]interface{}](ctx, client, req)
+	Expect(err).ShouldNot(HaveOccurred())
+	ms, _ := strconv.Atoi(budgets[1])
+	Expect(ms).Should(BeNumerically("<=", 50))
+	Expect(result.Stats.LatencyBudget).Should(BeNumerically("<=", 50*time.Millisecond))
+	Expect(result.Stats.BudgetOutcome).Should(Equal(graphql.BudgetIgnored))
+
+	// without a budget, only requests with deadlines carry one
+	plain := graphql.NewClient(srv.URL, graphql.WithLatencyBudget(graphql.LatencyBudget{Header: "X-Latency-Budget-Ms"}))
+	result, err = graphql.Do[map[string]interface{}](context.Background(), plain, req)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(budgets[2]).Should(BeEmpty())
+	Expect(result.Stats.LatencyBudget).Should(BeZero())
+	Expect(result.Stats.BudgetOutcome).Should(Equal(graphql.BudgetUnanswered))
+
+	// deadlines are measured in real time, whatever the client's clock
+	clocked := graphql.NewClient(srv.URL,
+		graphql.WithClock(graphqltest.NewClock(time.Now().Add(-time.Hour))),
+		graphql.WithLatencyBudget(graphql.LatencyBudget{Header: "X-Latency-Budget-Ms"}),
+	)
+	result, err = graphql.Do[map[string]interface{}](ctx, clocked, req)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(result.Stats.LatencyBudget).Should(BeNumerically("<=", 50*time.Millisecond))
+}
@@ -0,0 +1,338 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Capabilities are the optional features of a GraphQL server, as found
+// by Client.Capabilities.
+type Capabilities struct {
+	// GET is whether the server runs queries sent in GET requests.
+	GET bool
+	// PersistedQueries is whether the server supports automatic
+	// persisted queries, sent as a hash of the query.
+	PersistedQueries bool
+	// Batching is whether the server accepts batches of requests, as
+	// sent by RunBatch.
+	Batching bool
+	// MaxBatchSize is the most requests the server accepts in a batch,
+	// if it accepts batches but not batches of maxBatchProbe requests.
+	// It is zero if no limit was found.
+	MaxBatchSize int
+	// Subscriptions is whether the schema has a subscription type.
+	Subscriptions bool
+	// Defer and Stream are whether the schema has the @defer and
+	// @stream directives.
+	Defer  bool
+	Stream bool
+}
+
+// capabilitiesRetryInterval is the longest a client waits to probe its
+// server again after a probe failed; it waits a second after the first
+// failure, doubling with each failure after that.
+const capabilitiesRetryInterval = time.Minute
+
+// capabilitiesState caches a client's capabilities. Its fields are
+// guarded by mu, which isn't held while probing.
+type capabilitiesState struct {
+	mu           sync.Mutex
+	capabilities *Capabilities
+	// probing is closed when the probe in progress, if any, ends.
+	probing chan struct{}
+	// err is the error of the last probe, if it failed, which is
+	// returned until retry.
+	err      error
+	failures int
+	retry    time.Time
+}
+
+// maxBatchProbe is the size of the largest batch sent to find the
+// server's batch size limit.
+const maxBatchProbe = 64
+
+// probeQuery is the query sent by capability probes.
+const probeQuery = `query CapabilitiesProbe { __typename }`
+
+// probeSchemaQuery is the query sent to probe the schema.
+const probeSchemaQuery = `query CapabilitiesProbe {
+  __schema {
+    subscriptionType { name }
+    directives { name }
+  }
+}`
+
+// Capabilities probes the server for optional features, by sending it a
+// few small requests, and caches the result for the life of the client.
+// A feature is only reported if the server is seen to support it, so
+// servers that reject introspection don't report Subscriptions, Defer or
+// Stream. Servers that accept batches are sent batches of growing size,
+// up to 64 requests, to find MaxBatchSize. Requests that fail outright
+// fail the probe; the error is returned by calls made in the next
+// second, doubling with each failure up to a minute, before the server
+// is probed again. Concurrent calls share one probe.
+//
+// Probes are sent with the client's headers and credentials, but aren't
+// passed through the client's plugins, policies or other request
+// handling. A client with an allow-list only sends the probes it allows,
+// and doesn't report the features it can't probe.
+func (c *Client) Capabilities(ctx context.Context) (Capabilities, error) {
+	s := &c.capabilities
+	s.mu.Lock()
+	for {
+		if s.capabilities != nil {
+			caps := *s.capabilities
+			s.mu.Unlock()
+			return caps, nil
+		}
+		if s.err != nil && c.now().Before(s.retry) {
+			err := s.err
+			s.mu.Unlock()
+			return Capabilities{}, err
+		}
+		if s.probing == nil {
+			break
+		}
+		probing := s.probing
+		s.mu.Unlock()
+		select {
+		case <-probing:
+		case <-ctx.Done():
+			return Capabilities{}, ctx.Err()
+		}
+		s.mu.Lock()
+	}
+	probing := make(chan struct{})
+	s.probing = probing
+	s.mu.Unlock()
+
+	caps, err := c.probeCapabilities(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.probing = nil
+	close(probing)
+	switch {
+	case err == nil:
+		s.capabilities, s.err, s.failures = &caps, nil, 0
+	case ctx.Err() == nil:
+		// a probe given up by its caller says nothing about the server
+		s.err = err
+		s.failures++
+		wait := capabilitiesRetryInterval
+		if s.failures <= 6 {
+			wait = time.Second << uint(s.failures-1)
+		}
+		s.retry = c.now().Add(wait)
+	}
+	return caps, err
+}
+
+// probeCapabilities sends the probes the client's allow-list allows.
+func (c *Client) probeCapabilities(ctx context.Context) (Capabilities, error) {
+	// probes aren't streamed when called from RunTo
+	ctx = context.WithValue(ctx, streamKey{}, nil)
+	var caps Capabilities
+	var err error
+	if c.checkAllowed(NewRequest(probeQuery)) == nil {
+		if caps.GET, err = c.probeGET(ctx); err != nil {
+			return Capabilities{}, err
+		}
+		if caps.PersistedQueries, err = c.probePersistedQueries(ctx); err != nil {
+			return Capabilities{}, err
+		}
+		if caps.Batching, err = c.probeBatch(ctx, 1); err != nil {
+			return Capabilities{}, err
+		}
+		if caps.Batching {
+			if caps.MaxBatchSize, err = c.probeMaxBatchSize(ctx); err != nil {
+				return Capabilities{}, err
+			}
+		}
+	}
+	if c.checkAllowed(NewRequest(probeSchemaQuery)) == nil {
+		if err := c.probeSchema(ctx, &caps); err != nil {
+			return Capabilities{}, err
+		}
+	}
+	return caps, nil
+}
+
+// probeResponse is the response to a probe.
+type probeResponse struct {
+	Data   json.RawMessage
+	Errors Errors
+}
+
+// ok reports whether the response has data and no errors.
+func (r *probeResponse) ok() bool {
+	return len(r.Errors) == 0 && len(r.Data) > 0 && string(r.Data) != "null"
+}
+
+// probe posts v to the server and decodes the response into out,
+// reporting whether it could be decoded.
+func (c *Client) probe(ctx context.Context, v interface{}, out interface{}) (bool, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return false, err
+	}
+	_, _, body, err := c.post(ctx, b, make(http.Header), &RequestStats{})
+	if err != nil {
+		return false, errors.Wrap(err, "graphql: probing capabilities")
+	}
+	return json.Unmarshal(body, out) == nil, nil
+}
+
+func (c *Client) probeGET(ctx context.Context) (bool, error) {
+	endpoint, err := c.resolveEndpoint(ctx)
+	if err != nil {
+		return false, err
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return false, err
+	}
+	q := u.Query()
+	q.Set("query", probeQuery)
+	u.RawQuery = q.Encode()
+	r, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return false, err
+	}
+	for name, values := range c.header {
+		r.Header[name] = values
+	}
+	r.Header.Set("Accept", c.acceptHeader())
+	if err := c.applyCredentials(r); err != nil {
+		return false, err
+	}
+	res, err := c.httpClient.Do(r.WithContext(ctx))
+	if err != nil {
+		return false, errors.Wrap(err, "graphql: probing capabilities")
+	}
+	defer res.Body.Close()
+	body, err := c.readBody(res.Body)
+	if err != nil {
+		return false, errors.Wrap(err, "graphql: probing capabilities")
+	}
+	var probe probeResponse
+	if res.StatusCode != http.StatusOK || json.Unmarshal(body, &probe) != nil {
+		return false, nil
+	}
+	return probe.ok(), nil
+}
+
+// probePersistedQueries sends the hash of the probe query without the
+// query. Servers that support persisted queries run it if they have it,
+// or ask for the query.
+func (c *Client) probePersistedQueries(ctx context.Context) (bool, error) {
+	var probe probeResponse
+	ok, err := c.probe(ctx, map[string]interface{}{
+		"extensions": map[string]interface{}{
+			"persistedQuery": map[string]interface{}{
+				"version":    1,
+				"sha256Hash": QueryHash(probeQuery),
+			},
+		},
+	}, &probe)
+	if err != nil || !ok {
+		return false, err
+	}
+	if probe.ok() {
+		return true, nil
+	}
+	for _, e := range probe.Errors {
+		if persistedQueryNotFound(e) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// probeBatch reports whether the server runs a batch of n probes.
+func (c *Client) probeBatch(ctx context.Context, n int) (bool, error) {
+	batch := make([]*Request, n)
+	for i := range batch {
+		batch[i] = NewRequest(probeQuery)
+	}
+	var probe []probeResponse
+	ok, err := c.probe(ctx, batch, &probe)
+	if err != nil || !ok || len(probe) != n {
+		return false, err
+	}
+	for i := range probe {
+		if !probe[i].ok() {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// probeMaxBatchSize finds the largest batch the server runs, by sending
+// batches of twice the size until one is rejected, and then searching
+// between the largest run and the smallest rejected. There is no
+// standard way to ask a server for its limit, so this is the only way
+// to find one. It is zero if batches of maxBatchProbe are run.
+func (c *Client) probeMaxBatchSize(ctx context.Context) (int, error) {
+	run, rejected := 1, 0
+	for n := 2; n <= maxBatchProbe; n *= 2 {
+		ok, err := c.probeBatch(ctx, n)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			rejected = n
+			break
+		}
+		run = n
+	}
+	if rejected == 0 {
+		return 0, nil
+	}
+	for rejected-run > 1 {
+		n := (run + rejected) / 2
+		ok, err := c.probeBatch(ctx, n)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			run = n
+		} else {
+			rejected = n
+		}
+	}
+	return run, nil
+}
+
+func (c *Client) probeSchema(ctx context.Context, caps *Capabilities) error {
+	var probe probeResponse
+	ok, err := c.probe(ctx, NewRequest(probeSchemaQuery), &probe)
+	if err != nil || !ok || !probe.ok() {
+		return err
+	}
+	var data struct {
+		Schema struct {
+			SubscriptionType *introspectionName
+			Directives       []introspectionName
+		} `json:"__schema"`
+	}
+	if err := json.Unmarshal(probe.Data, &data); err != nil {
+		return nil
+	}
+	caps.Subscriptions = data.Schema.SubscriptionType != nil
+	for _, d := range data.Schema.Directives {
+		switch d.Name {
+		case "defer":
+			caps.Defer = true
+		case "stream":
+			caps.Stream = true
+		}
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/joefitzgerald/graphql"
+	"github.com/joefitzgerald/graphql/graphqltest"
+	. "github.com/onsi/gomega"
+)
+
+func TestCapabilities(t *testing.T) {
+	RegisterTestingT(t)
+	var probes int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		probes++
+		Expect(r.Header.Get("Authorization")).Should(Equal("Bearer token"))
+		if r.Method == http.MethodGet {
+			Expect(r.URL.Query().Get("query")).Should(ContainSubstring("__typename"))
+			io.WriteString(w, `{"data":{"__typename":"Query"}}`)
+			return
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		if strings.HasPrefix(string(b), "[") {
+			io.WriteString(w, `{"errors":[{"message":"batching is not supported"}]}`)
+			return
+		}
+		var req struct {
+			Query      string
+			Extensions map[string]interface{}
+		}
+		json.Unmarshal(b, &req)
+		switch {
+		case req.Extensions["persistedQuery"] != nil:
+			io.WriteString(w, `{"errors":[{"message":"PersistedQueryNotFound","extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"}}]}`)
+		case strings.Contains(req.Query, "__schema"):
+			io.WriteString(w, `{"data":{"__schema":{"subscriptionType":null,"directives":[{"name":"include"},{"name":"skip"},{"name":"defer"}]}}}`)
+		}
+	}))
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL, graphql.WithCredentials(graphql.BearerToken("token")))
+
+	caps, err := client.Capabilities(context.Background())
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(caps).Should(Equal(graphql.Capabilities{
+		GET:              true,
+		PersistedQueries: true,
+		Defer:            true,
+	}))
+	Expect(probes).Should(Equal(4))
+
+	caps, err = client.Capabilities(context.Background())
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(caps.GET).Should(BeTrue())
+	Expect(probes).Should(Equal(4))
+
+	srv.Close()
+	client = graphql.NewClient(srv.URL)
+	_, err = client.Capabilities(context.Background())
+	Expect(err).Should(HaveOccurred())
+}
+
+func TestCapabilitiesConcurrent(t *testing.T) {
+	RegisterTestingT(t)
+	var probes int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&probes, 1)
+		<-release
+		io.WriteString(w, `{"data":{"__typename":"Query"}}`)
+	}))
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			caps, err := client.Capabilities(context.Background())
+			if err != nil || !caps.GET {
+				t.Errorf("got %+v, %v", caps, err)
+			}
+		}()
+	}
+	// callers waiting for the probe give up on their own context
+	Eventually(func() int32 { return atomic.LoadInt32(&probes) }).Should(BeEquivalentTo(1))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := client.Capabilities(ctx)
+	Expect(err).Should(Equal(context.Canceled))
+	close(release)
+	wg.Wait()
+	Expect(atomic.LoadInt32(&probes)).Should(BeEquivalentTo(4))
+}
+
+func TestCapabilitiesFailureBackoff(t *testing.T) {
+	RegisterTestingT(t)
+	var requests int32
+	up := int32(0)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if atomic.LoadInt32(&up) == 0 {
+			panic(http.ErrAbortHandler)
+		}
+		io.WriteString(w, `{"data":{"__typename":"Query"}}`)
+	}))
+	defer srv.Close()
+	clock := graphqltest.NewClock(time.Now())
+	client := graphql.NewClient(srv.URL, graphql.WithClock(clock))
+
+	_, err := client.Capabilities(context.Background())
+	Expect(err).Should(HaveOccurred())
+	_, again := client.Capabilities(context.Background())
+	Expect(again).Should(Equal(err))
+	Expect(atomic.LoadInt32(&requests)).Should(BeEquivalentTo(1))
+
+	atomic.StoreInt32(&up, 1)
+	clock.Advance(time.Second)
+	caps, err := client.Capabilities(context.Background())
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(caps.GET).Should(BeTrue())
+}
+
+func TestCapabilitiesAllowList(t *testing.T) {
+	RegisterTestingT(t)
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		queries = append(queries, r.URL.Query().Get("query")+string(b))
+		io.WriteString(w, `{"data":{"__typename":"Query"}}`)
+	}))
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL, graphql.WithAllowList(graphql.NewAllowList()))
+
+	caps, err := client.Capabilities(context.Background())
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(caps).Should(Equal(graphql.Capabilities{}))
+	Expect(queries).Should(BeEmpty())
+
+	client = graphql.NewClient(srv.URL, graphql.WithAllowList(graphql.NewAllowList(graphql.QueryHash(`query CapabilitiesProbe { __typename }`))))
+	caps, err = client.Capabilities(context.Background())
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(caps.GET).Should(BeTrue())
+	Expect(queries).Should(HaveLen(3))
+	for _, q := range queries {
+		Expect(q).ShouldNot(ContainSubstring("__schema"))
+	}
+}
+
+func TestCapabilitiesMaxBatchSize(t *testing.T) {
+	RegisterTestingT(t)
+	limit := 5
+	var sizes []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var batch []json.RawMessage
+		if r.Method != http.MethodPost || json.NewDecoder(r.Body).Decode(&batch) != nil {
+			io.WriteString(w, `{"data":{"__typename":"Query"}}`)
+			return
+		}
+		sizes = append(sizes, len(batch))
+		if len(batch) > limit {
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"errors":[{"message":"batch is too large"}]}`)
+			return
+		}
+		io.WriteString(w, "["+strings.Repeat(`{"data":{"__typename":"Query"}},`, len(batch)-1)+`{"data":{"__typename":"Query"}}]`)
+	}))
+	defer srv.Close()
+
+	caps, err := graphql.NewClient(srv.URL).Capabilities(context.Background())
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(caps.Batching).Should(BeTrue())
+	Expect(caps.MaxBatchSize).Should(Equal(5))
+	Expect(sizes).Should(Equal([]int{1, 2, 4, 8, 6, 5}))
+
+	// servers that run the largest batch probed have no known limit
+	limit = 100
+	caps, err = graphql.NewClient(srv.URL).Capabilities(context.Background())
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(caps.Batching).Should(BeTrue())
+	Expect(caps.MaxBatchSize).Should(BeZero())
+}
@@ -0,0 +1,105 @@
+package graphql
+
+import (
+	"net/http"
+	"time"
+)
+
+// Capture is a full record of a call, for debugging. Headers and
+// variables are redacted, and response fields masked, by the client's
+// Redaction.
+type Capture struct {
+	// Operation is the request's MetricsName.
+	Operation     string
+	Query         string
+	OperationName string
+	Variables     map[string]interface{}
+	Header        http.Header
+	// Status, ResponseHeader and ResponseBody are from the HTTP
+	// response; they are empty if there was none.
+	Status         int
+	ResponseHeader http.Header
+	ResponseBody   []byte
+	// Errors are the GraphQL errors in the response.
+	Errors Errors
+	// Err is the error that failed the call, if any.
+	Err      error
+	Duration time.Duration
+}
+
+// CapturePolicy selects the calls to capture. A call is captured if it
+// is selected by any of the rules.
+type CapturePolicy struct {
+	// Rate is the fraction of calls captured at random, from 0 to 1.
+	Rate float64
+	// Errors captures calls that fail or return GraphQL errors.
+	Errors bool
+	// SlowerThan captures calls that take longer, unless it is zero.
+	SlowerThan time.Duration
+}
+
+func (p CapturePolicy) selects(capture *Capture, sample float64) bool {
+	switch {
+	case p.Errors && (capture.Err != nil || len(capture.Errors) > 0):
+		return true
+	case p.SlowerThan > 0 && capture.Duration > p.SlowerThan:
+		return true
+	}
+	return p.Rate > 0 && sample < p.Rate
+}
+
+// WithCapture makes the client record the calls selected by policy, and
+// pass them to sink, for debugging production traffic without logging
+// every call. Each request in a batch is captured with the batch's
+// headers and its own part of the response.
+//  NewClient(endpoint, WithCapture(CapturePolicy{Rate: 0.01, Errors: true}, func(c Capture) {
+//      debugStore.Save(c)
+//  }))
+func WithCapture(policy CapturePolicy, sink func(Capture)) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.capturePolicy = policy
+		client.captureSink = sink
+	})
+}
+
+// startCapture begins a capture of a call with the prepared request, or
+// returns nil if the client doesn't capture calls.
+func (c *Client) startCapture(req *Request, header http.Header) *Capture {
+	if c.captureSink == nil {
+		return nil
+	}
+	r := c.redactor()
+	return &Capture{
+		Operation:     req.MetricsName(),
+		Query:         req.Query,
+		OperationName: req.OperationName,
+		Variables:     r.Variables(req.Variables),
+		Header:        r.Header(header),
+	}
+}
+
+// captureResponse records the HTTP response of a captured call.
+func (c *Client) captureResponse(capture *Capture, res *http.Response, body []byte) {
+	if capture == nil {
+		return
+	}
+	capture.Status = res.StatusCode
+	capture.ResponseHeader = c.redactor().Header(res.Header)
+	capture.ResponseBody = c.redactor().Response(body)
+}
+
+// finishCapture completes a capture, passing it to the sink if the
+// policy selects it.
+func (c *Client) finishCapture(capture *Capture, start time.Time, errs Errors, err error) {
+	if capture == nil {
+		return
+	}
+	capture.Duration = c.since(start)
+	capture.Errors = errs
+	capture.Err = err
+	if !c.capturePolicy.selects(capture, c.sample()) {
+		return
+	}
+	defer c.recoverHook("capture sink", nil)
+	c.captureSink(*capture)
+}
@@ -0,0 +1,69 @@
+package graphql_test
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestCapture(t *testing.T) {
+	RegisterTestingT(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Slow") != "" {
+			time.Sleep(20 * time.Millisecond)
+		}
+		w.Header().Set("X-Server", "a")
+		body, _ := ioutil.ReadAll(r.Body)
+		if string(body) == `{"query":"{ fail }"}` {
+			io.WriteString(w, `{"errors":[{"message":"boom"}]}`)
+			return
+		}
+		io.WriteString(w, `{"data":{"ok":true}}`)
+	}))
+	defer srv.Close()
+	var captures []graphql.Capture
+	client := graphql.NewClient(srv.URL, graphql.WithCapture(graphql.CapturePolicy{
+		Errors:     true,
+		SlowerThan: 10 * time.Millisecond,
+	}, func(c graphql.Capture) {
+		captures = append(captures, c)
+	}))
+	ctx := context.Background()
+
+	Expect(client.Run(ctx, graphql.NewRequest(`{ ok }`), nil)).Should(Succeed())
+	Expect(captures).Should(BeEmpty())
+
+	Expect(client.Run(ctx, graphql.NewRequest(`{ fail }`), nil)).ShouldNot(Succeed())
+	Expect(captures).Should(HaveLen(1))
+	Expect(captures[0].Query).Should(Equal(`{ fail }`))
+	Expect(captures[0].Status).Should(Equal(http.StatusOK))
+	Expect(captures[0].ResponseHeader.Get("X-Server")).Should(Equal("a"))
+	Expect(string(captures[0].ResponseBody)).Should(Equal(`{"errors":[{"message":"boom"}]}`))
+	Expect(captures[0].Errors).Should(HaveLen(1))
+
+	req := graphql.NewRequest(`query Slow($password: String) { ok }`)
+	req.Var("password", "hunter2")
+	req.Header = http.Header{"X-Slow": {"1"}, "Authorization": {"Bearer secret"}}
+	Expect(client.Run(ctx, req, nil)).Should(Succeed())
+	Expect(captures).Should(HaveLen(2))
+	Expect(captures[1].Operation).Should(Equal("Slow"))
+	Expect(captures[1].Duration).Should(BeNumerically(">", 10*time.Millisecond))
+	Expect(captures[1].Variables["password"]).ShouldNot(Equal("hunter2"))
+	Expect(captures[1].Header.Get("Authorization")).ShouldNot(ContainSubstring("secret"))
+
+	captures = nil
+	client = graphql.NewClient("http://127.0.0.1:1", graphql.WithCapture(graphql.CapturePolicy{Rate: 1}, func(c graphql.Capture) {
+		captures = append(captures, c)
+	}))
+	Expect(client.Run(ctx, graphql.NewRequest(`{ ok }`), nil)).ShouldNot(Succeed())
+	Expect(captures).Should(HaveLen(1))
+	Expect(captures[0].Err).Should(HaveOccurred())
+	Expect(captures[0].Status).Should(BeZero())
+}
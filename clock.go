@@ -0,0 +1,92 @@
+package graphql
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Clock tells the time. Tests can set a Clock that they control with
+// WithClock, so that timings, such as those in audit records and health
+// trackers, are deterministic.
+type Clock interface {
+	Now() time.Time
+}
+
+// TickingClock is a Clock that also ticks, so that periodic work, such
+// as refreshing a Snapshot, follows it. Periodic work with other clocks
+// follows real time.
+type TickingClock interface {
+	Clock
+	// Tick sends the time on the channel every d, dropping ticks for
+	// slow receivers, as a time.Ticker does, until stop is called.
+	Tick(d time.Duration) (ticks <-chan time.Time, stop func())
+}
+
+// Random is a source of random numbers in [0, 1). *rand.Rand is one.
+type Random interface {
+	Float64() float64
+}
+
+// WithClock sets the clock the client times calls with. Timeouts still
+// use real time. If the clock is a TickingClock, periodic work, such as
+// refreshing snapshots, follows it too.
+//  NewClient(endpoint, WithClock(clock))
+func WithClock(clock Clock) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.clock = clock
+	})
+}
+
+// WithRandom sets the source of random numbers the client samples calls
+// with, for shadowing and capture. Calls to r are serialised, so a
+// *rand.Rand can be used.
+//  NewClient(endpoint, WithRandom(rand.New(rand.NewSource(1))))
+func WithRandom(r Random) ClientOption {
+	l := &lockedRandom{r: r}
+	return ClientOption(func(client *Client) {
+		client.random = l
+	})
+}
+
+type lockedRandom struct {
+	mu sync.Mutex
+	r  Random
+}
+
+func (l *lockedRandom) Float64() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Float64()
+}
+
+// now gets the time from the client's clock.
+func (c *Client) now() time.Time {
+	if c.clock == nil {
+		return time.Now()
+	}
+	return c.clock.Now()
+}
+
+// since gets the time elapsed on the client's clock since t.
+func (c *Client) since(t time.Time) time.Duration {
+	return c.now().Sub(t)
+}
+
+// tick sends the time every d, by the client's clock if it ticks, or
+// else by real time, until stop is called.
+func (c *Client) tick(d time.Duration) (ticks <-chan time.Time, stop func()) {
+	if clock, ok := c.clock.(TickingClock); ok {
+		return clock.Tick(d)
+	}
+	ticker := time.NewTicker(d)
+	return ticker.C, ticker.Stop
+}
+
+// sample gets a random number in [0, 1) from the client's source.
+func (c *Client) sample() float64 {
+	if c.random == nil {
+		return rand.Float64()
+	}
+	return c.random.Float64()
+}
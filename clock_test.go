@@ -0,0 +1,60 @@
+package graphql_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+type fixedRandom float64
+
+func (r fixedRandom) Float64() float64 {
+	return float64(r)
+}
+
+func TestClockAndRandom(t *testing.T) {
+	RegisterTestingT(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{"ok":true}}`)
+	}))
+	defer srv.Close()
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	var records []graphql.AuditRecord
+	var captures []graphql.Capture
+	options := func(r graphql.Random) []graphql.ClientOption {
+		return []graphql.ClientOption{
+			graphql.WithClock(fixedClock(start)),
+			graphql.WithRandom(r),
+			graphql.WithAudit(func(r graphql.AuditRecord) {
+				records = append(records, r)
+			}),
+			graphql.WithCapture(graphql.CapturePolicy{Rate: 0.5}, func(c graphql.Capture) {
+				captures = append(captures, c)
+			}),
+		}
+	}
+
+	client := graphql.NewClient(srv.URL, options(fixedRandom(0.4))...)
+	result, err := graphql.Do[map[string]interface{}](context.Background(), client, graphql.NewRequest(`mutation M { ok }`))
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(result.Duration).Should(BeZero())
+	Expect(records).Should(HaveLen(1))
+	Expect(records[0].Time).Should(Equal(start))
+	Expect(captures).Should(HaveLen(1))
+
+	client = graphql.NewClient(srv.URL, options(fixedRandom(0.6))...)
+	Expect(client.Run(context.Background(), graphql.NewRequest(`mutation M { ok }`), nil)).Should(Succeed())
+	Expect(captures).Should(HaveLen(1))
+}
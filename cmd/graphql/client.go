@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/joefitzgerald/graphql"
+	"github.com/pkg/errors"
+)
+
+// clientFlags are the flags shared by commands that talk to a server.
+type clientFlags struct {
+	endpoint string
+	profile  string
+	headers  headerFlags
+	timeout  time.Duration
+}
+
+// defaultTimeout is the timeout when neither -timeout nor the profile
+// gives one.
+const defaultTimeout = 30 * time.Second
+
+func (f *clientFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.endpoint, "endpoint", "", "URL of the GraphQL server")
+	fs.StringVar(&f.profile, "profile", os.Getenv("GRAPHQL_PROFILE"), "`name` of the profile in the config file to use; defaults to $GRAPHQL_PROFILE")
+	fs.Var(&f.headers, "H", "header to send, as `Name: value` (repeatable)")
+	fs.DurationVar(&f.timeout, "timeout", 0, "timeout for each request; defaults to the profile's, or 30s")
+}
+
+// client makes a client for the profile, if any, with the endpoint,
+// headers and timeout given by flags instead. The default profile is
+// only used when no endpoint is given, so its credentials aren't sent
+// to other servers. It sets the endpoint to the one used.
+func (f *clientFlags) client() (*graphql.Client, error) {
+	var p *profile
+	if f.profile != "" || f.endpoint == "" {
+		var err error
+		if p, err = loadProfile(f.profile); err != nil {
+			return nil, err
+		}
+	}
+	var cfg graphql.ClientConfig
+	var opts []graphql.ClientOption
+	if p != nil {
+		cfg = p.ClientConfig
+		if p.TokenCommand != "" {
+			opts = append(opts, graphql.WithCredentials(&tokenCommand{command: p.TokenCommand}))
+		}
+	}
+	if f.endpoint != "" {
+		cfg.Endpoint = f.endpoint
+	}
+	if cfg.Endpoint == "" {
+		return nil, errors.New("-endpoint or -profile is required")
+	}
+	f.endpoint = cfg.Endpoint
+	if f.timeout > 0 {
+		cfg.Timeout = graphql.Duration(f.timeout)
+	} else if cfg.Timeout == 0 {
+		cfg.Timeout = graphql.Duration(defaultTimeout)
+	}
+	// headers given as flags replace the profile's
+	headers := make(map[string]string, len(cfg.Headers)+len(f.headers))
+	for name, value := range cfg.Headers {
+		headers[http.CanonicalHeaderKey(name)] = value
+	}
+	for _, h := range f.headers {
+		headers[http.CanonicalHeaderKey(h[0])] = h[1]
+	}
+	cfg.Headers = headers
+	return graphql.NewClientFromConfig(cfg, opts...)
+}
+
+// headerFlags collects repeated -H flags.
+type headerFlags [][2]string
+
+func (h *headerFlags) String() string {
+	s := make([]string, len(*h))
+	for i, kv := range *h {
+		s[i] = kv[0] + ": " + kv[1]
+	}
+	return strings.Join(s, ", ")
+}
+
+func (h *headerFlags) Set(s string) error {
+	i := strings.Index(s, ":")
+	if i <= 0 {
+		return errors.Errorf("header %q is not in the form Name: value", s)
+	}
+	*h = append(*h, [2]string{strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+1:])})
+	return nil
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joefitzgerald/graphql"
+	"github.com/pkg/errors"
+)
+
+// schemaFlags are the flags of commands that need a schema, from a
+// snapshot file or by introspecting the server.
+type schemaFlags struct {
+	clientFlags
+	file     string
+	cacheTTL time.Duration
+}
+
+func (f *schemaFlags) register(fs *flag.FlagSet) {
+	f.clientFlags.register(fs)
+	fs.StringVar(&f.file, "schema", "", "schema snapshot to use instead of introspecting the server")
+	fs.DurationVar(&f.cacheTTL, "cache-ttl", time.Hour, "how long to cache the introspected schema; 0 turns off the cache")
+}
+
+// schema loads the schema from the snapshot file, or the server. Schemas
+// introspected from a server are cached in the user's cache directory,
+// and revalidated once they are older than -cache-ttl.
+func (f *schemaFlags) schema() (*graphql.Schema, error) {
+	if f.file != "" {
+		b, err := ioutil.ReadFile(f.file)
+		if err != nil {
+			return nil, err
+		}
+		schema, err := graphql.ParseSchema(b)
+		return schema, errors.Wrap(err, f.file)
+	}
+	client, err := f.client()
+	if err != nil {
+		return nil, errors.Wrap(err, "no -schema")
+	}
+	if f.cacheTTL <= 0 {
+		return client.Introspect(context.Background())
+	}
+	return graphql.NewSchemaCache(f.cacheTTL, schemaCacheDir()).Schema(context.Background(), client)
+}
+
+// schemaCacheDir gets the directory introspected schemas are cached in,
+// or "" if the user has no cache directory.
+func schemaCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "graphql", "schemas")
+}
+
+func completeCommand(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("complete", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var sf schemaFlags
+	sf.register(fs)
+	opType := fs.String("type", "query", "operation `type` the path starts from: query, mutation or subscription")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: graphql complete [-schema file | -endpoint url] [-type query] path")
+		fmt.Fprintln(stderr, "completes a path of fields, such as user.fri, or the arguments of a field, such as user(")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return errUsage
+	}
+	schema, err := sf.schema()
+	if err != nil {
+		return err
+	}
+	for _, c := range completions(schema, *opType, fs.Arg(0)) {
+		fmt.Fprintln(stdout, c)
+	}
+	return nil
+}
+
+// completions gets the completions of a dot-separated path of fields
+// from the root of the operation type: the fields, or if the last
+// segment has an opening parenthesis, the arguments, that the last
+// segment is a prefix of.
+func completions(schema *graphql.Schema, opType, path string) []string {
+	t := schema.RootType(opType)
+	segments := strings.Split(path, ".")
+	for _, name := range segments[:len(segments)-1] {
+		if t == nil {
+			return nil
+		}
+		if i := strings.Index(name, "("); i >= 0 {
+			name = name[:i]
+		}
+		f := t.Field(name)
+		if f == nil {
+			return nil
+		}
+		t = schema.Type(f.Type.NamedType())
+	}
+	if t == nil {
+		return nil
+	}
+	prefix := strings.Join(segments[:len(segments)-1], ".")
+	if prefix != "" {
+		prefix += "."
+	}
+	last := segments[len(segments)-1]
+	var out []string
+	if i := strings.Index(last, "("); i >= 0 {
+		f := t.Field(last[:i])
+		if f == nil {
+			return nil
+		}
+		// complete the last argument after any given in full
+		given := last[:i+1]
+		partial := last[i+1:]
+		if j := strings.LastIndexAny(partial, ", "); j >= 0 {
+			given += partial[:j+1]
+			partial = partial[j+1:]
+		}
+		for _, arg := range f.Args {
+			if strings.HasPrefix(arg.Name, partial) {
+				out = append(out, prefix+given+arg.Name+":")
+			}
+		}
+		sort.Strings(out)
+		return out
+	}
+	for _, f := range t.Fields {
+		if strings.HasPrefix(f.Name, last) && !strings.HasPrefix(f.Name, "__") {
+			out = append(out, prefix+f.Name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func init() {
+	// registered here, since it lists the commands
+	commands["completion"] = command{
+		usage: "print a shell completion script",
+		run:   completionCommand,
+	}
+}
+
+func completionCommand(args []string, stdout, stderr io.Writer) error {
+	if len(args) != 1 || args[0] != "bash" && args[0] != "zsh" {
+		fmt.Fprintln(stderr, "usage: graphql completion bash|zsh")
+		fmt.Fprintln(stderr, "prints a shell completion script, to be sourced from your shell's profile:")
+		fmt.Fprintln(stderr, "  source <(graphql completion bash)")
+		return errUsage
+	}
+	if args[0] == "zsh" {
+		fmt.Fprintln(stdout, "autoload -U +X bashcompinit && bashcompinit")
+	}
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(stdout, bashCompletion, strings.Join(names, " "))
+	return nil
+}
+
+// bashCompletion completes command names, and paths given to graphql
+// complete, using the other flags on the command line.
+const bashCompletion = `_graphql() {
+  local cur=${COMP_WORDS[COMP_CWORD]}
+  if [ "$COMP_CWORD" -eq 1 ]; then
+    COMPREPLY=($(compgen -W "%s" -- "$cur"))
+    return
+  fi
+  if [ "${COMP_WORDS[1]}" = complete ] && [ "${cur#-}" = "$cur" ]; then
+    local IFS=$'\n'
+    COMPREPLY=($("${COMP_WORDS[0]}" complete "${COMP_WORDS[@]:2:COMP_CWORD-2}" "$cur" 2>/dev/null))
+    compopt -o nospace 2>/dev/null
+    return
+  fi
+  COMPREPLY=($(compgen -f -- "$cur"))
+}
+complete -F _graphql graphql
+`
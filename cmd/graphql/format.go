@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/joefitzgerald/graphql"
+	"github.com/pkg/errors"
+)
+
+func formatCommand(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("format", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	write := fs.Bool("w", false, "write the result to the files instead of standard output")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: graphql format [-w] [file ...]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() == 0 {
+		if *write {
+			fmt.Fprintln(stderr, "graphql: -w needs files to write")
+			return errUsage
+		}
+		b, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return errors.Wrap(err, "reading standard input")
+		}
+		s, err := graphql.Format(string(b))
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(stdout, s)
+		return err
+	}
+	for _, file := range fs.Args() {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		s, err := graphql.Format(string(b))
+		if err != nil {
+			return errors.Wrap(err, file)
+		}
+		if *write {
+			if s != string(b) {
+				if err := ioutil.WriteFile(file, []byte(s), 0644); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if _, err := io.WriteString(stdout, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// stringsFlag is a flag that can be given more than once.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(s string) error {
+	*f = append(*f, s)
+	return nil
+}
+
+// lintResult is a use of a deprecated field or value, for -output.
+type lintResult struct {
+	File       string `json:"file"`
+	Line       int    `json:"line,omitempty"`
+	Column     int    `json:"column,omitempty"`
+	Fragment   string `json:"fragment,omitempty"`
+	Coordinate string `json:"coordinate"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+func lintCommand(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var sf schemaFlags
+	sf.register(fs)
+	var fragmentFiles stringsFlag
+	fs.Var(&fragmentFiles, "fragments", "`file` of shared fragments the documents use; may be repeated")
+	var of outputFlags
+	of.register(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: graphql lint [-schema schema.json | -endpoint url] [-fragments file] [-output format] file ...")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if err := of.parse(); err != nil {
+		return err
+	}
+	if sf.file == "" && sf.endpoint == "" && sf.profile == "" || fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+	schema, err := sf.schema()
+	if err != nil {
+		return err
+	}
+	var fragments []string
+	for _, file := range fragmentFiles {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		fragments = append(fragments, string(b))
+	}
+	p := of.printer(stdout)
+	var found int
+	for _, file := range fs.Args() {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		for _, d := range schema.Deprecations(string(b), fragments...) {
+			sep := ":"
+			if d.Location == nil {
+				sep = ": "
+			}
+			r := lintResult{File: file, Fragment: d.Fragment, Coordinate: d.Coordinate, Reason: d.Reason}
+			if d.Location != nil {
+				r.Line, r.Column = d.Location.Line, d.Location.Column
+			}
+			if err := p.print(r, file+sep+d.String()); err != nil {
+				return err
+			}
+			found++
+		}
+	}
+	if err := p.close(); err != nil {
+		return err
+	}
+	if found > 0 {
+		return errors.Errorf("found %d uses of deprecated fields or values", found)
+	}
+	return nil
+}
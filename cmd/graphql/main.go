@@ -0,0 +1,142 @@
+// Command graphql is a command line client for GraphQL services.
+//
+//  graphql schema fetch -endpoint https://example.com/graphql > schema.json
+//  graphql schema check -endpoint https://example.com/graphql -file schema.json -fail breaking
+//  graphql format -w queries/*.graphql
+//  graphql lint -schema schema.json queries/*.graphql
+//  graphql complete -endpoint https://example.com/graphql user.fri
+//  source <(graphql completion bash)
+//  graphql repl -endpoint https://example.com/graphql
+//  graphql run -endpoint https://example.com/graphql -dir queries -output ndjson
+//
+// Commands that talk to a server can instead use a named profile, with
+// -profile or $GRAPHQL_PROFILE, from the JSON config file at
+// $GRAPHQL_CONFIG or graphql/config in the user's config directory. A
+// profile has an endpoint, headers, and auth, which can take a token
+// from the environment or from a command such as a keychain lookup:
+//  {"profiles": {"prod": {"endpoint": "https://example.com/graphql", "tokenCommand": "pass show graphql"}}}
+//
+// Commands that report results take -output json, ndjson, table or
+// go-template=template to print them for other programs. Commands exit
+// with status 1 on errors, 2 on bad arguments, and 3 when the server
+// returned GraphQL errors but otherwise worked.
+//
+// Run graphql help for the list of commands.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/joefitzgerald/graphql"
+	"github.com/pkg/errors"
+)
+
+// Exit statuses.
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+	// exitGraphQL is for GraphQL errors in responses, to tell them
+	// apart from the server or the network failing.
+	exitGraphQL = 3
+)
+
+// errUsage is returned by commands given bad arguments; the flag
+// package has already explained why.
+var errUsage = errors.New("usage")
+
+// statusError is an error to exit with a status other than exitError.
+type statusError struct {
+	status int
+	err    error
+}
+
+func (e *statusError) Error() string {
+	return e.err.Error()
+}
+
+type command struct {
+	usage string
+	run   func(args []string, stdout, stderr io.Writer) error
+}
+
+var commands = map[string]command{
+	"complete": {
+		usage: "complete paths of fields and arguments from the schema",
+		run:   completeCommand,
+	},
+	"format": {
+		usage: "print query documents in canonical form",
+		run:   formatCommand,
+	},
+	"lint": {
+		usage: "report uses of deprecated fields in query documents",
+		run:   lintCommand,
+	},
+	"repl": {
+		usage: "run queries interactively",
+		run:   replCommand,
+	},
+	"run": {
+		usage: "run the operations in query documents, reporting failures",
+		run:   runCommand,
+	},
+	"schema": {
+		usage: "fetch or check schema snapshots",
+		run:   schemaCommand,
+	},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "-help" {
+		usage(stderr)
+		return exitUsage
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "graphql: unknown command %q\n", args[0])
+		usage(stderr)
+		return exitUsage
+	}
+	err := cmd.run(args[1:], stdout, stderr)
+	switch {
+	case err == nil:
+		return exitOK
+	case err == errUsage:
+		return exitUsage
+	}
+	fmt.Fprintf(stderr, "graphql: %v\n", err)
+	return exitStatus(err)
+}
+
+// exitStatus gets the status to exit with after the error.
+func exitStatus(err error) int {
+	if e, ok := err.(*statusError); ok {
+		return e.status
+	}
+	if _, ok := errors.Cause(err).(graphql.Errors); ok {
+		return exitGraphQL
+	}
+	return exitError
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: graphql <command> [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-10s %s\n", name, commands[name].usage)
+	}
+}
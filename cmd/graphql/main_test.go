@@ -0,0 +1,384 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func schemaServer(schema []byte) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(schema)
+	}))
+}
+
+func TestRunUsage(t *testing.T) {
+	RegisterTestingT(t)
+	var stdout, stderr bytes.Buffer
+	Expect(run(nil, &stdout, &stderr)).Should(Equal(exitUsage))
+	Expect(stderr.String()).Should(ContainSubstring("schema"))
+
+	stderr.Reset()
+	Expect(run([]string{"nope"}, &stdout, &stderr)).Should(Equal(exitUsage))
+	Expect(stderr.String()).Should(ContainSubstring(`unknown command "nope"`))
+}
+
+func TestSchemaCheck(t *testing.T) {
+	RegisterTestingT(t)
+	vendored, err := ioutil.ReadFile("../../testdata/schema.json")
+	Expect(err).ShouldNot(HaveOccurred())
+	dir, err := ioutil.TempDir("", "graphql")
+	Expect(err).ShouldNot(HaveOccurred())
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "schema.json")
+	Expect(ioutil.WriteFile(file, vendored, 0644)).Should(Succeed())
+
+	srv := schemaServer(vendored)
+	defer srv.Close()
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"schema", "check", "-endpoint", srv.URL, "-file", file, "-fail", "any"}, &stdout, &stderr)
+	Expect(code).Should(Equal(exitOK))
+	Expect(stdout.String()).Should(ContainSubstring("is up to date"))
+
+	live := strings.Replace(string(vendored), `"name": "nickname"`, `"name": "nick"`, 1)
+	srv2 := schemaServer([]byte(live))
+	defer srv2.Close()
+	stdout.Reset()
+	code = run([]string{"schema", "check", "-endpoint", srv2.URL, "-file", file, "-fail", "breaking", "-update"}, &stdout, &stderr)
+	Expect(code).Should(Equal(exitError))
+	Expect(stdout.String()).Should(ContainSubstring("BREAKING CreateUserInput.nickname: input field was removed"))
+	Expect(stdout.String()).Should(ContainSubstring("SAFE CreateUserInput.nick: input field was added"))
+	Expect(stderr.String()).Should(ContainSubstring("has drifted"))
+
+	// updated snapshot matches the server
+	stdout.Reset()
+	code = run([]string{"schema", "check", "-endpoint", srv2.URL, "-file", file, "-fail", "any"}, &stdout, &stderr)
+	Expect(code).Should(Equal(exitOK))
+}
+
+func TestSchemaFetch(t *testing.T) {
+	RegisterTestingT(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Expect(r.Header.Get("Authorization")).Should(Equal("Bearer abc"))
+		f, _ := os.Open("../../testdata/schema.json")
+		defer f.Close()
+		io.Copy(w, f)
+	}))
+	defer srv.Close()
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"schema", "fetch", "-endpoint", srv.URL, "-H", "Authorization: Bearer abc"}, &stdout, &stderr)
+	Expect(code).Should(Equal(exitOK), stderr.String())
+	Expect(stdout.String()).Should(ContainSubstring(`"__schema"`))
+}
+
+func TestFormat(t *testing.T) {
+	RegisterTestingT(t)
+	dir, err := ioutil.TempDir("", "graphql")
+	Expect(err).ShouldNot(HaveOccurred())
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "user.graphql")
+	Expect(ioutil.WriteFile(file, []byte(`query User { user { id } }`), 0644)).Should(Succeed())
+
+	var stdout, stderr bytes.Buffer
+	Expect(run([]string{"format", file}, &stdout, &stderr)).Should(Equal(exitOK))
+	Expect(stdout.String()).Should(Equal("query User {\n  user {\n    id\n  }\n}\n"))
+
+	stdout.Reset()
+	Expect(run([]string{"format", "-w", file}, &stdout, &stderr)).Should(Equal(exitOK))
+	Expect(stdout.String()).Should(BeEmpty())
+	b, err := ioutil.ReadFile(file)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(string(b)).Should(Equal("query User {\n  user {\n    id\n  }\n}\n"))
+
+	Expect(ioutil.WriteFile(file, []byte(`{ user `), 0644)).Should(Succeed())
+	Expect(run([]string{"format", file}, &stdout, &stderr)).Should(Equal(exitError))
+}
+
+func TestLint(t *testing.T) {
+	RegisterTestingT(t)
+	dir, err := ioutil.TempDir("", "graphql")
+	Expect(err).ShouldNot(HaveOccurred())
+	defer os.RemoveAll(dir)
+	schema := filepath.Join("..", "..", "testdata", "schema.json")
+	file := filepath.Join(dir, "users.graphql")
+	Expect(ioutil.WriteFile(file, []byte("query Users {\n  users { email }\n}\n"), 0644)).Should(Succeed())
+
+	var stdout, stderr bytes.Buffer
+	Expect(run([]string{"lint", "-schema", schema, file}, &stdout, &stderr)).Should(Equal(exitError))
+	Expect(stdout.String()).Should(Equal(file + ":2:11: User.email is deprecated: Use emails.\n"))
+	Expect(stderr.String()).Should(ContainSubstring("found 1 uses of deprecated fields or values"))
+
+	Expect(ioutil.WriteFile(file, []byte(`query Users { users { emails } }`), 0644)).Should(Succeed())
+	stdout.Reset()
+	Expect(run([]string{"lint", "-schema", schema, file}, &stdout, &stderr)).Should(Equal(exitOK))
+	Expect(stdout.String()).Should(BeEmpty())
+
+	Expect(run([]string{"lint", file}, &stdout, &stderr)).Should(Equal(exitUsage))
+}
+
+func TestComplete(t *testing.T) {
+	RegisterTestingT(t)
+	complete := func(args ...string) []string {
+		var stdout, stderr bytes.Buffer
+		code := run(append([]string{"complete", "-schema", "../../testdata/schema.json"}, args...), &stdout, &stderr)
+		Expect(code).Should(Equal(exitOK), stderr.String())
+		return strings.FieldsFunc(stdout.String(), func(r rune) bool { return r == '\n' })
+	}
+	Expect(complete("us")).Should(Equal([]string{"user", "users"}))
+	Expect(complete("user.fri")).Should(Equal([]string{"user.friends"}))
+	Expect(complete("users.friends.e")).Should(Equal([]string{"users.friends.email", "users.friends.emails"}))
+	Expect(complete("users(")).Should(Equal([]string{"users(first:", "users(role:"}))
+	Expect(complete("users(first: 10, r")).Should(Equal([]string{"users(first: 10, role:"}))
+	Expect(complete("user(id: 1).na")).Should(Equal([]string{"user(id: 1).name"}))
+	Expect(complete("-type", "mutation", "")).Should(Equal([]string{"createUser"}))
+	Expect(complete("nope.x")).Should(BeEmpty())
+}
+
+func TestCompleteCachesSchema(t *testing.T) {
+	RegisterTestingT(t)
+	dir, err := ioutil.TempDir("", "graphql")
+	Expect(err).ShouldNot(HaveOccurred())
+	defer os.RemoveAll(dir)
+	os.Setenv("XDG_CACHE_HOME", dir)
+	defer os.Unsetenv("XDG_CACHE_HOME")
+	schema, err := ioutil.ReadFile("../../testdata/schema.json")
+	Expect(err).ShouldNot(HaveOccurred())
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write(schema)
+	}))
+	defer srv.Close()
+
+	for i := 0; i < 2; i++ {
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"complete", "-endpoint", srv.URL, "user.na"}, &stdout, &stderr)
+		Expect(code).Should(Equal(exitOK), stderr.String())
+		Expect(stdout.String()).Should(Equal("user.name\n"))
+	}
+	Expect(calls).Should(Equal(1))
+
+	// lint shares the cached schema
+	file := filepath.Join(dir, "users.graphql")
+	Expect(ioutil.WriteFile(file, []byte(`query Users { users { emails } }`), 0644)).Should(Succeed())
+	var stdout, stderr bytes.Buffer
+	Expect(run([]string{"lint", "-endpoint", srv.URL, file}, &stdout, &stderr)).Should(Equal(exitOK), stderr.String())
+	Expect(calls).Should(Equal(1))
+
+	Expect(run([]string{"complete", "-endpoint", srv.URL, "-cache-ttl", "0", "user.na"}, &stdout, &stderr)).Should(Equal(exitOK))
+	Expect(calls).Should(Equal(2))
+}
+
+func TestCompletion(t *testing.T) {
+	RegisterTestingT(t)
+	var stdout, stderr bytes.Buffer
+	Expect(run([]string{"completion", "bash"}, &stdout, &stderr)).Should(Equal(exitOK))
+	Expect(stdout.String()).Should(ContainSubstring(`compgen -W "complete completion format lint repl run schema"`))
+	Expect(run([]string{"completion", "fish"}, &stdout, &stderr)).Should(Equal(exitUsage))
+}
+
+func TestREPL(t *testing.T) {
+	RegisterTestingT(t)
+	dir, err := ioutil.TempDir("", "graphql")
+	Expect(err).ShouldNot(HaveOccurred())
+	defer os.RemoveAll(dir)
+	history := filepath.Join(dir, "history")
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query     string
+			Variables map[string]interface{}
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		queries = append(queries, req.Query)
+		if req.Variables["id"] == nil {
+			io.WriteString(w, `{"data":null,"errors":[{"message":"id is required"}]}`)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"user": req.Variables}})
+	}))
+	defer srv.Close()
+	defer func() { stdin = os.Stdin }()
+
+	stdin = strings.NewReader(`query ($id: ID!) {
+  user(id: $id) { name }
+}
+:set id 42
+:set name Ann Lee
+:vars
+:run 1
+:history
+:bogus
+:quit
+`)
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"repl", "-endpoint", srv.URL, "-history", history}, &stdout, &stderr)
+	Expect(code).Should(Equal(exitOK), stderr.String())
+	Expect(queries).Should(HaveLen(2))
+	Expect(queries[0]).Should(Equal("query ($id: ID!) {\n  user(id: $id) { name }\n}"))
+	Expect(stdout.String()).Should(ContainSubstring("   ...> "))
+	Expect(stdout.String()).Should(ContainSubstring(`"message": "id is required"`))
+	Expect(stdout.String()).Should(ContainSubstring("$id = 42\n$name = \"Ann Lee\"\n"))
+	Expect(stdout.String()).Should(ContainSubstring(`"id": 42`))
+	Expect(stdout.String()).Should(ContainSubstring("1: query ($id: ID!) {   user(id: $id) { name } }\n"))
+	Expect(stderr.String()).Should(ContainSubstring("unknown command :bogus"))
+
+	stdin = strings.NewReader(":history\n")
+	stdout.Reset()
+	Expect(run([]string{"repl", "-endpoint", srv.URL, "-history", history}, &stdout, &stderr)).Should(Equal(exitOK))
+	Expect(stdout.String()).Should(ContainSubstring("1: query ($id: ID!)"))
+}
+
+func TestRunDir(t *testing.T) {
+	RegisterTestingT(t)
+	dir, err := ioutil.TempDir("", "graphql")
+	Expect(err).ShouldNot(HaveOccurred())
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"users.graphql":  "query Users { users { id } }\nquery Admins { users(role: ADMIN) { id } }",
+		"user.graphql":   "query User($id: ID!) { user(id: $id) { id } }",
+		"user.json":      `{"id": "1"}`,
+		"broken.graphql": "query {",
+		"notes.txt":      "not a query",
+	}
+	for name, content := range files {
+		Expect(ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)).Should(Succeed())
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphql.Request
+		json.NewDecoder(r.Body).Decode(&req)
+		switch {
+		case req.OperationName == "Admins":
+			io.WriteString(w, `{"errors":[{"message":"forbidden"}]}`)
+		case req.OperationName == "User" && req.Variables["id"] != "1":
+			io.WriteString(w, `{"errors":[{"message":"no id"}]}`)
+		default:
+			io.WriteString(w, `{"data":{}}`)
+		}
+	}))
+	defer srv.Close()
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"run", "-endpoint", srv.URL, "-dir", dir}, &stdout, &stderr)
+	Expect(code).Should(Equal(exitError))
+	out := stdout.String()
+	Expect(out).Should(ContainSubstring("FAIL " + filepath.Join(dir, "broken.graphql") + ": "))
+	Expect(out).Should(MatchRegexp(`PASS .*user.graphql User \(\d+m?s\)`))
+	Expect(out).Should(MatchRegexp(`PASS .*users.graphql Users \(\d+m?s\)`))
+	Expect(out).Should(ContainSubstring("users.graphql Admins: graphql: forbidden"))
+	Expect(out).Should(ContainSubstring("4 operations, 2 failed"))
+	Expect(stderr.String()).Should(ContainSubstring("2 of 4 operations failed"))
+
+	stdout.Reset()
+	code = run([]string{"run", "-endpoint", srv.URL, filepath.Join(dir, "user.graphql")}, &stdout, &stderr)
+	Expect(code).Should(Equal(exitOK))
+	Expect(stdout.String()).Should(ContainSubstring("1 operations, 0 failed"))
+}
+
+func TestOutput(t *testing.T) {
+	RegisterTestingT(t)
+	dir, err := ioutil.TempDir("", "graphql")
+	Expect(err).ShouldNot(HaveOccurred())
+	defer os.RemoveAll(dir)
+	schema := filepath.Join("..", "..", "testdata", "schema.json")
+	file := filepath.Join(dir, "users.graphql")
+	Expect(ioutil.WriteFile(file, []byte("query Users {\n  users { email }\n}\nquery Admins { users { id } }\n"), 0644)).Should(Succeed())
+
+	var stdout, stderr bytes.Buffer
+	Expect(run([]string{"lint", "-schema", schema, "-output", "json", file}, &stdout, &stderr)).Should(Equal(exitError))
+	Expect(stdout.String()).Should(MatchJSON(`[{"file":"` + file + `","line":2,"column":11,"coordinate":"User.email","reason":"Use emails."}]`))
+
+	stdout.Reset()
+	Expect(run([]string{"lint", "-schema", schema, "-output", "table", file}, &stdout, &stderr)).Should(Equal(exitError))
+	Expect(stdout.String()).Should(MatchRegexp(`^FILE +LINE +COLUMN +FRAGMENT +COORDINATE +REASON\n.*users.graphql +2 +11 +User.email +Use emails.\n$`))
+
+	stdout.Reset()
+	Expect(run([]string{"lint", "-schema", schema, "-output", "go-template={{.Coordinate}} at {{.Line}}", file}, &stdout, &stderr)).Should(Equal(exitError))
+	Expect(stdout.String()).Should(Equal("User.email at 2\n"))
+
+	Expect(run([]string{"lint", "-schema", schema, "-output", "xml", file}, &stdout, &stderr)).Should(Equal(exitError))
+	Expect(stderr.String()).Should(ContainSubstring("-output must be text, json, ndjson, table or go-template=template"))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphql.Request
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.OperationName == "Admins" {
+			io.WriteString(w, `{"errors":[{"message":"forbidden"}]}`)
+			return
+		}
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	stdout.Reset()
+	Expect(run([]string{"run", "-endpoint", srv.URL, "-output", "ndjson", file}, &stdout, &stderr)).Should(Equal(exitGraphQL))
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	Expect(lines).Should(HaveLen(2))
+	var users, admins map[string]interface{}
+	Expect(json.Unmarshal([]byte(lines[0]), &users)).Should(Succeed())
+	Expect(users).Should(HaveKeyWithValue("operation", "Users"))
+	Expect(users).Should(HaveKeyWithValue("passed", true))
+	Expect(json.Unmarshal([]byte(lines[1]), &admins)).Should(Succeed())
+	Expect(admins).Should(HaveKeyWithValue("passed", false))
+	Expect(admins["errors"]).Should(ConsistOf(HaveKeyWithValue("message", "forbidden")))
+
+	srv.Close()
+	Expect(run([]string{"run", "-endpoint", srv.URL, "-output", "ndjson", file}, &stdout, &stderr)).Should(Equal(exitError))
+}
+
+func TestProfiles(t *testing.T) {
+	RegisterTestingT(t)
+	dir, err := ioutil.TempDir("", "graphql")
+	Expect(err).ShouldNot(HaveOccurred())
+	defer os.RemoveAll(dir)
+	var auth, team []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = append(auth, r.Header.Get("Authorization"))
+		team = append(team, r.Header.Get("X-Team"))
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	config := filepath.Join(dir, "config")
+	Expect(ioutil.WriteFile(config, []byte(`{
+		"default": "prod",
+		"profiles": {
+			"prod": {"endpoint": "`+srv.URL+`", "headers": {"x-team": "payments"}, "tokenCommand": "echo minted"},
+			"broken": {"endpoint": "`+srv.URL+`", "tokenCommand": "echo failed >&2; exit 1"}
+		}
+	}`), 0600)).Should(Succeed())
+	os.Setenv("GRAPHQL_CONFIG", config)
+	defer os.Unsetenv("GRAPHQL_CONFIG")
+	file := filepath.Join(dir, "users.graphql")
+	Expect(ioutil.WriteFile(file, []byte("query Users { users { id } }\nquery Admins { users { id } }"), 0644)).Should(Succeed())
+
+	var stdout, stderr bytes.Buffer
+	Expect(run([]string{"run", file}, &stdout, &stderr)).Should(Equal(exitOK))
+	Expect(auth).Should(Equal([]string{"Bearer minted", "Bearer minted"}))
+	Expect(team).Should(Equal([]string{"payments", "payments"}))
+
+	auth, team = nil, nil
+	Expect(run([]string{"run", "-profile", "prod", "-H", "X-Team: search", file}, &stdout, &stderr)).Should(Equal(exitOK))
+	Expect(team).Should(Equal([]string{"search", "search"}))
+
+	// the default profile isn't sent to other endpoints
+	auth = nil
+	Expect(run([]string{"run", "-endpoint", srv.URL, file}, &stdout, &stderr)).Should(Equal(exitOK))
+	Expect(auth).Should(Equal([]string{"", ""}))
+
+	stdout.Reset()
+	Expect(run([]string{"run", "-profile", "broken", file}, &stdout, &stderr)).Should(Equal(exitError))
+	Expect(stdout.String()).Should(ContainSubstring("token command: failed"))
+
+	stderr.Reset()
+	Expect(run([]string{"run", "-profile", "nope", file}, &stdout, &stderr)).Should(Equal(exitError))
+	Expect(stderr.String()).Should(ContainSubstring(`no profile "nope" in ` + config))
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// Values of the -output flag, besides go-template=template.
+const (
+	outputText   = "text"
+	outputJSON   = "json"
+	outputNDJSON = "ndjson"
+	outputTable  = "table"
+)
+
+const goTemplatePrefix = "go-template="
+
+// outputFlags are the flags of commands whose results can be printed in
+// machine-readable formats.
+type outputFlags struct {
+	format string
+	tmpl   *template.Template
+}
+
+func (f *outputFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.format, "output", outputText, "`format` to print results in: text, json, ndjson, table or go-template=template")
+}
+
+// parse checks the format, once the flags are parsed.
+func (f *outputFlags) parse() error {
+	switch f.format {
+	case outputText, outputJSON, outputNDJSON, outputTable:
+		return nil
+	}
+	if !strings.HasPrefix(f.format, goTemplatePrefix) {
+		return errors.Errorf("-output must be %s, %s, %s, %s or %stemplate", outputText, outputJSON, outputNDJSON, outputTable, goTemplatePrefix)
+	}
+	tmpl, err := template.New("output").Parse(strings.TrimPrefix(f.format, goTemplatePrefix))
+	if err != nil {
+		return errors.Wrap(err, "-output")
+	}
+	f.tmpl = tmpl
+	return nil
+}
+
+// text reports whether results are printed for people, with summaries.
+func (f *outputFlags) text() bool {
+	return f.format == outputText
+}
+
+// printer prints the results of a command to w in the format.
+func (f *outputFlags) printer(w io.Writer) *printer {
+	p := &printer{format: f.format, tmpl: f.tmpl, w: w}
+	if f.format == outputTable {
+		p.table = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	}
+	return p
+}
+
+// printer prints records, which are structs with JSON tags, one at a
+// time, so that ndjson and text output is written as results come in.
+type printer struct {
+	format  string
+	tmpl    *template.Template
+	w       io.Writer
+	table   *tabwriter.Writer
+	records []interface{}
+}
+
+// print prints the record, or text in the text format.
+func (p *printer) print(record interface{}, text string) error {
+	switch {
+	case p.tmpl != nil:
+		if err := p.tmpl.Execute(p.w, record); err != nil {
+			return errors.Wrap(err, "-output")
+		}
+		_, err := fmt.Fprintln(p.w)
+		return err
+	case p.format == outputJSON:
+		p.records = append(p.records, record)
+		return nil
+	case p.format == outputNDJSON:
+		return json.NewEncoder(p.w).Encode(record)
+	case p.format == outputTable:
+		names, values := columns(record)
+		if len(p.records) == 0 {
+			fmt.Fprintln(p.table, strings.ToUpper(strings.Join(names, "\t")))
+		}
+		p.records = append(p.records, record)
+		_, err := fmt.Fprintln(p.table, strings.Join(values, "\t"))
+		return err
+	}
+	_, err := fmt.Fprintln(p.w, text)
+	return err
+}
+
+// close prints what is held back until all the records are printed.
+func (p *printer) close() error {
+	switch p.format {
+	case outputJSON:
+		records := p.records
+		if records == nil {
+			records = []interface{}{}
+		}
+		b, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(p.w, "%s\n", b)
+		return err
+	case outputTable:
+		return p.table.Flush()
+	}
+	return nil
+}
+
+// columns gets the JSON names and the values of the fields of a record
+// for a table; zero values are left empty.
+func columns(record interface{}) (names, values []string) {
+	v := reflect.Indirect(reflect.ValueOf(record))
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name == "-" || t.Field(i).PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = t.Field(i).Name
+		}
+		names = append(names, name)
+		value := ""
+		if field := v.Field(i); !field.IsZero() {
+			value = strings.Join(strings.Fields(fmt.Sprint(field.Interface())), " ")
+		}
+		values = append(values, value)
+	}
+	return names, values
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joefitzgerald/graphql"
+	"github.com/pkg/errors"
+)
+
+// profileConfig is the config file of named profiles, at
+// $GRAPHQL_CONFIG or else graphql/config in the user's config directory:
+//  {
+//    "default": "prod",
+//    "profiles": {
+//      "prod": {
+//        "endpoint": "https://example.com/graphql",
+//        "headers": {"X-Team": "payments"},
+//        "tokenCommand": "security find-generic-password -s example-graphql -w"
+//      },
+//      "github": {
+//        "endpoint": "https://api.github.com/graphql",
+//        "auth": {"mode": "bearer", "tokenEnv": "GITHUB_TOKEN"}
+//      }
+//    }
+//  }
+type profileConfig struct {
+	// Default is the profile used when neither -endpoint nor -profile
+	// is given.
+	Default  string              `json:"default,omitempty"`
+	Profiles map[string]*profile `json:"profiles"`
+}
+
+// profile is a server and how to authenticate with it, so tokens
+// needn't be given on the command line.
+type profile struct {
+	graphql.ClientConfig
+	// TokenCommand is run with sh to get a bearer token, such as from
+	// the keychain or a login tool. It is run again after tokenTTL, so
+	// long sessions get fresh tokens.
+	TokenCommand string `json:"tokenCommand,omitempty"`
+}
+
+// tokenTTL is how long a token from a token command is used for.
+const tokenTTL = 5 * time.Minute
+
+// profileConfigPath gets the config file, or "" if the user has no
+// config directory.
+func profileConfigPath() string {
+	if path := os.Getenv("GRAPHQL_CONFIG"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "graphql", "config")
+}
+
+// loadProfile gets the named profile, or if name is "" the default
+// profile, which is nil if there isn't one.
+func loadProfile(name string) (*profile, error) {
+	path := profileConfigPath()
+	var cfg profileConfig
+	b, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err) && name == "":
+		return nil, nil
+	case os.IsNotExist(err):
+		return nil, errors.Errorf("no profile %q: %s doesn't exist", name, path)
+	case err != nil:
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return nil, errors.Wrap(err, path)
+	}
+	if name == "" {
+		if cfg.Default == "" {
+			return nil, nil
+		}
+		name = cfg.Default
+	}
+	p, ok := cfg.Profiles[name]
+	if !ok {
+		return nil, errors.Errorf("no profile %q in %s", name, path)
+	}
+	return p, nil
+}
+
+// tokenCommand is Credentials that send the token printed by a command.
+type tokenCommand struct {
+	command string
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func (c *tokenCommand) Apply(r *http.Request) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Now().After(c.expires) {
+		cmd := exec.CommandContext(r.Context(), "sh", "-c", c.command)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return errors.Wrapf(err, "token command: %s", strings.TrimSpace(stderr.String()))
+		}
+		c.token = strings.TrimSpace(string(out))
+		if c.token == "" {
+			return errors.New("token command printed no token")
+		}
+		c.expires = time.Now().Add(tokenTTL)
+	}
+	r.Header.Set("Authorization", "Bearer "+c.token)
+	return nil
+}
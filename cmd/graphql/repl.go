@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/joefitzgerald/graphql"
+)
+
+// stdin is read by interactive commands.
+var stdin io.Reader = os.Stdin
+
+const replHelp = `Enter a query, which may span lines, to run it. Commands:
+  :set name value   set a variable; value is JSON, or else a string
+  :unset name       remove a variable
+  :vars             list the variables
+  :history          list the queries run
+  :run n            run query n from the history again
+  :complete path    complete a path of fields or arguments
+  :help             print this help
+  :quit             leave
+`
+
+func replCommand(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("repl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var sf schemaFlags
+	sf.register(fs)
+	historyFile := fs.String("history", replHistoryPath(), "`file` to keep the history of queries in; empty for none")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: graphql repl -endpoint url [-H header] [-history file]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	client, err := sf.client()
+	if err != nil {
+		return err
+	}
+	r := &repl{
+		client:      client,
+		schema:      &sf,
+		vars:        make(map[string]interface{}),
+		historyFile: *historyFile,
+		stdout:      stdout,
+		stderr:      stderr,
+	}
+	r.loadHistory()
+	return r.run(stdin)
+}
+
+// repl is an interactive session with a server.
+type repl struct {
+	client      *graphql.Client
+	schema      *schemaFlags
+	vars        map[string]interface{}
+	history     []string
+	historyFile string
+	stdout      io.Writer
+	stderr      io.Writer
+}
+
+func (r *repl) run(in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(nil, 1<<20)
+	var query strings.Builder
+	fmt.Fprint(r.stdout, "graphql> ")
+	for scanner.Scan() {
+		line := scanner.Text()
+		if query.Len() == 0 && strings.HasPrefix(strings.TrimSpace(line), ":") {
+			if quit := r.command(strings.Fields(strings.TrimSpace(line))); quit {
+				return nil
+			}
+		} else if query.Len() > 0 || strings.TrimSpace(line) != "" {
+			query.WriteString(line)
+			query.WriteByte('\n')
+			if unfinished(query.String()) {
+				fmt.Fprint(r.stdout, "   ...> ")
+				continue
+			}
+			r.execute(strings.TrimSpace(query.String()))
+			query.Reset()
+		}
+		fmt.Fprint(r.stdout, "graphql> ")
+	}
+	fmt.Fprintln(r.stdout)
+	return scanner.Err()
+}
+
+// command runs a : command, reporting whether it ends the session.
+func (r *repl) command(fields []string) bool {
+	name, args := fields[0], fields[1:]
+	switch {
+	case name == ":quit" || name == ":q":
+		return true
+	case name == ":help":
+		fmt.Fprint(r.stdout, replHelp)
+	case name == ":set" && len(args) >= 2:
+		raw := strings.Join(args[1:], " ")
+		var value interface{}
+		if err := json.Unmarshal([]byte(raw), &value); err != nil {
+			value = raw
+		}
+		r.vars[strings.TrimPrefix(args[0], "$")] = value
+	case name == ":unset" && len(args) == 1:
+		delete(r.vars, strings.TrimPrefix(args[0], "$"))
+	case name == ":vars":
+		names := make([]string, 0, len(r.vars))
+		for name := range r.vars {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			b, _ := json.Marshal(r.vars[name])
+			fmt.Fprintf(r.stdout, "$%s = %s\n", name, b)
+		}
+	case name == ":history":
+		for i, query := range r.history {
+			fmt.Fprintf(r.stdout, "%d: %s\n", i+1, strings.Replace(query, "\n", " ", -1))
+		}
+	case name == ":run" && len(args) == 1:
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 || n > len(r.history) {
+			fmt.Fprintf(r.stderr, "no query %s in the history\n", args[0])
+			break
+		}
+		r.execute(r.history[n-1])
+	case name == ":complete" && len(args) <= 1:
+		schema, err := r.schema.schema()
+		if err != nil {
+			fmt.Fprintln(r.stderr, err)
+			break
+		}
+		path := ""
+		if len(args) == 1 {
+			path = args[0]
+		}
+		for _, c := range completions(schema, "query", path) {
+			fmt.Fprintln(r.stdout, c)
+		}
+	default:
+		fmt.Fprintf(r.stderr, "unknown command %s; try :help\n", strings.Join(fields, " "))
+	}
+	return false
+}
+
+// execute runs the query with the session's variables, and prints the
+// response.
+func (r *repl) execute(query string) {
+	r.remember(query)
+	req := graphql.NewRequest(query)
+	for name, value := range r.vars {
+		req.Var(name, value)
+	}
+	result, err := graphql.Do[json.RawMessage](context.Background(), r.client, req)
+	if err != nil {
+		fmt.Fprintln(r.stderr, err)
+		return
+	}
+	response := struct {
+		Data   json.RawMessage `json:"data,omitempty"`
+		Errors graphql.Errors  `json:"errors,omitempty"`
+	}{result.Data, result.Errors}
+	b, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		fmt.Fprintln(r.stderr, err)
+		return
+	}
+	fmt.Fprintf(r.stdout, "%s\n", b)
+}
+
+// remember adds the query to the history, and the history file.
+func (r *repl) remember(query string) {
+	if n := len(r.history); n > 0 && r.history[n-1] == query {
+		return
+	}
+	r.history = append(r.history, query)
+	if r.historyFile == "" {
+		return
+	}
+	if os.MkdirAll(filepath.Dir(r.historyFile), 0700) != nil {
+		return
+	}
+	f, err := os.OpenFile(r.historyFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	b, _ := json.Marshal(query)
+	fmt.Fprintf(f, "%s\n", b)
+}
+
+// loadHistory reads the history file, which holds a JSON string for each
+// query.
+func (r *repl) loadHistory() {
+	if r.historyFile == "" {
+		return
+	}
+	f, err := os.Open(r.historyFile)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var query string
+		if json.Unmarshal(scanner.Bytes(), &query) == nil {
+			r.history = append(r.history, query)
+		}
+	}
+}
+
+// replHistoryPath gets the default history file, or "" if the user has
+// no config directory.
+func replHistoryPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "graphql", "history")
+}
+
+// unfinished reports whether the document has unclosed braces or
+// parentheses, outside strings and comments, so more lines are needed.
+func unfinished(doc string) bool {
+	depth := 0
+	for i := 0; i < len(doc); i++ {
+		switch doc[i] {
+		case '#':
+			for i < len(doc) && doc[i] != '\n' {
+				i++
+			}
+		case '"':
+			if strings.HasPrefix(doc[i:], `"""`) {
+				end := strings.Index(doc[i+3:], `"""`)
+				if end < 0 {
+					return true
+				}
+				i += end + 5
+				continue
+			}
+			for i++; i < len(doc) && doc[i] != '"' && doc[i] != '\n'; i++ {
+				if doc[i] == '\\' {
+					i++
+				}
+			}
+		case '{', '(', '[':
+			depth++
+		case '}', ')', ']':
+			depth--
+		}
+	}
+	return depth > 0
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joefitzgerald/graphql"
+	"github.com/pkg/errors"
+)
+
+// runResult is the result of running an operation, for -output.
+type runResult struct {
+	File       string         `json:"file"`
+	Operation  string         `json:"operation,omitempty"`
+	Passed     bool           `json:"passed"`
+	DurationMS float64        `json:"durationMs"`
+	Error      string         `json:"error,omitempty"`
+	Errors     graphql.Errors `json:"errors,omitempty"`
+}
+
+func runCommand(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var cf clientFlags
+	cf.register(fs)
+	var of outputFlags
+	of.register(fs)
+	dir := fs.String("dir", "", "`directory` of .graphql files to run")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: graphql run -endpoint url [-dir directory] [-output format] [file ...]")
+		fmt.Fprintln(stderr, "runs every operation in the files, with variables from a .json file of the same name")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if err := of.parse(); err != nil {
+		return err
+	}
+	files := fs.Args()
+	if *dir != "" {
+		matches, err := filepath.Glob(filepath.Join(*dir, "*.graphql"))
+		if err != nil {
+			return err
+		}
+		sort.Strings(matches)
+		files = append(files, matches...)
+	}
+	if len(files) == 0 {
+		fs.Usage()
+		return errUsage
+	}
+	client, err := cf.client()
+	if err != nil {
+		return err
+	}
+	p := of.printer(stdout)
+	// operations that only failed with GraphQL errors exit with
+	// exitGraphQL, unless others failed otherwise
+	var ran, failed, graphQLFailed int
+	start := time.Now()
+	for _, file := range files {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		vars, err := readVariables(strings.TrimSuffix(file, filepath.Ext(file)) + ".json")
+		if err != nil {
+			return err
+		}
+		ops, err := graphql.ParseOperations(string(b))
+		if err != nil {
+			ran++
+			failed++
+			r := runResult{File: file, Error: err.Error()}
+			if err := p.print(r, fmt.Sprintf("FAIL %s: %v", file, err)); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, op := range ops {
+			req := graphql.NewRequest(string(b))
+			req.OperationName = op.Name
+			for name, value := range vars {
+				req.Var(name, value)
+			}
+			result, err := graphql.Do[json.RawMessage](context.Background(), client, req)
+			ran++
+			name := file
+			if op.Name != "" {
+				name += " " + op.Name
+			}
+			r := runResult{File: file, Operation: op.Name}
+			var text string
+			switch {
+			case err != nil:
+				failed++
+				r.Error = err.Error()
+				text = fmt.Sprintf("FAIL %s: %v", name, err)
+			case len(result.Errors) > 0:
+				failed++
+				graphQLFailed++
+				r.Errors = result.Errors
+				r.DurationMS = milliseconds(result.Duration)
+				text = fmt.Sprintf("FAIL %s: %v", name, result.Errors)
+			default:
+				r.Passed = true
+				r.DurationMS = milliseconds(result.Duration)
+				text = fmt.Sprintf("PASS %s (%s)", name, result.Duration.Round(time.Millisecond))
+			}
+			if err := p.print(r, text); err != nil {
+				return err
+			}
+		}
+	}
+	if err := p.close(); err != nil {
+		return err
+	}
+	if of.text() {
+		fmt.Fprintf(stdout, "%d operations, %d failed (%s)\n", ran, failed, time.Since(start).Round(time.Millisecond))
+	}
+	if failed > 0 {
+		err := errors.Errorf("%d of %d operations failed", failed, ran)
+		if failed == graphQLFailed {
+			return &statusError{status: exitGraphQL, err: err}
+		}
+		return err
+	}
+	return nil
+}
+
+// milliseconds gets d in milliseconds, to a microsecond.
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Round(time.Microsecond)) / float64(time.Millisecond)
+}
+
+// readVariables reads the variables in the JSON file, if it exists.
+func readVariables(file string) (map[string]interface{}, error) {
+	b, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var vars map[string]interface{}
+	if err := json.Unmarshal(b, &vars); err != nil {
+		return nil, errors.Wrap(err, file)
+	}
+	return vars, nil
+}
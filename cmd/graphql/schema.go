@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/joefitzgerald/graphql"
+	"github.com/pkg/errors"
+)
+
+// Values of the schema check -fail flag.
+const (
+	failNone     = "none"
+	failBreaking = "breaking"
+	failAny      = "any"
+)
+
+func schemaCommand(args []string, stdout, stderr io.Writer) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "fetch":
+			return schemaFetch(args[1:], stdout, stderr)
+		case "check":
+			return schemaCheck(args[1:], stdout, stderr)
+		}
+	}
+	fmt.Fprintln(stderr, "usage: graphql schema fetch|check [arguments]")
+	return errUsage
+}
+
+func schemaFetch(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("schema fetch", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var cf clientFlags
+	cf.register(fs)
+	out := fs.String("o", "", "file to write the schema to, instead of standard output")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	client, err := cf.client()
+	if err != nil {
+		return err
+	}
+	schema, err := client.Introspect(context.Background())
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		return graphql.WriteSchema(stdout, schema)
+	}
+	return writeSchemaFile(*out, schema)
+}
+
+// schemaChange is a difference between the snapshot and the live
+// schema, for -output.
+type schemaChange struct {
+	Severity string `json:"severity"`
+	Path     string `json:"path"`
+	Message  string `json:"message"`
+}
+
+func schemaCheck(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("schema check", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var cf clientFlags
+	cf.register(fs)
+	file := fs.String("file", "", "vendored schema snapshot to compare with the server")
+	fail := fs.String("fail", failNone, "fail on `changes`: none, breaking or any")
+	update := fs.Bool("update", false, "write the live schema to the snapshot file")
+	var of outputFlags
+	of.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if err := of.parse(); err != nil {
+		return err
+	}
+	if *file == "" {
+		return errors.New("-file is required")
+	}
+	if *fail != failNone && *fail != failBreaking && *fail != failAny {
+		return errors.Errorf("-fail must be %s, %s or %s", failNone, failBreaking, failAny)
+	}
+	client, err := cf.client()
+	if err != nil {
+		return err
+	}
+	b, err := ioutil.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+	vendored, err := graphql.ParseSchema(b)
+	if err != nil {
+		return err
+	}
+	live, err := client.Introspect(context.Background())
+	if err != nil {
+		return err
+	}
+	changes := graphql.DiffSchemas(vendored, live)
+	p := of.printer(stdout)
+	for _, c := range changes {
+		r := schemaChange{Severity: string(c.Severity), Path: c.Path, Message: c.Message}
+		if err := p.print(r, c.String()); err != nil {
+			return err
+		}
+	}
+	if err := p.close(); err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		if of.text() {
+			fmt.Fprintf(stdout, "%s is up to date\n", *file)
+		}
+		return nil
+	}
+	if *update {
+		if err := writeSchemaFile(*file, live); err != nil {
+			return err
+		}
+		if of.text() {
+			fmt.Fprintf(stdout, "updated %s\n", *file)
+		}
+	}
+	switch {
+	case *fail == failAny,
+		*fail == failBreaking && graphql.HasBreakingChanges(changes):
+		return errors.Errorf("%s has drifted from %s (%d changes)", *file, cf.endpoint, len(changes))
+	}
+	return nil
+}
+
+func writeSchemaFile(path string, schema *graphql.Schema) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := graphql.WriteSchema(f, schema); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
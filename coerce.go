@@ -0,0 +1,220 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// coerceVariables coerces the request variables to the types the
+// operation declares for them, as servers coerce variables, so that
+// values that can't be coerced fail before the request is sent. Numbers
+// are made Ints or Floats as declared, single values are wrapped in
+// lists, and enum values and input object fields are checked.
+// Variables the operation doesn't declare are left as they are.
+func (c *Client) coerceVariables(req *Request) (*Request, error) {
+	schema := c.currentSchema()
+	if schema == nil {
+		return req, nil
+	}
+	doc, err := parseDocument(req.Query)
+	if err != nil {
+		// leave the server to report documents that are not valid
+		return req, nil
+	}
+	op, err := doc.operation(req.OperationName)
+	if err != nil || len(op.varDefs) == 0 {
+		return req, nil
+	}
+	out := req.clone()
+	if out.Variables == nil {
+		out.Variables = make(map[string]interface{})
+	}
+	co := &coercer{schema: schema}
+	for _, vd := range op.varDefs {
+		path := "$" + vd.name
+		t := vd.typ.schemaRef()
+		v, ok := req.Variables[vd.name]
+		if !ok {
+			if t.Kind == KindNonNull && vd.defaultValue == nil {
+				co.problem(path, "required variable is not set")
+			}
+			continue
+		}
+		// normalise the value to its JSON form first, so that structs,
+		// Inputs and values with encoders are all handled alike
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "encoding variable %q", vd.name)
+		}
+		d := json.NewDecoder(bytes.NewReader(b))
+		d.UseNumber()
+		var jv interface{}
+		if err := d.Decode(&jv); err != nil {
+			return nil, errors.Wrapf(err, "encoding variable %q", vd.name)
+		}
+		out.Variables[vd.name] = co.coerce(jv, t, path)
+	}
+	if len(co.problems) > 0 {
+		return nil, errors.Errorf("graphql: invalid input: %s", strings.Join(co.problems, "; "))
+	}
+	return out, nil
+}
+
+type coercer struct {
+	schema   *Schema
+	problems []string
+}
+
+func (co *coercer) problem(path, format string, args ...interface{}) {
+	co.problems = append(co.problems, path+": "+fmt.Sprintf(format, args...))
+}
+
+// coerce coerces the JSON value v to the type t.
+func (co *coercer) coerce(v interface{}, t *TypeRef, path string) interface{} {
+	if t.Kind == KindNonNull {
+		if v == nil {
+			co.problem(path, "%s cannot be null", t)
+			return nil
+		}
+		return co.coerce(v, t.OfType, path)
+	}
+	if v == nil {
+		return nil
+	}
+	if t.Kind == KindList {
+		items, ok := v.([]interface{})
+		if !ok {
+			// a single value is coerced to a list of one
+			return []interface{}{co.coerce(v, t.OfType, path)}
+		}
+		out := make([]interface{}, len(items))
+		for i, item := range items {
+			out[i] = co.coerce(item, t.OfType, fmt.Sprintf("%s[%d]", path, i))
+		}
+		return out
+	}
+	named := co.schema.Type(t.Name)
+	if named == nil {
+		// unknown types are for the server to report
+		return v
+	}
+	switch named.Kind {
+	case KindScalar:
+		return co.scalar(v, named.Name, path)
+	case KindEnum:
+		s, ok := v.(string)
+		if !ok || named.EnumValue(s) == nil {
+			co.problem(path, "%s is not a value of %s", describeJSON(v), named.Name)
+		}
+		return v
+	case KindInputObject:
+		fields, ok := v.(map[string]interface{})
+		if !ok {
+			co.problem(path, "%s is not a %s object", describeJSON(v), named.Name)
+			return v
+		}
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		out := make(map[string]interface{}, len(fields))
+		for _, name := range names {
+			f := named.InputField(name)
+			if f == nil {
+				co.problem(path, "unknown field %s of %s", name, named.Name)
+				continue
+			}
+			out[name] = co.coerce(fields[name], f.Type, path+"."+name)
+		}
+		for _, f := range named.InputFields {
+			if _, ok := fields[f.Name]; !ok && f.Type.Kind == KindNonNull && f.DefaultValue == nil {
+				co.problem(path+"."+f.Name, "required field is not set")
+			}
+		}
+		return out
+	}
+	co.problem(path, "%s is not an input type", named.Name)
+	return v
+}
+
+// scalar coerces v to a built-in scalar type. Custom scalars are left
+// for the server to coerce.
+func (co *coercer) scalar(v interface{}, scalar, path string) interface{} {
+	switch scalar {
+	case "Int":
+		if n, ok := v.(json.Number); ok {
+			if i, err := strconv.ParseInt(string(n), 10, 32); err == nil {
+				return i
+			}
+			// numbers such as 3.0 or 3e2 are accepted if integral
+			f, err := strconv.ParseFloat(string(n), 64)
+			if err == nil && f == math.Trunc(f) && f >= math.MinInt32 && f <= math.MaxInt32 {
+				return int64(f)
+			}
+		}
+	case "Float":
+		if n, ok := v.(json.Number); ok {
+			if f, err := strconv.ParseFloat(string(n), 64); err == nil && !math.IsInf(f, 0) {
+				return f
+			}
+		}
+	case "String":
+		if _, ok := v.(string); ok {
+			return v
+		}
+	case "Boolean":
+		if _, ok := v.(bool); ok {
+			return v
+		}
+	case "ID":
+		switch v := v.(type) {
+		case string:
+			return v
+		case json.Number:
+			if _, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+				return string(v)
+			}
+		}
+	default:
+		return v
+	}
+	co.problem(path, "%s is not a valid %s", describeJSON(v), scalar)
+	return v
+}
+
+// describeJSON describes a JSON value in error messages.
+func describeJSON(v interface{}) string {
+	switch v := v.(type) {
+	case []interface{}:
+		return "a list"
+	case map[string]interface{}:
+		return "an object"
+	case string:
+		return strconv.Quote(v)
+	}
+	return fmt.Sprint(v)
+}
+
+// schemaRef converts the type reference to a schema TypeRef. The kind
+// of a named type isn't known without the schema, so named types are
+// given as scalars, and looked up by name when coercing.
+func (t *typeRef) schemaRef() *TypeRef {
+	var ref *TypeRef
+	if t.elem != nil {
+		ref = &TypeRef{Kind: KindList, OfType: t.elem.schemaRef()}
+	} else {
+		ref = &TypeRef{Kind: KindScalar, Name: t.name}
+	}
+	if t.nonNull {
+		return &TypeRef{Kind: KindNonNull, OfType: ref}
+	}
+	return ref
+}
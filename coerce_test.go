@@ -0,0 +1,46 @@
+package graphql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestCoerceVariables(t *testing.T) {
+	RegisterTestingT(t)
+	var vars map[string]interface{}
+	srv := variablesServer(&vars)
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL, graphql.WithSchema(loadSchema()))
+	ctx := context.Background()
+	type filter struct {
+		Role string `json:"role"`
+	}
+
+	req := graphql.NewRequest(`query ($first: Int, $role: Role, $id: ID!) { users(first: $first, role: $role) { id } user(id: $id) { id } }`)
+	req.Var("first", 10.0)
+	req.Var("role", "ADMIN")
+	req.Var("id", 42)
+	Expect(client.Run(ctx, req, nil)).Should(Succeed())
+	Expect(vars).Should(Equal(map[string]interface{}{"first": 10.0, "role": "ADMIN", "id": "42"}))
+
+	req = graphql.NewRequest(`query ($first: Int, $role: Role, $id: ID!) { users(first: $first, role: $role) { id } user(id: $id) { id } }`)
+	req.Var("first", 2.5)
+	req.Var("role", "OWNER")
+	err := client.Run(ctx, req, nil)
+	Expect(err).Should(MatchError(`graphql: invalid input: $first: 2.5 is not a valid Int; $role: "OWNER" is not a value of Role; $id: required variable is not set`))
+
+	req = graphql.NewRequest(`query ($ids: [ID!]!, $filter: UserFilter) { search(filter: $filter) { __typename } }`)
+	req.Var("ids", "a")
+	req.Var("filter", filter{Role: "GUEST"})
+	Expect(client.Run(ctx, req, nil)).Should(Succeed())
+	Expect(vars["ids"]).Should(Equal([]interface{}{"a"}))
+	Expect(vars["filter"]).Should(HaveKeyWithValue("role", "GUEST"))
+
+	req = graphql.NewRequest(`mutation ($input: CreateUserInput!) { createUser(input: $input) { id } }`)
+	req.Var("input", map[string]interface{}{"nickname": "m", "age": 3})
+	err = client.Run(ctx, req, nil)
+	Expect(err).Should(MatchError("graphql: invalid input: $input: unknown field age of CreateUserInput; $input.name: required field is not set"))
+}
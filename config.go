@@ -0,0 +1,290 @@
+package graphql
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ClientConfig describes how to make a Client, so that client behaviour
+// can be tuned without rebuilding. It can be unmarshalled from JSON or
+// YAML, or read from the environment with ClientConfigFromEnv.
+//  endpoint: https://api.github.com/graphql
+//  timeout: 30s
+//  maxResponseSize: 10485760
+//  auth:
+//    mode: bearer
+//    tokenEnv: GITHUB_TOKEN
+//  tls:
+//    caFile: /etc/ssl/internal-ca.pem
+type ClientConfig struct {
+	// Endpoint is the URL of the GraphQL server.
+	Endpoint string `json:"endpoint" yaml:"endpoint"`
+	// ReadEndpoints are read replicas that queries are sent to.
+	ReadEndpoints []string `json:"readEndpoints,omitempty" yaml:"readEndpoints,omitempty"`
+	// Timeout limits the time taken by each HTTP request, including
+	// reading the response. Zero means no timeout.
+	Timeout Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// KeepAlive is the period between TCP keepalive probes.
+	KeepAlive Duration `json:"keepAlive,omitempty" yaml:"keepAlive,omitempty"`
+	// ReadTimeout limits how long to wait for the server to send anything.
+	ReadTimeout Duration `json:"readTimeout,omitempty" yaml:"readTimeout,omitempty"`
+	// Headers are sent with every request.
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	// MaxResponseSize limits the size of response bodies in bytes.
+	MaxResponseSize int64 `json:"maxResponseSize,omitempty" yaml:"maxResponseSize,omitempty"`
+	// MaxDepth limits the nesting depth of response bodies.
+	MaxDepth int `json:"maxDepth,omitempty" yaml:"maxDepth,omitempty"`
+	// SpecCompliance turns on GraphQL-over-HTTP spec compliance mode.
+	SpecCompliance bool `json:"specCompliance,omitempty" yaml:"specCompliance,omitempty"`
+	// TLS configures the TLS connection to the server.
+	TLS *TLSConfig `json:"tls,omitempty" yaml:"tls,omitempty"`
+	// Auth configures how requests are authenticated.
+	Auth *AuthConfig `json:"auth,omitempty" yaml:"auth,omitempty"`
+}
+
+// TLSConfig configures TLS connections.
+type TLSConfig struct {
+	// CAFile is a PEM file of certificate authorities to trust instead
+	// of the system roots.
+	CAFile string `json:"caFile,omitempty" yaml:"caFile,omitempty"`
+	// CertFile and KeyFile are PEM files of a client certificate
+	// and its key.
+	CertFile string `json:"certFile,omitempty" yaml:"certFile,omitempty"`
+	KeyFile  string `json:"keyFile,omitempty" yaml:"keyFile,omitempty"`
+	// ServerName overrides the name used to verify the server certificate.
+	ServerName string `json:"serverName,omitempty" yaml:"serverName,omitempty"`
+	// InsecureSkipVerify turns off verification of the server
+	// certificate. Only use it for testing.
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty"`
+}
+
+// Authentication modes for AuthConfig.
+const (
+	AuthNone   = "none"
+	AuthBearer = "bearer"
+	AuthBasic  = "basic"
+)
+
+// AuthConfig configures how requests are authenticated. Secrets can be
+// given directly or named by environment variable, so config files
+// needn't contain them.
+type AuthConfig struct {
+	// Mode is AuthNone, AuthBearer or AuthBasic.
+	Mode string `json:"mode" yaml:"mode"`
+	// Token is the bearer token, or TokenEnv the variable holding it.
+	Token    string `json:"token,omitempty" yaml:"token,omitempty"`
+	TokenEnv string `json:"tokenEnv,omitempty" yaml:"tokenEnv,omitempty"`
+	// Username and Password are the basic auth credentials, or
+	// PasswordEnv the variable holding the password.
+	Username    string `json:"username,omitempty" yaml:"username,omitempty"`
+	Password    string `json:"password,omitempty" yaml:"password,omitempty"`
+	PasswordEnv string `json:"passwordEnv,omitempty" yaml:"passwordEnv,omitempty"`
+}
+
+// Duration is a time.Duration that can be unmarshalled from strings
+// such as "1m30s", or from numbers of seconds.
+type Duration time.Duration
+
+// UnmarshalJSON reads a duration string or number of seconds.
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var seconds float64
+	if err := json.Unmarshal(b, &seconds); err == nil {
+		*d = Duration(seconds * float64(time.Second))
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return errors.Errorf("invalid duration %s", b)
+	}
+	return d.UnmarshalText([]byte(s))
+}
+
+// UnmarshalText reads a duration string.
+func (d *Duration) UnmarshalText(b []byte) error {
+	v, err := time.ParseDuration(string(b))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalText writes the duration as a string.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// NewClientFromConfig makes a Client as described by cfg. opts are
+// applied after the config, so they take precedence; a client given with
+// WithHTTPClient replaces the one made for the config's timeout and TLS
+// settings.
+func NewClientFromConfig(cfg ClientConfig, opts ...ClientOption) (*Client, error) {
+	if cfg.Endpoint == "" {
+		return nil, errors.New("graphql: config has no endpoint")
+	}
+	explicit := opts
+	opts = nil
+	for name, value := range cfg.Headers {
+		opts = append(opts, WithHeader(name, value))
+	}
+	if len(cfg.ReadEndpoints) > 0 {
+		opts = append(opts, WithReadEndpoints(cfg.ReadEndpoints...))
+	}
+	if cfg.MaxResponseSize > 0 {
+		opts = append(opts, WithMaxResponseSize(cfg.MaxResponseSize))
+	}
+	if cfg.MaxDepth > 0 {
+		opts = append(opts, WithMaxDepth(cfg.MaxDepth))
+	}
+	if cfg.KeepAlive > 0 {
+		opts = append(opts, WithKeepAlive(time.Duration(cfg.KeepAlive)))
+	}
+	if cfg.ReadTimeout > 0 {
+		opts = append(opts, WithReadTimeout(time.Duration(cfg.ReadTimeout)))
+	}
+	if cfg.SpecCompliance {
+		opts = append(opts, WithSpecCompliance())
+	}
+	if cfg.Auth != nil {
+		opt, err := cfg.Auth.option()
+		if err != nil {
+			return nil, err
+		}
+		if opt != nil {
+			opts = append(opts, opt)
+		}
+	}
+	if cfg.Timeout > 0 || cfg.TLS != nil {
+		httpClient := &http.Client{Timeout: time.Duration(cfg.Timeout)}
+		if cfg.TLS != nil {
+			tlsConfig, err := cfg.TLS.config()
+			if err != nil {
+				return nil, err
+			}
+			transport := http.DefaultTransport.(*http.Transport).Clone()
+			transport.TLSClientConfig = tlsConfig
+			httpClient.Transport = transport
+		}
+		opts = append(opts, WithHTTPClient(httpClient))
+	}
+	return NewClient(cfg.Endpoint, append(opts, explicit...)...), nil
+}
+
+// ClientConfigFromEnv reads a ClientConfig from environment variables
+// named with the prefix:
+//  PREFIX_ENDPOINT, PREFIX_READ_ENDPOINTS, PREFIX_TIMEOUT, PREFIX_MAX_RESPONSE_SIZE, PREFIX_MAX_DEPTH,
+//  PREFIX_KEEP_ALIVE, PREFIX_READ_TIMEOUT,
+//  PREFIX_SPEC_COMPLIANCE, PREFIX_HEADER_<NAME>,
+//  PREFIX_TLS_CA_FILE, PREFIX_TLS_CERT_FILE, PREFIX_TLS_KEY_FILE,
+//  PREFIX_TLS_SERVER_NAME, PREFIX_TLS_INSECURE_SKIP_VERIFY,
+//  PREFIX_AUTH_MODE, PREFIX_AUTH_TOKEN, PREFIX_AUTH_TOKEN_ENV,
+//  PREFIX_AUTH_USERNAME, PREFIX_AUTH_PASSWORD, PREFIX_AUTH_PASSWORD_ENV
+// Underscores in header names stand for dashes, and read endpoints are
+// separated by commas. Values that can't be
+// parsed are ignored.
+func ClientConfigFromEnv(prefix string) ClientConfig {
+	prefix = strings.ToUpper(prefix) + "_"
+	get := func(name string) string {
+		return os.Getenv(prefix + name)
+	}
+	cfg := ClientConfig{Endpoint: get("ENDPOINT")}
+	if endpoints := get("READ_ENDPOINTS"); endpoints != "" {
+		cfg.ReadEndpoints = strings.Split(endpoints, ",")
+	}
+	cfg.Timeout.UnmarshalText([]byte(get("TIMEOUT")))
+	cfg.KeepAlive.UnmarshalText([]byte(get("KEEP_ALIVE")))
+	cfg.ReadTimeout.UnmarshalText([]byte(get("READ_TIMEOUT")))
+	cfg.MaxResponseSize, _ = strconv.ParseInt(get("MAX_RESPONSE_SIZE"), 10, 64)
+	cfg.MaxDepth, _ = strconv.Atoi(get("MAX_DEPTH"))
+	cfg.SpecCompliance, _ = strconv.ParseBool(get("SPEC_COMPLIANCE"))
+	for _, kv := range os.Environ() {
+		key := strings.SplitN(kv, "=", 2)[0]
+		if strings.HasPrefix(key, prefix+"HEADER_") {
+			if cfg.Headers == nil {
+				cfg.Headers = make(map[string]string)
+			}
+			name := strings.Replace(strings.TrimPrefix(key, prefix+"HEADER_"), "_", "-", -1)
+			cfg.Headers[http.CanonicalHeaderKey(name)] = os.Getenv(key)
+		}
+	}
+	tlsConfig := TLSConfig{
+		CAFile:     get("TLS_CA_FILE"),
+		CertFile:   get("TLS_CERT_FILE"),
+		KeyFile:    get("TLS_KEY_FILE"),
+		ServerName: get("TLS_SERVER_NAME"),
+	}
+	tlsConfig.InsecureSkipVerify, _ = strconv.ParseBool(get("TLS_INSECURE_SKIP_VERIFY"))
+	if tlsConfig != (TLSConfig{}) {
+		cfg.TLS = &tlsConfig
+	}
+	auth := AuthConfig{
+		Mode:        get("AUTH_MODE"),
+		Token:       get("AUTH_TOKEN"),
+		TokenEnv:    get("AUTH_TOKEN_ENV"),
+		Username:    get("AUTH_USERNAME"),
+		Password:    get("AUTH_PASSWORD"),
+		PasswordEnv: get("AUTH_PASSWORD_ENV"),
+	}
+	if auth != (AuthConfig{}) {
+		cfg.Auth = &auth
+	}
+	return cfg
+}
+
+func (t *TLSConfig) config() (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+	if t.CAFile != "" {
+		pem, err := ioutil.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading CA file")
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.Errorf("graphql: no certificates in %s", t.CAFile)
+		}
+	}
+	if t.CertFile != "" || t.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "loading client certificate")
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// option gets the ClientOption that authenticates requests.
+func (a *AuthConfig) option() (ClientOption, error) {
+	switch a.Mode {
+	case "", AuthNone:
+		return nil, nil
+	case AuthBearer:
+		token := a.Token
+		if a.TokenEnv != "" {
+			token = os.Getenv(a.TokenEnv)
+		}
+		if token == "" {
+			return nil, errors.New("graphql: bearer auth has no token")
+		}
+		return WithCredentials(BearerToken(token)), nil
+	case AuthBasic:
+		password := a.Password
+		if a.PasswordEnv != "" {
+			password = os.Getenv(a.PasswordEnv)
+		}
+		return WithCredentials(BasicAuth(a.Username, password)), nil
+	}
+	return nil, errors.Errorf("graphql: unknown auth mode %q", a.Mode)
+}
@@ -0,0 +1,90 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestNewClientFromConfig(t *testing.T) {
+	RegisterTestingT(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Expect(r.Header.Get("Authorization")).Should(Equal("Bearer secret"))
+		Expect(r.Header.Get("X-Tenant")).Should(Equal("acme"))
+		io.WriteString(w, `{"data":{"value":"ok"}}`)
+	}))
+	defer srv.Close()
+	os.Setenv("GQLTEST_TOKEN", "secret")
+	defer os.Unsetenv("GQLTEST_TOKEN")
+
+	var cfg graphql.ClientConfig
+	err := json.Unmarshal([]byte(`{
+		"endpoint": "`+srv.URL+`",
+		"timeout": "5s",
+		"headers": {"X-Tenant": "acme"},
+		"auth": {"mode": "bearer", "tokenEnv": "GQLTEST_TOKEN"}
+	}`), &cfg)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(time.Duration(cfg.Timeout)).Should(Equal(5 * time.Second))
+
+	client, err := graphql.NewClientFromConfig(cfg)
+	Expect(err).ShouldNot(HaveOccurred())
+	var resp struct {
+		Value string
+	}
+	err = client.Run(context.Background(), graphql.NewRequest(`{ value }`), &resp)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(resp.Value).Should(Equal("ok"))
+
+	// explicit options take precedence over the config
+	used := false
+	httpClient := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		used = true
+		return http.DefaultTransport.RoundTrip(r)
+	})}
+	client, err = graphql.NewClientFromConfig(cfg, graphql.WithHTTPClient(httpClient))
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(client.Run(context.Background(), graphql.NewRequest(`{ value }`), &resp)).Should(Succeed())
+	Expect(used).Should(BeTrue())
+
+	_, err = graphql.NewClientFromConfig(graphql.ClientConfig{Endpoint: srv.URL, Auth: &graphql.AuthConfig{Mode: "magic"}})
+	Expect(err).Should(HaveOccurred())
+}
+
+func TestClientConfigFromEnv(t *testing.T) {
+	RegisterTestingT(t)
+	env := map[string]string{
+		"GQLTEST_ENDPOINT":          "https://example.com/graphql",
+		"GQLTEST_READ_ENDPOINTS":    "https://r1.example.com/graphql,https://r2.example.com/graphql",
+		"GQLTEST_TIMEOUT":           "1m",
+		"GQLTEST_READ_TIMEOUT":      "10s",
+		"GQLTEST_HEADER_X_TENANT":   "acme",
+		"GQLTEST_AUTH_MODE":         "basic",
+		"GQLTEST_AUTH_USERNAME":     "mat",
+		"GQLTEST_TLS_SERVER_NAME":   "internal",
+		"GQLTEST_SPEC_COMPLIANCE":   "true",
+		"GQLTEST_MAX_RESPONSE_SIZE": "1024",
+	}
+	for k, v := range env {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+	cfg := graphql.ClientConfigFromEnv("gqltest")
+	Expect(cfg.Endpoint).Should(Equal("https://example.com/graphql"))
+	Expect(cfg.ReadEndpoints).Should(Equal([]string{"https://r1.example.com/graphql", "https://r2.example.com/graphql"}))
+	Expect(time.Duration(cfg.Timeout)).Should(Equal(time.Minute))
+	Expect(time.Duration(cfg.ReadTimeout)).Should(Equal(10 * time.Second))
+	Expect(cfg.Headers).Should(Equal(map[string]string{"X-Tenant": "acme"}))
+	Expect(cfg.Auth).Should(Equal(&graphql.AuthConfig{Mode: "basic", Username: "mat"}))
+	Expect(cfg.TLS).Should(Equal(&graphql.TLSConfig{ServerName: "internal"}))
+	Expect(cfg.SpecCompliance).Should(BeTrue())
+	Expect(cfg.MaxResponseSize).Should(BeEquivalentTo(1024))
+}
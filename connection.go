@@ -0,0 +1,74 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// Connection is a Relay connection of nodes of type T, or a page of one.
+type Connection[T any] struct {
+	Nodes []T
+	Edges []Edge[T]
+	// PageInfo is the connection's pageInfo.
+	PageInfo PageInfo
+	// TotalCount is the connection's totalCount, if selected.
+	TotalCount int
+}
+
+// Edge is an edge of a Relay connection.
+type Edge[T any] struct {
+	Cursor string
+	Node   T
+}
+
+// PageInfo is the pageInfo of a Relay connection.
+type PageInfo struct {
+	HasNextPage     bool
+	HasPreviousPage bool
+	StartCursor     string
+	EndCursor       string
+}
+
+// Merge appends the nodes and edges of the next page to the connection,
+// and updates its page info and total count, so that it holds the pages
+// seen so far. The start of the page info is kept from the first page.
+func (c *Connection[T]) Merge(next *Connection[T]) {
+	first := len(c.Nodes) == 0 && len(c.Edges) == 0 && c.PageInfo == (PageInfo{})
+	c.Nodes = append(c.Nodes, next.Nodes...)
+	c.Edges = append(c.Edges, next.Edges...)
+	start := c.PageInfo
+	c.PageInfo = next.PageInfo
+	if !first {
+		c.PageInfo.HasPreviousPage = start.HasPreviousPage
+		c.PageInfo.StartCursor = start.StartCursor
+	}
+	if next.TotalCount != 0 {
+		c.TotalCount = next.TotalCount
+	}
+}
+
+// MergePages runs a query for a Relay connection page by page, merging
+// the pages into one Connection. path and the query are as for Export:
+// path is the dot-separated path of the connection in the response data,
+// and the query must take the cursor of the next page as the variable
+// $after and select pageInfo { hasNextPage endCursor }.
+//  members, err := graphql.MergePages[Member](ctx, client, req, "organization.members")
+func MergePages[T any](ctx context.Context, client *Client, req *Request, path string) (*Connection[T], error) {
+	req = req.clone()
+	all := &Connection[T]{}
+	for {
+		page, err := connectionPage[T](ctx, client, req, path)
+		if err != nil {
+			return all, err
+		}
+		all.Merge(page)
+		if !page.PageInfo.HasNextPage {
+			return all, nil
+		}
+		if page.PageInfo.EndCursor == "" {
+			return all, errors.Errorf("graphql: %s has a next page but no endCursor", path)
+		}
+		req.Var("after", page.PageInfo.EndCursor)
+	}
+}
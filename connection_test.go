@@ -0,0 +1,51 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestMergePages(t *testing.T) {
+	RegisterTestingT(t)
+	pages := map[string]string{
+		"":   `{"data":{"repo":{"issues":{"edges":[{"cursor":"c1","node":{"number":1}},{"cursor":"c2","node":{"number":2}}],"pageInfo":{"hasNextPage":true,"hasPreviousPage":false,"startCursor":"c1","endCursor":"c2"},"totalCount":3}}}}`,
+		"c2": `{"data":{"repo":{"issues":{"edges":[{"cursor":"c3","node":{"number":3}}],"pageInfo":{"hasNextPage":false,"hasPreviousPage":true,"startCursor":"c3","endCursor":"c3"},"totalCount":3}}}}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphql.Request
+		json.NewDecoder(r.Body).Decode(&req)
+		after, _ := req.Variables["after"].(string)
+		io.WriteString(w, pages[after])
+	}))
+	defer srv.Close()
+	type issue struct {
+		Number int
+	}
+	req := graphql.NewRequest(`query ($after: String) { repo { issues(after: $after) { edges { cursor node { number } } pageInfo { hasNextPage hasPreviousPage startCursor endCursor } totalCount } } }`)
+	issues, err := graphql.MergePages[issue](context.Background(), graphql.NewClient(srv.URL), req, "repo.issues")
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(issues.Edges).Should(HaveLen(3))
+	Expect(issues.Edges[2]).Should(Equal(graphql.Edge[issue]{Cursor: "c3", Node: issue{Number: 3}}))
+	Expect(issues.TotalCount).Should(Equal(3))
+	Expect(issues.PageInfo).Should(Equal(graphql.PageInfo{
+		HasNextPage: false, HasPreviousPage: false, StartCursor: "c1", EndCursor: "c3",
+	}))
+	Expect(req.Variables).Should(BeEmpty())
+}
+
+func TestConnectionMerge(t *testing.T) {
+	RegisterTestingT(t)
+	var all graphql.Connection[string]
+	all.Merge(&graphql.Connection[string]{Nodes: []string{"a"}, PageInfo: graphql.PageInfo{HasNextPage: true, EndCursor: "1"}, TotalCount: 2})
+	all.Merge(&graphql.Connection[string]{Nodes: []string{"b"}, PageInfo: graphql.PageInfo{EndCursor: "2"}})
+	Expect(all.Nodes).Should(Equal([]string{"a", "b"}))
+	Expect(all.PageInfo).Should(Equal(graphql.PageInfo{EndCursor: "2"}))
+	Expect(all.TotalCount).Should(Equal(2))
+}
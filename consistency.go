@@ -0,0 +1,84 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// WithConsistencyTokens makes the client read-your-writes consistent
+// with servers that return a session token in the named header of
+// mutation responses. The client keeps the latest token and sends it in
+// the same header with later requests, so they see the writes.
+// Tokens are shared by all requests made with the client, unless the
+// context was made with WithConsistencyScope.
+//  NewClient(endpoint, WithConsistencyTokens("X-Consistency-Token"))
+func WithConsistencyTokens(header string) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.consistencyHeader = header
+	})
+}
+
+// WithConsistencyScope gets a context in which requests keep their own
+// consistency token, rather than sharing the client's, so that the
+// requests of one user or job only wait for their own writes.
+//  ctx = graphql.WithConsistencyScope(ctx)
+func WithConsistencyScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, consistencyKey{}, &consistencyToken{})
+}
+
+type consistencyKey struct{}
+
+// consistencyToken holds the latest consistency token.
+type consistencyToken struct {
+	mu    sync.Mutex
+	token string
+}
+
+func (t *consistencyToken) get() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.token
+}
+
+func (t *consistencyToken) set(token string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.token = token
+}
+
+// consistencyToken gets the token holder for requests made with ctx, or
+// nil if the client doesn't use consistency tokens.
+func (c *Client) consistencyToken(ctx context.Context) *consistencyToken {
+	if c.consistencyHeader == "" {
+		return nil
+	}
+	if t, ok := ctx.Value(consistencyKey{}).(*consistencyToken); ok {
+		return t
+	}
+	return &c.consistency
+}
+
+// sendConsistencyToken adds the latest consistency token to header.
+func (c *Client) sendConsistencyToken(ctx context.Context, header http.Header) {
+	if t := c.consistencyToken(ctx); t != nil {
+		if token := t.get(); token != "" {
+			header.Set(c.consistencyHeader, token)
+		}
+	}
+}
+
+// keepConsistencyToken keeps the token from the response to a mutation.
+func (c *Client) keepConsistencyToken(ctx context.Context, req *Request, res *http.Response) {
+	t := c.consistencyToken(ctx)
+	if t == nil {
+		return
+	}
+	token := res.Header.Get(c.consistencyHeader)
+	if token == "" {
+		return
+	}
+	if op, err := req.Operation(); err == nil && op.Type == "mutation" {
+		t.set(token)
+	}
+}
@@ -0,0 +1,48 @@
+package graphql_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestConsistencyTokens(t *testing.T) {
+	RegisterTestingT(t)
+	var sent []string
+	httpClient := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			sent = append(sent, req.Header.Get("X-Consistency"))
+			header := make(http.Header)
+			header.Set("X-Consistency", "t"+string(rune('0'+len(sent))))
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     header,
+				Body:       ioutil.NopCloser(strings.NewReader(`{"data":{}}`)),
+			}, nil
+		}),
+	}
+	client := graphql.NewClient("", graphql.WithHTTPClient(httpClient),
+		graphql.WithConsistencyTokens("X-Consistency"))
+	ctx := context.Background()
+	run := func(ctx context.Context, query string) {
+		Expect(client.Run(ctx, graphql.NewRequest(query), nil)).Should(Succeed())
+	}
+
+	run(ctx, `{ a }`)
+	run(ctx, `mutation { b }`)
+	run(ctx, `{ a }`)
+	run(ctx, `{ a }`)
+	Expect(sent).Should(Equal([]string{"", "", "t2", "t2"}))
+
+	scoped := graphql.WithConsistencyScope(ctx)
+	run(scoped, `{ a }`)
+	run(scoped, `mutation { b }`)
+	run(scoped, `{ a }`)
+	run(ctx, `{ a }`)
+	Expect(sent[4:]).Should(Equal([]string{"", "", "t6", "t2"}))
+}
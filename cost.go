@@ -0,0 +1,135 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// CostEstimate is a server's estimate of the cost of an operation, read
+// from the "cost" extension of a dry-run response.
+type CostEstimate struct {
+	// Cost is the estimated cost of the operation.
+	Cost float64
+	// Available and Maximum are the cost budget remaining and the size
+	// of the budget, if the server reports them, or else zero.
+	Available float64
+	Maximum   float64
+	// Extension is the decoded "cost" extension.
+	Extension interface{}
+}
+
+// ErrNoCostDryRun is returned by EstimateCost when the client has no
+// CostDryRun.
+var ErrNoCostDryRun = errors.New("graphql: no cost dry run set with WithCostDryRun")
+
+// CostDryRun turns a request into one that the server costs without
+// executing it.
+type CostDryRun func(req *Request) (*Request, error)
+
+// WithCostDryRun sets how EstimateCost asks the server for the cost of
+// an operation without running it. Without it, EstimateCost fails. For
+// validation-only endpoints, which never run operations, a CostDryRun
+// can return requests as they are.
+//  NewClient(endpoint, WithCostDryRun(CostDirective("cost")))
+func WithCostDryRun(dryRun CostDryRun) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.costDryRun = dryRun
+	})
+}
+
+// CostDirective makes a CostDryRun that adds the directive
+// @name(dryRun: true) to the operation.
+func CostDirective(name string) CostDryRun {
+	return func(req *Request) (*Request, error) {
+		doc, err := parseDocument(req.Query)
+		if err != nil {
+			return nil, err
+		}
+		op, err := doc.operation(req.OperationName)
+		if err != nil {
+			return nil, err
+		}
+		toks, err := lex(req.Query)
+		if err != nil {
+			return nil, err
+		}
+		r := req.clone()
+		directive := "@" + name + "(dryRun: true) "
+		if op.nameEnd < 0 {
+			r.Query = req.Query[:op.start] + "query " + directive + req.Query[op.start:]
+			return r, nil
+		}
+		// insert the directive before the operation's selection set
+		depth := 0
+		for _, t := range toks {
+			if t.start < op.start || t.kind != tokPunct {
+				continue
+			}
+			switch t.value {
+			case "(":
+				depth++
+			case ")":
+				depth--
+			case "{":
+				if depth == 0 {
+					r.Query = req.Query[:t.start] + directive + req.Query[t.start:]
+					return r, nil
+				}
+			}
+		}
+		return nil, errors.New("graphql: operation has no selection set")
+	}
+}
+
+// EstimateCost asks the server for the cost of the request without
+// executing it, so that expensive work can be paced. The server must
+// support cost dry runs, configured with WithCostDryRun, and report the
+// cost in the "cost" extension of the response; either as a number, or
+// as an object with a requestedQueryCost and optional throttleStatus.
+func (c *Client) EstimateCost(ctx context.Context, req *Request) (*CostEstimate, error) {
+	if c.costDryRun == nil {
+		// sending the request as it is would run it
+		return nil, ErrNoCostDryRun
+	}
+	err := c.callHook("cost dry run", func() error {
+		var err error
+		req, err = c.costDryRun(req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	var extensions struct {
+		Cost interface{} `json:"cost"`
+	}
+	if len(res.extensions) > 0 {
+		if err := json.Unmarshal(res.extensions, &extensions); err != nil {
+			return nil, errors.Wrap(err, "graphql: decoding extensions")
+		}
+	}
+	estimate := &CostEstimate{Extension: extensions.Cost}
+	switch cost := extensions.Cost.(type) {
+	case float64:
+		estimate.Cost = cost
+		return estimate, nil
+	case map[string]interface{}:
+		if requested, ok := cost["requestedQueryCost"].(float64); ok {
+			estimate.Cost = requested
+			if status, ok := cost["throttleStatus"].(map[string]interface{}); ok {
+				estimate.Available, _ = status["currentlyAvailable"].(float64)
+				estimate.Maximum, _ = status["maximumAvailable"].(float64)
+			}
+			return estimate, nil
+		}
+	}
+	if len(res.errors) > 0 {
+		return nil, res.errors[0]
+	}
+	return nil, errors.New("graphql: no cost in response extensions")
+}
@@ -0,0 +1,78 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestCostDirective(t *testing.T) {
+	RegisterTestingT(t)
+	dryRun := graphql.CostDirective("cost")
+
+	r, err := dryRun(graphql.NewRequest(`{ a }`))
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(r.Query).Should(Equal(`query @cost(dryRun: true) { a }`))
+
+	req := graphql.NewRequest(`query A { a } query B($f: F = {a: 1}) @live { b(f: $f) }`)
+	req.OperationName = "B"
+	r, err = dryRun(req)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(r.Query).Should(Equal(`query A { a } query B($f: F = {a: 1}) @live @cost(dryRun: true) { b(f: $f) }`))
+	Expect(req.Query).Should(Equal(`query A { a } query B($f: F = {a: 1}) @live { b(f: $f) }`))
+
+	_, err = dryRun(graphql.NewRequest(`query A { a } query B { b }`))
+	Expect(err).Should(HaveOccurred())
+}
+
+func TestEstimateCost(t *testing.T) {
+	RegisterTestingT(t)
+	var sent graphql.Request
+	body := ""
+	httpClient := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			b, _ := ioutil.ReadAll(req.Body)
+			json.Unmarshal(b, &sent)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(strings.NewReader(body)),
+			}, nil
+		}),
+	}
+	client := graphql.NewClient("", graphql.WithHTTPClient(httpClient),
+		graphql.WithCostDryRun(graphql.CostDirective("cost")))
+	ctx := context.Background()
+
+	body = `{"data":null,"extensions":{"cost":{"requestedQueryCost":42,"throttleStatus":{"maximumAvailable":1000,"currentlyAvailable":900}}}}`
+	estimate, err := client.EstimateCost(ctx, graphql.NewRequest(`query Q { a }`))
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(sent.Query).Should(Equal(`query Q @cost(dryRun: true) { a }`))
+	Expect(estimate.Cost).Should(Equal(42.0))
+	Expect(estimate.Available).Should(Equal(900.0))
+	Expect(estimate.Maximum).Should(Equal(1000.0))
+
+	body = `{"errors":[{"message":"dry run"}],"extensions":{"cost":7}}`
+	estimate, err = client.EstimateCost(ctx, graphql.NewRequest(`query Q { a }`))
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(estimate.Cost).Should(Equal(7.0))
+
+	body = `{"errors":[{"message":"unknown directive"}]}`
+	_, err = client.EstimateCost(ctx, graphql.NewRequest(`query Q { a }`))
+	Expect(err).Should(MatchError("graphql: unknown directive"))
+
+	body = `{"data":{"a":1}}`
+	_, err = client.EstimateCost(ctx, graphql.NewRequest(`query Q { a }`))
+	Expect(err).Should(MatchError("graphql: no cost in response extensions"))
+
+	// requests aren't run to cost them
+	sent = graphql.Request{}
+	_, err = graphql.NewClient("", graphql.WithHTTPClient(httpClient)).EstimateCost(ctx, graphql.NewRequest(`mutation M { a }`))
+	Expect(err).Should(Equal(graphql.ErrNoCostDryRun))
+	Expect(sent.Query).Should(BeEmpty())
+}
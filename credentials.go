@@ -0,0 +1,113 @@
+package graphql
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// Credentials authenticate the HTTP requests made by a client.
+type Credentials interface {
+	// Apply adds the credentials to the request, for example by setting
+	// its Authorization header or signing it.
+	Apply(r *http.Request) error
+}
+
+// CredentialsFunc is a function that implements Credentials.
+type CredentialsFunc func(r *http.Request) error
+
+// Apply calls f(r).
+func (f CredentialsFunc) Apply(r *http.Request) error {
+	return f(r)
+}
+
+// BearerToken makes Credentials that send token as a bearer token.
+func BearerToken(token string) Credentials {
+	return CredentialsFunc(func(r *http.Request) error {
+		r.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
+
+// BasicAuth makes Credentials that send a username and password with
+// basic authentication.
+func BasicAuth(username, password string) Credentials {
+	credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	return CredentialsFunc(func(r *http.Request) error {
+		r.Header.Set("Authorization", "Basic "+credentials)
+		return nil
+	})
+}
+
+// WithCredentials sets the credentials the client authenticates with.
+// They can be replaced later with SetCredentials.
+//  NewClient(endpoint, WithCredentials(BearerToken(token)))
+func WithCredentials(creds Credentials) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.SetCredentials(creds)
+	})
+}
+
+// SetCredentials replaces the credentials the client authenticates with,
+// taking effect from the next request. It is safe to call while requests
+// are being made, so tokens and keys can be rotated without making a
+// new Client. Pass nil to stop authenticating.
+func (c *Client) SetCredentials(creds Credentials) {
+	c.credentials.Store(credentialsHolder{creds})
+	if c.shadowClient != nil {
+		c.shadowClient.SetCredentials(creds)
+	}
+	if c.dryRunClient != nil {
+		c.dryRunClient.SetCredentials(creds)
+	}
+}
+
+// credentialsHolder lets nil credentials be stored in an atomic.Value,
+// which needs values of the same concrete type.
+type credentialsHolder struct {
+	creds Credentials
+}
+
+// applyCredentials authenticates r with the client's current
+// credentials.
+func (c *Client) applyCredentials(r *http.Request) error {
+	holder, _ := c.credentials.Load().(credentialsHolder)
+	if holder.creds == nil {
+		return nil
+	}
+	err := c.callHook("credentials", func() error {
+		return holder.creds.Apply(r)
+	})
+	if err != nil {
+		return errors.Wrap(err, "applying credentials")
+	}
+	return nil
+}
+
+// CertificateHolder holds a client certificate that can be replaced while
+// connections are being made. Use its GetClientCertificate method in the
+// tls.Config of the client's transport, so that new connections present
+// the latest certificate.
+//  holder := &graphql.CertificateHolder{}
+//  holder.Set(cert)
+//  transport.TLSClientConfig = &tls.Config{GetClientCertificate: holder.GetClientCertificate}
+type CertificateHolder struct {
+	cert atomic.Value
+}
+
+// Set replaces the certificate.
+func (h *CertificateHolder) Set(cert tls.Certificate) {
+	h.cert.Store(&cert)
+}
+
+// GetClientCertificate gets the current certificate, or an empty one,
+// which sends no certificate, if none has been set.
+func (h *CertificateHolder) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	if cert, ok := h.cert.Load().(*tls.Certificate); ok {
+		return cert, nil
+	}
+	return &tls.Certificate{}, nil
+}
@@ -0,0 +1,61 @@
+package graphql_test
+
+import (
+	"context"
+	"crypto/tls"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+func TestSetCredentials(t *testing.T) {
+	RegisterTestingT(t)
+	var auth string
+	httpClient := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			auth = req.Header.Get("Authorization")
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(strings.NewReader(`{"data":{}}`)),
+			}, nil
+		}),
+	}
+	client := graphql.NewClient("", graphql.WithHTTPClient(httpClient),
+		graphql.WithCredentials(graphql.BearerToken("one")))
+	ctx := context.Background()
+
+	Expect(client.Run(ctx, graphql.NewRequest(`{ a }`), nil)).Should(Succeed())
+	Expect(auth).Should(Equal("Bearer one"))
+
+	client.SetCredentials(graphql.BasicAuth("mat", "secret"))
+	Expect(client.Run(ctx, graphql.NewRequest(`{ a }`), nil)).Should(Succeed())
+	Expect(auth).Should(Equal("Basic bWF0OnNlY3JldA=="))
+
+	client.SetCredentials(nil)
+	Expect(client.Run(ctx, graphql.NewRequest(`{ a }`), nil)).Should(Succeed())
+	Expect(auth).Should(BeEmpty())
+
+	client.SetCredentials(graphql.CredentialsFunc(func(r *http.Request) error {
+		return errors.New("key expired")
+	}))
+	err := client.Run(ctx, graphql.NewRequest(`{ a }`), nil)
+	Expect(err).Should(MatchError("applying credentials: key expired"))
+}
+
+func TestCertificateHolder(t *testing.T) {
+	RegisterTestingT(t)
+	holder := &graphql.CertificateHolder{}
+	cert, err := holder.GetClientCertificate(nil)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(cert.Certificate).Should(BeEmpty())
+
+	holder.Set(tls.Certificate{Certificate: [][]byte{[]byte("der")}})
+	cert, err = holder.GetClientCertificate(nil)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(cert.Certificate).Should(Equal([][]byte{[]byte("der")}))
+}
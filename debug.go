@@ -0,0 +1,70 @@
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+)
+
+// DebugState is a snapshot of a client's internals, for triage.
+type DebugState struct {
+	// Endpoint is the client's endpoint, without its user information
+	// or the values of query parameters the client's Redaction redacts,
+	// such as tokens.
+	Endpoint string `json:"endpoint"`
+	// InFlight is the number of requests being made.
+	InFlight int `json:"inFlight"`
+	// Detached is the number of detached calls running, and Draining
+	// whether Drain has been called.
+	Detached int  `json:"detached"`
+	Draining bool `json:"draining"`
+	// WarmedUp is whether Warmup has succeeded.
+	WarmedUp bool `json:"warmedUp"`
+	// Capabilities are the server's capabilities, if they have been
+	// probed.
+	Capabilities *Capabilities `json:"capabilities,omitempty"`
+	// Health is the health of each operation, if the client has a
+	// HealthTracker.
+	Health []OperationHealth `json:"health,omitempty"`
+}
+
+// DebugState gets a snapshot of the client's internals.
+func (c *Client) DebugState() DebugState {
+	state := DebugState{
+		Endpoint: c.redactor().URL(c.endpoint),
+		InFlight: int(atomic.LoadInt32(&c.inFlight)),
+	}
+	c.detached.mu.Lock()
+	state.Detached = c.detached.running
+	state.Draining = c.detached.draining
+	c.detached.mu.Unlock()
+	c.warmup.mu.Lock()
+	state.WarmedUp = c.warmup.done
+	c.warmup.mu.Unlock()
+	c.capabilities.mu.Lock()
+	if c.capabilities.capabilities != nil {
+		capabilities := *c.capabilities.capabilities
+		state.Capabilities = &capabilities
+	}
+	c.capabilities.mu.Unlock()
+	if c.health != nil {
+		state.Health = c.health.Operations()
+	}
+	return state
+}
+
+// DebugHandler serves the client's DebugState as JSON, to be mounted on
+// a service's debug mux. It can also be published with expvar.
+//  mux.Handle("/debug/graphql", client.DebugHandler())
+//  expvar.Publish("graphql", expvar.Func(func() interface{} { return client.DebugState() }))
+func (c *Client) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := json.MarshalIndent(c.DebugState(), "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(b)
+	})
+}
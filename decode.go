@@ -0,0 +1,127 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// DefaultMaxDepth is the maximum nesting depth of objects and arrays
+// allowed in a response body, unless changed with WithMaxDepth.
+const DefaultMaxDepth = 1000
+
+// DecodeError is returned when a response body can't be decoded.
+type DecodeError struct {
+	// Offset is the byte offset in the body at which the problem
+	// was found, or -1 if unknown.
+	Offset int64
+	// Reason describes the problem.
+	Reason string
+	// Err is the underlying error, if any.
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	if e.Offset >= 0 {
+		return fmt.Sprintf("decoding response: %s (offset %d)", e.Reason, e.Offset)
+	}
+	return "decoding response: " + e.Reason
+}
+
+// WithMaxDepth limits the nesting depth of objects and arrays in
+// response bodies. Deeper responses fail with a DecodeError.
+// Pass a value <= 0 to remove the limit.
+//  NewClient(endpoint, WithMaxDepth(64))
+func WithMaxDepth(depth int) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.maxDepth = depth
+	})
+}
+
+// WithMaxResponseSize limits the size of response bodies in bytes.
+// Larger responses fail with a DecodeError without being read in full.
+//  NewClient(endpoint, WithMaxResponseSize(10<<20))
+func WithMaxResponseSize(size int64) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.maxResponseSize = size
+	})
+}
+
+// readBody reads the response body, enforcing the response size limit.
+func (c *Client) readBody(r io.Reader) ([]byte, error) {
+	if c.maxResponseSize > 0 {
+		r = io.LimitReader(r, c.maxResponseSize+1)
+	}
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, r); err != nil {
+		return nil, err
+	}
+	if c.maxResponseSize > 0 && int64(buf.Len()) > c.maxResponseSize {
+		return nil, &DecodeError{
+			Offset: c.maxResponseSize,
+			Reason: fmt.Sprintf("response body exceeds %d bytes", c.maxResponseSize),
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// decode checks body against the client's limits and unmarshals it
+// into v.
+func (c *Client) decode(body []byte, v interface{}) error {
+	if err := checkJSON(body, c.maxDepth); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		decodeErr := &DecodeError{Offset: -1, Reason: err.Error(), Err: err}
+		switch err := err.(type) {
+		case *json.SyntaxError:
+			decodeErr.Offset = err.Offset
+		case *json.UnmarshalTypeError:
+			decodeErr.Offset = err.Offset
+		}
+		return decodeErr
+	}
+	return nil
+}
+
+// checkJSON rejects bodies that are not valid UTF-8 or are nested more
+// than maxDepth levels deep, without allocating for each value.
+func checkJSON(body []byte, maxDepth int) error {
+	if !utf8.Valid(body) {
+		return &DecodeError{Offset: -1, Reason: "invalid UTF-8"}
+	}
+	if maxDepth <= 0 {
+		return nil
+	}
+	depth := 0
+	inString := false
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		if inString {
+			switch c {
+			case '\\':
+				i++
+			case '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+			if depth > maxDepth {
+				return &DecodeError{
+					Offset: int64(i),
+					Reason: fmt.Sprintf("nesting exceeds maximum depth of %d", maxDepth),
+				}
+			}
+		case '}', ']':
+			depth--
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package graphql_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+func bodyClient(body string, opts ...graphql.ClientOption) *graphql.Client {
+	httpClient := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(strings.NewReader(body)),
+			}, nil
+		}),
+	}
+	return graphql.NewClient("", append(opts, graphql.WithHTTPClient(httpClient))...)
+}
+
+func TestDecodeErrors(t *testing.T) {
+	RegisterTestingT(t)
+	ctx := context.Background()
+	var resp map[string]interface{}
+
+	deep := `{"data":` + strings.Repeat("[", 100) + strings.Repeat("]", 100) + `}`
+	err := bodyClient(deep, graphql.WithMaxDepth(10)).Run(ctx, graphql.NewRequest(`{}`), &resp)
+	decodeErr, ok := errors.Cause(err).(*graphql.DecodeError)
+	Expect(ok).Should(BeTrue())
+	Expect(decodeErr.Offset).Should(BeEquivalentTo(17))
+
+	err = bodyClient(deep).Run(ctx, graphql.NewRequest(`{}`), nil)
+	Expect(err).ShouldNot(HaveOccurred())
+
+	err = bodyClient("{\"data\":{\"a\":\"\xff\"}}").Run(ctx, graphql.NewRequest(`{}`), &resp)
+	_, ok = err.(*graphql.DecodeError)
+	Expect(ok).Should(BeTrue())
+
+	err = bodyClient(`{"data":{"a":1e400}}`).Run(ctx, graphql.NewRequest(`{}`), &resp)
+	_, ok = err.(*graphql.DecodeError)
+	Expect(ok).Should(BeTrue())
+
+	err = bodyClient(`{"data":{"a":"`+strings.Repeat("x", 100)+`"}}`, graphql.WithMaxResponseSize(50)).Run(ctx, graphql.NewRequest(`{}`), &resp)
+	_, ok = err.(*graphql.DecodeError)
+	Expect(ok).Should(BeTrue())
+}
+
+func FuzzDecodeResponse(f *testing.F) {
+	f.Add([]byte(`{"data":{"something":"yes"}}`))
+	f.Add([]byte(`{"errors":[{"message":"Something went wrong"}]}`))
+	f.Add([]byte(`{"data":[[[[[[[[[[[[]]]]]]]]]]]]}`))
+	f.Add([]byte(`{"data":{"n":123456789012345678901234567890e999}}`))
+	f.Fuzz(func(t *testing.T, body []byte) {
+		var resp map[string]interface{}
+		err := bodyClient(string(body), graphql.WithMaxDepth(32)).Run(context.Background(), graphql.NewRequest(`{}`), &resp)
+		if err == nil {
+			return
+		}
+		if _, ok := err.(*graphql.DecodeError); ok {
+			return
+		}
+		if strings.HasPrefix(err.Error(), "graphql: ") {
+			return
+		}
+		t.Fatalf("unexpected error type %T: %v", err, err)
+	})
+}
@@ -0,0 +1,52 @@
+package graphql
+
+import (
+	"context"
+)
+
+// Decoders make the values that the data of operations, by their
+// MetricsName, is decoded into, for responses to a mix of operations.
+//  decoders := graphql.Decoders{
+//      "User":         graphql.DecodeAs[UserData](),
+//      "Organization": graphql.DecodeAs[OrgData](),
+//  }
+type Decoders map[string]func() interface{}
+
+// DecodeAs makes a Decoders function that makes a new *T.
+func DecodeAs[T any]() func() interface{} {
+	return func() interface{} {
+		return new(T)
+	}
+}
+
+// RunBatchDecoders runs the requests as RunBatch does, decoding the data
+// of each response into a new value made by the decoder of its
+// operation, which is the Data of its BatchResult. The data of
+// operations without a decoder isn't decoded.
+//  results, err := client.RunBatchDecoders(ctx, reqs, decoders)
+//  for _, result := range results {
+//      switch data := result.Data.(type) {
+//      case *UserData:
+//          // ...
+//      case *OrgData:
+//          // ...
+//      }
+//  }
+func (c *Client) RunBatchDecoders(ctx context.Context, reqs []*Request, decoders Decoders) ([]BatchResult, error) {
+	resps := make([]interface{}, len(reqs))
+	for i, req := range reqs {
+		if decoder, ok := decoders[req.MetricsName()]; ok {
+			resps[i] = decoder()
+		}
+	}
+	results, err := c.RunBatch(ctx, reqs, resps)
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		if results[i].Status != BatchFailed {
+			results[i].Data = resps[i]
+		}
+	}
+	return results, nil
+}
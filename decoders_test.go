@@ -0,0 +1,60 @@
+package graphql_test
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestRunBatchDecoders(t *testing.T) {
+	RegisterTestingT(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		io.WriteString(w, `[
+			{"data": {"user": {"name": "Mat"}}},
+			{"data": {"organization": {"login": "acme"}}},
+			{"data": null, "errors": [{"message": "not found"}]},
+			{"data": {"viewer": {"id": "1"}}}
+		]`)
+	}))
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL)
+	type userData struct {
+		User struct {
+			Name string
+		}
+	}
+	type orgData struct {
+		Organization struct {
+			Login string
+		}
+	}
+	reqs := []*graphql.Request{
+		graphql.NewRequest(`query User { user { name } }`),
+		graphql.NewRequest(`query Org { organization { login } }`),
+		graphql.NewRequest(`query User { user { name } }`),
+		graphql.NewRequest(`query Viewer { viewer { id } }`),
+	}
+	results, err := client.RunBatchDecoders(context.Background(), reqs, graphql.Decoders{
+		"User": graphql.DecodeAs[userData](),
+		"Org":  graphql.DecodeAs[orgData](),
+	})
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(results).Should(HaveLen(4))
+	Expect(results[0].Data).Should(BeAssignableToTypeOf(&userData{}))
+	Expect(results[0].Data.(*userData).User.Name).Should(Equal("Mat"))
+	Expect(results[1].Data).Should(BeAssignableToTypeOf(&orgData{}))
+	Expect(results[1].Data.(*orgData).Organization.Login).Should(Equal("acme"))
+
+	Expect(results[2].Status).Should(Equal(graphql.BatchFailed))
+	Expect(results[2].Data).Should(BeNil())
+	// operations without a decoder aren't decoded
+	Expect(results[3].Status).Should(Equal(graphql.BatchOK))
+	Expect(results[3].Data).Should(BeNil())
+}
@@ -0,0 +1,67 @@
+package graphql
+
+// WithDefaultVars sets variables sent with every request whose operation
+// declares them, unless the request sets them itself.
+//  NewClient(endpoint, WithDefaultVars(map[string]interface{}{
+//      "locale": "en-GB",
+//      "tenant": tenantID,
+//  }))
+func WithDefaultVars(vars map[string]interface{}) ClientOption {
+	return ClientOption(func(client *Client) {
+		if client.defaultVars == nil {
+			client.defaultVars = make(map[string]interface{}, len(vars))
+		}
+		for k, v := range vars {
+			client.defaultVars[k] = v
+		}
+	})
+}
+
+// VarDefault sets a default for a variable, used if the variable is not
+// set with Var. Request defaults override the client's defaults (see
+// WithDefaultVars).
+func (req *Request) VarDefault(key string, value interface{}) {
+	if req.defaultVars == nil {
+		req.defaultVars = make(map[string]interface{})
+	}
+	req.defaultVars[key] = value
+}
+
+// applyDefaultVars gets the request with default variables added. Only
+// variables declared by the operation are added; if the document can't be
+// parsed, all of them are. The request is copied if it needs changing.
+func (c *Client) applyDefaultVars(req *Request) *Request {
+	if len(c.defaultVars) == 0 && len(req.defaultVars) == 0 {
+		return req
+	}
+	var declared map[string]bool
+	if doc, err := parseDocument(req.Query); err == nil {
+		declared = make(map[string]bool)
+		for _, op := range doc.operations {
+			if req.OperationName != "" && op.name != req.OperationName {
+				continue
+			}
+			for _, vd := range op.varDefs {
+				declared[vd.name] = true
+			}
+		}
+	}
+	out := req
+	apply := func(defaults map[string]interface{}) {
+		for k, v := range defaults {
+			if _, ok := out.Variables[k]; ok {
+				continue
+			}
+			if declared != nil && !declared[k] {
+				continue
+			}
+			if out == req {
+				out = req.clone()
+			}
+			out.Var(k, v)
+		}
+	}
+	apply(req.defaultVars)
+	apply(c.defaultVars)
+	return out
+}
@@ -0,0 +1,47 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestDefaultVars(t *testing.T) {
+	RegisterTestingT(t)
+	var vars map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphql.Request
+		json.NewDecoder(r.Body).Decode(&req)
+		vars = req.Variables
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL, graphql.WithDefaultVars(map[string]interface{}{
+		"locale": "en-GB",
+		"tenant": "acme",
+		"first":  10,
+	}))
+
+	req := graphql.NewRequest(`query ($locale: String, $tenant: ID, $first: Int) { users { id } }`)
+	req.Var("tenant", "other")
+	req.VarDefault("first", 20)
+	err := client.Run(context.Background(), req, nil)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(vars).Should(Equal(map[string]interface{}{
+		"locale": "en-GB",
+		"tenant": "other",
+		"first":  float64(20),
+	}))
+	Expect(req.Variables).Should(HaveLen(1))
+
+	// undeclared defaults aren't sent
+	err = client.Run(context.Background(), graphql.NewRequest(`query ($locale: String) { users { id } }`), nil)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(vars).Should(Equal(map[string]interface{}{"locale": "en-GB"}))
+}
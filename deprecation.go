@@ -0,0 +1,63 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Deprecation is a use of a deprecated field or enum value in a
+// query document.
+type Deprecation struct {
+	// Coordinate names what is deprecated, such as User.login for a
+	// field or Role.GUEST for an enum value.
+	Coordinate string
+	// Reason is the deprecation reason given by the schema, which
+	// usually says what to use instead.
+	Reason string
+	// Location is where it is used, unless it is used in a shared
+	// fragment, when Fragment names the fragment instead.
+	Location *Location
+	Fragment string
+}
+
+func (d Deprecation) String() string {
+	var b strings.Builder
+	if d.Location != nil {
+		fmt.Fprintf(&b, "%d:%d: ", d.Location.Line, d.Location.Column)
+	}
+	fmt.Fprintf(&b, "%s is deprecated", d.Coordinate)
+	if d.Fragment != "" {
+		fmt.Fprintf(&b, " (in fragment %q)", d.Fragment)
+	}
+	if d.Reason != "" {
+		fmt.Fprintf(&b, ": %s", d.Reason)
+	}
+	return b.String()
+}
+
+// Deprecations finds the deprecated fields and enum values that a query
+// document uses, so that operations can be migrated before the server
+// removes them. Fragments are documents of shared fragment definitions
+// that the query may use, as for Validate.
+//
+// Only the parts of the document that are valid are checked, so
+// documents should be validated too. Enum values passed in variables
+// can't be seen, and aren't reported.
+func (s *Schema) Deprecations(query string, fragments ...string) []Deprecation {
+	return s.check(query, fragments).deprecations
+}
+
+// deprecated records a use of something deprecated at the byte offset.
+func (v *validator) deprecated(offset int, coordinate, reason string) {
+	d := Deprecation{Coordinate: coordinate, Reason: reason, Fragment: v.inExternal}
+	if v.inExternal == "" {
+		loc := location(v.src, offset)
+		d.Location = &loc
+	}
+	key := "deprecated:" + d.String()
+	if v.seen[key] {
+		return
+	}
+	v.seen[key] = true
+	v.deprecations = append(v.deprecations, d)
+}
@@ -0,0 +1,29 @@
+package graphql_test
+
+import (
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestDeprecations(t *testing.T) {
+	RegisterTestingT(t)
+	schema := loadSchema()
+	deprecations := schema.Deprecations(`query {
+  users(role: GUEST) {
+    email
+    emails
+    ...Contact
+  }
+}`, `fragment Contact on User { email }`)
+	Expect(deprecations).Should(Equal([]graphql.Deprecation{
+		{Coordinate: "Role.GUEST", Reason: "Guests were removed.", Location: &graphql.Location{Line: 2, Column: 3}},
+		{Coordinate: "User.email", Reason: "Use emails.", Location: &graphql.Location{Line: 3, Column: 5}},
+		{Coordinate: "User.email", Reason: "Use emails.", Fragment: "Contact"},
+	}))
+	Expect(deprecations[0].String()).Should(Equal("2:3: Role.GUEST is deprecated: Guests were removed."))
+	Expect(deprecations[2].String()).Should(Equal(`User.email is deprecated (in fragment "Contact"): Use emails.`))
+
+	Expect(schema.Deprecations(`{ users(role: ADMIN) { name emails } }`)).Should(BeEmpty())
+}
@@ -0,0 +1,38 @@
+package graphql_test
+
+import (
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// TestDependencies checks that the package imports nothing but the
+// standard library and github.com/pkg/errors; integrations belong in
+// sub-packages.
+func TestDependencies(t *testing.T) {
+	RegisterTestingT(t)
+	files, err := filepath.Glob("*.go")
+	Expect(err).ShouldNot(HaveOccurred())
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, parser.ImportsOnly)
+		Expect(err).ShouldNot(HaveOccurred())
+		for _, imp := range f.Imports {
+			path, err := strconv.Unquote(imp.Path.Value)
+			Expect(err).ShouldNot(HaveOccurred())
+			first := strings.Split(path, "/")[0]
+			if !strings.Contains(first, ".") {
+				continue // standard library
+			}
+			Expect(path).Should(Equal("github.com/pkg/errors"), file)
+		}
+	}
+}
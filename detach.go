@@ -0,0 +1,88 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrDraining is returned by RunDetached once the client has started
+// draining.
+var ErrDraining = errors.New("graphql: client is draining")
+
+// detachedCalls tracks a client's detached calls.
+type detachedCalls struct {
+	mu       sync.Mutex
+	draining bool
+	running  int
+	calls    sync.WaitGroup
+}
+
+// detachedContext has the values of its parent, but not its deadline or
+// cancellation.
+type detachedContext struct {
+	context.Context
+	parent context.Context
+}
+
+func (ctx detachedContext) Value(key interface{}) interface{} {
+	return ctx.parent.Value(key)
+}
+
+// RunDetached runs req in the background, as Run does, on a context
+// that keeps the values of ctx but isn't cancelled with it, so that
+// calls such as audit-log mutations aren't cut short when the inbound
+// request that made them ends. The call is cancelled after timeout, if
+// it is positive. The returned channel gets the call's error; it needn't
+// be read. resp must not be used until it has.
+//
+// Drain waits for detached calls to finish.
+//  client.RunDetached(r.Context(), auditReq, nil, 10*time.Second)
+func (c *Client) RunDetached(ctx context.Context, req *Request, resp interface{}, timeout time.Duration) <-chan error {
+	done := make(chan error, 1)
+	c.detached.mu.Lock()
+	if c.detached.draining {
+		c.detached.mu.Unlock()
+		done <- ErrDraining
+		return done
+	}
+	c.detached.calls.Add(1)
+	c.detached.running++
+	c.detached.mu.Unlock()
+	var cancel context.CancelFunc = func() {}
+	ctx = detachedContext{Context: context.Background(), parent: ctx}
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	go func() {
+		defer c.detached.calls.Done()
+		err := c.Run(ctx, req, resp)
+		cancel()
+		c.detached.mu.Lock()
+		c.detached.running--
+		c.detached.mu.Unlock()
+		done <- err
+	}()
+	return done
+}
+
+// Drain stops the client starting detached calls, and waits for those
+// running to finish, or for ctx to be done, for a graceful shutdown.
+func (c *Client) Drain(ctx context.Context) error {
+	c.detached.mu.Lock()
+	c.detached.draining = true
+	c.detached.mu.Unlock()
+	finished := make(chan struct{})
+	go func() {
+		c.detached.calls.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
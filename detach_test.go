@@ -0,0 +1,66 @@
+package graphql_test
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestRunDetached(t *testing.T) {
+	RegisterTestingT(t)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+			return
+		}
+		io.WriteString(w, `{"data":{"ok":true}}`)
+	}))
+	defer srv.Close()
+	var seen interface{}
+	client := graphql.NewClient(srv.URL, graphql.WithPolicy(func(ctx context.Context, req *graphql.PolicyRequest) error {
+		seen = ctx.Value(tenantKey{})
+		return nil
+	}))
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), tenantKey{}, "acme"))
+	var resp struct{ OK bool }
+	done := client.RunDetached(ctx, graphql.NewRequest(`mutation Audit { ok }`), &resp, time.Minute)
+	cancel()
+	drained := make(chan error, 1)
+	go func() {
+		drained <- client.Drain(context.Background())
+	}()
+	Consistently(drained, 50*time.Millisecond).ShouldNot(Receive())
+	Expect(<-client.RunDetached(context.Background(), graphql.NewRequest(`mutation Audit { ok }`), nil, 0)).Should(Equal(graphql.ErrDraining))
+	close(release)
+	Expect(<-done).Should(Succeed())
+	Expect(resp.OK).Should(BeTrue())
+	Expect(seen).Should(Equal("acme"))
+	Eventually(drained).Should(Receive(BeNil()))
+}
+
+func TestRunDetachedTimeout(t *testing.T) {
+	RegisterTestingT(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL)
+	err := <-client.RunDetached(context.Background(), graphql.NewRequest(`mutation Audit { ok }`), nil, 20*time.Millisecond)
+	Expect(err).Should(HaveOccurred())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	Expect(client.Drain(ctx)).Should(Succeed())
+}
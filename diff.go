@@ -0,0 +1,174 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Difference is a place where two JSON values differ.
+type Difference struct {
+	// Path is the location of the values, such as "data.users[1].name".
+	Path string
+	// A and B are the differing values; nil if missing or null.
+	A, B interface{}
+}
+
+func (d Difference) String() string {
+	return fmt.Sprintf("%s: %s != %s", d.Path, jsonString(d.A), jsonString(d.B))
+}
+
+// DiffOption configures Diff.
+type DiffOption func(*differ)
+
+// IgnorePath makes Diff skip the values at paths matching pattern, and
+// everything beneath them. In patterns, * matches any object key and
+// [*] matches any list index:
+//  IgnorePath("data.users[*].updatedAt")
+func IgnorePath(pattern string) DiffOption {
+	return func(d *differ) {
+		d.ignore = append(d.ignore, pathPattern(pattern))
+	}
+}
+
+// pathPattern compiles a path pattern, as described for IgnorePath, to
+// a regexp matching the paths it covers.
+func pathPattern(pattern string) *regexp.Regexp {
+	quoted := regexp.QuoteMeta(pattern)
+	quoted = strings.Replace(quoted, `\[\*\]`, `\[\d+\]`, -1)
+	quoted = strings.Replace(quoted, `\*`, `[^.\[]+`, -1)
+	return regexp.MustCompile(`^` + quoted + `(?:$|[.\[])`)
+}
+
+// NumericTolerance makes Diff treat numbers as equal if they differ by
+// no more than tolerance.
+func NumericTolerance(tolerance float64) DiffOption {
+	return func(d *differ) {
+		d.tolerance = tolerance
+	}
+}
+
+// Diff compares two JSON documents, such as GraphQL responses,
+// structurally: object key order doesn't matter, and numbers are compared
+// by value. It returns the differences, sorted by path.
+//  diffs, err := graphql.Diff(staging, production, graphql.IgnorePath("extensions"))
+func Diff(a, b []byte, opts ...DiffOption) ([]Difference, error) {
+	va, err := decodeJSON(a)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding first document")
+	}
+	vb, err := decodeJSON(b)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding second document")
+	}
+	d := &differ{}
+	for _, opt := range opts {
+		opt(d)
+	}
+	d.diff("", va, vb)
+	return d.diffs, nil
+}
+
+type differ struct {
+	ignore    []*regexp.Regexp
+	tolerance float64
+	diffs     []Difference
+}
+
+func (d *differ) ignored(path string) bool {
+	for _, re := range d.ignore {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *differ) diff(path string, a, b interface{}) {
+	if d.ignored(path) {
+		return
+	}
+	switch a := a.(type) {
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make(map[string]bool)
+		for k := range a {
+			keys[k] = true
+		}
+		for k := range b {
+			keys[k] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+		for _, k := range sorted {
+			d.diff(joinPath(path, k), a[k], b[k])
+		}
+		return
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			break
+		}
+		for i := range a {
+			d.diff(path+"["+strconv.Itoa(i)+"]", a[i], b[i])
+		}
+		return
+	case json.Number:
+		if b, ok := b.(json.Number); ok {
+			fa, errA := a.Float64()
+			fb, errB := b.Float64()
+			if errA == nil && errB == nil && math.Abs(fa-fb) <= d.tolerance {
+				return
+			}
+		}
+	default:
+		if a == b {
+			return
+		}
+	}
+	d.diffs = append(d.diffs, Difference{Path: path, A: a, B: b})
+}
+
+func decodeJSON(b []byte) (interface{}, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func jsonString(v interface{}) string {
+	if v == nil {
+		return "null"
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
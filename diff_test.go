@@ -0,0 +1,34 @@
+package graphql_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestDiff(t *testing.T) {
+	RegisterTestingT(t)
+	a := []byte(`{"data":{"users":[{"name":"Mat","score":1.0,"updatedAt":"1"}],"total":10}}`)
+	b := []byte(`{"data":{"total":10.0,"users":[{"score":1.001,"updatedAt":"2","name":"Matt"}]}}`)
+
+	diffs, err := graphql.Diff(a, b)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(diffs).Should(HaveLen(3))
+	Expect(diffs[0].String()).Should(Equal(`data.users[0].name: "Mat" != "Matt"`))
+	Expect(diffs[1].Path).Should(Equal("data.users[0].score"))
+	Expect(diffs[2].Path).Should(Equal("data.users[0].updatedAt"))
+
+	diffs, err = graphql.Diff(a, b,
+		graphql.IgnorePath("data.users[*].updatedAt"),
+		graphql.IgnorePath("data.*[*].name"),
+		graphql.NumericTolerance(0.01),
+	)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(diffs).Should(BeEmpty())
+
+	diffs, err = graphql.Diff([]byte(`{"a":[1,2]}`), []byte(`{"a":[1]}`))
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(diffs).Should(Equal([]graphql.Difference{{Path: "a", A: []interface{}{json.Number("1"), json.Number("2")}, B: []interface{}{json.Number("1")}}}))
+}
@@ -0,0 +1,41 @@
+package graphql
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+)
+
+// Digest header names for WithContentDigest.
+const (
+	// ContentDigestHeader and ReprDigestHeader are the digest headers of
+	// RFC 9530, sent as sha-256=:<base64 digest>:.
+	ContentDigestHeader = "Content-Digest"
+	ReprDigestHeader    = "Repr-Digest"
+	// DigestHeader is the older digest header of RFC 3230, sent as
+	// SHA-256=<base64 digest>.
+	DigestHeader = "Digest"
+)
+
+// WithContentDigest sends the SHA-256 digest of the body of each POST
+// request in the named header, as required by some CDNs to cache posted
+// requests. The digest is of the body as sent, after plugins and other
+// request handling. name is ContentDigestHeader, ReprDigestHeader or
+// DigestHeader.
+//  NewClient(endpoint, WithContentDigest(ReprDigestHeader))
+func WithContentDigest(name string) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.digestHeader = http.CanonicalHeaderKey(name)
+	})
+}
+
+// digest sets the digest header of the request body b in header.
+func (c *Client) digest(header http.Header, b []byte) {
+	sum := sha256.Sum256(b)
+	encoded := base64.StdEncoding.EncodeToString(sum[:])
+	if c.digestHeader == DigestHeader {
+		header.Set(c.digestHeader, "SHA-256="+encoded)
+		return
+	}
+	header.Set(c.digestHeader, "sha-256=:"+encoded+":")
+}
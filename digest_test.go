@@ -0,0 +1,49 @@
+package graphql_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestContentDigest(t *testing.T) {
+	RegisterTestingT(t)
+	var (
+		body   []byte
+		header http.Header
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = ioutil.ReadAll(r.Body)
+		header = r.Header
+		io.WriteString(w, `{"data":{"ok":true}}`)
+	}))
+	defer srv.Close()
+	digest := func() string {
+		sum := sha256.Sum256(body)
+		return base64.StdEncoding.EncodeToString(sum[:])
+	}
+	var resp map[string]interface{}
+
+	client := graphql.NewClient(srv.URL, graphql.WithContentDigest(graphql.ReprDigestHeader))
+	req := graphql.NewRequest(`query ($id: ID) { ok }`)
+	req.Var("id", "1")
+	Expect(client.Run(context.Background(), req, &resp)).Should(Succeed())
+	Expect(header.Get("Repr-Digest")).Should(Equal("sha-256=:" + digest() + ":"))
+	Expect(req.Header).Should(BeNil())
+
+	client = graphql.NewClient(srv.URL, graphql.WithContentDigest("digest"))
+	Expect(client.Run(context.Background(), req, &resp)).Should(Succeed())
+	Expect(header.Get("Digest")).Should(Equal("SHA-256=" + digest()))
+
+	client = graphql.NewClient(srv.URL)
+	Expect(client.Run(context.Background(), req, &resp)).Should(Succeed())
+	Expect(header.Get("Digest")).Should(BeEmpty())
+}
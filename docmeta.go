@@ -0,0 +1,150 @@
+package graphql
+
+import (
+	"container/list"
+	"sort"
+	"sync"
+)
+
+// documentCacheSize is the number of query documents whose metadata is
+// kept. Services usually send a few hundred static documents, so with
+// room to spare, generated documents only evict each other.
+const documentCacheSize = 1024
+
+// documentMeta is what metrics, traces and logs need to know about a
+// query document, read once for each distinct document. It isn't
+// changed once made.
+type documentMeta struct {
+	operations []Operation
+	err        error
+	// rootFields are the sorted names of the top-level fields each
+	// operation selects, by operation name.
+	rootFields map[string][]string
+	hash       string
+}
+
+// operation gets the operation named name, or the only operation in
+// the document if name is empty.
+func (m *documentMeta) operation(name string) (Operation, error) {
+	if m.err != nil {
+		return Operation{}, m.err
+	}
+	return selectOperation(m.operations, name)
+}
+
+// documentCache is a least recently used cache of documentMeta by
+// query document. It is safe for concurrent use.
+type documentCache struct {
+	size int
+
+	mu      sync.Mutex
+	order   *list.List // of *documentCacheEntry, most recently used first
+	entries map[string]*list.Element
+}
+
+type documentCacheEntry struct {
+	query string
+	meta  *documentMeta
+}
+
+func newDocumentCache(size int) *documentCache {
+	return &documentCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+var documents = newDocumentCache(documentCacheSize)
+
+// documentInfo gets the metadata of the query document.
+func documentInfo(query string) *documentMeta {
+	return documents.get(query)
+}
+
+// get gets the metadata of the query document, reading it if it isn't
+// cached. Documents are read without the lock held, so a document may be
+// read more than once by concurrent callers; the first read is kept.
+func (c *documentCache) get(query string) *documentMeta {
+	c.mu.Lock()
+	if e, ok := c.entries[query]; ok {
+		c.order.MoveToFront(e)
+		c.mu.Unlock()
+		return e.Value.(*documentCacheEntry).meta
+	}
+	c.mu.Unlock()
+	meta := readDocumentMeta(query)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[query]; ok {
+		c.order.MoveToFront(e)
+		return e.Value.(*documentCacheEntry).meta
+	}
+	c.entries[query] = c.order.PushFront(&documentCacheEntry{query: query, meta: meta})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*documentCacheEntry).query)
+	}
+	return meta
+}
+
+// readDocumentMeta reads the metadata of the query document.
+func readDocumentMeta(query string) *documentMeta {
+	meta := &documentMeta{hash: QueryHash(query)}
+	meta.operations, meta.err = ParseOperations(query)
+	doc, err := parseDocument(query)
+	if err != nil {
+		return meta
+	}
+	fragments := make(map[string]*fragmentDef, len(doc.fragments))
+	for _, frag := range doc.fragments {
+		fragments[frag.name] = frag
+	}
+	meta.rootFields = make(map[string][]string, len(doc.operations))
+	for _, op := range doc.operations {
+		names := make(map[string]bool)
+		collectFieldNames(op.selections, fragments, names, make(map[string]bool))
+		fields := make([]string, 0, len(names))
+		for name := range names {
+			fields = append(fields, name)
+		}
+		sort.Strings(fields)
+		meta.rootFields[op.name] = fields
+	}
+	return meta
+}
+
+// collectFieldNames adds the names of the fields of the selection set to
+// names, looking into fragments. seen holds the fragments spread so far,
+// so that cycles terminate.
+func collectFieldNames(selections []selection, fragments map[string]*fragmentDef, names, seen map[string]bool) {
+	for _, sel := range selections {
+		switch sel := sel.(type) {
+		case *field:
+			names[sel.name] = true
+		case *inlineFragment:
+			collectFieldNames(sel.selections, fragments, names, seen)
+		case *fragmentSpread:
+			frag, ok := fragments[sel.name]
+			if !ok || seen[sel.name] {
+				continue
+			}
+			seen[sel.name] = true
+			collectFieldNames(frag.selections, fragments, names, seen)
+		}
+	}
+}
+
+// RootFields gets the names of the top-level fields the request's
+// operation selects, sorted, including those selected through
+// fragments. It is nil if the document can't be read.
+func (req *Request) RootFields() []string {
+	meta := documentInfo(req.Query)
+	op, err := meta.operation(req.OperationName)
+	if err != nil {
+		return nil
+	}
+	fields := meta.rootFields[op.Name]
+	return append([]string(nil), fields...)
+}
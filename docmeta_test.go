@@ -0,0 +1,53 @@
+package graphql_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestRootFields(t *testing.T) {
+	RegisterTestingT(t)
+	req := graphql.NewRequest(`
+query Users { users { id } me: viewer { id } ...Counts }
+mutation Save { save { ok } }
+fragment Counts on Query { userCount ... on Query { users { name } } ...Counts }`)
+	req.OperationName = "Users"
+	Expect(req.RootFields()).Should(Equal([]string{"userCount", "users", "viewer"}))
+	req.OperationName = "Save"
+	Expect(req.RootFields()).Should(Equal([]string{"save"}))
+
+	// the fields are copies
+	req.RootFields()[0] = "changed"
+	Expect(req.RootFields()).Should(Equal([]string{"save"}))
+
+	req.OperationName = ""
+	Expect(req.RootFields()).Should(BeNil())
+	Expect(graphql.NewRequest(`{ users { id }`).RootFields()).Should(BeNil())
+	Expect(graphql.NewRequest(`{ __typename }`).RootFields()).Should(Equal([]string{"__typename"}))
+}
+
+func TestOperationConcurrent(t *testing.T) {
+	RegisterTestingT(t)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// more documents than are cached, so some are evicted
+			for j := 0; j < 2000; j++ {
+				name := fmt.Sprintf("Q%d", (i+j)%1500)
+				req := graphql.NewRequest("query " + name + " { f" + name + " }")
+				op, err := req.Operation()
+				if err != nil || op.Name != name || req.MetricsName() != name || req.RootFields()[0] != "f"+name {
+					t.Errorf("%s: got %v, %v, %v", name, op, err, req.RootFields())
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,221 @@
+package graphql
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// Operation describes an operation defined in a query document.
+type Operation struct {
+	// Type is the operation type: "query", "mutation" or "subscription".
+	Type string
+	// Name is the name of the operation, or empty if it is anonymous.
+	Name string
+}
+
+// ParseOperations reads the type and name of every operation
+// defined in the query document.
+func ParseOperations(query string) ([]Operation, error) {
+	toks, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+	var ops []Operation
+	depth := 0
+	inHeader := false
+	for i := 0; i < len(toks); i++ {
+		t := toks[i]
+		switch {
+		case t.kind == tokPunct && (t.value == "{" || t.value == "("):
+			if depth == 0 && t.value == "{" {
+				if !inHeader {
+					// shorthand query
+					ops = append(ops, Operation{Type: "query"})
+				}
+				inHeader = false
+			}
+			depth++
+		case t.kind == tokPunct && (t.value == "}" || t.value == ")"):
+			depth--
+		case depth == 0 && t.kind == tokName && t.value == "fragment" && !inHeader:
+			inHeader = true
+		case depth == 0 && t.kind == tokName && isOperationType(t.value) && !inHeader:
+			inHeader = true
+			op := Operation{Type: t.value}
+			if i+1 < len(toks) && toks[i+1].kind == tokName {
+				op.Name = toks[i+1].value
+			}
+			ops = append(ops, op)
+		}
+	}
+	if depth != 0 {
+		return nil, errors.New("graphql: unbalanced brackets in document")
+	}
+	return ops, nil
+}
+
+// Operation gets the operation the request will execute; the one named
+// by OperationName, or the only operation in the document. Documents
+// are read once and their operations cached, as requests usually reuse
+// a few documents.
+func (req *Request) Operation() (Operation, error) {
+	return documentInfo(req.Query).operation(req.OperationName)
+}
+
+// selectOperation gets the operation named name, or the only operation
+// if name is empty.
+func selectOperation(ops []Operation, name string) (Operation, error) {
+	if name != "" {
+		for _, op := range ops {
+			if op.Name == name {
+				return op, nil
+			}
+		}
+		return Operation{}, errors.Errorf("graphql: unknown operation %q", name)
+	}
+	if len(ops) != 1 {
+		return Operation{}, errors.Errorf("graphql: document has %d operations, OperationName must be set", len(ops))
+	}
+	return ops[0], nil
+}
+
+// SetMetricsName sets a logical label for the operation, used instead of
+// the operation name when recording metrics and traces. Use it for
+// dynamically generated operations so that they don't create a label for
+// every variation.
+//  req.SetMetricsName("sync.users.page")
+func (req *Request) SetMetricsName(name string) {
+	req.metricsName = name
+}
+
+// MetricsName gets the label for the request in metrics and traces: the
+// name set with SetMetricsName, or else the operation name. Anonymous
+// operations are labelled with their type, such as "query".
+func (req *Request) MetricsName() string {
+	if req.metricsName != "" {
+		return req.metricsName
+	}
+	op, err := req.Operation()
+	if err != nil {
+		return "unknown"
+	}
+	if op.Name == "" {
+		return op.Type
+	}
+	return op.Name
+}
+
+func isOperationType(s string) bool {
+	return s == "query" || s == "mutation" || s == "subscription"
+}
+
+type tokenKind int
+
+const (
+	tokPunct tokenKind = iota
+	tokName
+	tokNumber
+	tokString
+)
+
+// token is a lexical token in a query document. start and end are
+// byte offsets into the source.
+type token struct {
+	kind       tokenKind
+	value      string
+	start, end int
+}
+
+// lex splits a query document into tokens, dropping whitespace,
+// commas and comments.
+func lex(src string) ([]token, error) {
+	var toks []token
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case strings.HasPrefix(src[i:], "\ufeff"):
+			i += len("\ufeff")
+		case c == '#':
+			for i < len(src) && src[i] != '\n' && src[i] != '\r' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "..."):
+			toks = append(toks, token{kind: tokPunct, value: "...", start: i, end: i + 3})
+			i += 3
+		case strings.IndexByte("!$&():=@[]{|}", c) >= 0:
+			toks = append(toks, token{kind: tokPunct, value: src[i : i+1], start: i, end: i + 1})
+			i++
+		case isNameStart(c):
+			start := i
+			for i < len(src) && isNameContinue(src[i]) {
+				i++
+			}
+			toks = append(toks, token{kind: tokName, value: src[start:i], start: start, end: i})
+		case c == '-' || (c >= '0' && c <= '9'):
+			start := i
+			i++
+			for i < len(src) && (isNameContinue(src[i]) || src[i] == '.' || ((src[i] == '-' || src[i] == '+') && (src[i-1] == 'e' || src[i-1] == 'E'))) {
+				i++
+			}
+			toks = append(toks, token{kind: tokNumber, value: src[start:i], start: start, end: i})
+		case c == '"':
+			start := i
+			end, err := scanString(src, i)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+			toks = append(toks, token{kind: tokString, value: src[start:end], start: start, end: end})
+		default:
+			r, _ := utf8.DecodeRuneInString(src[i:])
+			return nil, errors.Errorf("graphql: unexpected character %q at offset %d", r, i)
+		}
+	}
+	return toks, nil
+}
+
+// scanString returns the offset just past the string or block string
+// starting at src[start].
+func scanString(src string, start int) (int, error) {
+	if strings.HasPrefix(src[start:], `"""`) {
+		i := start + 3
+		for i < len(src) {
+			if strings.HasPrefix(src[i:], `\"""`) {
+				i += 4
+				continue
+			}
+			if strings.HasPrefix(src[i:], `"""`) {
+				return i + 3, nil
+			}
+			i++
+		}
+		return 0, errors.Errorf("graphql: unterminated block string at offset %d", start)
+	}
+	i := start + 1
+	for i < len(src) {
+		switch src[i] {
+		case '\\':
+			i += 2
+			continue
+		case '"':
+			return i + 1, nil
+		case '\n', '\r':
+			return 0, errors.Errorf("graphql: unterminated string at offset %d", start)
+		}
+		i++
+	}
+	return 0, errors.Errorf("graphql: unterminated string at offset %d", start)
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNameContinue(c byte) bool {
+	return isNameStart(c) || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,52 @@
+package graphql_test
+
+import (
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestParseOperations(t *testing.T) {
+	RegisterTestingT(t)
+	ops, err := graphql.ParseOperations(`
+		# comment with { brackets
+		query GetUser($id: ID! = "{") { user(id: $id) { ...UserFields } }
+		fragment UserFields on User { name }
+		mutation { deleteUser }
+	`)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(ops).Should(Equal([]graphql.Operation{
+		{Type: "query", Name: "GetUser"},
+		{Type: "mutation"},
+	}))
+
+	ops, err = graphql.ParseOperations(`{ items }`)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(ops).Should(Equal([]graphql.Operation{{Type: "query"}}))
+
+	_, err = graphql.ParseOperations(`query { items `)
+	Expect(err).Should(HaveOccurred())
+}
+
+func TestRequestOperation(t *testing.T) {
+	RegisterTestingT(t)
+	req := graphql.NewRequest(`query A { a } query B { b }`)
+	_, err := req.Operation()
+	Expect(err).Should(HaveOccurred())
+
+	req.OperationName = "B"
+	op, err := req.Operation()
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(op).Should(Equal(graphql.Operation{Type: "query", Name: "B"}))
+}
+
+func TestRequestMetricsName(t *testing.T) {
+	RegisterTestingT(t)
+	Expect(graphql.NewRequest(`query GetUser { user }`).MetricsName()).Should(Equal("GetUser"))
+	Expect(graphql.NewRequest(`mutation { deleteUser }`).MetricsName()).Should(Equal("mutation"))
+
+	req := graphql.NewRequest(`query Page_abc123 { users }`)
+	req.SetMetricsName("sync.users.page")
+	Expect(req.MetricsName()).Should(Equal("sync.users.page"))
+}
@@ -0,0 +1,107 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// ErrDryRun is returned for mutations that the client didn't send
+// because it is in dry-run mode.
+var ErrDryRun = errors.New("graphql: mutation not sent in dry run")
+
+// DryRunReport describes a mutation that wasn't sent because the client
+// is in dry-run mode.
+type DryRunReport struct {
+	// Request is the mutation, as it would have been sent.
+	Request *Request
+	// Errors are the errors found validating the mutation against the
+	// client's schema, or returned by the dry-run endpoint.
+	Errors Errors
+	// Data is the data returned by the dry-run endpoint.
+	Data json.RawMessage
+	// Err is the error from the dry-run endpoint, if the request failed.
+	Err error
+}
+
+// WithDryRun puts the client in dry-run mode, for planning changes:
+// mutations are prepared and checked as usual, validated against the
+// schema set with WithSchema, and reported to the function set with
+// WithDryRunReport, but never sent to the client's endpoint. If endpoint
+// isn't empty, they're sent there instead, to a server that checks
+// mutations without applying them. Queries are sent as usual.
+//
+// Mutations fail with the first error found, or ErrDryRun.
+//  NewClient(endpoint,
+//      WithDryRun(""),
+//      WithDryRunReport(printPlan),
+//  )
+func WithDryRun(endpoint string) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.dryRun = true
+		client.dryRunEndpoint = endpoint
+	})
+}
+
+// WithDryRunReport sets the function called with each mutation that
+// isn't sent in dry-run mode.
+func WithDryRunReport(fn func(DryRunReport)) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.dryRunReport = fn
+	})
+}
+
+// withoutDryRun turns off dry-run mode, auditing and health tracking,
+// for the dry-run client itself.
+func withoutDryRun() ClientOption {
+	return ClientOption(func(client *Client) {
+		client.dryRun = false
+		client.dryRunEndpoint = ""
+		client.auditSink = nil
+		client.health = nil
+	})
+}
+
+// dryRuns reports whether the prepared request isn't sent because it
+// is a mutation and the client is in dry-run mode.
+func (c *Client) dryRuns(req *Request) bool {
+	if !c.dryRun {
+		return false
+	}
+	op, err := req.Operation()
+	return err == nil && op.Type == "mutation"
+}
+
+// dryRunMutation validates the prepared mutation req, sends the
+// original request to the dry-run endpoint if there is one, and reports
+// the outcome. It returns the error the mutation fails with.
+func (c *Client) dryRunMutation(ctx context.Context, original, req *Request) error {
+	report := DryRunReport{Request: req}
+	if schema := c.currentSchema(); schema != nil {
+		report.Errors = schema.Validate(req.Query)
+	}
+	if len(report.Errors) == 0 && c.dryRunClient != nil {
+		// the endpoint and route chosen for this client don't apply
+		res, err := c.dryRunClient.do(unroutedContext{ctx}, original)
+		if err != nil {
+			report.Err = err
+		} else {
+			report.Data = res.data
+			report.Errors = res.errors
+		}
+	}
+	if c.dryRunReport != nil {
+		func() {
+			defer c.recoverHook("dry run report", nil)
+			c.dryRunReport(report)
+		}()
+	}
+	switch {
+	case report.Err != nil:
+		return report.Err
+	case len(report.Errors) > 0:
+		return report.Errors[0]
+	}
+	return ErrDryRun
+}
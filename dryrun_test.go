@@ -0,0 +1,92 @@
+package graphql_test
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestDryRun(t *testing.T) {
+	RegisterTestingT(t)
+	var sent []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		sent = append(sent, r.URL.Path)
+		switch {
+		case strings.HasPrefix(string(b), "["):
+			io.WriteString(w, `[{"data":{"user":{"id":"1"}}}]`)
+		case r.URL.Path == "/plan":
+			io.WriteString(w, `{"data":{"createUser":{"id":"2"}}}`)
+		default:
+			io.WriteString(w, `{"data":{"user":{"id":"1"}}}`)
+		}
+	}))
+	defer srv.Close()
+	var reports []graphql.DryRunReport
+	client := graphql.NewClient(srv.URL+"/graphql",
+		graphql.WithSchema(loadSchema()),
+		graphql.WithDryRun(srv.URL+"/plan"),
+		graphql.WithDryRunReport(func(r graphql.DryRunReport) {
+			reports = append(reports, r)
+		}),
+	)
+	ctx := context.Background()
+	var resp map[string]interface{}
+
+	Expect(client.Run(ctx, graphql.NewRequest(`{ user(id: "1") { id } }`), &resp)).Should(Succeed())
+	Expect(sent).Should(Equal([]string{"/graphql"}))
+
+	create := graphql.NewRequest(`mutation { createUser(input: {name: "Mat"}) { id } }`)
+	Expect(client.Run(ctx, create, &resp)).Should(MatchError(graphql.ErrDryRun))
+	Expect(sent).Should(Equal([]string{"/graphql", "/plan"}))
+	Expect(reports).Should(HaveLen(1))
+	Expect(reports[0].Errors).Should(BeEmpty())
+	Expect(reports[0].Data).Should(MatchJSON(`{"createUser":{"id":"2"}}`))
+
+	err := client.Run(ctx, graphql.NewRequest(`mutation { deleteUser }`), &resp)
+	Expect(err).Should(MatchError(`graphql: Cannot query field "deleteUser" on type "Mutation"`))
+	Expect(sent).Should(HaveLen(2))
+	Expect(reports).Should(HaveLen(2))
+
+	results, err := client.RunBatch(ctx, []*graphql.Request{
+		graphql.NewRequest(`{ user(id: "1") { id } }`),
+		create,
+	}, nil)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(sent).Should(Equal([]string{"/graphql", "/plan", "/plan", "/graphql"}))
+	Expect(results[0].Status).Should(Equal(graphql.BatchOK))
+	Expect(results[1].Status).Should(Equal(graphql.BatchFailed))
+	Expect(results[1].Err).Should(Equal(graphql.ErrDryRun))
+}
+
+func TestDryRunRouting(t *testing.T) {
+	RegisterTestingT(t)
+	var sent []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		sent = append(sent, r.URL.Path+" "+r.Header.Get("Authorization"))
+		io.WriteString(w, `{"data":{"createUser":{"id":"2"}}}`)
+	}))
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL+"/graphql",
+		graphql.WithDryRun(srv.URL+"/plan"),
+		graphql.WithReadEndpoints(srv.URL+"/replica"),
+		graphql.WithPolicy(func(ctx context.Context, req *graphql.PolicyRequest) error { return nil }),
+	)
+	client.SetCredentials(graphql.BearerToken("rotated"))
+	ctx := context.Background()
+
+	// the endpoint pinned for the policies isn't the dry-run endpoint
+	create := graphql.NewRequest(`mutation { createUser(input: {name: "Mat"}) { id } }`)
+	Expect(client.Run(ctx, create, nil)).Should(MatchError(graphql.ErrDryRun))
+	create.SetRoute(graphql.RouteReplica)
+	Expect(client.Run(ctx, create, nil)).Should(MatchError(graphql.ErrDryRun))
+	Expect(sent).Should(Equal([]string{"/plan Bearer rotated", "/plan Bearer rotated"}))
+}
@@ -0,0 +1,150 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// FieldCipher encrypts variable values and decrypts response fields, for
+// end-to-end encryption of values that gateways between the client and
+// the server mustn't see. It is usually backed by a key service.
+type FieldCipher interface {
+	// Encrypt gets the ciphertext sent in place of the value at the
+	// path of the operation's variables, given as JSON.
+	Encrypt(ctx context.Context, operation, path string, plaintext []byte) (string, error)
+	// Decrypt gets the value, as JSON, of the ciphertext the server sent
+	// for the field at the path of the operation's response.
+	Decrypt(ctx context.Context, operation, path string, ciphertext string) ([]byte, error)
+}
+
+// EncryptedFields are the paths of the encrypted values of an operation.
+// Paths are dot-separated, starting with the variable name or response
+// key, such as "input.ssn" or "user.ssn"; a path through a list applies
+// to each of its elements.
+type EncryptedFields struct {
+	Variables []string
+	Response  []string
+}
+
+// WithFieldEncryption encrypts and decrypts the values of the operations,
+// by their MetricsName, at the paths given for them. Values missing or
+// null in a request or response are left as they are. Data streamed with
+// RunTo isn't decrypted.
+//  NewClient(endpoint, WithFieldEncryption(keys, map[string]EncryptedFields{
+//      "CreatePatient": {Variables: []string{"input.ssn"}, Response: []string{"createPatient.ssn"}},
+//  }))
+func WithFieldEncryption(cipher FieldCipher, operations map[string]EncryptedFields) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.fieldCipher = cipher
+		client.encryptedFields = operations
+	})
+}
+
+// encryptVariables gets the request with the encrypted variable values
+// of its operation replaced by their ciphertexts.
+func (c *Client) encryptVariables(ctx context.Context, req *Request) (*Request, error) {
+	fields, ok := c.encryptedFields[req.MetricsName()]
+	if c.fieldCipher == nil || !ok || len(fields.Variables) == 0 || len(req.Variables) == 0 {
+		return req, nil
+	}
+	// variables may be structs, so they are rewritten as decoded JSON
+	var vars map[string]interface{}
+	if err := roundTripJSON(req.Variables, &vars); err != nil {
+		return nil, errors.Wrap(err, "graphql: encrypting variables")
+	}
+	for _, path := range fields.Variables {
+		err := rewritePath(vars, strings.Split(path, "."), func(v interface{}) (interface{}, error) {
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			return c.fieldCipher.Encrypt(ctx, req.MetricsName(), path, b)
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "graphql: encrypting $%s", path)
+		}
+	}
+	out := req.clone()
+	out.Variables = vars
+	return out, nil
+}
+
+// decryptData gets the data of the response to req with the encrypted
+// fields of its operation decrypted.
+func (c *Client) decryptData(ctx context.Context, req *Request, data json.RawMessage) (json.RawMessage, error) {
+	fields, ok := c.encryptedFields[req.MetricsName()]
+	if c.fieldCipher == nil || !ok || len(fields.Response) == 0 || len(data) == 0 {
+		return data, nil
+	}
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return nil, &DecodeError{Offset: -1, Reason: err.Error(), Err: err}
+	}
+	for _, path := range fields.Response {
+		err := rewritePath(v, strings.Split(path, "."), func(v interface{}) (interface{}, error) {
+			ciphertext, ok := v.(string)
+			if !ok {
+				return nil, errors.Errorf("ciphertext is a %T, not a string", v)
+			}
+			b, err := c.fieldCipher.Decrypt(ctx, req.MetricsName(), path, ciphertext)
+			if err != nil {
+				return nil, err
+			}
+			return json.RawMessage(b), nil
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "graphql: decrypting %s", path)
+		}
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.Wrap(err, "graphql: decrypting response")
+	}
+	return b, nil
+}
+
+// rewritePath replaces the values at the path in v, which is decoded
+// JSON, with their rewrites. Lists on the path are rewritten throughout,
+// and missing and null values are skipped.
+func rewritePath(v interface{}, path []string, rewrite func(v interface{}) (interface{}, error)) error {
+	switch v := v.(type) {
+	case []interface{}:
+		for _, elem := range v {
+			if err := rewritePath(elem, path, rewrite); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		value, ok := v[path[0]]
+		if !ok || value == nil {
+			return nil
+		}
+		if len(path) > 1 {
+			return rewritePath(value, path[1:], rewrite)
+		}
+		rewritten, err := rewrite(value)
+		if err != nil {
+			return err
+		}
+		v[path[0]] = rewritten
+	}
+	return nil
+}
+
+// roundTripJSON decodes the JSON encoding of v into out, keeping numbers
+// as json.Number.
+func roundTripJSON(v, out interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	return d.Decode(out)
+}
@@ -0,0 +1,163 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+// base64Cipher "encrypts" values by base64 encoding them.
+type base64Cipher struct{}
+
+func (base64Cipher) Encrypt(ctx context.Context, operation, path string, plaintext []byte) (string, error) {
+	return operation + ":" + path + ":" + base64.StdEncoding.EncodeToString(plaintext), nil
+}
+
+func (base64Cipher) Decrypt(ctx context.Context, operation, path string, ciphertext string) ([]byte, error) {
+	prefix := operation + ":" + path + ":"
+	if !strings.HasPrefix(ciphertext, prefix) {
+		return nil, errors.Errorf("bad ciphertext %q", ciphertext)
+	}
+	return base64.StdEncoding.DecodeString(strings.TrimPrefix(ciphertext, prefix))
+}
+
+func TestWithFieldEncryption(t *testing.T) {
+	RegisterTestingT(t)
+	enc := func(path, json string) string {
+		return "CreatePatients:" + path + ":" + base64.StdEncoding.EncodeToString([]byte(json))
+	}
+	var received graphql.Request
+	response := `{"data":{"createPatients":[{"name":"a","ssn":"` + enc("createPatients.ssn", `"123"`) + `"},{"name":"b","ssn":null}]}}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = graphql.Request{}
+		json.NewDecoder(r.Body).Decode(&received)
+		io.WriteString(w, response)
+	}))
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL, graphql.WithFieldEncryption(base64Cipher{}, map[string]graphql.EncryptedFields{
+		"CreatePatients": {Variables: []string{"input.ssn", "input.card.number"}, Response: []string{"createPatients.ssn"}},
+	}))
+	type card struct {
+		Number int `json:"number"`
+	}
+	type input struct {
+		Name string `json:"name"`
+		SSN  string `json:"ssn"`
+		Card *card  `json:"card,omitempty"`
+	}
+	req := graphql.NewRequest(`mutation CreatePatients($input: [PatientInput!]!) { createPatients(input: $input) { name ssn } }`)
+	req.Var("input", []input{{Name: "a", SSN: "123", Card: &card{Number: 42}}, {Name: "b"}})
+	var resp struct {
+		CreatePatients []struct {
+			Name string
+			SSN  *string
+		}
+	}
+	Expect(client.Run(context.Background(), req, &resp)).Should(Succeed())
+	Expect(received.Variables["input"]).Should(Equal([]interface{}{
+		map[string]interface{}{"name": "a", "ssn": enc("input.ssn", `"123"`), "card": map[string]interface{}{"number": enc("input.card.number", "42")}},
+		map[string]interface{}{"name": "b", "ssn": enc("input.ssn", `""`)},
+	}))
+	Expect(*resp.CreatePatients[0].SSN).Should(Equal("123"))
+	Expect(resp.CreatePatients[1].SSN).Should(BeNil())
+	// the caller's request is unchanged
+	Expect(req.Variables["input"].([]input)[0].SSN).Should(Equal("123"))
+
+	// other operations aren't encrypted
+	other := graphql.NewRequest(`query Patients($ssn: String) { patients { ssn } }`)
+	other.Var("ssn", "123")
+	var raw map[string]interface{}
+	Expect(client.Run(context.Background(), other, &raw)).Should(Succeed())
+	Expect(received.Variables["ssn"]).Should(Equal("123"))
+
+	response = `{"data":{"createPatients":[{"name":"a","ssn":"tampered"}]}}`
+	Expect(client.Run(context.Background(), req, &resp)).Should(MatchError(ContainSubstring(`graphql: decrypting createPatients.ssn: bad ciphertext "tampered"`)))
+}
+
+func TestWithFieldEncryptionBatch(t *testing.T) {
+	RegisterTestingT(t)
+	ciphertext := "Patient:patient.ssn:" + base64.StdEncoding.EncodeToString([]byte(`"123"`))
+	var received []graphql.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		io.WriteString(w, `[{"data":{"patient":{"ssn":"`+ciphertext+`"}}}]`)
+	}))
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL, graphql.WithFieldEncryption(base64Cipher{}, map[string]graphql.EncryptedFields{
+		"Patient": {Variables: []string{"ssn"}, Response: []string{"patient.ssn"}},
+	}))
+	req := graphql.NewRequest(`query Patient($ssn: String!) { patient(ssn: $ssn) { ssn } }`)
+	req.Var("ssn", "123")
+	var resp struct {
+		Patient struct {
+			SSN string
+		}
+	}
+	results, err := client.RunBatch(context.Background(), []*graphql.Request{req}, []interface{}{&resp})
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(results[0].Err).ShouldNot(HaveOccurred())
+	Expect(received[0].Variables["ssn"]).Should(Equal("Patient:ssn:" + base64.StdEncoding.EncodeToString([]byte(`"123"`))))
+	Expect(resp.Patient.SSN).Should(Equal("123"))
+}
+
+// noncedCipher encrypts values differently every time, as real ciphers
+// with random nonces do.
+type noncedCipher struct {
+	n int
+}
+
+func (c *noncedCipher) Encrypt(ctx context.Context, operation, path string, plaintext []byte) (string, error) {
+	c.n++
+	return fmt.Sprintf("%d:%s", c.n, plaintext), nil
+}
+
+func (c *noncedCipher) Decrypt(ctx context.Context, operation, path string, ciphertext string) ([]byte, error) {
+	return []byte(ciphertext), nil
+}
+
+func TestWithFieldEncryptionHashesPlaintext(t *testing.T) {
+	RegisterTestingT(t)
+	var fingerprints []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fingerprints = append(fingerprints, r.Header.Get("X-Fingerprint"))
+		if b, _ := io.ReadAll(r.Body); len(b) > 0 && b[0] == '[' {
+			io.WriteString(w, `[{"data":{}}]`)
+			return
+		}
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	var audits []graphql.AuditRecord
+	client := graphql.NewClient(srv.URL,
+		graphql.WithFingerprints("X-Fingerprint"),
+		graphql.WithAudit(func(r graphql.AuditRecord) { audits = append(audits, r) }),
+		graphql.WithFieldEncryption(&noncedCipher{}, map[string]graphql.EncryptedFields{
+			"CreatePatient": {Variables: []string{"ssn"}},
+		}),
+	)
+	req := graphql.NewRequest(`mutation CreatePatient($ssn: String!) { createPatient(ssn: $ssn) }`)
+	req.Var("ssn", "123")
+	fp, err := graphql.Fingerprint(req)
+	Expect(err).ShouldNot(HaveOccurred())
+
+	Expect(client.Run(context.Background(), req, nil)).Should(Succeed())
+	Expect(client.Run(context.Background(), req, nil)).Should(Succeed())
+	Expect(fingerprints).Should(Equal([]string{fp, fp}))
+	Expect(audits).Should(HaveLen(2))
+	Expect(audits[1].VariablesHash).Should(Equal(audits[0].VariablesHash))
+
+	_, err = client.RunBatch(context.Background(), []*graphql.Request{req}, nil)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(audits).Should(HaveLen(3))
+	Expect(audits[2].VariablesHash).Should(Equal(audits[0].VariablesHash))
+}
@@ -0,0 +1,130 @@
+package graphql
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// EndpointResolver gets the endpoint to send a request to, for example
+// from service discovery. It is called for every request.
+type EndpointResolver func(ctx context.Context) (string, error)
+
+// WithEndpointResolver makes the client get its endpoint from resolver
+// for each request, instead of using the endpoint passed to NewClient.
+//  NewClient("", WithEndpointResolver(func(ctx context.Context) (string, error) {
+//      return discovery.Lookup(ctx, "graphql")
+//  }))
+func WithEndpointResolver(resolver EndpointResolver) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.endpointResolver = resolver
+	})
+}
+
+// Route is where a request is sent when the client has read endpoints.
+type Route int
+
+// Routes.
+const (
+	// RouteAuto sends queries to the read endpoints, and other
+	// operations to the primary endpoint.
+	RouteAuto Route = iota
+	// RoutePrimary sends the request to the primary endpoint.
+	RoutePrimary
+	// RouteReplica sends the request to a read endpoint.
+	RouteReplica
+)
+
+// WithReadEndpoints adds read replica endpoints to the client. Queries
+// are sent to them in turn, while mutations and subscriptions go to the
+// primary endpoint passed to NewClient, or got from the endpoint
+// resolver. Use Request.SetRoute to override the choice for a request,
+// such as for a query that must read its own writes.
+//  NewClient(primary, WithReadEndpoints(replica1, replica2))
+func WithReadEndpoints(endpoints ...string) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.readEndpoints = endpoints
+	})
+}
+
+// SetRoute sets where the request is sent when the client has read
+// endpoints. The default is RouteAuto.
+func (req *Request) SetRoute(route Route) {
+	req.route = route
+}
+
+// endpointKey is the context key of an endpoint already resolved for a
+// request.
+type endpointKey struct{}
+
+// replicaKey is the context key marking requests for the read endpoints.
+type replicaKey struct{}
+
+// unroutedContext has the values of its parent context except for the
+// endpoint and route chosen for the parent's request, for requests sent
+// to another client, such as the dry-run client.
+type unroutedContext struct {
+	context.Context
+}
+
+func (ctx unroutedContext) Value(key interface{}) interface{} {
+	switch key.(type) {
+	case endpointKey, replicaKey:
+		return nil
+	}
+	return ctx.Context.Value(key)
+}
+
+// routeContext marks the context of requests that should be sent to a
+// read endpoint.
+func (c *Client) routeContext(ctx context.Context, reqs ...*Request) context.Context {
+	if !c.toReplica(reqs) {
+		return ctx
+	}
+	return context.WithValue(ctx, replicaKey{}, true)
+}
+
+// toReplica is whether requests sent together should go to a read
+// endpoint: whether they all may.
+func (c *Client) toReplica(reqs []*Request) bool {
+	if len(c.readEndpoints) == 0 {
+		return false
+	}
+	for _, req := range reqs {
+		switch req.route {
+		case RoutePrimary:
+			return false
+		case RouteAuto:
+			op, err := req.Operation()
+			if err != nil || op.Type != "query" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// resolveEndpoint gets the endpoint for a request.
+func (c *Client) resolveEndpoint(ctx context.Context) (string, error) {
+	if endpoint, ok := ctx.Value(endpointKey{}).(string); ok {
+		return endpoint, nil
+	}
+	if replica, _ := ctx.Value(replicaKey{}).(bool); replica && len(c.readEndpoints) > 0 {
+		n := atomic.AddUint32(&c.readNext, 1)
+		return c.readEndpoints[int(n-1)%len(c.readEndpoints)], nil
+	}
+	if c.endpointResolver == nil {
+		return c.endpoint, nil
+	}
+	var endpoint string
+	err := c.callHook("endpoint resolver", func() error {
+		var err error
+		endpoint, err = c.endpointResolver(ctx)
+		return err
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "resolving endpoint")
+	}
+	return endpoint, nil
+}
@@ -0,0 +1,77 @@
+package graphql_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestEndpointResolver(t *testing.T) {
+	RegisterTestingT(t)
+	newServer := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, `{"data":{"server":"`+name+`"}}`)
+		}))
+	}
+	a, b := newServer("a"), newServer("b")
+	defer a.Close()
+	defer b.Close()
+
+	endpoint := a.URL
+	var resolveErr error
+	client := graphql.NewClient("", graphql.WithEndpointResolver(func(ctx context.Context) (string, error) {
+		return endpoint, resolveErr
+	}))
+	var resp struct {
+		Server string
+	}
+	Expect(client.Run(context.Background(), graphql.NewRequest(`{ server }`), &resp)).ShouldNot(HaveOccurred())
+	Expect(resp.Server).Should(Equal("a"))
+
+	endpoint = b.URL
+	Expect(client.Run(context.Background(), graphql.NewRequest(`{ server }`), &resp)).ShouldNot(HaveOccurred())
+	Expect(resp.Server).Should(Equal("b"))
+
+	resolveErr = errors.New("no instances")
+	err := client.Run(context.Background(), graphql.NewRequest(`{ server }`), &resp)
+	Expect(err).Should(MatchError("resolving endpoint: no instances"))
+}
+
+func TestReadEndpoints(t *testing.T) {
+	RegisterTestingT(t)
+	newServer := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, `{"data":{"server":"`+name+`"}}`)
+		}))
+	}
+	primary, r1, r2 := newServer("primary"), newServer("r1"), newServer("r2")
+	defer primary.Close()
+	defer r1.Close()
+	defer r2.Close()
+
+	client := graphql.NewClient(primary.URL, graphql.WithReadEndpoints(r1.URL, r2.URL))
+	ctx := context.Background()
+	server := func(req *graphql.Request) string {
+		var resp struct {
+			Server string
+		}
+		Expect(client.Run(ctx, req, &resp)).ShouldNot(HaveOccurred())
+		return resp.Server
+	}
+	Expect(server(graphql.NewRequest(`{ server }`))).Should(Equal("r1"))
+	Expect(server(graphql.NewRequest(`query Q { server }`))).Should(Equal("r2"))
+	Expect(server(graphql.NewRequest(`mutation M { server }`))).Should(Equal("primary"))
+
+	req := graphql.NewRequest(`{ server }`)
+	req.SetRoute(graphql.RoutePrimary)
+	Expect(server(req)).Should(Equal("primary"))
+	req = graphql.NewRequest(`mutation M { server }`)
+	req.SetRoute(graphql.RouteReplica)
+	Expect(server(req)).Should(Equal("r1"))
+}
@@ -0,0 +1,37 @@
+package graphql
+
+import "reflect"
+
+// WithEnumFallback decodes values of the enum type T that aren't among
+// known as unknown, rather than keeping them or, if T checks its values
+// when it is unmarshalled, failing the response, so that servers adding
+// enum values don't break deployed clients. It can be given once for
+// each enum type.
+//  NewClient(endpoint, WithEnumFallback(RoleUnknown, RoleAdmin, RoleMember))
+func WithEnumFallback[T ~string](unknown T, known ...T) ClientOption {
+	return ClientOption(func(client *Client) {
+		e := enumFallback{unknown: string(unknown), known: make(map[string]bool, len(known))}
+		for _, k := range known {
+			e.known[string(k)] = true
+		}
+		if client.walker.enums == nil {
+			client.walker.enums = make(map[reflect.Type]enumFallback)
+		}
+		client.walker.enums[reflect.TypeOf(unknown)] = e
+	})
+}
+
+// enumFallback is the known values of an enum type, and the value
+// others decode as.
+type enumFallback struct {
+	unknown string
+	known   map[string]bool
+}
+
+// value gets the value v decodes as.
+func (e enumFallback) value(v interface{}) interface{} {
+	if s, ok := v.(string); ok && !e.known[s] {
+		return e.unknown
+	}
+	return v
+}
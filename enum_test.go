@@ -0,0 +1,61 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+type role string
+
+const (
+	roleUnknown role = "UNKNOWN"
+	roleAdmin   role = "ADMIN"
+	roleMember  role = "MEMBER"
+)
+
+func (r *role) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	switch role(s) {
+	case roleUnknown, roleAdmin, roleMember:
+		*r = role(s)
+		return nil
+	}
+	return errors.Errorf("unknown role %q", s)
+}
+
+func TestWithEnumFallback(t *testing.T) {
+	RegisterTestingT(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{"users":[{"role":"ADMIN","roles":["MEMBER","OWNER"]},{"role":"GUEST","roles":[]}]}}`)
+	}))
+	defer srv.Close()
+	var resp struct {
+		Users []struct {
+			Role  role
+			Roles []*role
+		}
+	}
+	req := graphql.NewRequest(`{ users { role roles } }`)
+
+	client := graphql.NewClient(srv.URL)
+	Expect(client.Run(context.Background(), req, &resp)).Should(MatchError(ContainSubstring(`unknown role "OWNER"`)))
+
+	client = graphql.NewClient(srv.URL, graphql.WithEnumFallback(roleUnknown, roleAdmin, roleMember))
+	Expect(client.Run(context.Background(), req, &resp)).Should(Succeed())
+	Expect(resp.Users).Should(HaveLen(2))
+	Expect(resp.Users[0].Role).Should(Equal(roleAdmin))
+	Expect(*resp.Users[0].Roles[0]).Should(Equal(roleMember))
+	Expect(*resp.Users[0].Roles[1]).Should(Equal(roleUnknown))
+	Expect(resp.Users[1].Role).Should(Equal(roleUnknown))
+}
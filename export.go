@@ -0,0 +1,234 @@
+package graphql
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// RowWriter is where Export writes rows. *csv.Writer is a RowWriter,
+// and other formats, such as Parquet, can be written with an adapter.
+type RowWriter interface {
+	Write(row []string) error
+}
+
+// Export runs a query for a Relay connection page by page, writing a row
+// for each node to w, after a row of column names. It returns the number
+// of nodes written.
+//
+// path is the dot-separated path of the connection in the response data,
+// such as "organization.members". The connection must select its nodes,
+// either as nodes or as edges { node }, and pageInfo { hasNextPage
+// endCursor }. The query must take the cursor of the next page as the
+// variable $after.
+//
+// The columns are the fields of T, named by their csv tags, or else by
+// their json tags. Nested structs are flattened, joining names with dots.
+// Fields tagged csv:"-" are left out.
+//  n, err := graphql.Export[Member](ctx, client, req, "organization.members", csv.NewWriter(f))
+func Export[T any](ctx context.Context, client *Client, req *Request, path string, w RowWriter, opts ...ExportOption) (int, error) {
+	var e exporter
+	for _, opt := range opts {
+		opt(&e)
+	}
+	columns := exportColumns(reflect.TypeOf((*T)(nil)).Elem(), nil, "")
+	req = req.clone()
+	if e.resume != "" {
+		req.Var("after", e.resume)
+	} else {
+		header := make([]string, len(columns))
+		for i, col := range columns {
+			header[i] = col.name
+		}
+		if err := w.Write(header); err != nil {
+			return 0, errors.Wrap(err, "graphql: writing row")
+		}
+	}
+	n := 0
+	for {
+		page, err := connectionPage[T](ctx, client, req, path)
+		if err != nil {
+			return n, err
+		}
+		nodes := page.Nodes
+		for _, edge := range page.Edges {
+			nodes = append(nodes, edge.Node)
+		}
+		for _, node := range nodes {
+			if err := w.Write(exportRow(reflect.ValueOf(node), columns)); err != nil {
+				return n, errors.Wrap(err, "graphql: writing row")
+			}
+			n++
+		}
+		if !page.PageInfo.HasNextPage {
+			return n, nil
+		}
+		if page.PageInfo.EndCursor == "" {
+			return n, errors.Errorf("graphql: %s has a next page but no endCursor", path)
+		}
+		if e.checkpoint != nil {
+			if err := e.checkpoint(page.PageInfo.EndCursor); err != nil {
+				return n, errors.Wrap(err, "graphql: saving checkpoint")
+			}
+		}
+		req.Var("after", page.PageInfo.EndCursor)
+	}
+}
+
+// ExportOption configures an Export.
+type ExportOption func(*exporter)
+
+type exporter struct {
+	checkpoint func(cursor string) error
+	resume     string
+}
+
+// ExportCheckpoint sets a function called with the cursor of the next
+// page after each page is written, to save it so that a failed export
+// can be resumed with ExportResume. Flush the RowWriter before saving
+// the cursor, so rows aren't lost. If it returns an error, the export
+// stops.
+func ExportCheckpoint(save func(cursor string) error) ExportOption {
+	return func(e *exporter) {
+		e.checkpoint = save
+	}
+}
+
+// ExportResume resumes an export from the page at the cursor saved by
+// ExportCheckpoint. The row of column names isn't written again, so rows
+// can be appended to those already exported.
+func ExportResume(cursor string) ExportOption {
+	return func(e *exporter) {
+		e.resume = cursor
+	}
+}
+
+// connectionPage runs req and gets the connection page at path.
+func connectionPage[T any](ctx context.Context, client *Client, req *Request, path string) (*Connection[T], error) {
+	res, err := client.do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(res.errors) > 0 {
+		return nil, res.errors[0]
+	}
+	data, err := client.dataAt(res.data, path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 || string(data) == "null" {
+		return nil, errors.Errorf("graphql: no %s in response", path)
+	}
+	var page Connection[T]
+	if err := client.decodeData(data, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// exportColumn is a column of an export, and the index path of the
+// struct field it comes from.
+type exportColumn struct {
+	name  string
+	index []int
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// exportColumns gets the columns of the fields of the struct type t.
+func exportColumns(t reflect.Type, index []int, prefix string) []exportColumn {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType) {
+		name := strings.TrimSuffix(prefix, ".")
+		if name == "" {
+			name = "value"
+		}
+		return []exportColumn{{name: name, index: index}}
+	}
+	var columns []exportColumn
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := f.Name
+		tag, ok := f.Tag.Lookup("csv")
+		if !ok {
+			tag = f.Tag.Get("json")
+		}
+		if tag := strings.Split(tag, ",")[0]; tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+		fieldIndex := append(append([]int(nil), index...), i)
+		columns = append(columns, exportColumns(f.Type, fieldIndex, prefix+name+".")...)
+	}
+	return columns
+}
+
+// exportRow formats the columns of v.
+func exportRow(v reflect.Value, columns []exportColumn) []string {
+	row := make([]string, len(columns))
+	for i, col := range columns {
+		row[i] = exportValue(fieldAt(v, col.index))
+	}
+	return row
+}
+
+// fieldAt gets the field of v at the index path, or an invalid Value if
+// a pointer on the way is nil.
+func fieldAt(v reflect.Value, index []int) reflect.Value {
+	for _, i := range index {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v
+}
+
+// exportValue formats a value for a row.
+func exportValue(v reflect.Value) string {
+	for v.IsValid() && v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		if v.Type().Implements(textMarshalerType) {
+			break
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return ""
+	}
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		b, err := m.MarshalText()
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Interface, reflect.Struct:
+		if (v.Kind() == reflect.Slice || v.Kind() == reflect.Map || v.Kind() == reflect.Interface) && v.IsNil() {
+			return ""
+		}
+		b, err := json.Marshal(v.Interface())
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,99 @@
+package graphql_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestExport(t *testing.T) {
+	RegisterTestingT(t)
+	pages := map[string]string{
+		"":   `{"data":{"org":{"members":{"nodes":[{"id":"1","name":"Ann","joined":"2020-01-02T00:00:00Z","team":{"name":"core"}},{"id":"2","name":"Bob","joined":"2021-03-04T00:00:00Z","team":null,"tags":["x"]}],"pageInfo":{"hasNextPage":true,"endCursor":"c2"}}}}}`,
+		"c2": `{"data":{"org":{"members":{"edges":[{"node":{"id":"3","name":"Cy","joined":"2022-05-06T00:00:00Z","team":{"name":"ops"}}}],"pageInfo":{"hasNextPage":false}}}}}`,
+	}
+	var cursors []interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphql.Request
+		json.NewDecoder(r.Body).Decode(&req)
+		cursors = append(cursors, req.Variables["after"])
+		after, _ := req.Variables["after"].(string)
+		io.WriteString(w, pages[after])
+	}))
+	defer srv.Close()
+	type member struct {
+		ID     graphql.ID `json:"id"`
+		Name   string     `csv:"full_name"`
+		Joined time.Time  `json:"joined"`
+		Team   *struct {
+			Name string `json:"name"`
+		} `json:"team"`
+		Tags   []string `json:"tags"`
+		Secret string   `csv:"-"`
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	req := graphql.NewRequest(`query ($after: String) { org { members(after: $after) { nodes { id name } pageInfo { hasNextPage endCursor } } } }`)
+	n, err := graphql.Export[member](context.Background(), graphql.NewClient(srv.URL), req, "org.members", w)
+	Expect(err).ShouldNot(HaveOccurred())
+	w.Flush()
+	Expect(n).Should(Equal(3))
+	Expect(cursors).Should(Equal([]interface{}{nil, "c2"}))
+	Expect(req.Variables).Should(BeEmpty())
+	Expect(buf.String()).Should(Equal(`id,full_name,joined,team.name,tags
+1,Ann,2020-01-02T00:00:00Z,core,
+2,Bob,2021-03-04T00:00:00Z,,"[""x""]"
+3,Cy,2022-05-06T00:00:00Z,ops,
+`))
+}
+
+func TestExportCheckpoints(t *testing.T) {
+	RegisterTestingT(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphql.Request
+		json.NewDecoder(r.Body).Decode(&req)
+		switch req.Variables["after"] {
+		case nil:
+			io.WriteString(w, `{"data":{"items":{"nodes":[{"id":1}],"pageInfo":{"hasNextPage":true,"endCursor":"c1"}}}}`)
+		case "c1":
+			io.WriteString(w, `{"errors":[{"message":"timeout"}]}`)
+		case "c2":
+			io.WriteString(w, `{"data":{"items":{"nodes":[{"id":3}],"pageInfo":{"hasNextPage":false}}}}`)
+		}
+	}))
+	defer srv.Close()
+	type item struct {
+		ID int `json:"id"`
+	}
+	client := graphql.NewClient(srv.URL)
+	req := graphql.NewRequest(`query ($after: String) { items(after: $after) { nodes { id } pageInfo { hasNextPage endCursor } } }`)
+
+	var saved []string
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	n, err := graphql.Export[item](context.Background(), client, req, "items", w, graphql.ExportCheckpoint(func(cursor string) error {
+		w.Flush()
+		saved = append(saved, cursor)
+		return nil
+	}))
+	Expect(err).Should(MatchError("graphql: timeout"))
+	Expect(n).Should(Equal(1))
+	Expect(saved).Should(Equal([]string{"c1"}))
+	Expect(buf.String()).Should(Equal("id\n1\n"))
+
+	n, err = graphql.Export[item](context.Background(), client, req, "items", w, graphql.ExportResume("c2"))
+	Expect(err).ShouldNot(HaveOccurred())
+	w.Flush()
+	Expect(n).Should(Equal(1))
+	Expect(buf.String()).Should(Equal("id\n1\n3\n"))
+}
@@ -0,0 +1,137 @@
+package graphql
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FieldDirective decides, when a request is sent, the directive to add
+// to a field, such as @include(if: false). It returns "" to leave the
+// field as it is.
+type FieldDirective func(ctx context.Context, req *PolicyRequest) string
+
+// IncludeIf makes a FieldDirective that adds @include(if: ...) with the
+// value of flag, for toggling fields with a feature flag.
+func IncludeIf(flag func(ctx context.Context) bool) FieldDirective {
+	return func(ctx context.Context, req *PolicyRequest) string {
+		return "@include(if: " + strconv.FormatBool(flag(ctx)) + ")"
+	}
+}
+
+// SkipIf makes a FieldDirective that adds @skip(if: ...) with the value
+// of flag.
+func SkipIf(flag func(ctx context.Context) bool) FieldDirective {
+	return func(ctx context.Context, req *PolicyRequest) string {
+		return "@skip(if: " + strconv.FormatBool(flag(ctx)) + ")"
+	}
+}
+
+// FieldDirectives makes a Policy that adds directives to fields before
+// requests are sent, so that expensive fields can be turned on and off
+// per call without keeping copies of documents. Fields are given by
+// their path in the response from the root of the operation, such as
+// "user.billing", which may go through fragments. Since fragments are
+// shared, a directive added to a field in a fragment applies everywhere
+// the fragment is spread.
+//  NewClient(endpoint, WithPolicy(FieldDirectives(map[string]FieldDirective{
+//      "user.billing": IncludeIf(billingEnabled),
+//  })))
+func FieldDirectives(directives map[string]FieldDirective) Policy {
+	return func(ctx context.Context, req *PolicyRequest) error {
+		doc, err := parseDocument(req.Request.Query)
+		if err != nil {
+			return nil
+		}
+		op, err := doc.operation(req.Request.OperationName)
+		if err != nil {
+			return nil
+		}
+		fragments := make(map[string]*fragmentDef)
+		for _, frag := range doc.fragments {
+			fragments[frag.name] = frag
+		}
+		query := req.Request.Query
+		var inserts []insertion
+		seen := make(map[int]bool)
+		var visit func(selections []selection, path string, spread map[string]bool)
+		visit = func(selections []selection, path string, spread map[string]bool) {
+			for _, sel := range selections {
+				switch sel := sel.(type) {
+				case *field:
+					p := joinPath(path, sel.responseKey())
+					if fd, ok := directives[p]; ok {
+						if d := fd(ctx, req); d != "" && !seen[sel.start] {
+							seen[sel.start] = true
+							inserts = append(inserts, directiveInsertion(query, sel, d))
+						}
+					}
+					visit(sel.selections, p, spread)
+				case *inlineFragment:
+					visit(sel.selections, path, spread)
+				case *fragmentSpread:
+					if frag := fragments[sel.name]; frag != nil && !spread[sel.name] {
+						spread[sel.name] = true
+						visit(frag.selections, path, spread)
+						delete(spread, sel.name)
+					}
+				}
+			}
+		}
+		visit(op.selections, "", make(map[string]bool))
+		if len(inserts) > 0 {
+			req.Request.Query = insert(query, inserts)
+		}
+		return nil
+	}
+}
+
+// insertion is text to insert into a document at an offset.
+type insertion struct {
+	at   int
+	text string
+}
+
+// directiveInsertion gets the insertion that adds the directive d to the
+// field: before its selection set, if it has one.
+func directiveInsertion(query string, f *field, d string) insertion {
+	if len(f.selections) == 0 {
+		return insertion{f.end, " " + d}
+	}
+	toks, err := lex(query[f.start:f.end])
+	if err != nil {
+		return insertion{f.end, " " + d}
+	}
+	depth := 0
+	for _, t := range toks {
+		if t.kind != tokPunct {
+			continue
+		}
+		switch t.value {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		case "{":
+			if depth == 0 {
+				return insertion{f.start + t.start, d + " "}
+			}
+		}
+	}
+	return insertion{f.end, " " + d}
+}
+
+// insert makes the insertions into s.
+func insert(s string, inserts []insertion) string {
+	sort.Slice(inserts, func(i, j int) bool { return inserts[i].at < inserts[j].at })
+	var b strings.Builder
+	pos := 0
+	for _, in := range inserts {
+		b.WriteString(s[pos:in.at])
+		b.WriteString(in.text)
+		pos = in.at
+	}
+	b.WriteString(s[pos:])
+	return b.String()
+}
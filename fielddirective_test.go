@@ -0,0 +1,50 @@
+package graphql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+type tenantKey struct{}
+
+func TestFieldDirectives(t *testing.T) {
+	RegisterTestingT(t)
+	premium := func(ctx context.Context) bool {
+		return ctx.Value(tenantKey{}) == "premium"
+	}
+	policy := graphql.FieldDirectives(map[string]graphql.FieldDirective{
+		"user.billing":       graphql.IncludeIf(premium),
+		"user.billing.plan":  graphql.SkipIf(premium),
+		"user.avatar":        graphql.IncludeIf(premium),
+		"user.friends.email": graphql.IncludeIf(premium),
+		"user.missing":       graphql.IncludeIf(premium),
+	})
+	query := `query U($id: ID!) {
+  user(id: $id) {
+    billing(filter: {a: 1}) { plan }
+    avatar
+    ... on User { friends { ...Friend } }
+  }
+}
+fragment Friend on User { email }`
+	req := &graphql.PolicyRequest{Request: graphql.NewRequest(query)}
+	ctx := context.WithValue(context.Background(), tenantKey{}, "premium")
+	Expect(policy(ctx, req)).Should(Succeed())
+	Expect(req.Request.Query).Should(Equal(`query U($id: ID!) {
+  user(id: $id) {
+    billing(filter: {a: 1}) @include(if: true) { plan @skip(if: true) }
+    avatar @include(if: true)
+    ... on User { friends { ...Friend } }
+  }
+}
+fragment Friend on User { email @include(if: true) }`))
+	_, err := graphql.ParseOperations(req.Request.Query)
+	Expect(err).ShouldNot(HaveOccurred())
+
+	req = &graphql.PolicyRequest{Request: graphql.NewRequest(`{ user { avatar } }`)}
+	Expect(policy(context.Background(), req)).Should(Succeed())
+	Expect(req.Request.Query).Should(Equal(`{ user { avatar @include(if: false) } }`))
+}
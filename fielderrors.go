@@ -0,0 +1,70 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FieldPath gets the error's path in the form users[2].name, or "" if
+// the error has no path.
+func (e Error) FieldPath() string {
+	var path string
+	for _, key := range e.Path {
+		switch key := key.(type) {
+		case string:
+			path = joinPath(path, key)
+		case float64:
+			path += "[" + strconv.Itoa(int(key)) + "]"
+		case int:
+			path += "[" + strconv.Itoa(key) + "]"
+		default:
+			path += "[" + fmt.Sprint(key) + "]"
+		}
+	}
+	return path
+}
+
+// ByField gets the errors that have a path, keyed by their FieldPath,
+// so callers can tell which fields and list elements failed.
+func (e Errors) ByField() map[string]Errors {
+	var fields map[string]Errors
+	for _, err := range e {
+		path := err.FieldPath()
+		if path == "" {
+			continue
+		}
+		if fields == nil {
+			fields = make(map[string]Errors)
+		}
+		fields[path] = append(fields[path], err)
+	}
+	return fields
+}
+
+// At gets the errors at or below the field path, such as users[2], so
+// callers can tell whether a part of the data is missing or incomplete.
+// The path "" gets every error that has a path.
+//  if errs := result.Errors.At("users[2]"); len(errs) > 0 {
+//      // users[2] failed
+//  }
+func (e Errors) At(path string) Errors {
+	var at Errors
+	for _, err := range e {
+		if within(err.FieldPath(), path) {
+			at = append(at, err)
+		}
+	}
+	return at
+}
+
+// within reports whether the field path p is path or below it.
+func within(p, path string) bool {
+	switch {
+	case p == "":
+		return false
+	case path == "", p == path:
+		return true
+	}
+	return strings.HasPrefix(p, path+".") || strings.HasPrefix(p, path+"[")
+}
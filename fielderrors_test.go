@@ -0,0 +1,46 @@
+package graphql_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestFieldErrors(t *testing.T) {
+	RegisterTestingT(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{
+			"data": {"users": [{"name": "a"}, {"name": "b"}, null]},
+			"errors": [
+				{"message": "not allowed", "path": ["users", 2, "name"]},
+				{"message": "timed out", "path": ["users", 2]},
+				{"message": "deprecated", "path": ["usersCount"]},
+				{"message": "overloaded"}
+			]
+		}`)
+	}))
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL)
+
+	result, err := graphql.Do[struct {
+		Users []*struct{ Name string }
+	}](context.Background(), client, graphql.NewRequest(`{ users { name } usersCount }`))
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(result.Data.Users).Should(HaveLen(3))
+	Expect(result.Data.Users[2]).Should(BeNil())
+	Expect(result.FieldErrors).Should(HaveLen(3))
+	Expect(result.FieldErrors["users[2].name"][0].Message).Should(Equal("not allowed"))
+	Expect(result.FieldErrors["users[2]"][0].Message).Should(Equal("timed out"))
+	Expect(result.FieldErrors["usersCount"][0].Message).Should(Equal("deprecated"))
+
+	Expect(result.Errors.At("users[2]")).Should(HaveLen(2))
+	Expect(result.Errors.At("users[1]")).Should(BeEmpty())
+	Expect(result.Errors.At("users")).Should(HaveLen(2))
+	Expect(result.Errors.At("")).Should(HaveLen(3))
+	Expect(result.Errors[3].FieldPath()).Should(BeEmpty())
+}
@@ -0,0 +1,61 @@
+package graphql
+
+import "strings"
+
+// FieldMatching is how the keys of response objects are matched to the
+// names of struct fields, or their json tags, when decoding.
+type FieldMatching int
+
+// Field matching strategies.
+const (
+	// MatchFieldsDefault matches as encoding/json does: exactly, or
+	// else ignoring case, so userName decodes into UserName.
+	MatchFieldsDefault FieldMatching = iota
+	// MatchFieldsExact only matches keys that are exactly the same as
+	// the name; other keys aren't decoded.
+	MatchFieldsExact
+	// MatchFieldsSnakeCase also matches keys that are the same as the
+	// name ignoring case and underscores, so user_name decodes into
+	// UserName.
+	MatchFieldsSnakeCase
+)
+
+// WithFieldMatching sets how response keys are matched to struct
+// fields, so fields needn't have json tags for every key.
+//  NewClient(endpoint, WithFieldMatching(MatchFieldsSnakeCase))
+func WithFieldMatching(matching FieldMatching) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.walker.matching = matching
+	})
+}
+
+// field finds the field the object key decodes into with the walker's
+// field matching.
+func (w *walker) field(fields structFields, key string) *structField {
+	return w.matching.field(fields, key)
+}
+
+// field finds the field of fields the object key decodes into.
+func (m FieldMatching) field(fields structFields, key string) *structField {
+	switch m {
+	case MatchFieldsExact:
+		for i := range fields {
+			if fields[i].name == key {
+				return &fields[i]
+			}
+		}
+		return nil
+	case MatchFieldsSnakeCase:
+		if f := fields.match(key); f != nil {
+			return f
+		}
+		key = strings.Replace(key, "_", "", -1)
+		for i := range fields {
+			if strings.EqualFold(strings.Replace(fields[i].name, "_", "", -1), key) {
+				return &fields[i]
+			}
+		}
+		return nil
+	}
+	return fields.match(key)
+}
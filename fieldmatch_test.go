@@ -0,0 +1,68 @@
+package graphql_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestWithFieldMatching(t *testing.T) {
+	RegisterTestingT(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{"user":{"user_name":"ann","emailAddress":"a@example.com","Age":30,"friends":[{"user_name":"bob"}]}}}`)
+	}))
+	defer srv.Close()
+	type user struct {
+		UserName     string
+		EmailAddress string
+		Age          int
+		Friends      []user `json:"friends"`
+	}
+	req := graphql.NewRequest(`{ user { user_name emailAddress Age friends { user_name } } }`)
+
+	var resp struct{ User user }
+	Expect(graphql.NewClient(srv.URL).Run(context.Background(), req, &resp)).Should(Succeed())
+	Expect(resp.User).Should(Equal(user{EmailAddress: "a@example.com", Age: 30, Friends: []user{{}}}))
+
+	resp.User = user{}
+	client := graphql.NewClient(srv.URL, graphql.WithFieldMatching(graphql.MatchFieldsSnakeCase))
+	Expect(client.Run(context.Background(), req, &resp)).Should(Succeed())
+	Expect(resp.User).Should(Equal(user{UserName: "ann", EmailAddress: "a@example.com", Age: 30, Friends: []user{{UserName: "bob"}}}))
+
+	var exact struct {
+		User user `json:"user"`
+	}
+	client = graphql.NewClient(srv.URL, graphql.WithFieldMatching(graphql.MatchFieldsExact))
+	Expect(client.Run(context.Background(), req, &exact)).Should(Succeed())
+	Expect(exact.User).Should(Equal(user{Age: 30, Friends: []user{{}}}))
+}
+
+func TestWithFieldMatchingOptionalAndPruning(t *testing.T) {
+	RegisterTestingT(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{"user":{"user_name":"ann","profile_badges":null}},"errors":[{"message":"badges are down","path":["user","profile_badges"]}]}`)
+	}))
+	defer srv.Close()
+	var resp struct {
+		User struct {
+			UserName      string
+			ProfileBadges []string `graphql:"optional"`
+		}
+	}
+	var unused []string
+	client := graphql.NewClient(srv.URL,
+		graphql.WithFieldMatching(graphql.MatchFieldsSnakeCase),
+		graphql.WithPruningReport(func(r graphql.PruningReport) {
+			unused = r.Unused
+		}),
+	)
+	req := graphql.NewRequest(`{ user { user_name profile_badges } }`)
+	Expect(client.Run(context.Background(), req, &resp)).Should(Succeed())
+	Expect(resp.User.UserName).Should(Equal("ann"))
+	Expect(unused).Should(BeEmpty())
+}
@@ -0,0 +1,113 @@
+package graphql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultFingerprintHeader is the header that carries request
+// fingerprints, unless changed with WithFingerprints.
+const DefaultFingerprintHeader = "X-Request-Fingerprint"
+
+// Fingerprint gets a stable identifier for the request: the QueryHash of
+// the query and the SHA-256 hash of its variables in canonical JSON,
+// joined by a dot. Requests with the same query and variables have the
+// same fingerprint, so a failed call can be matched with a recording of
+// the request to replay it.
+func Fingerprint(req *Request) (string, error) {
+	hash, err := variablesHash(req.Variables)
+	if err != nil {
+		return "", errors.Wrap(err, "graphql: fingerprinting variables")
+	}
+	return documentInfo(req.Query).hash + "." + hash, nil
+}
+
+// variablesHash gets the SHA-256 hash of vars in canonical JSON.
+func variablesHash(vars map[string]interface{}) (string, error) {
+	// encoding/json sorts map keys, which makes the encoding canonical
+	b, err := json.Marshal(vars)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// WithFingerprints makes the client send the Fingerprint of each request
+// in the named header, or DefaultFingerprintHeader if name is empty, and
+// attach it to the errors Run returns once the request is prepared.
+// Get it from errors with ErrorFingerprint.
+// Requests are fingerprinted as they are sent, after defaults are
+// applied and plugins run.
+//  NewClient(endpoint, WithFingerprints(""))
+func WithFingerprints(name string) ClientOption {
+	return ClientOption(func(client *Client) {
+		if name == "" {
+			name = DefaultFingerprintHeader
+		}
+		client.fingerprintHeader = name
+	})
+}
+
+// FingerprintError is an error from a request with a fingerprint.
+type FingerprintError struct {
+	Fingerprint string
+	Err         error
+}
+
+func (e *FingerprintError) Error() string {
+	return fmt.Sprintf("%v (fingerprint %s)", e.Err, e.Fingerprint)
+}
+
+// Cause gets the underlying error.
+func (e *FingerprintError) Cause() error {
+	return e.Err
+}
+
+// Unwrap gets the underlying error.
+func (e *FingerprintError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorFingerprint gets the fingerprint of the request that caused the
+// error, or "" if it has none.
+func ErrorFingerprint(err error) string {
+	for err != nil {
+		if e, ok := err.(*FingerprintError); ok {
+			return e.Fingerprint
+		}
+		cause, ok := err.(interface{ Cause() error })
+		if !ok {
+			return ""
+		}
+		err = cause.Cause()
+	}
+	return ""
+}
+
+// fingerprint adds the fingerprint of the prepared request to header,
+// returning the fingerprint, or "" if the client doesn't send them.
+func (c *Client) fingerprint(req *Request, header http.Header) (string, error) {
+	if c.fingerprintHeader == "" {
+		return "", nil
+	}
+	fp, err := Fingerprint(req)
+	if err != nil {
+		return "", err
+	}
+	header.Set(c.fingerprintHeader, fp)
+	return fp, nil
+}
+
+// withFingerprint attaches the fingerprint to err.
+func withFingerprint(err error, fingerprint string) error {
+	if err == nil || fingerprint == "" {
+		return err
+	}
+	return &FingerprintError{Fingerprint: fingerprint, Err: err}
+}
@@ -0,0 +1,63 @@
+package graphql_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+func TestFingerprint(t *testing.T) {
+	RegisterTestingT(t)
+	a := graphql.NewRequest(`{ a }`)
+	a.Var("x", 1)
+	a.Var("y", map[string]interface{}{"b": 2, "a": 1})
+	b := graphql.NewRequest(`{ a }`)
+	b.Var("y", map[string]interface{}{"a": 1, "b": 2})
+	b.Var("x", 1)
+	fa, err := graphql.Fingerprint(a)
+	Expect(err).ShouldNot(HaveOccurred())
+	fb, err := graphql.Fingerprint(b)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(fa).Should(Equal(fb))
+	Expect(fa).Should(HavePrefix(graphql.QueryHash(`{ a }`) + "."))
+
+	b.Var("x", 2)
+	fb, err = graphql.Fingerprint(b)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(fa).ShouldNot(Equal(fb))
+}
+
+func TestWithFingerprints(t *testing.T) {
+	RegisterTestingT(t)
+	var header http.Header
+	httpClient := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			header = req.Header
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(strings.NewReader(`{"errors":[{"message":"boom"}]}`)),
+			}, nil
+		}),
+	}
+	client := graphql.NewClient("", graphql.WithHTTPClient(httpClient), graphql.WithFingerprints(""))
+	req := graphql.NewRequest(`{ a }`)
+	req.Var("x", 1)
+	want, err := graphql.Fingerprint(req)
+	Expect(err).ShouldNot(HaveOccurred())
+
+	err = client.Run(context.Background(), req, nil)
+	Expect(err).Should(MatchError("graphql: boom (fingerprint " + want + ")"))
+	Expect(header.Get(graphql.DefaultFingerprintHeader)).Should(Equal(want))
+	Expect(graphql.ErrorFingerprint(err)).Should(Equal(want))
+	Expect(graphql.ErrorFingerprint(errors.Wrap(err, "loading"))).Should(Equal(want))
+	Expect(errors.Cause(err)).Should(BeAssignableToTypeOf(graphql.Error{}))
+	Expect(req.Header).Should(BeNil())
+
+	Expect(graphql.ErrorFingerprint(errors.New("other"))).Should(BeEmpty())
+}
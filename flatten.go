@@ -0,0 +1,33 @@
+package graphql
+
+// WithFlattenConnections decodes Relay connections into slice fields as
+// plain slices of their nodes, from either their nodes or their edges {
+// node } selection, so structs don't need wrapper types for each
+// connection. A field can ask for this without the option with a tag:
+//  type Organization struct {
+//      Members []Member `graphql:"flatten"`
+//  }
+func WithFlattenConnections() ClientOption {
+	return ClientOption(func(client *Client) {
+		client.walker.flatten = true
+	})
+}
+
+// flattenConnection gets the nodes of the connection obj, and whether
+// it has any.
+func flattenConnection(obj map[string]interface{}) ([]interface{}, bool) {
+	if nodes, ok := obj["nodes"].([]interface{}); ok {
+		return nodes, true
+	}
+	edges, ok := obj["edges"].([]interface{})
+	if !ok {
+		return nil, false
+	}
+	nodes := make([]interface{}, len(edges))
+	for i, edge := range edges {
+		if edge, ok := edge.(map[string]interface{}); ok {
+			nodes[i] = edge["node"]
+		}
+	}
+	return nodes, true
+}
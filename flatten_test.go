@@ -0,0 +1,52 @@
+package graphql_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestFlattenConnections(t *testing.T) {
+	RegisterTestingT(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{"organization":{
+			"members":{"edges":[{"node":{"login":"a"}},{"node":{"login":"b"}}]},
+			"teams":{"nodes":[{"name":"x"}],"totalCount":1}
+		}}}`)
+	}))
+	defer srv.Close()
+	req := graphql.NewRequest(`{ organization { members { edges { node { login } } } teams { nodes { name } totalCount } } }`)
+
+	var tagged struct {
+		Organization struct {
+			Members []struct{ Login string } `graphql:"flatten"`
+			Teams   struct {
+				Nodes      []struct{ Name string }
+				TotalCount int
+			}
+		}
+	}
+	Expect(graphql.NewClient(srv.URL).Run(context.Background(), req, &tagged)).Should(Succeed())
+	Expect(tagged.Organization.Members).Should(HaveLen(2))
+	Expect(tagged.Organization.Members[1].Login).Should(Equal("b"))
+	Expect(tagged.Organization.Teams.TotalCount).Should(Equal(1))
+
+	var flat struct {
+		Organization struct {
+			Members []*struct{ Login string }
+			Teams   []struct{ Name string }
+		}
+	}
+	client := graphql.NewClient(srv.URL, graphql.WithFlattenConnections())
+	Expect(client.Run(context.Background(), req, &flat)).Should(Succeed())
+	Expect(flat.Organization.Members[0].Login).Should(Equal("a"))
+	Expect(flat.Organization.Teams).Should(HaveLen(1))
+	Expect(flat.Organization.Teams[0].Name).Should(Equal("x"))
+
+	Expect(graphql.NewClient(srv.URL).Run(context.Background(), req, &flat)).ShouldNot(Succeed())
+}
@@ -0,0 +1,179 @@
+package graphql
+
+import (
+	"sort"
+	"strings"
+)
+
+// Format prints the query document in a canonical form: one selection
+// per line, indented by two spaces, with comments and redundant commas
+// and whitespace removed. Definitions keep their order. Documents that
+// are equivalent apart from layout format identically, so the result is
+// suitable for logs and stable diffs.
+func Format(query string) (string, error) {
+	doc, err := parseDocument(query)
+	if err != nil {
+		return "", err
+	}
+	type def struct {
+		start int
+		print func(*printer)
+	}
+	var defs []def
+	for _, op := range doc.operations {
+		op := op
+		defs = append(defs, def{op.start, func(p *printer) { p.operation(op) }})
+	}
+	for _, frag := range doc.fragments {
+		frag := frag
+		defs = append(defs, def{frag.start, func(p *printer) { p.fragment(frag) }})
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].start < defs[j].start })
+	p := &printer{}
+	for i, d := range defs {
+		if i > 0 {
+			p.WriteString("\n")
+		}
+		d.print(p)
+		p.WriteString("\n")
+	}
+	return p.String(), nil
+}
+
+// Formatted gets the request's query in the canonical form printed by
+// Format, or the query as it is if it can't be parsed.
+func (req *Request) Formatted() string {
+	s, err := Format(req.Query)
+	if err != nil {
+		return req.Query
+	}
+	return s
+}
+
+type printer struct {
+	strings.Builder
+	depth int
+}
+
+func (p *printer) operation(op *operationDef) {
+	if op.nameEnd < 0 {
+		p.selections(op.selections)
+		return
+	}
+	p.WriteString(op.opType)
+	if op.name != "" {
+		p.WriteString(" " + op.name)
+	}
+	if len(op.varDefs) > 0 {
+		if op.name == "" {
+			p.WriteString(" ")
+		}
+		p.WriteString("(")
+		for i, v := range op.varDefs {
+			if i > 0 {
+				p.WriteString(", ")
+			}
+			p.WriteString("$" + v.name + ": " + v.typ.String())
+			if v.defaultValue != nil {
+				p.WriteString(" = ")
+				p.value(v.defaultValue)
+			}
+			p.directives(v.directives)
+		}
+		p.WriteString(")")
+	}
+	p.directives(op.directives)
+	p.WriteString(" ")
+	p.selections(op.selections)
+}
+
+func (p *printer) fragment(frag *fragmentDef) {
+	p.WriteString("fragment " + frag.name + " on " + frag.typeCondition)
+	p.directives(frag.directives)
+	p.WriteString(" ")
+	p.selections(frag.selections)
+}
+
+func (p *printer) selections(selections []selection) {
+	p.WriteString("{")
+	p.depth++
+	for _, sel := range selections {
+		p.WriteString("\n" + strings.Repeat("  ", p.depth))
+		switch sel := sel.(type) {
+		case *field:
+			if sel.alias != "" {
+				p.WriteString(sel.alias + ": ")
+			}
+			p.WriteString(sel.name)
+			p.arguments(sel.args)
+			p.directives(sel.directives)
+			if len(sel.selections) > 0 {
+				p.WriteString(" ")
+				p.selections(sel.selections)
+			}
+		case *fragmentSpread:
+			p.WriteString("..." + sel.name)
+			p.directives(sel.directives)
+		case *inlineFragment:
+			p.WriteString("...")
+			if sel.typeCondition != "" {
+				p.WriteString(" on " + sel.typeCondition)
+			}
+			p.directives(sel.directives)
+			p.WriteString(" ")
+			p.selections(sel.selections)
+		}
+	}
+	p.depth--
+	p.WriteString("\n" + strings.Repeat("  ", p.depth) + "}")
+}
+
+func (p *printer) arguments(args []*argument) {
+	if len(args) == 0 {
+		return
+	}
+	p.WriteString("(")
+	for i, arg := range args {
+		if i > 0 {
+			p.WriteString(", ")
+		}
+		p.WriteString(arg.name + ": ")
+		p.value(arg.value)
+	}
+	p.WriteString(")")
+}
+
+func (p *printer) directives(directives []*directive) {
+	for _, d := range directives {
+		p.WriteString(" @" + d.name)
+		p.arguments(d.args)
+	}
+}
+
+func (p *printer) value(v *value) {
+	switch v.kind {
+	case valueVariable:
+		p.WriteString("$" + v.raw)
+	case valueList:
+		p.WriteString("[")
+		for i, item := range v.list {
+			if i > 0 {
+				p.WriteString(", ")
+			}
+			p.value(item)
+		}
+		p.WriteString("]")
+	case valueObject:
+		p.WriteString("{")
+		for i, f := range v.fields {
+			if i > 0 {
+				p.WriteString(", ")
+			}
+			p.WriteString(f.name + ": ")
+			p.value(f.value)
+		}
+		p.WriteString("}")
+	default:
+		p.WriteString(v.raw)
+	}
+}
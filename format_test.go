@@ -0,0 +1,63 @@
+package graphql_test
+
+import (
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestFormat(t *testing.T) {
+	RegisterTestingT(t)
+	s, err := graphql.Format(`
+	# find a user
+	query User($id: ID!, $n: Int = 10 ) @live { user(id: $id) { ...Parts,
+	friends(first: $n, filter: {roles: [ADMIN, MEMBER], name: "a, b"}) @include(if: true) { id }
+	... on User { me: name } ... @skip(if: false) { email } } }
+	fragment Parts on User { id name }`)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(s).Should(Equal(`query User($id: ID!, $n: Int = 10) @live {
+  user(id: $id) {
+    ...Parts
+    friends(first: $n, filter: {roles: [ADMIN, MEMBER], name: "a, b"}) @include(if: true) {
+      id
+    }
+    ... on User {
+      me: name
+    }
+    ... @skip(if: false) {
+      email
+    }
+  }
+}
+
+fragment Parts on User {
+  id
+  name
+}
+`))
+
+	again, err := graphql.Format(s)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(again).Should(Equal(s))
+}
+
+func TestFormatAnonymous(t *testing.T) {
+	RegisterTestingT(t)
+	s, err := graphql.Format(`{a}`)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(s).Should(Equal("{\n  a\n}\n"))
+
+	s, err = graphql.Format(`query($a:Int){b(a:$a)}`)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(s).Should(Equal("query ($a: Int) {\n  b(a: $a)\n}\n"))
+
+	_, err = graphql.Format(`{a`)
+	Expect(err).Should(HaveOccurred())
+}
+
+func TestRequestFormatted(t *testing.T) {
+	RegisterTestingT(t)
+	Expect(graphql.NewRequest(`{ a b }`).Formatted()).Should(Equal("{\n  a\n  b\n}\n"))
+	Expect(graphql.NewRequest(`{ a `).Formatted()).Should(Equal(`{ a `))
+}
@@ -28,28 +28,127 @@
 // To specify your own http.Client, use the WithHTTPClient option:
 //  httpclient := &http.Client{}
 //  client := graphql.NewClient("https://machinebox.io/graphql", graphql.WithHTTPClient(httpclient))
+//
+// Integrations
+//
+// The package depends only on the standard library and
+// github.com/pkg/errors, so that it stays light enough for constrained
+// builds. Integrations with other systems, such as tracing, caches and
+// other transports, belong in sub-packages that build on the client's
+// extension points: the http.Client given with WithHTTPClient, Plugin,
+// Policy, Credentials, FieldCipher, Clock, and report functions such as
+// WithStatsReport. Package graphqltest is one.
 package graphql
 
 import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"io"
+	"fmt"
 	"net/http"
+	"sync/atomic"
+	"time"
 
 	"github.com/pkg/errors"
 )
 
 // Client is a client for accessing a GraphQL dataset.
 type Client struct {
-	endpoint   string
-	httpClient *http.Client
+	endpoint         string
+	endpointResolver EndpointResolver
+	readEndpoints    []string
+	readNext         uint32
+	httpClient       *http.Client
+	keepAlive        time.Duration
+	readTimeout      time.Duration
+	credentials      atomic.Value
+
+	maxDepth         int
+	maxResponseSize  int64
+	responsePrefixes []string
+	maxQuerySize     int64
+	maxVariablesSize int64
+	maxListElements  int
+	maxListBytes     int
+	batchSplitDepth  int
+	strictVariables  bool
+	naming           OperationNaming
+	costDryRun       CostDryRun
+	walker           walker
+	plugins          []Plugin
+	redaction        *Redaction
+	schema           *Schema
+	schemaCache      *SchemaCache
+	defaultVars      map[string]interface{}
+	allowList        *AllowList
+	scalars          scalarRegistry
+	scalarDecoders   map[string]ScalarDecoder
+
+	idempotencyHeader   string
+	fingerprintHeader   string
+	digestHeader        string
+	autoIdempotencyKeys bool
+	consistencyHeader   string
+	consistency         consistencyToken
+	latencyBudget       LatencyBudget
+	fieldCipher         FieldCipher
+	encryptedFields     map[string]EncryptedFields
+	specCompliance      bool
+
+	contentType      string
+	accept           string
+	responseHandlers map[string]ResponseHandler
+
+	header       http.Header
+	warmup       warmupState
+	capabilities capabilitiesState
+	detached     detachedCalls
+	inFlight     int32
+	transport    Transport
+
+	shadowEndpoint string
+	shadowRate     float64
+	shadowReport   func(ShadowReport)
+	shadowClient   *Client
+
+	shapeReport   func(ShapeReport)
+	pruningReport func(PruningReport)
+	statsReport   func(RequestStats)
+	capturePolicy CapturePolicy
+	captureSink   func(Capture)
+	auditSink     func(AuditRecord)
+	health        *HealthTracker
+	runtimeTrace  bool
+	clock         Clock
+	random        Random
+
+	dryRun         bool
+	dryRunEndpoint string
+	dryRunClient   *Client
+	dryRunReport   func(DryRunReport)
+
+	warningHandler func(req *Request, w Warning)
+
+	policies     []Policy
+	hookPanics   HookPanicPolicy
+	hookPanicLog func(err *HookPanicError)
+}
+
+// Runner runs GraphQL requests. It is implemented by Client, and
+// by graphqltest.FakeClient for unit tests.
+type Runner interface {
+	Run(ctx context.Context, req *Request, resp interface{}) error
 }
 
+var _ Runner = (*Client)(nil)
+
 // NewClient makes a new Client capable of making GraphQL requests.
 func NewClient(endpoint string, opts ...ClientOption) *Client {
 	c := &Client{
-		endpoint: endpoint,
+		endpoint:          endpoint,
+		maxDepth:          DefaultMaxDepth,
+		idempotencyHeader: DefaultIdempotencyHeader,
+		contentType:       MediaTypeJSON,
 	}
 	for _, optionFunc := range opts {
 		optionFunc(c)
@@ -57,6 +156,15 @@ func NewClient(endpoint string, opts ...ClientOption) *Client {
 	if c.httpClient == nil {
 		c.httpClient = http.DefaultClient
 	}
+	if c.keepAlive > 0 {
+		c.httpClient = keepAliveClient(c.httpClient, c.keepAlive)
+	}
+	if c.shadowEndpoint != "" {
+		c.shadowClient = NewClient(c.shadowEndpoint, append(opts[:len(opts):len(opts)], withoutShadow())...)
+	}
+	if c.dryRunEndpoint != "" {
+		c.dryRunClient = NewClient(c.dryRunEndpoint, append(opts[:len(opts):len(opts)], withoutShadow(), withoutDryRun())...)
+	}
 	return c
 }
 
@@ -66,48 +174,307 @@ func NewClient(endpoint string, opts ...ClientOption) *Client {
 // If the request fails or the server returns an error, the first error
 // will be returned.
 func (c *Client) Run(ctx context.Context, req *Request, resp interface{}) error {
+	ctx, endTask := c.traceTask(ctx, req)
+	defer endTask()
+	res, err := c.do(ctx, req)
+	if err != nil {
+		return err
+	}
+	_, err = c.handleResponse(ctx, req, res, resp, false, func(data json.RawMessage) ([]Warning, error) {
+		return c.decodeResult(res.req, data, resp)
+	})
+	return err
+}
+
+// handleResponse runs the steps that follow every response to req:
+// shadow traffic, shape and stats reports, warnings, and decoding the
+// data with decode, which may be nil to skip it. resp is what the data
+// is decoded into, if anything, for optional fields and the pruning
+// report. Unless partial is set, errors in the response, other than at
+// optional fields of resp, fail the call; the first is returned. The
+// warnings about the response are returned.
+func (c *Client) handleResponse(ctx context.Context, req *Request, res *response, resp interface{}, partial bool, decode func(json.RawMessage) ([]Warning, error)) ([]Warning, error) {
+	// the data of streamed responses has already been written
+	streamed := streamTo(ctx) != nil
+	if !streamed {
+		c.shadow(req, res)
+		c.reportShape(req, res.data)
+	}
+	warnings := c.warnings(req, res, partial)
+	var more []Warning
+	if !partial {
+		var errs Errors
+		errs, more = c.optionalErrors(res.errors, resp)
+		if len(errs) > 0 {
+			c.reportStats(res.stats)
+			return warnings, withFingerprint(errs[0], res.fingerprint)
+		}
+	}
+	if decode != nil && !streamed {
+		start := c.now()
+		endRegion := c.traceRegion(ctx, "graphql.decodeResult")
+		decoded, err := decode(res.data)
+		endRegion()
+		res.stats.DecodeTime += c.since(start)
+		if err != nil {
+			c.reportStats(res.stats)
+			return warnings, withFingerprint(err, res.fingerprint)
+		}
+		more = append(more, decoded...)
+	}
+	c.reportStats(res.stats)
+	c.handleWarnings(req, more)
+	c.reportPruning(req, resp)
+	return append(warnings, more...), nil
+}
+
+// response is a GraphQL response, with its data still encoded, and
+// metadata about the call that got it.
+type response struct {
+	data       json.RawMessage
+	errors     Errors
+	extensions json.RawMessage
+	status     int
+	header     http.Header
+	duration   time.Duration
+	attempts   int
+	// req is the request as it was prepared and sent, before its
+	// variables were encrypted, for decoding by its document.
+	req *Request
+	// fingerprint is the request's fingerprint, if the client sends them.
+	fingerprint string
+	stats       RequestStats
+	truncations []Truncation
+}
+
+// do sends req and reads the GraphQL response.
+func (c *Client) do(ctx context.Context, req *Request) (_ *response, err error) {
 	select {
 	case <-ctx.Done():
-		return ctx.Err()
+		return nil, ctx.Err()
 	default:
 	}
+	atomic.AddInt32(&c.inFlight, 1)
+	defer atomic.AddInt32(&c.inFlight, -1)
+	ctx, endTask := c.traceTask(ctx, req)
+	defer endTask()
+	start := c.now()
 
-	var graphResponse = struct {
-		Data   interface{}
-		Errors []graphErr
-	}{
-		Data: resp,
+	c.refreshSchema(ctx)
+	original := req
+	req, err = c.prepare(req)
+	if err != nil {
+		return nil, err
+	}
+	reqs := []*Request{req}
+	if ctx, err = c.checkPolicies(ctx, reqs); err != nil {
+		return nil, err
+	}
+	ctx = c.routeContext(ctx, reqs[0])
+	if req, err = c.withIdempotencyKey(original, reqs[0]); err != nil {
+		return nil, err
+	}
+	if c.dryRuns(req) {
+		return nil, c.dryRunMutation(ctx, original, req)
+	}
+	// fingerprints and audit records are of the variables as given, not
+	// as encrypted, which change every time they are sent
+	plain := req
+	if req, err = c.encryptVariables(ctx, req); err != nil {
+		return nil, err
 	}
-
 	b, err := json.Marshal(req)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	header := req.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	if req.idempotencyKey != "" {
+		header.Set(c.idempotencyHeader, req.idempotencyKey)
+	}
+	c.sendConsistencyToken(ctx, header)
+	budget := c.sendLatencyBudget(ctx, header)
+	fingerprint, err := c.fingerprint(plain, header)
+	if err != nil {
+		return nil, err
+	}
+	var graphResponse struct {
+		Data       json.RawMessage
+		Errors     Errors
+		Extensions json.RawMessage
+	}
+	capture := c.startCapture(req, header)
+	audit := c.startAudit(ctx, plain)
+	defer func() {
+		c.finishCapture(capture, start, graphResponse.Errors, err)
+		c.finishAudit(audit, graphResponse.Errors, err)
+		c.recordHealth(req, start, graphResponse.Errors, err)
+	}()
+	stats := RequestStats{Operation: req.MetricsName(), LatencyBudget: budget}
+	if c.statsReport != nil {
+		stats.RootFields = req.RootFields()
+	}
+	endRegion := c.traceRegion(ctx, "graphql.send")
+	res, mediaType, body, err := c.send(ctx, req, b, header, &stats)
+	endRegion()
+	if err != nil {
+		return nil, withFingerprint(err, fingerprint)
+	}
+	c.captureResponse(capture, res, body)
+	c.keepConsistencyToken(ctx, req, res)
+	stats.BudgetOutcome = c.budgetOutcome(budget, res)
+	stats.FromCache = fromCache(res.Header)
+	if c.specCompliance {
+		if err := checkSpecResponse(res, mediaType, body); err != nil {
+			return nil, withFingerprint(err, fingerprint)
+		}
+	} else if err := checkStatusResponse(res, mediaType, body); err != nil {
+		return nil, withFingerprint(err, fingerprint)
+	}
+	decodeStart := c.now()
+	endRegion = c.traceRegion(ctx, "graphql.decode")
+	err = c.decode(body, &graphResponse)
+	endRegion()
+	if err != nil {
+		return nil, withFingerprint(err, fingerprint)
 	}
+	if graphResponse.Data, err = c.decryptData(ctx, req, graphResponse.Data); err != nil {
+		return nil, withFingerprint(err, fingerprint)
+	}
+	var truncations []Truncation
+	if graphResponse.Data, truncations, err = c.truncateLists(graphResponse.Data); err != nil {
+		return nil, withFingerprint(err, fingerprint)
+	}
+	stats.DecodeTime = c.since(decodeStart)
+	return &response{
+		data:        graphResponse.Data,
+		errors:      graphResponse.Errors,
+		extensions:  graphResponse.Extensions,
+		status:      res.StatusCode,
+		header:      res.Header,
+		duration:    c.since(start),
+		attempts:    1,
+		req:         plain,
+		fingerprint: fingerprint,
+		stats:       stats,
+		truncations: truncations,
+	}, nil
+}
 
-	r, err := http.NewRequest(http.MethodPost, c.endpoint, bytes.NewReader(b))
+// prepare gets the request as it will be sent, with defaults applied and
+// plugins run, and checks it may be sent.
+func (c *Client) prepare(req *Request) (*Request, error) {
+	req = c.applyDefaultVars(req)
+	req, err := c.transformRequest(req)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if req, err = c.nameOperation(req); err != nil {
+		return nil, err
+	}
+	return c.checkRequest(req)
+}
+
+// checkRequest checks that the request may be sent, and encodes and
+// coerces its variables. It is run again on requests rewritten by
+// policies, so that a rewrite can't get around the checks.
+func (c *Client) checkRequest(req *Request) (*Request, error) {
+	if err := c.checkAllowed(req); err != nil {
+		return nil, err
+	}
+	if err := c.checkUndeclaredVariables(req); err != nil {
+		return nil, err
+	}
+	if err := c.validateVariables(req); err != nil {
+		return nil, err
+	}
+	req, err := c.encodeVariables(req)
+	if err != nil {
+		return nil, err
+	}
+	if req, err = c.coerceVariables(req); err != nil {
+		return nil, err
+	}
+	if err := c.checkSize(req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// post sends the encoded request body b to the endpoint, and reads the
+// response body, which is returned with its media type.
+func (c *Client) post(ctx context.Context, b []byte, header http.Header, stats *RequestStats) (*http.Response, string, []byte, error) {
+	endpoint, err := c.resolveEndpoint(ctx)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	r, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(b))
+	if err != nil {
+		return nil, "", nil, err
+	}
+	stats.RequestBytes = len(b)
+	if c.digestHeader != "" {
+		header = header.Clone()
+		if header == nil {
+			header = make(http.Header)
+		}
+		c.digest(header, b)
+	}
+	return c.roundTrip(ctx, r, header, stats)
+}
+
+// roundTrip sends r with the client's and the given headers, and reads
+// the response body, which is returned with its media type.
+func (c *Client) roundTrip(ctx context.Context, r *http.Request, header http.Header, stats *RequestStats) (*http.Response, string, []byte, error) {
+	for name, values := range c.header {
+		r.Header[name] = values
 	}
-	r.Header.Set("Content-Type", "application/json")
-	r.Header.Set("Accept", "application/json")
+	for name, values := range header {
+		r.Header[name] = values
+	}
+	if r.Method == http.MethodPost {
+		r.Header.Set("Content-Type", c.contentType)
+	}
+	r.Header.Set("Accept", c.acceptHeader())
+	c.acceptGzip(r)
+	if err := c.applyCredentials(r); err != nil {
+		return nil, "", nil, err
+	}
+	ctx, timer, stop := c.startReadTimer(ctx)
+	defer stop()
 	r = r.WithContext(ctx)
 	res, err := c.httpClient.Do(r)
 	if err != nil {
-		return err
+		return nil, "", nil, timer.check(err)
 	}
 	defer res.Body.Close()
-	var buf bytes.Buffer
-	if _, err := io.Copy(&buf, res.Body); err != nil {
-		return errors.Wrap(err, "reading body")
+	res.Body = timer.body(res.Body)
+	if w := streamTo(ctx); w != nil && res.StatusCode >= 200 && res.StatusCode < 300 {
+		mediaType, body, err := c.streamResponse(res, stats, w)
+		if err = timer.check(err); err != nil {
+			return nil, "", nil, err
+		}
+		return res, mediaType, body, nil
+	}
+	body, err := c.readResponse(res, stats)
+	if err = timer.check(err); err != nil {
+		switch err.(type) {
+		case *ReadTimeoutError, *DecodeError:
+			return nil, "", nil, err
+		}
+		return nil, "", nil, errors.Wrap(err, "reading body")
 	}
-	if err := json.NewDecoder(&buf).Decode(&graphResponse); err != nil {
-		return errors.Wrap(err, "decoding response")
+	mediaType, body, err := c.handleMediaType(res, c.stripPrefix(body))
+	if err != nil {
+		return nil, "", nil, err
 	}
-	if len(graphResponse.Errors) > 0 {
-		// return first error
-		return graphResponse.Errors[0]
+	body, err = c.transformResponse(body)
+	if err != nil {
+		return nil, "", nil, err
 	}
-	return nil
+	return res, mediaType, body, nil
 }
 
 // WithHTTPClient specifies the underlying http.Client to use when
@@ -119,23 +486,71 @@ func WithHTTPClient(httpclient *http.Client) ClientOption {
 	})
 }
 
+// WithHeader adds a header sent with every request. Headers set on
+// a Request take precedence.
+//  NewClient(endpoint, WithHeader("Authorization", "Bearer "+token))
+func WithHeader(name, value string) ClientOption {
+	return ClientOption(func(client *Client) {
+		if client.header == nil {
+			client.header = make(http.Header)
+		}
+		client.header.Add(name, value)
+	})
+}
+
 // ClientOption are functions that are passed into NewClient to
 // modify the behaviour of the Client.
 type ClientOption func(*Client)
 
-type graphErr struct {
-	Message string
+// Error is an error returned by a GraphQL server.
+type Error struct {
+	Message   string     `json:"message"`
+	Locations []Location `json:"locations,omitempty"`
+	// Path is the path of the response field the error relates to,
+	// made up of field names (strings) and list indices (numbers).
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
 }
 
-func (e graphErr) Error() string {
+func (e Error) Error() string {
 	return "graphql: " + e.Message
 }
 
+// Location is a position in the query document.
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Errors are the errors returned by a GraphQL server.
+type Errors []Error
+
+func (e Errors) Error() string {
+	switch len(e) {
+	case 0:
+		return "graphql: no errors"
+	case 1:
+		return e[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", e[0].Error(), len(e)-1)
+}
+
 // Request is a GraphQL request.
 type Request struct {
 	OperationName string                 `json:"operationName,omitempty"`
 	Query         string                 `json:"query"`
 	Variables     map[string]interface{} `json:"variables,omitempty"`
+
+	// Header holds additional HTTP headers sent with the request.
+	Header http.Header `json:"-"`
+
+	idempotencyKey string
+	metricsName    string
+	route          Route
+	defaultVars    map[string]interface{}
+	// namedQuery is the document of a request the client named, and
+	// unnamedQuery the caller's document it was named from.
+	namedQuery, unnamedQuery string
 }
 
 // NewRequest makes a new Request with the specified string.
@@ -153,3 +568,19 @@ func (req *Request) Var(key string, value interface{}) {
 	}
 	req.Variables[key] = value
 }
+
+// clone makes a copy of the request that can be modified without
+// affecting the original.
+func (req *Request) clone() *Request {
+	r := *req
+	if req.Header != nil {
+		r.Header = req.Header.Clone()
+	}
+	if req.Variables != nil {
+		r.Variables = make(map[string]interface{}, len(req.Variables))
+		for k, v := range req.Variables {
+			r.Variables[k] = v
+		}
+	}
+	return &r
+}
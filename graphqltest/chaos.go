@@ -0,0 +1,169 @@
+package graphqltest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Fault is a kind of failure injected by ChaosTransport.
+type Fault string
+
+// Faults injected by ChaosTransport.
+const (
+	FaultLatency      Fault = "latency"
+	FaultReset        Fault = "reset"
+	FaultMalformed    Fault = "malformed"
+	FaultPartialError Fault = "partial_error"
+	FaultRateLimit    Fault = "rate_limit"
+)
+
+// ChaosTransport is an http.RoundTripper that injects faults into
+// requests at configured probabilities, for exercising error handling.
+// Rates are probabilities between 0 and 1 and are evaluated independently
+// for each request. Seed Rand to make the faults deterministic.
+//
+//  transport := &graphqltest.ChaosTransport{
+//      Rand:      rand.New(rand.NewSource(1)),
+//      ResetRate: 0.1,
+//  }
+//  client := graphql.NewClient(endpoint, graphql.WithHTTPClient(&http.Client{Transport: transport}))
+type ChaosTransport struct {
+	// Transport sends the requests. If nil, http.DefaultTransport is used.
+	Transport http.RoundTripper
+	// Rand is the source of randomness. If nil, a source seeded with 1
+	// is used.
+	Rand *rand.Rand
+
+	// Latency is added to requests selected by LatencyRate.
+	Latency     time.Duration
+	LatencyRate float64
+	// ResetRate is the probability of failing with a connection reset
+	// before the request is sent.
+	ResetRate float64
+	// MalformedRate is the probability of truncating the response body
+	// so that it is not valid JSON.
+	MalformedRate float64
+	// PartialErrorRate is the probability of adding an error to an
+	// otherwise successful response.
+	PartialErrorRate float64
+	// RateLimitRate is the probability of responding with
+	// 429 Too Many Requests and a Retry-After of RetryAfter.
+	RateLimitRate float64
+	RetryAfter    time.Duration
+
+	mu     sync.Mutex
+	counts map[Fault]int
+}
+
+var _ http.RoundTripper = (*ChaosTransport)(nil)
+
+// RoundTrip sends r, injecting faults. Like any RoundTripper, it closes
+// the request body, whether or not the request is sent, and the body of
+// any response it replaces.
+func (c *ChaosTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if c.roll(FaultLatency, c.LatencyRate) {
+		select {
+		case <-time.After(c.Latency):
+		case <-r.Context().Done():
+			closeRequest(r)
+			return nil, r.Context().Err()
+		}
+	}
+	if c.roll(FaultReset, c.ResetRate) {
+		closeRequest(r)
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+	}
+	if c.roll(FaultRateLimit, c.RateLimitRate) {
+		closeRequest(r)
+		res := fixtureResponse(r, http.StatusTooManyRequests, []byte(`{"errors":[{"message":"graphqltest: rate limited"}]}`))
+		res.Header.Set("Retry-After", strconv.Itoa(int(c.RetryAfter/time.Second)))
+		return res, nil
+	}
+	transport := c.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	res, err := transport.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+	malformed := c.roll(FaultMalformed, c.MalformedRate)
+	partial := c.roll(FaultPartialError, c.PartialErrorRate)
+	if !malformed && !partial {
+		return res, nil
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "graphqltest: reading response")
+	}
+	if partial {
+		body, err = addError(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if malformed {
+		body = body[:len(body)/2]
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+	res.ContentLength = int64(len(body))
+	res.Header.Del("Content-Length")
+	return res, nil
+}
+
+// closeRequest closes the body of a request that isn't sent.
+func closeRequest(r *http.Request) {
+	if r.Body != nil {
+		r.Body.Close()
+	}
+}
+
+// Count gets the number of times the fault has been injected.
+func (c *ChaosTransport) Count(fault Fault) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[fault]
+}
+
+// roll decides whether to inject the fault.
+func (c *ChaosTransport) roll(fault Fault, rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Rand == nil {
+		c.Rand = rand.New(rand.NewSource(1))
+	}
+	if c.Rand.Float64() >= rate {
+		return false
+	}
+	if c.counts == nil {
+		c.counts = make(map[Fault]int)
+	}
+	c.counts[fault]++
+	return true
+}
+
+func addError(body []byte) ([]byte, error) {
+	var payload map[string]interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, errors.Wrap(err, "graphqltest: decoding response")
+	}
+	errs, _ := payload["errors"].([]interface{})
+	payload["errors"] = append(errs, map[string]interface{}{
+		"message": "graphqltest: injected partial error",
+	})
+	return json.Marshal(payload)
+}
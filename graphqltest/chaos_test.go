@@ -0,0 +1,104 @@
+package graphqltest_test
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	"github.com/joefitzgerald/graphql/graphqltest"
+	. "github.com/onsi/gomega"
+)
+
+func TestChaosTransport(t *testing.T) {
+	RegisterTestingT(t)
+	fake := graphqltest.NewFakeClient()
+	fake.Respond("", `{"value":"ok"}`)
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	run := func(transport *graphqltest.ChaosTransport) error {
+		client := graphql.NewClient(srv.URL, graphql.WithHTTPClient(&http.Client{Transport: transport}))
+		return client.Run(context.Background(), graphql.NewRequest(`{ value }`), nil)
+	}
+
+	transport := &graphqltest.ChaosTransport{ResetRate: 1}
+	Expect(run(transport)).Should(HaveOccurred())
+	Expect(transport.Count(graphqltest.FaultReset)).Should(Equal(1))
+
+	transport = &graphqltest.ChaosTransport{MalformedRate: 1}
+	Expect(run(transport)).Should(HaveOccurred())
+
+	transport = &graphqltest.ChaosTransport{PartialErrorRate: 1}
+	err := run(transport)
+	Expect(err).Should(HaveOccurred())
+	Expect(err.Error()).Should(ContainSubstring("injected partial error"))
+
+	transport = &graphqltest.ChaosTransport{RateLimitRate: 1}
+	Expect(run(transport)).Should(HaveOccurred())
+
+	transport = &graphqltest.ChaosTransport{
+		Rand:      rand.New(rand.NewSource(42)),
+		ResetRate: 0.5,
+	}
+	var failures int
+	for i := 0; i < 100; i++ {
+		if run(transport) != nil {
+			failures++
+		}
+	}
+	Expect(failures).Should(Equal(transport.Count(graphqltest.FaultReset)))
+	Expect(failures).Should(BeNumerically(">", 20))
+	Expect(failures).Should(BeNumerically("<", 80))
+}
+
+// closeCounter counts the bodies closed.
+type closeCounter struct {
+	io.Reader
+	closed *int
+}
+
+func (b closeCounter) Close() error {
+	*b.closed++
+	return nil
+}
+
+func TestChaosTransportClosesBodies(t *testing.T) {
+	RegisterTestingT(t)
+	var responses int
+	upstream := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		r.Body.Close()
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       closeCounter{strings.NewReader(`{"data":{}}`), &responses},
+		}, nil
+	})
+	for _, transport := range []*graphqltest.ChaosTransport{
+		{Transport: upstream, ResetRate: 1},
+		{Transport: upstream, RateLimitRate: 1},
+		{Transport: upstream, MalformedRate: 1},
+		{Transport: upstream, PartialErrorRate: 1},
+	} {
+		var requests int
+		r, err := http.NewRequest(http.MethodPost, "http://example.com", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		r.Body = closeCounter{strings.NewReader(`{"query":"{ a }"}`), &requests}
+		res, err := transport.RoundTrip(r)
+		if err == nil {
+			res.Body.Close()
+		}
+		Expect(requests).Should(Equal(1))
+	}
+	Expect(responses).Should(Equal(2))
+}
+
+type roundTripperFunc func(r *http.Request) (*http.Response, error)
+
+func (fn roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return fn(r)
+}
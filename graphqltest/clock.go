@@ -0,0 +1,79 @@
+package graphqltest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/joefitzgerald/graphql"
+)
+
+// Clock is a graphql.TickingClock that only moves when told to, for
+// testing timings and periodic work without sleeping.
+//  clock := graphqltest.NewClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+//  client := graphql.NewClient(endpoint, graphql.WithClock(clock))
+//  ...
+//  clock.Advance(time.Minute)
+type Clock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*ticker
+}
+
+var _ graphql.TickingClock = (*Clock)(nil)
+
+type ticker struct {
+	interval time.Duration
+	next     time.Time
+	c        chan time.Time
+}
+
+// NewClock makes a Clock set to now.
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now gets the clock's time.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock on by d, sending the ticks that are due.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	for _, t := range c.tickers {
+		for !t.next.After(c.now) {
+			select {
+			case t.c <- t.next:
+			default:
+				// the receiver is slow, so the tick is dropped
+			}
+			t.next = t.next.Add(t.interval)
+		}
+	}
+}
+
+// Tick sends the clock's time every d that it is advanced by, until stop
+// is called.
+func (c *Clock) Tick(d time.Duration) (ticks <-chan time.Time, stop func()) {
+	if d <= 0 {
+		panic("graphqltest: non-positive interval for Clock.Tick")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &ticker{interval: d, next: c.now.Add(d), c: make(chan time.Time, 1)}
+	c.tickers = append(c.tickers, t)
+	return t.c, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for i, other := range c.tickers {
+			if other == t {
+				c.tickers = append(c.tickers[:i], c.tickers[i+1:]...)
+				break
+			}
+		}
+	}
+}
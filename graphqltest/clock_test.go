@@ -0,0 +1,48 @@
+package graphqltest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/joefitzgerald/graphql"
+	"github.com/joefitzgerald/graphql/graphqltest"
+	. "github.com/onsi/gomega"
+)
+
+func TestClock(t *testing.T) {
+	RegisterTestingT(t)
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := graphqltest.NewClock(start)
+	Expect(clock.Now()).Should(Equal(start))
+	clock.Advance(time.Minute)
+	Expect(clock.Now()).Should(Equal(start.Add(time.Minute)))
+
+	health := graphql.NewHealthTracker(time.Minute)
+	health.Clock = clock
+	health.Record("A", time.Second, true)
+	clock.Advance(30 * time.Second)
+	health.Record("A", time.Second, false)
+	Expect(health.Health("A").Calls).Should(Equal(2))
+	clock.Advance(31 * time.Second)
+	Expect(health.Health("A").Calls).Should(Equal(1))
+	clock.Advance(time.Minute)
+	Expect(health.Operations()).Should(BeEmpty())
+}
+
+func TestClockTick(t *testing.T) {
+	RegisterTestingT(t)
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := graphqltest.NewClock(start)
+	ticks, stop := clock.Tick(time.Minute)
+	clock.Advance(30 * time.Second)
+	Expect(ticks).ShouldNot(Receive())
+	clock.Advance(30 * time.Second)
+	Expect(ticks).Should(Receive(Equal(start.Add(time.Minute))))
+	// ticks aren't queued for slow receivers
+	clock.Advance(3 * time.Minute)
+	Expect(ticks).Should(Receive(Equal(start.Add(2 * time.Minute))))
+	Expect(ticks).ShouldNot(Receive())
+	stop()
+	clock.Advance(time.Minute)
+	Expect(ticks).ShouldNot(Receive())
+}
@@ -0,0 +1,121 @@
+package graphqltest
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/joefitzgerald/graphql"
+	"github.com/pkg/errors"
+)
+
+// ContractFailure is a stored query document that is not valid against
+// a schema.
+type ContractFailure struct {
+	// File is the path of the document.
+	File string
+	// Operations are the names of the operations defined in the document.
+	Operations []string
+	// Errors are the reasons the document is not valid.
+	Errors graphql.Errors
+}
+
+func (f ContractFailure) String() string {
+	var buf bytes.Buffer
+	buf.WriteString(f.File)
+	if len(f.Operations) > 0 {
+		fmt.Fprintf(&buf, " (%s)", strings.Join(f.Operations, ", "))
+	}
+	buf.WriteString(":")
+	for _, err := range f.Errors {
+		buf.WriteString("\n\t")
+		for _, loc := range err.Locations {
+			fmt.Fprintf(&buf, "%d:%d: ", loc.Line, loc.Column)
+		}
+		buf.WriteString(err.Message)
+	}
+	return buf.String()
+}
+
+// CheckOperations validates the query documents in the files matching
+// the glob patterns against schema, returning the documents that are not
+// valid. Documents can use fragments defined in any of the files.
+func CheckOperations(schema *graphql.Schema, patterns ...string) ([]ContractFailure, error) {
+	var files []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "graphqltest: bad pattern %q", pattern)
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+	docs := make([]string, len(files))
+	for i, file := range files {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, errors.Wrap(err, "graphqltest: reading operations")
+		}
+		docs[i] = string(b)
+	}
+	var failures []ContractFailure
+	for i, file := range files {
+		shared := make([]string, 0, len(docs)-1)
+		shared = append(shared, docs[:i]...)
+		shared = append(shared, docs[i+1:]...)
+		errs := schema.Validate(docs[i], shared...)
+		if len(errs) == 0 {
+			continue
+		}
+		failure := ContractFailure{File: file, Errors: errs}
+		ops, _ := graphql.ParseOperations(docs[i])
+		for _, op := range ops {
+			name := op.Name
+			if name == "" {
+				name = "anonymous " + op.Type
+			}
+			failure.Operations = append(failure.Operations, name)
+		}
+		failures = append(failures, failure)
+	}
+	return failures, nil
+}
+
+// TestingT is the part of testing.TB used by AssertContract.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// AssertContract checks that the operations in the files matching the
+// glob patterns are valid against the schema snapshot in schemaFile, the
+// JSON result of an introspection query. Each document that is not
+// valid is reported as a test error, so updating the snapshot shows
+// exactly which operations it breaks.
+//  func TestContract(t *testing.T) {
+//      graphqltest.AssertContract(t, "testdata/schema.json", "queries/*.graphql")
+//  }
+func AssertContract(t TestingT, schemaFile string, patterns ...string) {
+	t.Helper()
+	b, err := ioutil.ReadFile(schemaFile)
+	if err != nil {
+		t.Errorf("graphqltest: reading schema: %v", err)
+		return
+	}
+	schema, err := graphql.ParseSchema(b)
+	if err != nil {
+		t.Errorf("%v", err)
+		return
+	}
+	failures, err := CheckOperations(schema, patterns...)
+	if err != nil {
+		t.Errorf("%v", err)
+		return
+	}
+	for _, f := range failures {
+		t.Errorf("operations not valid against %s: %s", schemaFile, f)
+	}
+}
@@ -0,0 +1,57 @@
+package graphqltest_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	"github.com/joefitzgerald/graphql/graphqltest"
+	. "github.com/onsi/gomega"
+)
+
+type recordingT struct {
+	errors []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertContract(t *testing.T) {
+	RegisterTestingT(t)
+	dir, err := ioutil.TempDir("", "graphqltest")
+	Expect(err).ShouldNot(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"fragments.graphql": `fragment UserFields on User { id name }`,
+		"user.graphql":      `query GetUser($id: ID!) { user(id: $id) { ...UserFields } }`,
+		"users.graphql":     `query ListUsers { users { ...UserFields age } }`,
+	}
+	for name, src := range files {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(src), 0644)
+		Expect(err).ShouldNot(HaveOccurred())
+	}
+
+	rt := &recordingT{}
+	graphqltest.AssertContract(rt, "../testdata/schema.json", filepath.Join(dir, "*.graphql"))
+	Expect(rt.errors).Should(Equal([]string{
+		"operations not valid against ../testdata/schema.json: " + filepath.Join(dir, "users.graphql") + " (ListUsers):\n" +
+			"\t1:41: Cannot query field \"age\" on type \"User\"",
+	}))
+
+	b, err := ioutil.ReadFile("../testdata/schema.json")
+	Expect(err).ShouldNot(HaveOccurred())
+	schema, err := graphql.ParseSchema(b)
+	Expect(err).ShouldNot(HaveOccurred())
+	failures, err := graphqltest.CheckOperations(schema, filepath.Join(dir, "user.graphql"))
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(failures).Should(HaveLen(1))
+	Expect(failures[0].Operations).Should(Equal([]string{"GetUser"}))
+	Expect(failures[0].Errors[0].Message).Should(Equal(`Unknown fragment "UserFields"`))
+}
@@ -0,0 +1,325 @@
+// Package graphqltest provides utilities for testing code that uses
+// the graphql package.
+//
+//  fake := graphqltest.NewFakeClient()
+//  fake.Respond("GetUser", `{"user":{"name":"Mat"}}`)
+//
+//  // pass fake anywhere a graphql.Runner is expected
+//  svc := NewService(fake)
+//
+// Matchers
+//
+// Responses can also be selected with matchers:
+//  fake.When(
+//      graphqltest.MatchOperation("GetUser"),
+//      graphqltest.MatchVar("id", 42),
+//  ).Respond(`{"user":{"name":"Mat"}}`)
+// When no response matches, the error returned by Run explains why
+// each rule did not match.
+//
+// FakeClient is also an http.Handler, so it can act as a mock server:
+//  srv := httptest.NewServer(fake)
+//  client := graphql.NewClient(srv.URL)
+package graphqltest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/joefitzgerald/graphql"
+	"github.com/pkg/errors"
+)
+
+// FakeClient is a graphql.Runner that returns programmed responses
+// without making HTTP requests.
+// If more than one rule matches a request, the most recently added
+// rule is used.
+type FakeClient struct {
+	mu           sync.Mutex
+	rules        []*Rule
+	expectedVars map[string]map[string]interface{}
+	calls        []Call
+}
+
+type fakeResponse struct {
+	data json.RawMessage
+	err  error
+}
+
+// Call is a request received by a FakeClient.
+type Call struct {
+	// Operation is the name of the operation that was run.
+	Operation string
+	// Request is the request that was run.
+	Request *graphql.Request
+}
+
+var _ graphql.Runner = (*FakeClient)(nil)
+
+// NewFakeClient makes a new FakeClient with no programmed responses.
+func NewFakeClient() *FakeClient {
+	return &FakeClient{
+		expectedVars: make(map[string]map[string]interface{}),
+	}
+}
+
+// When adds a rule that responds to requests matching all of the
+// matchers. Program the response with the returned Rule; it may be
+// changed while requests are served.
+func (f *FakeClient) When(matchers ...Matcher) *Rule {
+	rule := &Rule{matchers: matchers, mu: &f.mu}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rules = append(f.rules, rule)
+	return rule
+}
+
+// Respond programs the data returned for the named operation.
+// Anonymous operations use the empty name.
+// data may be a JSON string, a []byte or json.RawMessage containing JSON,
+// or any value that can be marshalled to JSON.
+func (f *FakeClient) Respond(operation string, data interface{}) {
+	f.When(MatchOperation(operation)).Respond(data)
+}
+
+// RespondError programs the error returned for the named operation.
+func (f *FakeClient) RespondError(operation string, err error) {
+	f.When(MatchOperation(operation)).RespondError(err)
+}
+
+// ExpectVars makes Run fail for the named operation unless the request
+// variables equal vars. Values are compared after a round trip through JSON,
+// so an int expectation will match a float64 variable of the same value.
+func (f *FakeClient) ExpectVars(operation string, vars map[string]interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.expectedVars[operation] = vars
+}
+
+// Run records the call and unmarshals the programmed response
+// into resp.
+func (f *FakeClient) Run(ctx context.Context, req *graphql.Request, resp interface{}) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+	data, err := f.respond(req)
+	if err != nil {
+		return err
+	}
+	if resp == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, resp)
+}
+
+// ServeHTTP responds to GraphQL requests over HTTP as Run would.
+func (f *FakeClient) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req graphql.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var body struct {
+		Data   json.RawMessage `json:"data,omitempty"`
+		Errors []errorMessage  `json:"errors,omitempty"`
+	}
+	data, err := f.respond(&req)
+	if err != nil {
+		body.Errors = []errorMessage{{Message: err.Error()}}
+	} else {
+		body.Data = data
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
+
+type errorMessage struct {
+	Message string `json:"message"`
+}
+
+// respond records the call and gets the programmed response for it.
+func (f *FakeClient) respond(req *graphql.Request) (json.RawMessage, error) {
+	name := operationName(req)
+	f.mu.Lock()
+	f.calls = append(f.calls, Call{Operation: name, Request: req})
+	rules := f.rules
+	expected, checkVars := f.expectedVars[name]
+	f.mu.Unlock()
+	if checkVars {
+		if err := compareVars(expected, req.Variables); err != nil {
+			return nil, errors.Wrapf(err, "graphqltest: operation %q", name)
+		}
+	}
+	var mismatches []string
+	for i := len(rules) - 1; i >= 0; i-- {
+		err := rules[i].match(req)
+		if err == nil {
+			f.mu.Lock()
+			response := rules[i].response
+			f.mu.Unlock()
+			return response.data, response.err
+		}
+		mismatches = append(mismatches, "\n  "+rules[i].String()+": "+err.Error())
+	}
+	return nil, errors.Errorf("graphqltest: no response for operation %q%s", name, strings.Join(mismatches, ""))
+}
+
+// Calls gets every call made to the FakeClient, in order.
+func (f *FakeClient) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	calls := make([]Call, len(f.calls))
+	copy(calls, f.calls)
+	return calls
+}
+
+// CallsTo gets the calls made for the named operation, in order.
+func (f *FakeClient) CallsTo(operation string) []Call {
+	var calls []Call
+	for _, call := range f.Calls() {
+		if call.Operation == operation {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// operationName gets the name of the operation req executes, falling
+// back to the OperationName field when the document can't be parsed.
+func operationName(req *graphql.Request) string {
+	op, err := req.Operation()
+	if err != nil {
+		return req.OperationName
+	}
+	return op.Name
+}
+
+func toJSON(v interface{}) (json.RawMessage, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return json.RawMessage(v), nil
+	case []byte:
+		return json.RawMessage(v), nil
+	case json.RawMessage:
+		return v, nil
+	}
+	return json.Marshal(v)
+}
+
+// normalize round trips v through JSON so values of different Go types
+// that encode the same way compare equal.
+func normalize(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var n interface{}
+	if err := json.Unmarshal(b, &n); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func compareVars(expected, actual map[string]interface{}) error {
+	e, err := normalize(expected)
+	if err != nil {
+		return errors.Wrap(err, "expected variables")
+	}
+	a, err := normalize(actual)
+	if err != nil {
+		return errors.Wrap(err, "actual variables")
+	}
+	if len(expected) == 0 && len(actual) == 0 {
+		return nil
+	}
+	if !reflect.DeepEqual(e, a) {
+		return errors.Errorf("variables did not match:\n    %s", strings.Join(diffValues("", e, a), "\n    "))
+	}
+	return nil
+}
+
+// diffValues describes the differences between two normalized values,
+// one per path, such as `input.tags[1]: expected "a", got "b"`.
+func diffValues(path string, expected, actual interface{}) []string {
+	at := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(e)+len(a))
+		for k := range e {
+			keys = append(keys, k)
+		}
+		for k := range a {
+			if _, ok := e[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		var diffs []string
+		for _, k := range keys {
+			ev, inE := e[k]
+			av, inA := a[k]
+			switch {
+			case !inA:
+				diffs = append(diffs, fmt.Sprintf("%s: expected %s, but it is not set", at(k), jsonString(ev)))
+			case !inE:
+				diffs = append(diffs, fmt.Sprintf("%s: unexpected %s", at(k), jsonString(av)))
+			default:
+				diffs = append(diffs, diffValues(at(k), ev, av)...)
+			}
+		}
+		return diffs
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			break
+		}
+		var diffs []string
+		for i := 0; i < len(e) || i < len(a); i++ {
+			item := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(a):
+				diffs = append(diffs, fmt.Sprintf("%s: expected %s, but it is missing", item, jsonString(e[i])))
+			case i >= len(e):
+				diffs = append(diffs, fmt.Sprintf("%s: unexpected %s", item, jsonString(a[i])))
+			default:
+				diffs = append(diffs, diffValues(item, e[i], a[i])...)
+			}
+		}
+		return diffs
+	}
+	if reflect.DeepEqual(expected, actual) {
+		return nil
+	}
+	if path == "" {
+		return []string{fmt.Sprintf("expected %s, got %s", jsonString(expected), jsonString(actual))}
+	}
+	return []string{fmt.Sprintf("%s: expected %s, got %s", path, jsonString(expected), jsonString(actual))}
+}
+
+// jsonString formats a normalized value as JSON.
+func jsonString(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
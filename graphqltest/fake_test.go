@@ -0,0 +1,122 @@
+package graphqltest_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	"github.com/joefitzgerald/graphql/graphqltest"
+	. "github.com/onsi/gomega"
+)
+
+func TestFakeClient(t *testing.T) {
+	RegisterTestingT(t)
+	fake := graphqltest.NewFakeClient()
+	fake.Respond("GetUser", `{"user":{"name":"Mat"}}`)
+
+	req := graphql.NewRequest(`query GetUser($id: ID!) { user(id: $id) { name } }`)
+	req.Var("id", 42)
+	var resp struct {
+		User struct {
+			Name string
+		}
+	}
+	err := fake.Run(context.Background(), req, &resp)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(resp.User.Name).Should(Equal("Mat"))
+
+	calls := fake.CallsTo("GetUser")
+	Expect(calls).Should(HaveLen(1))
+	Expect(calls[0].Request.Variables["id"]).Should(Equal(42))
+}
+
+func TestFakeClientError(t *testing.T) {
+	RegisterTestingT(t)
+	fake := graphqltest.NewFakeClient()
+	fake.RespondError("DeleteUser", errors.New("nope"))
+
+	err := fake.Run(context.Background(), graphql.NewRequest(`mutation DeleteUser { deleteUser }`), nil)
+	Expect(err).Should(MatchError("nope"))
+
+	err = fake.Run(context.Background(), graphql.NewRequest(`query Other { other }`), nil)
+	Expect(err).Should(HaveOccurred())
+	Expect(fake.Calls()).Should(HaveLen(2))
+}
+
+func TestFakeClientExpectVars(t *testing.T) {
+	RegisterTestingT(t)
+	fake := graphqltest.NewFakeClient()
+	fake.Respond("GetUser", map[string]interface{}{"user": nil})
+	fake.ExpectVars("GetUser", map[string]interface{}{"id": 42})
+
+	req := graphql.NewRequest(`query GetUser($id: ID!) { user(id: $id) { name } }`)
+	req.Var("id", 42)
+	Expect(fake.Run(context.Background(), req, nil)).ShouldNot(HaveOccurred())
+
+	req.Var("id", 43)
+	Expect(fake.Run(context.Background(), req, nil)).Should(HaveOccurred())
+
+	fake.ExpectVars("CreateUser", map[string]interface{}{
+		"input": map[string]interface{}{"name": "Mat", "tags": []string{"a", "b"}, "admin": false},
+	})
+	fake.Respond("CreateUser", map[string]interface{}{"createUser": nil})
+	req = graphql.NewRequest(`mutation CreateUser($input: UserInput!) { createUser(input: $input) { id } }`)
+	req.Var("input", map[string]interface{}{"name": "Matt", "tags": []string{"a"}, "email": "m@example.com"})
+	err := fake.Run(context.Background(), req, nil)
+	Expect(err).Should(MatchError("graphqltest: operation \"CreateUser\": variables did not match:\n" +
+		"    input.admin: expected false, but it is not set\n" +
+		"    input.email: unexpected \"m@example.com\"\n" +
+		"    input.name: expected \"Mat\", got \"Matt\"\n" +
+		"    input.tags[1]: expected \"b\", but it is missing"))
+}
+
+func TestFakeClientServer(t *testing.T) {
+	RegisterTestingT(t)
+	fake := graphqltest.NewFakeClient()
+	fake.Respond("GetUser", `{"user":{"name":"Mat"}}`)
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	client := graphql.NewClient(srv.URL)
+	var resp struct {
+		User struct {
+			Name string
+		}
+	}
+	err := client.Run(context.Background(), graphql.NewRequest(`query GetUser { user { name } }`), &resp)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(resp.User.Name).Should(Equal("Mat"))
+
+	err = client.Run(context.Background(), graphql.NewRequest(`query Other { other }`), &resp)
+	Expect(err).Should(HaveOccurred())
+	Expect(err.Error()).Should(ContainSubstring(`operation: expected "GetUser", got "Other"`))
+}
+
+func TestFakeClientRespondWhileServing(t *testing.T) {
+	RegisterTestingT(t)
+	fake := graphqltest.NewFakeClient()
+	rule := fake.When(graphqltest.MatchOperation("GetUser"))
+	rule.Respond(`{"user":{"name":"Mat"}}`)
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			client.Run(context.Background(), graphql.NewRequest(`query GetUser { user { name } }`), nil)
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		rule.Respond(`{"user":{"name":"Ann"}}`)
+	}
+	<-done
+	var resp struct {
+		User struct{ Name string }
+	}
+	Expect(client.Run(context.Background(), graphql.NewRequest(`query GetUser { user { name } }`), &resp)).Should(Succeed())
+	Expect(resp.User.Name).Should(Equal("Ann"))
+}
@@ -0,0 +1,173 @@
+package graphqltest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/joefitzgerald/graphql"
+	"github.com/pkg/errors"
+)
+
+// UpdateEnv is the environment variable that, when set to a non-empty
+// value, puts fixtures made with NewFixtures into update mode.
+const UpdateEnv = "GRAPHQLTEST_UPDATE"
+
+// Fixtures is an http.RoundTripper that serves GraphQL responses from
+// golden files, keyed by operation name and a hash of the variables.
+//
+//  fixtures := graphqltest.NewFixtures("testdata")
+//  client := fixtures.Client("https://example.com/graphql")
+//
+// In update mode, requests are sent to the real endpoint and the
+// responses are written to the fixture files, with their status if it
+// isn't 200 OK, so that errors are replayed as they were recorded.
+type Fixtures struct {
+	// Dir is the directory holding the fixture files.
+	Dir string
+	// Update records responses from Upstream into Dir rather than
+	// serving them.
+	Update bool
+	// Upstream is the RoundTripper used in update mode.
+	// If nil, http.DefaultTransport is used.
+	Upstream http.RoundTripper
+	// Redaction, if set, masks the response fields listed in its
+	// Fields before responses are recorded, so that fixtures don't
+	// hold personal data. Recorded responses are served masked too, as
+	// they will be when replayed.
+	Redaction *graphql.Redaction
+}
+
+var _ http.RoundTripper = (*Fixtures)(nil)
+
+// NewFixtures makes Fixtures that serve files from dir, in update mode
+// if the GRAPHQLTEST_UPDATE environment variable is set.
+func NewFixtures(dir string) *Fixtures {
+	return &Fixtures{
+		Dir:    dir,
+		Update: os.Getenv(UpdateEnv) != "",
+	}
+}
+
+// Client makes a graphql.Client that sends requests to endpoint through
+// the fixtures.
+func (f *Fixtures) Client(endpoint string, opts ...graphql.ClientOption) *graphql.Client {
+	opts = append(opts, graphql.WithHTTPClient(&http.Client{Transport: f}))
+	return graphql.NewClient(endpoint, opts...)
+}
+
+// Path gets the fixture file that holds the response to req.
+func (f *Fixtures) Path(req *graphql.Request) (string, error) {
+	vars, err := json.Marshal(req.Variables)
+	if err != nil {
+		return "", errors.Wrap(err, "graphqltest: encoding variables")
+	}
+	sum := sha256.Sum256(vars)
+	name := operationName(req)
+	if name == "" {
+		name = "anonymous"
+	}
+	return filepath.Join(f.Dir, name+"."+hex.EncodeToString(sum[:6])+".json"), nil
+}
+
+// RoundTrip serves the fixture for the GraphQL request in r, or records
+// it in update mode.
+func (f *Fixtures) RoundTrip(r *http.Request) (*http.Response, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "graphqltest: reading request")
+	}
+	var req graphql.Request
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, errors.Wrap(err, "graphqltest: decoding request")
+	}
+	path, err := f.Path(&req)
+	if err != nil {
+		return nil, err
+	}
+	if f.Update {
+		return f.record(r, body, path)
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "graphqltest: no fixture for operation %q (set %s=1 to record)", operationName(&req), UpdateEnv)
+	}
+	status, b := readFixture(b)
+	return fixtureResponse(r, status, b), nil
+}
+
+// fixtureFile is the form of fixtures for responses whose status isn't
+// 200 OK. Responses with that status are kept as they are, so that their
+// fixtures are easy to read and edit; GraphQL responses have no status
+// field, so the two can't be confused.
+type fixtureFile struct {
+	Status int     `json:"status"`
+	Body   *string `json:"body"`
+}
+
+// readFixture gets the status and body of the response in a fixture.
+func readFixture(b []byte) (int, []byte) {
+	var f fixtureFile
+	if json.Unmarshal(b, &f) == nil && f.Status != 0 && f.Body != nil {
+		return f.Status, []byte(*f.Body)
+	}
+	return http.StatusOK, b
+}
+
+// writeFixture gets the fixture of a response.
+func writeFixture(status int, body []byte) ([]byte, error) {
+	if status == http.StatusOK {
+		return body, nil
+	}
+	s := string(body)
+	return json.MarshalIndent(fixtureFile{Status: status, Body: &s}, "", "  ")
+}
+
+func (f *Fixtures) record(r *http.Request, body []byte, path string) (*http.Response, error) {
+	upstream := f.Upstream
+	if upstream == nil {
+		upstream = http.DefaultTransport
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	res, err := upstream.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "graphqltest: reading response")
+	}
+	if f.Redaction != nil {
+		b = f.Redaction.Response(b)
+	}
+	fixture, err := writeFixture(res.StatusCode, b)
+	if err != nil {
+		return nil, errors.Wrap(err, "graphqltest: encoding fixture")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(path, fixture, 0644); err != nil {
+		return nil, errors.Wrap(err, "graphqltest: writing fixture")
+	}
+	return fixtureResponse(r, res.StatusCode, b), nil
+}
+
+func fixtureResponse(r *http.Request, status int, body []byte) *http.Response {
+	return &http.Response{
+		StatusCode:    status,
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       r,
+	}
+}
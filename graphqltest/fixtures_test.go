@@ -0,0 +1,110 @@
+package graphqltest_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	"github.com/joefitzgerald/graphql/graphqltest"
+	. "github.com/onsi/gomega"
+)
+
+func TestFixtures(t *testing.T) {
+	RegisterTestingT(t)
+	dir, err := ioutil.TempDir("", "graphqltest")
+	Expect(err).ShouldNot(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		io.WriteString(w, `{"data":{"user":{"name":"Mat"}}}`)
+	}))
+	defer srv.Close()
+
+	req := graphql.NewRequest(`query GetUser($id: ID!) { user(id: $id) { name } }`)
+	req.Var("id", 1)
+	var resp struct {
+		User struct {
+			Name string
+		}
+	}
+
+	// record
+	fixtures := &graphqltest.Fixtures{Dir: dir, Update: true}
+	err = fixtures.Client(srv.URL).Run(context.Background(), req, &resp)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(calls).Should(Equal(1))
+	path, err := fixtures.Path(req)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(path).Should(BeAnExistingFile())
+
+	// replay
+	fixtures.Update = false
+	resp.User.Name = ""
+	err = fixtures.Client(srv.URL).Run(context.Background(), req, &resp)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(calls).Should(Equal(1))
+	Expect(resp.User.Name).Should(Equal("Mat"))
+
+	// different variables have no fixture
+	req.Var("id", 2)
+	err = fixtures.Client(srv.URL).Run(context.Background(), req, &resp)
+	Expect(err).Should(HaveOccurred())
+}
+
+func TestFixturesStatus(t *testing.T) {
+	RegisterTestingT(t)
+	dir, err := ioutil.TempDir("", "graphqltest")
+	Expect(err).ShouldNot(HaveOccurred())
+	defer os.RemoveAll(dir)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		io.WriteString(w, "down for maintenance")
+	}))
+	defer srv.Close()
+	req := graphql.NewRequest(`query GetUser { user { name } }`)
+
+	fixtures := &graphqltest.Fixtures{Dir: dir, Update: true}
+	recorded := fixtures.Client(srv.URL).Run(context.Background(), req, nil)
+	Expect(recorded).Should(HaveOccurred())
+
+	fixtures.Update = false
+	err = fixtures.Client(srv.URL).Run(context.Background(), req, nil)
+	Expect(err).Should(MatchError(recorded.Error()))
+	var se *graphql.StatusError
+	Expect(errors.As(err, &se)).Should(BeTrue())
+	Expect(se.StatusCode).Should(Equal(http.StatusServiceUnavailable))
+}
+
+func TestFixturesRedaction(t *testing.T) {
+	RegisterTestingT(t)
+	dir, err := ioutil.TempDir("", "graphqltest")
+	Expect(err).ShouldNot(HaveOccurred())
+	defer os.RemoveAll(dir)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{"user":{"name":"Mat","email":"mat@example.com"}}}`)
+	}))
+	defer srv.Close()
+	req := graphql.NewRequest(`query GetUser { user { name email } }`)
+
+	fixtures := &graphqltest.Fixtures{
+		Dir:       dir,
+		Update:    true,
+		Redaction: &graphql.Redaction{Fields: []string{"email"}},
+	}
+	var resp map[string]interface{}
+	Expect(fixtures.Client(srv.URL).Run(context.Background(), req, &resp)).Should(Succeed())
+	Expect(resp).Should(HaveKeyWithValue("user", HaveKeyWithValue("email", graphql.Redacted)))
+	path, err := fixtures.Path(req)
+	Expect(err).ShouldNot(HaveOccurred())
+	b, err := ioutil.ReadFile(path)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(b).Should(MatchJSON(`{"data":{"user":{"name":"Mat","email":"[REDACTED]"}}}`))
+}
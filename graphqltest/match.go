@@ -0,0 +1,160 @@
+package graphqltest
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/joefitzgerald/graphql"
+	"github.com/pkg/errors"
+)
+
+// Matcher decides whether a request matches an expectation.
+type Matcher interface {
+	// Match returns nil if req matches, or an error describing
+	// the mismatch.
+	Match(req *graphql.Request) error
+	// String describes the expectation.
+	String() string
+}
+
+// MatchOperation matches requests for the named operation.
+func MatchOperation(name string) Matcher {
+	return operationMatcher(name)
+}
+
+type operationMatcher string
+
+func (m operationMatcher) Match(req *graphql.Request) error {
+	if name := operationName(req); name != string(m) {
+		return errors.Errorf("operation: expected %q, got %q", string(m), name)
+	}
+	return nil
+}
+
+func (m operationMatcher) String() string {
+	return fmt.Sprintf("MatchOperation(%q)", string(m))
+}
+
+// MatchVar matches requests where the named variable equals value.
+// Values are compared after a round trip through JSON.
+func MatchVar(name string, value interface{}) Matcher {
+	return varMatcher{name: name, value: value}
+}
+
+type varMatcher struct {
+	name  string
+	value interface{}
+}
+
+func (m varMatcher) Match(req *graphql.Request) error {
+	actual, ok := req.Variables[m.name]
+	if !ok {
+		return errors.Errorf("variable %q: expected %v, but it is not set", m.name, m.value)
+	}
+	e, err := normalize(m.value)
+	if err != nil {
+		return errors.Wrapf(err, "variable %q", m.name)
+	}
+	a, err := normalize(actual)
+	if err != nil {
+		return errors.Wrapf(err, "variable %q", m.name)
+	}
+	if !reflect.DeepEqual(e, a) {
+		return errors.Errorf("variable %q: %s", m.name, strings.Join(diffValues("", e, a), ", "))
+	}
+	return nil
+}
+
+func (m varMatcher) String() string {
+	return fmt.Sprintf("MatchVar(%q, %v)", m.name, m.value)
+}
+
+// MatchQueryContains matches requests whose query document contains s.
+func MatchQueryContains(s string) Matcher {
+	return queryContainsMatcher(s)
+}
+
+type queryContainsMatcher string
+
+func (m queryContainsMatcher) Match(req *graphql.Request) error {
+	if !strings.Contains(req.Query, string(m)) {
+		return errors.Errorf("query: expected to contain %q", string(m))
+	}
+	return nil
+}
+
+func (m queryContainsMatcher) String() string {
+	return fmt.Sprintf("MatchQueryContains(%q)", string(m))
+}
+
+// MatchFunc matches requests for which fn returns nil. description is
+// used in diagnostics.
+func MatchFunc(description string, fn func(req *graphql.Request) error) Matcher {
+	return funcMatcher{description: description, fn: fn}
+}
+
+type funcMatcher struct {
+	description string
+	fn          func(req *graphql.Request) error
+}
+
+func (m funcMatcher) Match(req *graphql.Request) error {
+	return m.fn(req)
+}
+
+func (m funcMatcher) String() string {
+	return m.description
+}
+
+// Rule is a programmed response that is used for requests matching
+// all of its matchers.
+type Rule struct {
+	matchers []Matcher
+	// mu is the mutex of the FakeClient the rule was added to, which
+	// guards response.
+	mu       *sync.Mutex
+	response fakeResponse
+}
+
+// Respond sets the data returned for requests matching the rule.
+// data is interpreted as for FakeClient.Respond.
+func (r *Rule) Respond(data interface{}) {
+	b, err := toJSON(data)
+	r.setResponse(fakeResponse{data: b, err: err})
+}
+
+// RespondError sets the error returned for requests matching the rule.
+func (r *Rule) RespondError(err error) {
+	r.setResponse(fakeResponse{err: err})
+}
+
+func (r *Rule) setResponse(response fakeResponse) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.response = response
+}
+
+// match returns nil if every matcher matches req, otherwise it describes
+// each mismatch.
+func (r *Rule) match(req *graphql.Request) error {
+	var problems []string
+	for _, m := range r.matchers {
+		if err := m.Match(req); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func (r *Rule) String() string {
+	names := make([]string, len(r.matchers))
+	for i, m := range r.matchers {
+		names[i] = m.String()
+	}
+	return "When(" + strings.Join(names, ", ") + ")"
+}
@@ -0,0 +1,50 @@
+package graphqltest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	"github.com/joefitzgerald/graphql/graphqltest"
+	. "github.com/onsi/gomega"
+)
+
+func TestMatchers(t *testing.T) {
+	RegisterTestingT(t)
+	fake := graphqltest.NewFakeClient()
+	fake.When(
+		graphqltest.MatchOperation("GetUser"),
+		graphqltest.MatchVar("id", 1),
+	).Respond(`{"user":{"name":"first"}}`)
+	fake.When(
+		graphqltest.MatchOperation("GetUser"),
+		graphqltest.MatchVar("id", 2),
+		graphqltest.MatchQueryContains("fragment UserFields"),
+	).Respond(`{"user":{"name":"second"}}`)
+
+	var resp struct {
+		User struct {
+			Name string
+		}
+	}
+	req := graphql.NewRequest(`query GetUser($id: ID!) { user(id: $id) { ...UserFields } } fragment UserFields on User { name }`)
+	req.Var("id", 2)
+	Expect(fake.Run(context.Background(), req, &resp)).ShouldNot(HaveOccurred())
+	Expect(resp.User.Name).Should(Equal("second"))
+
+	req.Var("id", 1)
+	Expect(fake.Run(context.Background(), req, &resp)).ShouldNot(HaveOccurred())
+	Expect(resp.User.Name).Should(Equal("first"))
+
+	req.Var("id", 3)
+	err := fake.Run(context.Background(), req, &resp)
+	Expect(err).Should(HaveOccurred())
+	Expect(err.Error()).Should(ContainSubstring(`variable "id": expected 1, got 3`))
+	Expect(err.Error()).Should(ContainSubstring(`variable "id": expected 2, got 3`))
+
+	fake.When(graphqltest.MatchVar("filter", map[string]interface{}{"role": "ADMIN", "ids": []int{1, 2}})).Respond(`{"users":[]}`)
+	req = graphql.NewRequest(`query Users($filter: Filter) { users(filter: $filter) { name } }`)
+	req.Var("filter", map[string]interface{}{"role": "GUEST", "ids": []int{1, 3}})
+	err = fake.Run(context.Background(), req, &resp)
+	Expect(err.Error()).Should(ContainSubstring(`variable "filter": ids[1]: expected 2, got 3, role: expected "ADMIN", got "GUEST"`))
+}
@@ -0,0 +1,162 @@
+package graphql
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxHealthSamples limits the calls a HealthTracker keeps for each
+// operation.
+const maxHealthSamples = 1024
+
+// OperationHealth summarises the recent calls of an operation.
+type OperationHealth struct {
+	// Operation is the requests' MetricsName.
+	Operation string
+	// Calls is the number of calls in the window, and Failures the
+	// number that failed or returned GraphQL errors.
+	Calls    int
+	Failures int
+	// SuccessRatio is the fraction of calls that succeeded; 1 if there
+	// were no calls.
+	SuccessRatio float64
+	// P50, P90 and P99 are percentiles of the calls' latencies.
+	P50, P90, P99 time.Duration
+}
+
+// HealthTracker keeps the success ratio and latencies of the calls
+// of each operation over a rolling window, so that jobs can stop when
+// the server is degraded rather than carrying on failing. It is safe
+// for concurrent use. Only the most recent calls of each operation are
+// kept.
+//  health := graphql.NewHealthTracker(5 * time.Minute)
+//  client := graphql.NewClient(endpoint, graphql.WithHealthTracker(health))
+//  ...
+//  if h := health.Health("SyncUsers"); h.Calls > 100 && h.SuccessRatio < 0.95 {
+//      return errors.New("backend degraded")
+//  }
+type HealthTracker struct {
+	// Clock is the clock calls are timed with; the system clock if nil.
+	Clock Clock
+
+	window time.Duration
+
+	mu  sync.Mutex
+	ops map[string][]healthSample
+}
+
+type healthSample struct {
+	at      time.Time
+	latency time.Duration
+	failed  bool
+}
+
+// NewHealthTracker makes a HealthTracker of the calls in the last window.
+func NewHealthTracker(window time.Duration) *HealthTracker {
+	return &HealthTracker{
+		window: window,
+		ops:    make(map[string][]healthSample),
+	}
+}
+
+// WithHealthTracker records the outcome and latency of every request in
+// the tracker, including each request in a batch, which has the latency
+// of the whole batch. Requests that aren't sent, such as those denied by
+// a policy, aren't recorded.
+func WithHealthTracker(t *HealthTracker) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.health = t
+	})
+}
+
+// Record records a call of the operation.
+func (t *HealthTracker) Record(operation string, latency time.Duration, failed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	samples := t.trim(operation)
+	if len(samples) == maxHealthSamples {
+		samples = samples[1:]
+	}
+	t.ops[operation] = append(samples, healthSample{at: t.now(), latency: latency, failed: failed})
+}
+
+// Health gets the health of the operation.
+func (t *HealthTracker) Health(operation string) OperationHealth {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return summarise(operation, t.trim(operation))
+}
+
+// Operations gets the health of every operation with calls in the
+// window, sorted by operation.
+func (t *HealthTracker) Operations() []OperationHealth {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var out []OperationHealth
+	for operation := range t.ops {
+		if samples := t.trim(operation); len(samples) > 0 {
+			out = append(out, summarise(operation, samples))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Operation < out[j].Operation
+	})
+	return out
+}
+
+func (t *HealthTracker) now() time.Time {
+	if t.Clock == nil {
+		return time.Now()
+	}
+	return t.Clock.Now()
+}
+
+// trim drops the operation's calls from before the window, returning
+// those left. t.mu must be held.
+func (t *HealthTracker) trim(operation string) []healthSample {
+	samples := t.ops[operation]
+	since := t.now().Add(-t.window)
+	i := sort.Search(len(samples), func(i int) bool {
+		return samples[i].at.After(since)
+	})
+	samples = samples[i:]
+	if len(samples) == 0 {
+		delete(t.ops, operation)
+		return nil
+	}
+	t.ops[operation] = samples
+	return samples
+}
+
+func summarise(operation string, samples []healthSample) OperationHealth {
+	h := OperationHealth{Operation: operation, Calls: len(samples), SuccessRatio: 1}
+	if len(samples) == 0 {
+		return h
+	}
+	latencies := make([]time.Duration, len(samples))
+	for i, s := range samples {
+		latencies[i] = s.latency
+		if s.failed {
+			h.Failures++
+		}
+	}
+	h.SuccessRatio = float64(h.Calls-h.Failures) / float64(h.Calls)
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i] < latencies[j]
+	})
+	percentile := func(p float64) time.Duration {
+		return latencies[int(p*float64(len(latencies)-1)+0.5)]
+	}
+	h.P50, h.P90, h.P99 = percentile(0.5), percentile(0.9), percentile(0.99)
+	return h
+}
+
+// recordHealth records a call in the client's health tracker, if it
+// has one.
+func (c *Client) recordHealth(req *Request, start time.Time, errs Errors, err error) {
+	if c.health == nil {
+		return
+	}
+	c.health.Record(req.MetricsName(), c.since(start), err != nil || len(errs) > 0)
+}
@@ -0,0 +1,93 @@
+package graphql_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestHealthTracker(t *testing.T) {
+	RegisterTestingT(t)
+	health := graphql.NewHealthTracker(time.Minute)
+	Expect(health.Health("A")).Should(Equal(graphql.OperationHealth{Operation: "A", SuccessRatio: 1}))
+	for i := 1; i <= 100; i++ {
+		health.Record("A", time.Duration(i)*time.Millisecond, i%4 == 0)
+	}
+	health.Record("B", time.Second, false)
+
+	h := health.Health("A")
+	Expect(h.Calls).Should(Equal(100))
+	Expect(h.Failures).Should(Equal(25))
+	Expect(h.SuccessRatio).Should(Equal(0.75))
+	Expect(h.P50).Should(Equal(51 * time.Millisecond))
+	Expect(h.P90).Should(Equal(90 * time.Millisecond))
+	Expect(h.P99).Should(Equal(99 * time.Millisecond))
+
+	ops := health.Operations()
+	Expect(ops).Should(HaveLen(2))
+	Expect(ops[0].Operation).Should(Equal("A"))
+	Expect(ops[1]).Should(Equal(graphql.OperationHealth{
+		Operation: "B", Calls: 1, SuccessRatio: 1,
+		P50: time.Second, P90: time.Second, P99: time.Second,
+	}))
+}
+
+func TestWithHealthTracker(t *testing.T) {
+	RegisterTestingT(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("fail") != "" {
+			io.WriteString(w, `{"errors":[{"message":"unavailable"}]}`)
+			return
+		}
+		io.WriteString(w, `{"data":{"ok":true}}`)
+	}))
+	defer srv.Close()
+	health := graphql.NewHealthTracker(time.Minute)
+	client := graphql.NewClient(srv.URL, graphql.WithHealthTracker(health))
+	failing := graphql.NewClient(srv.URL+"?fail=1", graphql.WithHealthTracker(health))
+
+	var resp map[string]interface{}
+	Expect(client.Run(context.Background(), graphql.NewRequest(`query Users { ok }`), &resp)).Should(Succeed())
+	Expect(failing.Run(context.Background(), graphql.NewRequest(`query Users { ok }`), &resp)).ShouldNot(Succeed())
+	closed := graphql.NewClient("http://127.0.0.1:1", graphql.WithHealthTracker(health))
+	Expect(closed.Run(context.Background(), graphql.NewRequest(`query Users { ok }`), &resp)).ShouldNot(Succeed())
+
+	h := health.Health("Users")
+	Expect(h.Calls).Should(Equal(3))
+	Expect(h.Failures).Should(Equal(2))
+	Expect(h.P99).Should(BeNumerically(">", 0))
+}
+
+func TestHealthTrackerShadowAndDryRun(t *testing.T) {
+	RegisterTestingT(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/graphql" {
+			io.WriteString(w, `{"errors":[{"message":"unavailable"}]}`)
+			return
+		}
+		io.WriteString(w, `{"data":{"ok":true}}`)
+	}))
+	defer srv.Close()
+	health := graphql.NewHealthTracker(time.Minute)
+	shadowed := make(chan graphql.ShadowReport, 1)
+	client := graphql.NewClient(srv.URL+"/graphql",
+		graphql.WithHealthTracker(health),
+		graphql.WithShadow(srv.URL+"/shadow", 1),
+		graphql.WithShadowReport(func(r graphql.ShadowReport) { shadowed <- r }),
+		graphql.WithDryRun(srv.URL+"/plan"),
+	)
+	Expect(client.Run(context.Background(), graphql.NewRequest(`query Ok { ok }`), nil)).Should(Succeed())
+	<-shadowed
+	Expect(client.Run(context.Background(), graphql.NewRequest(`mutation Save { save }`), nil)).ShouldNot(Succeed())
+
+	// only the primary's calls are counted
+	Expect(health.Health("Ok").Calls).Should(Equal(1))
+	Expect(health.Health("Ok").Failures).Should(Equal(0))
+	Expect(health.Health("Save").Calls).Should(Equal(0))
+}
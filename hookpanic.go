@@ -0,0 +1,80 @@
+package graphql
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// HookPanicPolicy is what a client does when a function it was given,
+// such as a plugin, report callback or credentials, panics.
+type HookPanicPolicy int
+
+// Hook panic policies.
+const (
+	// HookPanicRecover recovers from the panic. Hooks that can fail
+	// fail the request with a *HookPanicError; report callbacks are
+	// skipped.
+	HookPanicRecover HookPanicPolicy = iota
+	// HookPanicPropagate lets the panic continue.
+	HookPanicPropagate
+)
+
+// HookPanicError is the error from a hook that panicked.
+type HookPanicError struct {
+	// Hook is the kind of hook that panicked, such as "plugin".
+	Hook string
+	// Value is the value passed to panic.
+	Value interface{}
+	// Stack is the stack trace of the panic.
+	Stack []byte
+}
+
+func (e *HookPanicError) Error() string {
+	return fmt.Sprintf("graphql: %s panicked: %v", e.Hook, e.Value)
+}
+
+// WithHookPanicPolicy sets what the client does when a hook panics. The
+// default is HookPanicRecover.
+//  NewClient(endpoint, WithHookPanicPolicy(HookPanicPropagate))
+func WithHookPanicPolicy(policy HookPanicPolicy) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.hookPanics = policy
+	})
+}
+
+// WithHookPanicLogger sets a function called with each hook panic the
+// client recovers from.
+//  NewClient(endpoint, WithHookPanicLogger(func(err *HookPanicError) {
+//      log.Printf("%v\n%s", err, err.Stack)
+//  }))
+func WithHookPanicLogger(log func(err *HookPanicError)) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.hookPanicLog = log
+	})
+}
+
+// callHook calls f, recovering from panics according to the client's
+// policy.
+func (c *Client) callHook(hook string, f func() error) (err error) {
+	defer c.recoverHook(hook, &err)
+	return f()
+}
+
+// recoverHook recovers from a panic in a hook, setting *err to a
+// *HookPanicError if err is not nil. It must be deferred.
+func (c *Client) recoverHook(hook string, err *error) {
+	if c.hookPanics == HookPanicPropagate {
+		return
+	}
+	v := recover()
+	if v == nil {
+		return
+	}
+	panicErr := &HookPanicError{Hook: hook, Value: v, Stack: debug.Stack()}
+	if c.hookPanicLog != nil {
+		c.hookPanicLog(panicErr)
+	}
+	if err != nil {
+		*err = panicErr
+	}
+}
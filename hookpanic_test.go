@@ -0,0 +1,43 @@
+package graphql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+func TestHookPanics(t *testing.T) {
+	RegisterTestingT(t)
+	ctx := context.Background()
+	var logged []*graphql.HookPanicError
+	panicky := graphql.WithPlugin(graphql.PluginFuncs{
+		Request: func(req *graphql.Request) error {
+			panic("oops")
+		},
+	})
+	client := bodyClient(`{"data":{"a":1}}`, panicky, graphql.WithHookPanicLogger(func(err *graphql.HookPanicError) {
+		logged = append(logged, err)
+	}))
+	err := client.Run(ctx, graphql.NewRequest(`{ a }`), nil)
+	Expect(err).Should(MatchError("transforming request: graphql: plugin panicked: oops"))
+	panicErr, ok := errors.Cause(err).(*graphql.HookPanicError)
+	Expect(ok).Should(BeTrue())
+	Expect(panicErr.Value).Should(Equal("oops"))
+	Expect(panicErr.Stack).ShouldNot(BeEmpty())
+	Expect(logged).Should(Equal([]*graphql.HookPanicError{panicErr}))
+
+	client = bodyClient(`{"data":{"a":1}}`, graphql.WithShapeReport(func(graphql.ShapeReport) {
+		panic("report")
+	}))
+	var resp struct{ A int }
+	Expect(client.Run(ctx, graphql.NewRequest(`{ a }`), &resp)).Should(Succeed())
+	Expect(resp.A).Should(Equal(1))
+
+	client = bodyClient(`{"data":{"a":1}}`, panicky, graphql.WithHookPanicPolicy(graphql.HookPanicPropagate))
+	Expect(func() {
+		client.Run(ctx, graphql.NewRequest(`{ a }`), nil)
+	}).Should(PanicWith("oops"))
+}
@@ -0,0 +1,87 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ID is a GraphQL ID. IDs are always strings on the wire, but servers
+// may send numeric IDs as JSON numbers, so ID decodes either.
+//  var resp struct {
+//      User struct {
+//          ID graphql.ID
+//      }
+//  }
+type ID string
+
+// IntID makes an ID from an integer.
+func IntID(id int64) ID {
+	return ID(strconv.FormatInt(id, 10))
+}
+
+// String gets the ID as a string.
+func (id ID) String() string {
+	return string(id)
+}
+
+// Int64 gets the ID as an integer, for services that use numeric IDs.
+func (id ID) Int64() (int64, error) {
+	n, err := strconv.ParseInt(string(id), 10, 64)
+	if err != nil {
+		return 0, errors.Errorf("graphql: ID %q is not an integer", string(id))
+	}
+	return n, nil
+}
+
+// MarshalJSON encodes the ID as a JSON string.
+func (id ID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(id))
+}
+
+// UnmarshalJSON decodes a JSON string or number.
+func (id *ID) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		*id = ID(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return errors.Errorf("graphql: cannot decode %s into ID", b)
+	}
+	*id = ID(n)
+	return nil
+}
+
+// GlobalID makes a Relay global object ID: the base64 encoding of the
+// type name and the ID within the type, separated by a colon.
+//  graphql.GlobalID("User", "42") // "VXNlcjo0Mg=="
+func GlobalID(typeName string, id string) ID {
+	return ID(base64.StdEncoding.EncodeToString([]byte(typeName + ":" + id)))
+}
+
+// ParseGlobalID splits a Relay global object ID made by GlobalID into
+// its type name and the ID within the type.
+func ParseGlobalID(id ID) (typeName string, localID string, err error) {
+	b, err := base64.StdEncoding.DecodeString(string(id))
+	if err != nil {
+		b, err = base64.RawURLEncoding.DecodeString(strings.TrimRight(string(id), "="))
+	}
+	if err != nil {
+		return "", "", errors.Errorf("graphql: global ID %q is not base64", string(id))
+	}
+	i := bytes.IndexByte(b, ':')
+	if i <= 0 {
+		return "", "", errors.Errorf("graphql: global ID %q has no type name", string(id))
+	}
+	return string(b[:i]), string(b[i+1:]), nil
+}
@@ -0,0 +1,49 @@
+package graphql_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestID(t *testing.T) {
+	RegisterTestingT(t)
+	var v struct {
+		A, B graphql.ID
+	}
+	err := json.Unmarshal([]byte(`{"A":"abc","B":12345678901234567890}`), &v)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(v.A).Should(Equal(graphql.ID("abc")))
+	Expect(v.B).Should(Equal(graphql.ID("12345678901234567890")))
+
+	b, err := json.Marshal(map[string]interface{}{"id": graphql.IntID(42)})
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(string(b)).Should(Equal(`{"id":"42"}`))
+
+	n, err := graphql.ID("42").Int64()
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(n).Should(BeEquivalentTo(42))
+	_, err = graphql.ID("abc").Int64()
+	Expect(err).Should(HaveOccurred())
+
+	err = json.Unmarshal([]byte(`{"A":true}`), &v)
+	Expect(err).Should(HaveOccurred())
+}
+
+func TestGlobalID(t *testing.T) {
+	RegisterTestingT(t)
+	id := graphql.GlobalID("User", "42")
+	Expect(id).Should(Equal(graphql.ID("VXNlcjo0Mg==")))
+
+	typeName, localID, err := graphql.ParseGlobalID(id)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(typeName).Should(Equal("User"))
+	Expect(localID).Should(Equal("42"))
+
+	_, _, err = graphql.ParseGlobalID("!!!")
+	Expect(err).Should(HaveOccurred())
+	_, _, err = graphql.ParseGlobalID(graphql.ID("NDI=")) // "42"
+	Expect(err).Should(MatchError(`graphql: global ID "NDI=" has no type name`))
+}
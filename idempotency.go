@@ -0,0 +1,111 @@
+package graphql
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultIdempotencyHeader is the header that carries idempotency keys,
+// unless changed with WithIdempotencyHeader.
+const DefaultIdempotencyHeader = "Idempotency-Key"
+
+// SetIdempotencyKey sets a key that lets servers which support it
+// recognise repeated sends of the same mutation, so that a retried
+// request is only applied once. Use NewIdempotencyKey to generate one.
+// A batch is sent with the key of its one request with a key, or one
+// derived from the keys of its requests if there are several.
+func (req *Request) SetIdempotencyKey(key string) {
+	req.idempotencyKey = key
+}
+
+// IdempotencyKey gets the request's idempotency key, if it has one.
+func (req *Request) IdempotencyKey() string {
+	return req.idempotencyKey
+}
+
+// NewIdempotencyKey generates a random (version 4) UUID for use as an
+// idempotency key.
+func NewIdempotencyKey() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", errors.Wrap(err, "generating idempotency key")
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// WithIdempotencyHeader sets the header that carries idempotency keys.
+//  NewClient(endpoint, WithIdempotencyHeader("X-Idempotency-Key"))
+func WithIdempotencyHeader(name string) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.idempotencyHeader = name
+	})
+}
+
+// WithAutoIdempotencyKeys makes the client send every mutation that
+// doesn't have an idempotency key with one generated with
+// NewIdempotencyKey. The key is set on the Request the first time it is
+// sent, so running the same Request again, as a retry, sends the same
+// key; make a new Request for a new mutation.
+func WithAutoIdempotencyKeys() ClientOption {
+	return ClientOption(func(client *Client) {
+		client.autoIdempotencyKeys = true
+	})
+}
+
+// withIdempotencyKey gets the prepared request with the key it is sent
+// with: its own, or, if it is a mutation and automatic keys are on, that
+// of the caller's original request, which is generated the first time
+// it is sent. req is copied before the key is set.
+func (c *Client) withIdempotencyKey(original, req *Request) (*Request, error) {
+	if !c.autoIdempotencyKeys || req.idempotencyKey != "" {
+		return req, nil
+	}
+	op, err := req.Operation()
+	if err != nil || op.Type != "mutation" {
+		return req, nil
+	}
+	if original.idempotencyKey == "" {
+		if original.idempotencyKey, err = NewIdempotencyKey(); err != nil {
+			return nil, err
+		}
+	}
+	req = req.clone()
+	req.idempotencyKey = original.idempotencyKey
+	return req, nil
+}
+
+// derivedIdempotencyKey derives a key from parts, formatted as a custom
+// (version 8) UUID.
+func derivedIdempotencyKey(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		fmt.Fprintf(h, "%d:%s", len(part), part)
+	}
+	b := h.Sum(nil)[:16]
+	b[6] = (b[6] & 0x0f) | 0x80
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// batchIdempotencyKey gets the key a batch is sent with: the key of its
+// one request with a key, or one derived from the keys of its requests.
+func batchIdempotencyKey(reqs []*Request) string {
+	var keys []string
+	for _, req := range reqs {
+		if req.idempotencyKey != "" {
+			keys = append(keys, req.idempotencyKey)
+		}
+	}
+	switch len(keys) {
+	case 0:
+		return ""
+	case 1:
+		return keys[0]
+	}
+	return derivedIdempotencyKey(keys...)
+}
@@ -0,0 +1,95 @@
+package graphql_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestIdempotencyKey(t *testing.T) {
+	RegisterTestingT(t)
+	var keys []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("X-Idempotency-Key"))
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	client := graphql.NewClient(srv.URL, graphql.WithIdempotencyHeader("X-Idempotency-Key"))
+	req := graphql.NewRequest(`mutation { createUser }`)
+	req.SetIdempotencyKey("abc")
+	Expect(client.Run(ctx, req, nil)).ShouldNot(HaveOccurred())
+	Expect(keys).Should(Equal([]string{"abc"}))
+
+	keys = nil
+	client = graphql.NewClient(srv.URL, graphql.WithIdempotencyHeader("X-Idempotency-Key"), graphql.WithAutoIdempotencyKeys())
+	req = graphql.NewRequest(`mutation { createUser }`)
+	Expect(client.Run(ctx, req, nil)).ShouldNot(HaveOccurred())
+	Expect(client.Run(ctx, req, nil)).ShouldNot(HaveOccurred())
+	Expect(client.Run(ctx, graphql.NewRequest(`query { user }`), nil)).ShouldNot(HaveOccurred())
+	Expect(keys).Should(HaveLen(3))
+	Expect(keys[0]).Should(MatchRegexp(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`))
+	// sending the same request again is a retry, with the same key
+	Expect(keys[1]).Should(Equal(keys[0]))
+	Expect(keys[2]).Should(BeEmpty())
+	Expect(req.IdempotencyKey()).Should(Equal(keys[0]))
+}
+
+func TestIdempotencyKeyRepeatedMutation(t *testing.T) {
+	RegisterTestingT(t)
+	var keys []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	// sending the same mutation twice is two mutations, not a retry
+	client := graphql.NewClient(srv.URL, graphql.WithAutoIdempotencyKeys())
+	for i := 0; i < 2; i++ {
+		req := graphql.NewRequest(`mutation ($name: String!) { createUser(name: $name) }`)
+		req.Var("name", "Mat")
+		Expect(client.Run(ctx, req, nil)).ShouldNot(HaveOccurred())
+	}
+	Expect(keys).Should(HaveLen(2))
+	Expect(keys[0]).ShouldNot(BeEmpty())
+	Expect(keys[1]).ShouldNot(BeEmpty())
+	Expect(keys[1]).ShouldNot(Equal(keys[0]))
+}
+
+func TestIdempotencyKeyBatch(t *testing.T) {
+	RegisterTestingT(t)
+	var keys []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		io.WriteString(w, `[{"data":{}},{"data":{}}]`)
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	client := graphql.NewClient(srv.URL)
+
+	create := graphql.NewRequest(`mutation { createUser }`)
+	create.SetIdempotencyKey("abc")
+	_, err := client.RunBatch(ctx, []*graphql.Request{create, graphql.NewRequest(`{ user }`)}, nil)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(keys).Should(Equal([]string{"abc"}))
+
+	keys = nil
+	client = graphql.NewClient(srv.URL, graphql.WithAutoIdempotencyKeys())
+	batch := []*graphql.Request{create, graphql.NewRequest(`mutation { deleteUser }`)}
+	for i := 0; i < 2; i++ {
+		_, err = client.RunBatch(ctx, batch, nil)
+		Expect(err).ShouldNot(HaveOccurred())
+	}
+	Expect(keys).Should(HaveLen(2))
+	Expect(keys[0]).ShouldNot(BeEmpty())
+	Expect(keys[0]).ShouldNot(Equal("abc"))
+	Expect(keys[1]).Should(Equal(keys[0]))
+}
@@ -0,0 +1,158 @@
+package graphql
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Input is a value of a GraphQL input object type, built field by field.
+// It can be used as a variable, or as a field of another Input.
+//  input := graphql.NewInput("CreateUserInput").
+//      Set("name", name).
+//      SetNull("nickname")
+//  req.Var("input", input)
+// If the client has a schema (see WithSchema), Inputs in the variables
+// are checked against it before the request is sent.
+type Input struct {
+	typeName string
+	fields   map[string]interface{}
+}
+
+// NewInput makes an empty Input of the named input object type.
+func NewInput(typeName string) *Input {
+	return &Input{
+		typeName: typeName,
+		fields:   make(map[string]interface{}),
+	}
+}
+
+// Set sets a field of the input.
+func (in *Input) Set(name string, value interface{}) *Input {
+	in.fields[name] = value
+	return in
+}
+
+// SetNull sets a field of the input to null. Unlike a field that isn't
+// set, which the server treats as absent, a null field is sent.
+func (in *Input) SetNull(name string) *Input {
+	return in.Set(name, nil)
+}
+
+// TypeName gets the name of the input object type.
+func (in *Input) TypeName() string {
+	return in.typeName
+}
+
+// MarshalJSON encodes the fields of the input as a JSON object.
+func (in *Input) MarshalJSON() ([]byte, error) {
+	return json.Marshal(in.fields)
+}
+
+// Validate checks that the input's type is an input object type in the
+// schema, that its fields are defined by the type, and that its required
+// fields are set. Inputs set as fields are checked too.
+func (in *Input) Validate(schema *Schema) error {
+	var problems []string
+	in.validate(schema, in.typeName, &problems)
+	if len(problems) > 0 {
+		return errors.Errorf("graphql: invalid input: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func (in *Input) validate(schema *Schema, path string, problems *[]string) {
+	t := schema.Type(in.typeName)
+	if t == nil || t.Kind != KindInputObject {
+		*problems = append(*problems, path+": unknown input type "+in.typeName)
+		return
+	}
+	names := make([]string, 0, len(in.fields))
+	for name := range in.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f := t.InputField(name)
+		if f == nil {
+			*problems = append(*problems, path+": unknown field "+name)
+			continue
+		}
+		value := in.fields[name]
+		if value == nil && f.Type.Kind == KindNonNull {
+			*problems = append(*problems, path+"."+name+": "+f.Type.String()+" cannot be null")
+		}
+		validateInputs(schema, value, path+"."+name, f.Type.NamedType(), problems)
+	}
+	for _, f := range t.InputFields {
+		if _, ok := in.fields[f.Name]; !ok && f.Type.Kind == KindNonNull && f.DefaultValue == nil {
+			*problems = append(*problems, path+"."+f.Name+": required field is not set")
+		}
+	}
+}
+
+// validateInputs checks the Inputs in v, which is expected to be of the
+// named type, or lists of it. If typeName is empty, the type isn't known.
+func validateInputs(schema *Schema, v interface{}, path, typeName string, problems *[]string) {
+	switch v := v.(type) {
+	case *Input:
+		if typeName != "" && v.typeName != typeName {
+			*problems = append(*problems, path+": expected "+typeName+", got "+v.typeName)
+			return
+		}
+		v.validate(schema, path, problems)
+	case []*Input:
+		for _, item := range v {
+			validateInputs(schema, item, path+"[]", typeName, problems)
+		}
+	case []interface{}:
+		for _, item := range v {
+			validateInputs(schema, item, path+"[]", typeName, problems)
+		}
+	}
+}
+
+// WithSchema gives the client the schema of the service, so requests
+// can be checked against it before they are sent.
+//  schema, err := client.Introspect(ctx)
+//  NewClient(endpoint, WithSchema(schema))
+func WithSchema(schema *Schema) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.schema = schema
+	})
+}
+
+// validateVariables checks the Inputs in the request variables against
+// the client's schema, and the types the operation declares for them.
+func (c *Client) validateVariables(req *Request) error {
+	schema := c.currentSchema()
+	if schema == nil {
+		return nil
+	}
+	types := make(map[string]string)
+	if doc, err := parseDocument(req.Query); err == nil {
+		for _, op := range doc.operations {
+			if req.OperationName != "" && op.name != req.OperationName {
+				continue
+			}
+			for _, vd := range op.varDefs {
+				types[vd.name] = namedType(vd.typ)
+			}
+		}
+	}
+	names := make([]string, 0, len(req.Variables))
+	for name := range req.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var problems []string
+	for _, name := range names {
+		validateInputs(schema, req.Variables[name], "$"+name, types[name], &problems)
+	}
+	if len(problems) > 0 {
+		return errors.Errorf("graphql: invalid input: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
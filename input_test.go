@@ -0,0 +1,45 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestInput(t *testing.T) {
+	RegisterTestingT(t)
+	schema := loadSchema()
+	input := graphql.NewInput("CreateUserInput").
+		Set("name", "Mat").
+		SetNull("nickname")
+	Expect(input.TypeName()).Should(Equal("CreateUserInput"))
+	Expect(input.Validate(schema)).Should(Succeed())
+	b, err := json.Marshal(input)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(string(b)).Should(Equal(`{"name":"Mat","nickname":null}`))
+
+	err = graphql.NewInput("CreateUserInput").Set("nick", "m").Validate(schema)
+	Expect(err).Should(MatchError("graphql: invalid input: CreateUserInput: unknown field nick; CreateUserInput.name: required field is not set"))
+
+	err = graphql.NewInput("CreateUserInput").SetNull("name").Validate(schema)
+	Expect(err).Should(MatchError("graphql: invalid input: CreateUserInput.name: String! cannot be null"))
+
+	err = graphql.NewInput("User").Validate(schema)
+	Expect(err).Should(MatchError("graphql: invalid input: User: unknown input type User"))
+}
+
+func TestWithSchema(t *testing.T) {
+	RegisterTestingT(t)
+	client := bodyClient(`{"data":{}}`, graphql.WithSchema(loadSchema()))
+	req := graphql.NewRequest(`mutation ($input: CreateUserInput!) { createUser(input: $input) { id } }`)
+	req.Var("input", graphql.NewInput("UserFilter").Set("name", "Mat"))
+	err := client.Run(context.Background(), req, nil)
+	Expect(err).Should(MatchError("graphql: invalid input: $input: expected CreateUserInput, got UserFilter"))
+
+	req.Var("input", graphql.NewInput("CreateUserInput").Set("name", "Mat"))
+	err = client.Run(context.Background(), req, nil)
+	Expect(err).ShouldNot(HaveOccurred())
+}
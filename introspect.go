@@ -0,0 +1,130 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+)
+
+// IntrospectionQuery is the query used by Introspect. Its result can be
+// read with ParseSchema.
+const IntrospectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types { ...FullType }
+  }
+}
+
+fragment FullType on __Type {
+  kind
+  name
+  description
+  fields(includeDeprecated: true) {
+    name
+    description
+    args { ...InputValue }
+    type { ...TypeRef }
+    isDeprecated
+    deprecationReason
+  }
+  inputFields { ...InputValue }
+  interfaces { ...TypeRef }
+  enumValues(includeDeprecated: true) {
+    name
+    description
+    isDeprecated
+    deprecationReason
+  }
+  possibleTypes { ...TypeRef }
+}
+
+fragment InputValue on __InputValue {
+  name
+  description
+  type { ...TypeRef }
+  defaultValue
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType {
+    kind
+    name
+    ofType {
+      kind
+      name
+      ofType {
+        kind
+        name
+        ofType {
+          kind
+          name
+          ofType {
+            kind
+            name
+            ofType {
+              kind
+              name
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+// Introspect gets the schema of the service by running IntrospectionQuery.
+func (c *Client) Introspect(ctx context.Context) (*Schema, error) {
+	var resp struct {
+		Schema *Schema `json:"__schema"`
+	}
+	if err := c.Run(ctx, NewRequest(IntrospectionQuery), &resp); err != nil {
+		return nil, errors.Wrap(err, "introspecting schema")
+	}
+	if resp.Schema == nil {
+		return nil, errors.New("graphql: no __schema in introspection result")
+	}
+	return resp.Schema, nil
+}
+
+// WriteSchema writes the schema as an indented introspection result,
+// the form ParseSchema reads, for vendoring as a snapshot file.
+func WriteSchema(w io.Writer, schema *Schema) error {
+	b, err := json.MarshalIndent(map[string]interface{}{
+		"data": map[string]interface{}{"__schema": schema},
+	}, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "graphql: encoding schema")
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// CheckSchemaFile compares the schema snapshot in the file at path with
+// the live schema of the service, and gets the changes the service has
+// made since the snapshot was taken.
+//  changes, err := graphql.CheckSchemaFile(ctx, client, "schema.json")
+//  if graphql.HasBreakingChanges(changes) {
+//      // the snapshot is stale; update it and check your operations
+//  }
+func CheckSchemaFile(ctx context.Context, client *Client, path string) ([]SchemaChange, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "graphql: reading schema")
+	}
+	vendored, err := ParseSchema(b)
+	if err != nil {
+		return nil, err
+	}
+	live, err := client.Introspect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return DiffSchemas(vendored, live), nil
+}
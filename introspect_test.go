@@ -0,0 +1,46 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestIntrospect(t *testing.T) {
+	RegisterTestingT(t)
+	vendored, err := ioutil.ReadFile("testdata/schema.json")
+	Expect(err).ShouldNot(HaveOccurred())
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphql.Request
+		json.NewDecoder(r.Body).Decode(&req)
+		Expect(req.Query).Should(Equal(graphql.IntrospectionQuery))
+		w.Write(vendored)
+	}))
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL)
+
+	schema, err := client.Introspect(context.Background())
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(schema.Type("User").Field("friends").Type.String()).Should(Equal("[User]"))
+
+	dir, err := ioutil.TempDir("", "graphql")
+	Expect(err).ShouldNot(HaveOccurred())
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "schema.json")
+	f, err := os.Create(file)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(graphql.WriteSchema(f, schema)).Should(Succeed())
+	f.Close()
+
+	changes, err := graphql.CheckSchemaFile(context.Background(), client, file)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(changes).Should(BeEmpty())
+}
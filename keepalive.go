@@ -0,0 +1,130 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ReadTimeoutError is returned when the server sends nothing for
+// longer than the read timeout set by WithReadTimeout.
+type ReadTimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e *ReadTimeoutError) Error() string {
+	return fmt.Sprintf("graphql: no response from server for %s", e.Timeout)
+}
+
+// WithKeepAlive turns on TCP keepalive probes every period, so that
+// half-open connections, such as those dropped by a NAT gateway, are
+// detected quickly. It applies to the default transport, or to an
+// *http.Transport given with WithHTTPClient, which is copied rather than
+// changed. Other round trippers are left alone.
+//  NewClient(endpoint, WithKeepAlive(5*time.Second))
+func WithKeepAlive(period time.Duration) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.keepAlive = period
+	})
+}
+
+// WithReadTimeout limits how long the client waits for the server to
+// send anything, from when the request is sent until the response has
+// been read. Unlike a request timeout it doesn't limit slow but steady
+// responses. Requests that time out fail with a ReadTimeoutError.
+//  NewClient(endpoint, WithReadTimeout(10*time.Second))
+func WithReadTimeout(timeout time.Duration) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.readTimeout = timeout
+	})
+}
+
+// keepAliveClient returns a copy of httpClient whose transport dials
+// connections with TCP keepalive probes every period.
+func keepAliveClient(httpClient *http.Client, period time.Duration) *http.Client {
+	transport := httpClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	t, ok := transport.(*http.Transport)
+	if !ok {
+		return httpClient
+	}
+	t = t.Clone()
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: period,
+	}
+	t.DialContext = dialer.DialContext
+	copied := *httpClient
+	copied.Transport = t
+	return &copied
+}
+
+// readTimer cancels a request when nothing has been read for longer
+// than its timeout.
+type readTimer struct {
+	timeout time.Duration
+	timer   *time.Timer
+
+	mu      sync.Mutex
+	expired bool
+}
+
+// startReadTimer starts a readTimer for a request made with the
+// returned context. It returns a nil readTimer if there's no read timeout.
+func (c *Client) startReadTimer(ctx context.Context) (context.Context, *readTimer, context.CancelFunc) {
+	if c.readTimeout <= 0 {
+		return ctx, nil, func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	t := &readTimer{timeout: c.readTimeout}
+	t.timer = time.AfterFunc(c.readTimeout, func() {
+		t.mu.Lock()
+		t.expired = true
+		t.mu.Unlock()
+		cancel()
+	})
+	return ctx, t, func() {
+		t.timer.Stop()
+		cancel()
+	}
+}
+
+// body returns r, resetting the timer whenever something is read.
+func (t *readTimer) body(r io.ReadCloser) io.ReadCloser {
+	if t == nil {
+		return r
+	}
+	return &timedReader{ReadCloser: r, t: t}
+}
+
+// check returns a ReadTimeoutError in place of err if the timer expired.
+func (t *readTimer) check(err error) error {
+	if t == nil || err == nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.expired {
+		return &ReadTimeoutError{Timeout: t.timeout}
+	}
+	return err
+}
+
+type timedReader struct {
+	io.ReadCloser
+	t *readTimer
+}
+
+func (r *timedReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.t.timer.Reset(r.t.timeout)
+	}
+	return n, err
+}
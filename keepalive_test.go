@@ -0,0 +1,44 @@
+package graphql_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestReadTimeout(t *testing.T) {
+	RegisterTestingT(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/steady":
+			for _, part := range []string{`{"data":`, `{"value":`, `1}}`} {
+				io.WriteString(w, part)
+				w.(http.Flusher).Flush()
+				time.Sleep(30 * time.Millisecond)
+			}
+		case "/stalled":
+			io.WriteString(w, `{"data":`)
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	var resp map[string]interface{}
+	client := graphql.NewClient(srv.URL+"/steady", graphql.WithReadTimeout(50*time.Millisecond), graphql.WithKeepAlive(time.Second))
+	Expect(client.Run(ctx, graphql.NewRequest(`{ value }`), &resp)).ShouldNot(HaveOccurred())
+	Expect(resp["value"]).Should(BeEquivalentTo(1))
+
+	client = graphql.NewClient(srv.URL+"/stalled", graphql.WithReadTimeout(50*time.Millisecond))
+	err := client.Run(ctx, graphql.NewRequest(`{ value }`), &resp)
+	Expect(err).Should(Equal(&graphql.ReadTimeoutError{Timeout: 50 * time.Millisecond}))
+	Expect(err).Should(MatchError("graphql: no response from server for 50ms"))
+}
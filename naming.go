@@ -0,0 +1,117 @@
+package graphql
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// OperationNaming is how a client treats anonymous operations, such as
+// query { ... }, which servers can't tell apart in metrics or safelists.
+type OperationNaming int
+
+// Operation naming policies.
+const (
+	// NamingOptional sends anonymous operations as they are.
+	NamingOptional OperationNaming = iota
+	// NamingRequired rejects anonymous operations with an
+	// *AnonymousOperationError, without sending them.
+	NamingRequired
+	// NamingAuto names anonymous operations after the label set with
+	// Request.SetMetricsName, or else after the function that ran them,
+	// for development. The allow-list checks them as the caller wrote
+	// them, without the name.
+	NamingAuto
+)
+
+// AnonymousOperationError is returned for anonymous operations when the
+// client requires operations to be named.
+type AnonymousOperationError struct {
+	// Type is the type of the operation, such as "query".
+	Type string
+}
+
+func (e *AnonymousOperationError) Error() string {
+	return fmt.Sprintf("graphql: anonymous %s: operations must be named", e.Type)
+}
+
+// WithOperationNaming sets how the client treats anonymous operations.
+// The default is NamingOptional.
+//  NewClient(endpoint, WithOperationNaming(NamingRequired))
+func WithOperationNaming(naming OperationNaming) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.naming = naming
+	})
+}
+
+// nameOperation applies the client's operation naming policy to the
+// request, returning a named copy of it if it was auto-named.
+func (c *Client) nameOperation(req *Request) (*Request, error) {
+	if c.naming == NamingOptional || req.OperationName != "" {
+		return req, nil
+	}
+	doc, err := parseDocument(req.Query)
+	if err != nil || len(doc.operations) != 1 || doc.operations[0].name != "" {
+		// leave the server to report documents that are not valid
+		return req, nil
+	}
+	op := doc.operations[0]
+	label := req.metricsName
+	if c.naming == NamingAuto && label == "" {
+		label = callerName()
+	}
+	if c.naming == NamingRequired || label == "" {
+		return nil, &AnonymousOperationError{Type: op.opType}
+	}
+	name := operationName(label)
+	r := req.clone()
+	if op.nameEnd < 0 {
+		r.Query = req.Query[:op.start] + "query " + name + " " + req.Query[op.start:]
+	} else {
+		r.Query = req.Query[:op.nameEnd] + " " + name + req.Query[op.nameEnd:]
+	}
+	r.OperationName = name
+	r.namedQuery, r.unnamedQuery = r.Query, req.Query
+	return r, nil
+}
+
+// operationName makes a valid GraphQL name from the label, replacing
+// other characters with underscores.
+func operationName(label string) string {
+	name := strings.Map(func(r rune) rune {
+		if r < 128 && isNameContinue(byte(r)) {
+			return r
+		}
+		return '_'
+	}, label)
+	if !isNameStart(name[0]) {
+		name = "_" + name
+	}
+	return name
+}
+
+// packagePath is the import path of this package, as it appears in
+// function names.
+var packagePath = reflect.TypeOf(Client{}).PkgPath()
+
+// callerName gets the name of the first function outside this package
+// and the runtime on the call stack, such as Server.loadUsers for a
+// method, or "" if there is none.
+func callerName() string {
+	pc := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pc[:runtime.Callers(2, pc)])
+	for {
+		frame, more := frames.Next()
+		fn := frame.Function
+		if fn != "" && !strings.HasPrefix(fn, packagePath+".") && !strings.HasPrefix(fn, "runtime.") {
+			// github.com/org/pkg.(*Server).loadUsers.func1
+			name := fn[strings.LastIndex(fn, "/")+1:]
+			name = name[strings.Index(name, ".")+1:]
+			return strings.NewReplacer("(*", "", ")", "").Replace(name)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestOperationNaming(t *testing.T) {
+	RegisterTestingT(t)
+	var sent graphql.Request
+	httpClient := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			b, _ := ioutil.ReadAll(req.Body)
+			json.Unmarshal(b, &sent)
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(strings.NewReader(`{"data":{}}`)),
+			}, nil
+		}),
+	}
+	ctx := context.Background()
+
+	client := graphql.NewClient("", graphql.WithHTTPClient(httpClient),
+		graphql.WithOperationNaming(graphql.NamingRequired))
+	err := client.Run(ctx, graphql.NewRequest(`{ a }`), nil)
+	Expect(err).Should(Equal(&graphql.AnonymousOperationError{Type: "query"}))
+	err = client.Run(ctx, graphql.NewRequest(`mutation ($a: Int) { b(a: $a) }`), nil)
+	Expect(err).Should(MatchError("graphql: anonymous mutation: operations must be named"))
+	Expect(client.Run(ctx, graphql.NewRequest(`query A { a }`), nil)).Should(Succeed())
+
+	client = graphql.NewClient("", graphql.WithHTTPClient(httpClient),
+		graphql.WithOperationNaming(graphql.NamingAuto))
+	Expect(client.Run(ctx, graphql.NewRequest(`{ a }`), nil)).Should(Succeed())
+	Expect(sent.Query).Should(Equal(`query TestOperationNaming { a }`))
+	Expect(sent.OperationName).Should(Equal("TestOperationNaming"))
+
+	req := graphql.NewRequest(`{ a }`)
+	req.SetMetricsName("sync.users-page")
+	Expect(client.Run(ctx, req, nil)).Should(Succeed())
+	Expect(sent.Query).Should(Equal(`query sync_users_page { a }`))
+	Expect(sent.OperationName).Should(Equal("sync_users_page"))
+	Expect(req.Query).Should(Equal(`{ a }`))
+
+	req = graphql.NewRequest(`mutation ($a: Int) { b(a: $a) }`)
+	req.SetMetricsName("1up")
+	Expect(client.Run(ctx, req, nil)).Should(Succeed())
+	Expect(sent.Query).Should(Equal(`mutation _1up ($a: Int) { b(a: $a) }`))
+}
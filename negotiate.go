@@ -0,0 +1,75 @@
+package graphql
+
+import (
+	"mime"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// ResponseHandler converts a response body of a particular media type
+// into a GraphQL response in JSON.
+type ResponseHandler func(body []byte) ([]byte, error)
+
+// WithContentType sets the Content-Type header of requests.
+// The body is always JSON; this is for servers that expect a
+// different label for it.
+//  NewClient(endpoint, WithContentType("application/vnd.example+json"))
+func WithContentType(contentType string) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.contentType = contentType
+	})
+}
+
+// WithAccept sets the Accept header of requests, overriding the
+// default for the client's mode.
+func WithAccept(accept string) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.accept = accept
+	})
+}
+
+// WithResponseHandler registers a handler for responses of the given
+// media type. The handled response is treated as application/json.
+// To accept a legacy media type unchanged, pass a nil handler:
+//  NewClient(endpoint, WithResponseHandler("text/json", nil))
+func WithResponseHandler(mediaType string, handler ResponseHandler) ClientOption {
+	return ClientOption(func(client *Client) {
+		if client.responseHandlers == nil {
+			client.responseHandlers = make(map[string]ResponseHandler)
+		}
+		client.responseHandlers[mediaType] = handler
+	})
+}
+
+// acceptHeader gets the Accept header to send.
+func (c *Client) acceptHeader() string {
+	switch {
+	case c.accept != "":
+		return c.accept
+	case c.specCompliance:
+		return specAccept
+	}
+	return MediaTypeJSON
+}
+
+// handleMediaType runs the handler registered for the media type of res,
+// if there is one, returning the effective media type and body.
+func (c *Client) handleMediaType(res *http.Response, body []byte) (string, []byte, error) {
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	handler, ok := c.responseHandlers[mediaType]
+	if !ok {
+		return mediaType, body, nil
+	}
+	if handler != nil {
+		err := c.callHook("response handler", func() error {
+			var err error
+			body, err = handler(body)
+			return err
+		})
+		if err != nil {
+			return "", nil, errors.Wrapf(err, "handling %s response", mediaType)
+		}
+	}
+	return MediaTypeJSON, body, nil
+}
@@ -0,0 +1,48 @@
+package graphql_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestContentNegotiation(t *testing.T) {
+	RegisterTestingT(t)
+	var contentType, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Expect(r.Header.Get("Content-Type")).Should(Equal("application/vnd.example.graphql+json"))
+		Expect(r.Header.Get("Accept")).Should(Equal("text/json, application/vnd.example+json"))
+		w.Header().Set("Content-Type", contentType)
+		io.WriteString(w, body)
+	}))
+	defer srv.Close()
+
+	client := graphql.NewClient(srv.URL,
+		graphql.WithSpecCompliance(),
+		graphql.WithContentType("application/vnd.example.graphql+json"),
+		graphql.WithAccept("text/json, application/vnd.example+json"),
+		graphql.WithResponseHandler("text/json", nil),
+		graphql.WithResponseHandler("application/vnd.example+json", func(b []byte) ([]byte, error) {
+			return bytes.TrimPrefix(b, []byte("vendor:")), nil
+		}),
+	)
+	var resp struct {
+		Value string
+	}
+
+	contentType, body = "text/json; charset=utf-8", `{"data":{"value":"legacy"}}`
+	err := client.Run(context.Background(), graphql.NewRequest(`{ value }`), &resp)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(resp.Value).Should(Equal("legacy"))
+
+	contentType, body = "application/vnd.example+json", `vendor:{"data":{"value":"vendor"}}`
+	err = client.Run(context.Background(), graphql.NewRequest(`{ value }`), &resp)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(resp.Value).Should(Equal("vendor"))
+}
@@ -0,0 +1,113 @@
+package graphql
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// optionalErrors splits errs into those that fail a call decoding into
+// resp, and warnings for those at optional fields of resp.
+func (c *Client) optionalErrors(errs Errors, resp interface{}) (Errors, []Warning) {
+	if len(errs) == 0 || resp == nil || !typeHasTags(reflect.TypeOf(resp)) {
+		return errs, nil
+	}
+	var failing Errors
+	var warnings []Warning
+	for _, err := range errs {
+		if !optionalPath(reflect.TypeOf(resp), err.Path, c.walker.matching) {
+			failing = append(failing, err)
+			continue
+		}
+		warnings = append(warnings, Warning{Kind: WarningOptionalField, Message: err.Message, Path: err.Path})
+	}
+	return failing, warnings
+}
+
+// optionalPath reports whether the response path goes through an
+// optional field of t, matching keys to fields with matching.
+func optionalPath(t reflect.Type, path []interface{}, matching FieldMatching) bool {
+	for _, segment := range path {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		key, isKey := segment.(string)
+		switch t.Kind() {
+		case reflect.Struct:
+			if !isKey {
+				return false
+			}
+			f := matching.field(cachedFields(t), key)
+			if f == nil {
+				return false
+			}
+			if _, ok := f.tag["optional"]; ok {
+				return true
+			}
+			t = f.typ
+		case reflect.Slice, reflect.Array:
+			// a flattened connection's edges, nodes and node keys
+			// stand for the slice itself
+			if !isKey {
+				t = t.Elem()
+			} else if key != "edges" && key != "nodes" && key != "node" {
+				return false
+			}
+		case reflect.Map:
+			t = t.Elem()
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// optional checks that the value rewritten for an optional field can be
+// decoded into it, and if it can't, or err is set, records a warning and
+// gets null for the field instead.
+func (w *walker) optional(v interface{}, t reflect.Type, path string, err error) interface{} {
+	if err == nil {
+		var b []byte
+		if b, err = json.Marshal(v); err == nil {
+			err = json.Unmarshal(b, reflect.New(t).Interface())
+		}
+	}
+	if err == nil {
+		return v
+	}
+	w.warnings = append(w.warnings, Warning{
+		Kind:    WarningOptionalField,
+		Message: err.Error(),
+		Path:    splitPath(path),
+	})
+	return nil
+}
+
+// splitPath splits a walker path, such as .users[2].name, into the
+// segments of a response path.
+func splitPath(path string) []interface{} {
+	var segments []interface{}
+	for _, part := range strings.Split(strings.TrimPrefix(path, "."), ".") {
+		for {
+			i := strings.IndexByte(part, '[')
+			if i < 0 {
+				break
+			}
+			if i > 0 {
+				segments = append(segments, part[:i])
+			}
+			end := strings.IndexByte(part, ']')
+			if end < i {
+				break
+			}
+			n, _ := strconv.Atoi(part[i+1 : end])
+			segments = append(segments, n)
+			part = part[end+1:]
+		}
+		if part != "" {
+			segments = append(segments, part)
+		}
+	}
+	return segments
+}
@@ -0,0 +1,74 @@
+package graphql_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestOptionalFields(t *testing.T) {
+	RegisterTestingT(t)
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, body)
+	}))
+	defer srv.Close()
+	var warnings []graphql.Warning
+	client := graphql.NewClient(srv.URL, graphql.WithWarningHandler(func(req *graphql.Request, w graphql.Warning) {
+		warnings = append(warnings, w)
+	}))
+	type user struct {
+		Name       string
+		Reputation *struct {
+			Score int
+		} `graphql:"optional"`
+		Badges []string `graphql:"optional"`
+	}
+	var resp struct {
+		Users []user
+	}
+	req := graphql.NewRequest(`{ users { name reputation { score } badges } }`)
+
+	body = `{"data":{"users":[{"name":"a","reputation":null,"badges":["x"]}]},
+		"errors":[{"message":"reputation service down","path":["users",0,"reputation","score"]}]}`
+	Expect(client.Run(context.Background(), req, &resp)).Should(Succeed())
+	Expect(resp.Users).Should(Equal([]user{{Name: "a", Badges: []string{"x"}}}))
+	Expect(warnings).Should(Equal([]graphql.Warning{{
+		Kind:    graphql.WarningOptionalField,
+		Message: "reputation service down",
+		Path:    []interface{}{"users", 0.0, "reputation", "score"},
+	}}))
+
+	warnings = nil
+	body = `{"data":{"users":[{"name":"a","reputation":{"score":"high"},"badges":"x"}]}}`
+	Expect(client.Run(context.Background(), req, &resp)).Should(Succeed())
+	Expect(resp.Users[0].Name).Should(Equal("a"))
+	Expect(resp.Users[0].Reputation).Should(BeNil())
+	Expect(warnings).Should(HaveLen(2))
+	Expect(warnings[0].Kind).Should(Equal(graphql.WarningOptionalField))
+	paths := [][]interface{}{warnings[0].Path, warnings[1].Path}
+	Expect(paths).Should(ConsistOf(
+		[]interface{}{"users", 0, "reputation"},
+		[]interface{}{"users", 0, "badges"},
+	))
+
+	// errors elsewhere still fail the call
+	body = `{"data":{"users":null},"errors":[{"message":"boom","path":["users"]}]}`
+	Expect(client.Run(context.Background(), req, &resp)).Should(MatchError(ContainSubstring("boom")))
+	body = `{"data":{"users":[{"name":5}]}}`
+	Expect(client.Run(context.Background(), req, &resp)).ShouldNot(Succeed())
+
+	warnings = nil
+	body = `{"data":{"users":[{"name":"b","badges":{}}]}}`
+	result, err := graphql.Do[struct{ Users []user }](context.Background(), client, req)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(result.Data.Users[0].Name).Should(Equal("b"))
+	Expect(result.Warnings).Should(HaveLen(1))
+	Expect(result.Warnings[0].Path).Should(Equal([]interface{}{"users", 0, "badges"}))
+	Expect(warnings).Should(HaveLen(1))
+}
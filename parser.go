@@ -0,0 +1,538 @@
+package graphql
+
+import (
+	"github.com/pkg/errors"
+)
+
+// document is the syntax tree of an executable GraphQL document.
+// Nodes record the byte offsets of their source text so documents can
+// be rewritten without reprinting them.
+type document struct {
+	operations []*operationDef
+	fragments  []*fragmentDef
+}
+
+// operation gets the named operation, or the only operation if name is
+// empty.
+func (doc *document) operation(name string) (*operationDef, error) {
+	if name != "" {
+		for _, op := range doc.operations {
+			if op.name == name {
+				return op, nil
+			}
+		}
+		return nil, errors.Errorf("graphql: unknown operation %q", name)
+	}
+	if len(doc.operations) != 1 {
+		return nil, errors.Errorf("graphql: document has %d operations, OperationName must be set", len(doc.operations))
+	}
+	return doc.operations[0], nil
+}
+
+type operationDef struct {
+	opType     string
+	name       string
+	varDefs    []*varDef
+	directives []*directive
+	selections []selection
+	start, end int
+	// nameEnd is the offset just past the operation type or name, where
+	// a name can be inserted; -1 for shorthand queries.
+	nameEnd int
+	// varDefsStart and varDefsEnd are the offsets of the parentheses
+	// around the variable definitions, if there are any.
+	varDefsStart, varDefsEnd int
+}
+
+type varDef struct {
+	name         string
+	typ          *typeRef
+	defaultValue *value
+	directives   []*directive
+	start, end   int
+}
+
+// typeRef is a reference to a type, such as [ID!]!.
+type typeRef struct {
+	name    string
+	elem    *typeRef
+	nonNull bool
+}
+
+func (t *typeRef) String() string {
+	s := t.name
+	if t.elem != nil {
+		s = "[" + t.elem.String() + "]"
+	}
+	if t.nonNull {
+		s += "!"
+	}
+	return s
+}
+
+type fragmentDef struct {
+	name          string
+	typeCondition string
+	directives    []*directive
+	selections    []selection
+	start, end    int
+}
+
+// selection is a *field, *fragmentSpread or *inlineFragment.
+type selection interface {
+	span() (start, end int)
+}
+
+type field struct {
+	alias      string
+	name       string
+	args       []*argument
+	directives []*directive
+	selections []selection
+	start, end int
+}
+
+func (f *field) span() (int, int) { return f.start, f.end }
+
+// responseKey gets the key the field has in the response.
+func (f *field) responseKey() string {
+	if f.alias != "" {
+		return f.alias
+	}
+	return f.name
+}
+
+type fragmentSpread struct {
+	name       string
+	directives []*directive
+	start, end int
+}
+
+func (f *fragmentSpread) span() (int, int) { return f.start, f.end }
+
+type inlineFragment struct {
+	typeCondition string
+	directives    []*directive
+	selections    []selection
+	start, end    int
+}
+
+func (f *inlineFragment) span() (int, int) { return f.start, f.end }
+
+type argument struct {
+	name  string
+	value *value
+}
+
+type directive struct {
+	name       string
+	args       []*argument
+	start, end int
+}
+
+type valueKind int
+
+const (
+	valueVariable valueKind = iota
+	valueInt
+	valueFloat
+	valueString
+	valueBlockString
+	valueBoolean
+	valueNull
+	valueEnum
+	valueList
+	valueObject
+)
+
+type value struct {
+	kind valueKind
+	// raw is the source text of scalar values, or the name of variables.
+	raw    string
+	list   []*value
+	fields []*argument
+}
+
+// parseDocument parses an executable GraphQL document.
+func parseDocument(src string) (*document, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{src: src, toks: toks}
+	doc := &document{}
+	for !p.done() {
+		t := p.peek()
+		switch {
+		case t.kind == tokPunct && t.value == "{":
+			op := &operationDef{opType: "query", start: t.start, nameEnd: -1}
+			if op.selections, err = p.selectionSet(); err != nil {
+				return nil, err
+			}
+			op.end = p.last().end
+			doc.operations = append(doc.operations, op)
+		case t.kind == tokName && isOperationType(t.value):
+			op, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, op)
+		case t.kind == tokName && t.value == "fragment":
+			frag, err := p.fragment()
+			if err != nil {
+				return nil, err
+			}
+			doc.fragments = append(doc.fragments, frag)
+		default:
+			return nil, p.unexpected()
+		}
+	}
+	return doc, nil
+}
+
+type parser struct {
+	src  string
+	toks []token
+	pos  int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.toks)
+}
+
+func (p *parser) peek() token {
+	if p.done() {
+		return token{kind: tokPunct, start: len(p.src), end: len(p.src)}
+	}
+	return p.toks[p.pos]
+}
+
+func (p *parser) last() token {
+	return p.toks[p.pos-1]
+}
+
+func (p *parser) next() token {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+// is reports whether the next token is the punctuator s.
+func (p *parser) is(s string) bool {
+	t := p.peek()
+	return !p.done() && t.kind == tokPunct && t.value == s
+}
+
+func (p *parser) unexpected() error {
+	if p.done() {
+		return errors.New("graphql: unexpected end of document")
+	}
+	t := p.peek()
+	return errors.Errorf("graphql: unexpected %q at offset %d", t.value, t.start)
+}
+
+func (p *parser) expect(s string) error {
+	if !p.is(s) {
+		return p.unexpected()
+	}
+	p.pos++
+	return nil
+}
+
+func (p *parser) name() (string, error) {
+	if p.done() || p.peek().kind != tokName {
+		return "", p.unexpected()
+	}
+	return p.next().value, nil
+}
+
+func (p *parser) operation() (*operationDef, error) {
+	t := p.next()
+	op := &operationDef{opType: t.value, start: t.start, nameEnd: t.end}
+	var err error
+	if !p.done() && p.peek().kind == tokName {
+		n := p.next()
+		op.name = n.value
+		op.nameEnd = n.end
+	}
+	if p.is("(") {
+		op.varDefsStart = p.next().start
+		for !p.is(")") {
+			v, err := p.varDef()
+			if err != nil {
+				return nil, err
+			}
+			op.varDefs = append(op.varDefs, v)
+		}
+		op.varDefsEnd = p.next().start
+	}
+	if op.directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if op.selections, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	op.end = p.last().end
+	return op, nil
+}
+
+func (p *parser) varDef() (*varDef, error) {
+	start := p.peek().start
+	if err := p.expect("$"); err != nil {
+		return nil, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	v := &varDef{name: name, start: start}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	if v.typ, err = p.typeRef(); err != nil {
+		return nil, err
+	}
+	if p.is("=") {
+		p.pos++
+		if v.defaultValue, err = p.value(); err != nil {
+			return nil, err
+		}
+	}
+	if v.directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	v.end = p.last().end
+	return v, nil
+}
+
+func (p *parser) typeRef() (*typeRef, error) {
+	t := &typeRef{}
+	if p.is("[") {
+		p.pos++
+		elem, err := p.typeRef()
+		if err != nil {
+			return nil, err
+		}
+		t.elem = elem
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+	} else {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		t.name = name
+	}
+	if p.is("!") {
+		p.pos++
+		t.nonNull = true
+	}
+	return t, nil
+}
+
+func (p *parser) fragment() (*fragmentDef, error) {
+	frag := &fragmentDef{start: p.next().start}
+	var err error
+	if frag.name, err = p.name(); err != nil {
+		return nil, err
+	}
+	if on, err := p.name(); err != nil || on != "on" {
+		return nil, errors.Errorf("graphql: expected \"on\" in fragment %s", frag.name)
+	}
+	if frag.typeCondition, err = p.name(); err != nil {
+		return nil, err
+	}
+	if frag.directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if frag.selections, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	frag.end = p.last().end
+	return frag, nil
+}
+
+func (p *parser) selectionSet() ([]selection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var selections []selection
+	for !p.is("}") {
+		if p.done() {
+			return nil, p.unexpected()
+		}
+		s, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, s)
+	}
+	p.pos++
+	return selections, nil
+}
+
+func (p *parser) selection() (selection, error) {
+	start := p.peek().start
+	var err error
+	if p.is("...") {
+		p.pos++
+		if !p.done() && p.peek().kind == tokName && p.peek().value != "on" {
+			spread := &fragmentSpread{name: p.next().value, start: start}
+			if spread.directives, err = p.directives(); err != nil {
+				return nil, err
+			}
+			spread.end = p.last().end
+			return spread, nil
+		}
+		frag := &inlineFragment{start: start}
+		if !p.done() && p.peek().kind == tokName {
+			p.pos++ // on
+			if frag.typeCondition, err = p.name(); err != nil {
+				return nil, err
+			}
+		}
+		if frag.directives, err = p.directives(); err != nil {
+			return nil, err
+		}
+		if frag.selections, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+		frag.end = p.last().end
+		return frag, nil
+	}
+	f := &field{start: start}
+	if f.name, err = p.name(); err != nil {
+		return nil, err
+	}
+	if p.is(":") {
+		p.pos++
+		f.alias = f.name
+		if f.name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if f.args, err = p.arguments(); err != nil {
+		return nil, err
+	}
+	if f.directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if p.is("{") {
+		if f.selections, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	f.end = p.last().end
+	return f, nil
+}
+
+func (p *parser) arguments() ([]*argument, error) {
+	if !p.is("(") {
+		return nil, nil
+	}
+	p.pos++
+	var args []*argument
+	for !p.is(")") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, &argument{name: name, value: v})
+	}
+	p.pos++
+	return args, nil
+}
+
+func (p *parser) directives() ([]*directive, error) {
+	var directives []*directive
+	for p.is("@") {
+		start := p.next().start
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		d := &directive{name: name, start: start}
+		if d.args, err = p.arguments(); err != nil {
+			return nil, err
+		}
+		d.end = p.last().end
+		directives = append(directives, d)
+	}
+	return directives, nil
+}
+
+func (p *parser) value() (*value, error) {
+	if p.done() {
+		return nil, p.unexpected()
+	}
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		for _, c := range t.value {
+			if c == '.' || c == 'e' || c == 'E' {
+				return &value{kind: valueFloat, raw: t.value}, nil
+			}
+		}
+		return &value{kind: valueInt, raw: t.value}, nil
+	case tokString:
+		if len(t.value) >= 6 && t.value[:3] == `"""` {
+			return &value{kind: valueBlockString, raw: t.value}, nil
+		}
+		return &value{kind: valueString, raw: t.value}, nil
+	case tokName:
+		switch t.value {
+		case "true", "false":
+			return &value{kind: valueBoolean, raw: t.value}, nil
+		case "null":
+			return &value{kind: valueNull, raw: t.value}, nil
+		}
+		return &value{kind: valueEnum, raw: t.value}, nil
+	}
+	switch t.value {
+	case "$":
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		return &value{kind: valueVariable, raw: name}, nil
+	case "[":
+		v := &value{kind: valueList}
+		for !p.is("]") {
+			item, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			v.list = append(v.list, item)
+		}
+		p.pos++
+		return v, nil
+	case "{":
+		v := &value{kind: valueObject}
+		for !p.is("}") {
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			item, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			v.fields = append(v.fields, &argument{name: name, value: item})
+		}
+		p.pos++
+		return v, nil
+	}
+	p.pos--
+	return nil, p.unexpected()
+}
@@ -0,0 +1,82 @@
+package graphql
+
+import "github.com/pkg/errors"
+
+// Plugin rewrites requests before they are sent and response bodies
+// before they are decoded, for example to add directives to the document
+// or to unwrap a gateway envelope around the GraphQL response.
+type Plugin interface {
+	// TransformRequest modifies the request about to be sent. It is
+	// given a copy, so changes don't affect the caller's Request.
+	TransformRequest(req *Request) error
+	// TransformResponse rewrites the raw response body.
+	TransformResponse(body []byte) ([]byte, error)
+}
+
+// PluginFuncs is a Plugin made from functions. Either function may be nil.
+type PluginFuncs struct {
+	Request  func(req *Request) error
+	Response func(body []byte) ([]byte, error)
+}
+
+var _ Plugin = PluginFuncs{}
+
+// TransformRequest calls p.Request.
+func (p PluginFuncs) TransformRequest(req *Request) error {
+	if p.Request == nil {
+		return nil
+	}
+	return p.Request(req)
+}
+
+// TransformResponse calls p.Response.
+func (p PluginFuncs) TransformResponse(body []byte) ([]byte, error) {
+	if p.Response == nil {
+		return body, nil
+	}
+	return p.Response(body)
+}
+
+// WithPlugin adds a Plugin to the client. Plugins transform requests in
+// the order they were added, and responses in the reverse order.
+//  NewClient(endpoint, WithPlugin(PluginFuncs{
+//      Response: unwrapEnvelope,
+//  }))
+func WithPlugin(plugin Plugin) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.plugins = append(client.plugins, plugin)
+	})
+}
+
+// transformRequest runs the plugins on a copy of req.
+func (c *Client) transformRequest(req *Request) (*Request, error) {
+	if len(c.plugins) == 0 {
+		return req, nil
+	}
+	r := req.clone()
+	for _, plugin := range c.plugins {
+		err := c.callHook("plugin", func() error {
+			return plugin.TransformRequest(r)
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "transforming request")
+		}
+	}
+	return r, nil
+}
+
+// transformResponse runs the plugins on the response body.
+func (c *Client) transformResponse(body []byte) ([]byte, error) {
+	for i := len(c.plugins) - 1; i >= 0; i-- {
+		plugin := c.plugins[i]
+		err := c.callHook("plugin", func() error {
+			var err error
+			body, err = plugin.TransformResponse(body)
+			return err
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "transforming response")
+		}
+	}
+	return body, nil
+}
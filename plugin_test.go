@@ -0,0 +1,48 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestPlugin(t *testing.T) {
+	RegisterTestingT(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphql.Request
+		json.NewDecoder(r.Body).Decode(&req)
+		Expect(req.Query).Should(Equal(`query @gateway { value }`))
+		io.WriteString(w, `{"envelope":{"data":{"value":"some data"}}}`)
+	}))
+	defer srv.Close()
+
+	unwrap := graphql.PluginFuncs{
+		Request: func(req *graphql.Request) error {
+			req.Query = strings.Replace(req.Query, "query", "query @gateway", 1)
+			return nil
+		},
+		Response: func(body []byte) ([]byte, error) {
+			var envelope struct {
+				Envelope json.RawMessage
+			}
+			err := json.Unmarshal(body, &envelope)
+			return envelope.Envelope, err
+		},
+	}
+	client := graphql.NewClient(srv.URL, graphql.WithPlugin(unwrap))
+	req := graphql.NewRequest(`query { value }`)
+	var resp struct {
+		Value string
+	}
+	err := client.Run(context.Background(), req, &resp)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(resp.Value).Should(Equal("some data"))
+	Expect(req.Query).Should(Equal(`query { value }`))
+}
@@ -0,0 +1,156 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// PointerHandler is called with a value in the data of a response.
+type PointerHandler func(value json.RawMessage) error
+
+// RunPointers runs req as RunTo does, calling the handler of each JSON
+// pointer (RFC 6901) into the data with each value at it as the data is
+// read, so that giant results can be processed one element at a time
+// without holding them in memory or defining their whole structure. A
+// pointer segment of - matches every element of a list. Values outside
+// the pointers are skipped. If a handler returns an error, the response
+// is abandoned and the error returned.
+//  err := client.RunPointers(ctx, req, map[string]graphql.PointerHandler{
+//      "/search/nodes/-": func(node json.RawMessage) error {
+//          return index(node)
+//      },
+//  })
+func (c *Client) RunPointers(ctx context.Context, req *Request, handlers map[string]PointerHandler) error {
+	p := &pointerDecoder{handlers: make(map[string]PointerHandler, len(handlers))}
+	for pointer, handler := range handlers {
+		if pointer != "" && !strings.HasPrefix(pointer, "/") {
+			return errors.Errorf("graphql: invalid JSON pointer %q", pointer)
+		}
+		segments := strings.Split(pointer, "/")[1:]
+		for i, s := range segments {
+			segments[i] = strings.Replace(strings.Replace(s, "~1", "/", -1), "~0", "~", -1)
+		}
+		p.pointers = append(p.pointers, segments)
+		p.handlers[pointer] = handler
+		p.names = append(p.names, pointer)
+	}
+	r, w := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		err := c.RunTo(ctx, req, w)
+		w.CloseWithError(err)
+		done <- err
+	}()
+	p.d = json.NewDecoder(r)
+	p.d.UseNumber()
+	err := p.value(nil)
+	if err == io.EOF {
+		// null data isn't written
+		err = nil
+	}
+	if err != nil {
+		r.CloseWithError(err)
+		<-done
+		return err
+	}
+	io.Copy(ioutil.Discard, r)
+	return <-done
+}
+
+// pointerDecoder reads data, passing the values at the pointers to
+// their handlers.
+type pointerDecoder struct {
+	d *json.Decoder
+	// pointers are the segments of each pointer, and names the pointer
+	// each was given as.
+	pointers [][]string
+	names    []string
+	handlers map[string]PointerHandler
+}
+
+// value reads the next value, which is at path.
+func (p *pointerDecoder) value(path []string) error {
+	within := false
+	for i, pointer := range p.pointers {
+		if !pointerMatches(pointer, path) {
+			continue
+		}
+		if len(pointer) == len(path) {
+			var raw json.RawMessage
+			if err := p.d.Decode(&raw); err != nil {
+				return err
+			}
+			return p.handlers[p.names[i]](raw)
+		}
+		within = true
+	}
+	tok, err := p.d.Token()
+	if err != nil {
+		return err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok || delim == '}' || delim == ']' {
+		return nil
+	}
+	if !within {
+		return p.skip()
+	}
+	for i := 0; p.d.More(); i++ {
+		segment := strconv.Itoa(i)
+		if delim == '{' {
+			key, err := p.d.Token()
+			if err != nil {
+				return err
+			}
+			segment, _ = key.(string)
+		}
+		if err := p.value(append(path, segment)); err != nil {
+			return err
+		}
+	}
+	_, err = p.d.Token()
+	return err
+}
+
+// skip reads the rest of the object or list just opened.
+func (p *pointerDecoder) skip() error {
+	depth := 1
+	for depth > 0 {
+		tok, err := p.d.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+	return nil
+}
+
+// pointerMatches reports whether path is the pointer or within it.
+// Indexes of lists on the path match - in the pointer.
+func pointerMatches(pointer, path []string) bool {
+	if len(path) > len(pointer) {
+		return false
+	}
+	for i, segment := range path {
+		if pointer[i] != segment && !(pointer[i] == "-" && isIndex(segment)) {
+			return false
+		}
+	}
+	return true
+}
+
+func isIndex(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
@@ -0,0 +1,67 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+func TestRunPointers(t *testing.T) {
+	RegisterTestingT(t)
+	body := `{"data":{"search":{"total":3,"nodes":[{"id":"1"},{"id":"2"},{"id":"3","tags":["a/b"]}]},"me":{"a/b":{"c~d":true}}},"extensions":{"cost":1}}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, body)
+	}))
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL)
+	req := graphql.NewRequest(`{ search { total nodes { id tags } } me }`)
+
+	var ids []string
+	var total int
+	var cd bool
+	err := client.RunPointers(context.Background(), req, map[string]graphql.PointerHandler{
+		"/search/nodes/-": func(node json.RawMessage) error {
+			var n struct{ ID string }
+			if err := json.Unmarshal(node, &n); err != nil {
+				return err
+			}
+			ids = append(ids, n.ID)
+			return nil
+		},
+		"/search/total": func(v json.RawMessage) error {
+			return json.Unmarshal(v, &total)
+		},
+		"/me/a~1b/c~0d": func(v json.RawMessage) error {
+			return json.Unmarshal(v, &cd)
+		},
+	})
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(ids).Should(Equal([]string{"1", "2", "3"}))
+	Expect(total).Should(Equal(3))
+	Expect(cd).Should(BeTrue())
+
+	var tags []string
+	err = client.RunPointers(context.Background(), req, map[string]graphql.PointerHandler{
+		"/search/nodes/2/tags/0": func(v json.RawMessage) error {
+			tags = append(tags, string(v))
+			return errors.New("enough")
+		},
+	})
+	Expect(err).Should(MatchError("enough"))
+	Expect(tags).Should(Equal([]string{`"a/b"`}))
+
+	body = `{"data":null,"errors":[{"message":"search is down"}]}`
+	err = client.RunPointers(context.Background(), req, map[string]graphql.PointerHandler{
+		"/search/nodes/-": func(json.RawMessage) error { return nil },
+	})
+	Expect(err).Should(MatchError("graphql: search is down"))
+
+	Expect(client.RunPointers(context.Background(), req, map[string]graphql.PointerHandler{"search": nil})).Should(MatchError(`graphql: invalid JSON pointer "search"`))
+}
@@ -0,0 +1,106 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// PolicyRequest is a request about to be sent, given to a Policy.
+type PolicyRequest struct {
+	// Request is a copy of the request as it will be sent. A policy may
+	// rewrite it, or annotate it by setting headers.
+	Request *Request
+	// Operation is the operation the request executes. It is empty if
+	// the document can't be parsed.
+	Operation Operation
+	// Endpoint is the URL the request will be sent to, by its route as
+	// given to the policy. A policy that changes the route, with
+	// SetRoute, sends it by the new one.
+	Endpoint string
+}
+
+// Policy decides whether a request may be sent, returning an error to
+// deny it. It may also change the request.
+type Policy func(ctx context.Context, req *PolicyRequest) error
+
+// PolicyError is returned for requests denied by a Policy.
+type PolicyError struct {
+	Operation Operation
+	// Err is the reason the policy gave.
+	Err error
+}
+
+func (e *PolicyError) Error() string {
+	name := e.Operation.Name
+	if name == "" {
+		name = "anonymous " + e.Operation.Type
+	}
+	return fmt.Sprintf("graphql: %s denied by policy: %v", name, e.Err)
+}
+
+// Cause gets the reason the policy gave.
+func (e *PolicyError) Cause() error {
+	return e.Err
+}
+
+// WithPolicy adds a Policy that every request must pass before it is
+// sent. Policies run in the order they were added, after plugins, so
+// they see requests as they will be sent. Requests they rewrite are
+// checked again against the allow-list, size limits and schema.
+//  NewClient(endpoint, WithPolicy(DenyMutations()))
+func WithPolicy(policy Policy) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.policies = append(client.policies, policy)
+	})
+}
+
+// DenyMutations makes a Policy that only allows queries and
+// subscriptions, for services that must not write.
+func DenyMutations() Policy {
+	return func(ctx context.Context, req *PolicyRequest) error {
+		if req.Operation.Type == "mutation" {
+			return errors.New("mutations are not allowed")
+		}
+		return nil
+	}
+}
+
+// checkPolicies runs the client's policies on the prepared requests,
+// replacing them with the requests to send. The endpoint is resolved
+// for the policies, by the route of the requests they are given, and
+// pinned in the returned context so that the requests are sent where
+// the policies were told, unless they rewrote the requests to be routed
+// elsewhere; the route is then left to be taken from the requests the
+// policies returned.
+func (c *Client) checkPolicies(ctx context.Context, reqs []*Request) (context.Context, error) {
+	if len(c.policies) == 0 {
+		return ctx, nil
+	}
+	replica := c.toReplica(reqs)
+	endpoint, err := c.resolveEndpoint(c.routeContext(ctx, reqs...))
+	if err != nil {
+		return nil, err
+	}
+	pinned := context.WithValue(ctx, endpointKey{}, endpoint)
+	for i, req := range reqs {
+		pr := &PolicyRequest{Request: req.clone(), Endpoint: endpoint}
+		pr.Operation, _ = req.Operation()
+		for _, policy := range c.policies {
+			err := c.callHook("policy", func() error {
+				return policy(pinned, pr)
+			})
+			if err != nil {
+				return nil, &PolicyError{Operation: pr.Operation, Err: err}
+			}
+		}
+		if reqs[i], err = c.checkRequest(pr.Request); err != nil {
+			return nil, err
+		}
+	}
+	if c.toReplica(reqs) != replica {
+		return ctx, nil
+	}
+	return pinned, nil
+}
@@ -0,0 +1,125 @@
+package graphql_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+func TestPolicy(t *testing.T) {
+	RegisterTestingT(t)
+	var tenant string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant = r.Header.Get("X-Tenant")
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	var seen []*graphql.PolicyRequest
+	client := graphql.NewClient(srv.URL,
+		graphql.WithPolicy(graphql.DenyMutations()),
+		graphql.WithPolicy(func(ctx context.Context, req *graphql.PolicyRequest) error {
+			seen = append(seen, req)
+			if req.Request.Header == nil {
+				req.Request.Header = make(http.Header)
+			}
+			req.Request.Header.Set("X-Tenant", "acme")
+			return nil
+		}),
+	)
+	ctx := context.Background()
+
+	req := graphql.NewRequest(`query Q { a }`)
+	Expect(client.Run(ctx, req, nil)).Should(Succeed())
+	Expect(tenant).Should(Equal("acme"))
+	Expect(req.Header).Should(BeNil())
+	Expect(seen).Should(HaveLen(1))
+	Expect(seen[0].Operation).Should(Equal(graphql.Operation{Type: "query", Name: "Q"}))
+	Expect(seen[0].Endpoint).Should(Equal(srv.URL))
+
+	tenant = ""
+	err := client.Run(ctx, graphql.NewRequest(`mutation M { b }`), nil)
+	Expect(err).Should(MatchError("graphql: M denied by policy: mutations are not allowed"))
+	Expect(errors.Cause(err)).Should(MatchError("mutations are not allowed"))
+	Expect(tenant).Should(BeEmpty())
+	Expect(seen).Should(HaveLen(1))
+
+	_, err = client.RunBatch(ctx, []*graphql.Request{
+		graphql.NewRequest(`query Q { a }`),
+		graphql.NewRequest(`mutation { b }`),
+	}, nil)
+	Expect(err).Should(MatchError("graphql: anonymous mutation denied by policy: mutations are not allowed"))
+}
+
+func TestPolicyRewriteChecked(t *testing.T) {
+	RegisterTestingT(t)
+	sent := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent++
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	allowed := `query Users { users { id } }`
+	rewrite := func(query string) graphql.Policy {
+		return func(ctx context.Context, req *graphql.PolicyRequest) error {
+			req.Request.Query = query
+			return nil
+		}
+	}
+	client := graphql.NewClient(srv.URL,
+		graphql.WithAllowList(graphql.NewAllowList(graphql.QueryHash(allowed))),
+		graphql.WithPolicy(rewrite(`query Users { users { id secret } }`)),
+	)
+	err := client.Run(context.Background(), graphql.NewRequest(allowed), nil)
+	Expect(err).Should(BeAssignableToTypeOf(&graphql.NotAllowedError{}))
+	_, err = client.RunBatch(context.Background(), []*graphql.Request{graphql.NewRequest(allowed)}, nil)
+	Expect(err).Should(BeAssignableToTypeOf(&graphql.NotAllowedError{}))
+
+	client = graphql.NewClient(srv.URL,
+		graphql.WithMaxQuerySize(int64(len(allowed))),
+		graphql.WithPolicy(rewrite(allowed+" # padding")),
+	)
+	Expect(client.Run(context.Background(), graphql.NewRequest(allowed), nil)).ShouldNot(Succeed())
+	Expect(sent).Should(Equal(0))
+}
+
+func TestPolicyRoute(t *testing.T) {
+	RegisterTestingT(t)
+	newServer := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, `{"data":{"server":"`+name+`"}}`)
+		}))
+	}
+	primary, replica := newServer("primary"), newServer("replica")
+	defer primary.Close()
+	defer replica.Close()
+	var endpoints []string
+	client := graphql.NewClient(primary.URL,
+		graphql.WithReadEndpoints(replica.URL),
+		graphql.WithPolicy(func(ctx context.Context, req *graphql.PolicyRequest) error {
+			endpoints = append(endpoints, req.Endpoint)
+			if req.Operation.Name == "Fresh" {
+				req.Request.SetRoute(graphql.RoutePrimary)
+			}
+			return nil
+		}),
+	)
+	ctx := context.Background()
+	server := func(req *graphql.Request) string {
+		var resp struct {
+			Server string
+		}
+		Expect(client.Run(ctx, req, &resp)).Should(Succeed())
+		return resp.Server
+	}
+
+	Expect(server(graphql.NewRequest(`query Cached { server }`))).Should(Equal("replica"))
+	// the route is taken from the request the policies return
+	Expect(server(graphql.NewRequest(`query Fresh { server }`))).Should(Equal("primary"))
+	Expect(endpoints).Should(Equal([]string{replica.URL, replica.URL}))
+}
@@ -0,0 +1,65 @@
+package graphql
+
+import (
+	"bufio"
+	"bytes"
+)
+
+// DefaultResponsePrefixes are the anti-JSON-hijacking guards stripped by
+// WithResponsePrefixes when no prefixes are given.
+var DefaultResponsePrefixes = []string{")]}'", "while(1);", "for(;;);"}
+
+// WithResponsePrefixes strips any of the prefixes from the start of
+// response bodies before they are decoded, for gateways that guard JSON
+// responses against hijacking. With no prefixes, DefaultResponsePrefixes
+// are stripped.
+//  NewClient(endpoint, WithResponsePrefixes())
+func WithResponsePrefixes(prefixes ...string) ClientOption {
+	if len(prefixes) == 0 {
+		prefixes = DefaultResponsePrefixes
+	}
+	return ClientOption(func(client *Client) {
+		client.responsePrefixes = prefixes
+	})
+}
+
+// stripPrefix removes the first of the client's response prefixes found
+// at the start of body, ignoring leading white space.
+func (c *Client) stripPrefix(body []byte) []byte {
+	if len(c.responsePrefixes) == 0 {
+		return body
+	}
+	trimmed := bytes.TrimLeft(body, " \t\r\n")
+	for _, prefix := range c.responsePrefixes {
+		if bytes.HasPrefix(trimmed, []byte(prefix)) {
+			return trimmed[len(prefix):]
+		}
+	}
+	return body
+}
+
+// stripStreamPrefix is stripPrefix for a response body that is streamed:
+// it discards the first of the client's response prefixes found at the
+// start of r, ignoring leading white space.
+func (c *Client) stripStreamPrefix(r *bufio.Reader) {
+	if len(c.responsePrefixes) == 0 {
+		return
+	}
+	for {
+		b, err := r.Peek(1)
+		if err != nil {
+			// the error is returned when the body is read
+			return
+		}
+		if b[0] != ' ' && b[0] != '\t' && b[0] != '\r' && b[0] != '\n' {
+			break
+		}
+		r.Discard(1)
+	}
+	for _, prefix := range c.responsePrefixes {
+		if b, _ := r.Peek(len(prefix)); bytes.Equal(b, []byte(prefix)) {
+			r.Discard(len(prefix))
+			return
+		}
+	}
+}
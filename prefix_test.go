@@ -0,0 +1,45 @@
+package graphql_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestResponsePrefixes(t *testing.T) {
+	RegisterTestingT(t)
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, body)
+	}))
+	defer srv.Close()
+	var resp struct {
+		Value string
+	}
+
+	body = ")]}'\n" + `{"data":{"value":"guarded"}}`
+	err := graphql.NewClient(srv.URL).Run(context.Background(), graphql.NewRequest(`{ value }`), &resp)
+	Expect(err).Should(HaveOccurred())
+
+	client := graphql.NewClient(srv.URL, graphql.WithResponsePrefixes())
+	Expect(client.Run(context.Background(), graphql.NewRequest(`{ value }`), &resp)).Should(Succeed())
+	Expect(resp.Value).Should(Equal("guarded"))
+
+	body = `while(1);{"data":{"value":"loop"}}`
+	Expect(client.Run(context.Background(), graphql.NewRequest(`{ value }`), &resp)).Should(Succeed())
+	Expect(resp.Value).Should(Equal("loop"))
+
+	body = `{"data":{"value":"plain"}}`
+	Expect(client.Run(context.Background(), graphql.NewRequest(`{ value }`), &resp)).Should(Succeed())
+	Expect(resp.Value).Should(Equal("plain"))
+
+	body = `&&&START&&&{"data":{"value":"custom"}}`
+	client = graphql.NewClient(srv.URL, graphql.WithResponsePrefixes("&&&START&&&"))
+	Expect(client.Run(context.Background(), graphql.NewRequest(`{ value }`), &resp)).Should(Succeed())
+	Expect(resp.Value).Should(Equal("custom"))
+}
@@ -0,0 +1,51 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// RunPath runs req as Run does, but decodes only the value at path in
+// the response data into resp, saving wrapper structs for deeply nested
+// fields. path is dot-separated, such as
+// "organization.repositories.nodes". If a field on the path is null,
+// resp is left unchanged.
+//  var repos []Repository
+//  err := client.RunPath(ctx, req, "organization.repositories.nodes", &repos)
+func (c *Client) RunPath(ctx context.Context, req *Request, path string, resp interface{}) error {
+	res, err := c.do(ctx, req)
+	if err != nil {
+		return err
+	}
+	// resp holds only part of the data, so errors and unused fields
+	// can't be matched against it
+	_, err = c.handleResponse(ctx, req, res, nil, false, func(data json.RawMessage) ([]Warning, error) {
+		data, err := c.dataAt(data, path)
+		if err != nil {
+			return nil, err
+		}
+		return c.decodeDataWarnings(data, resp)
+	})
+	return err
+}
+
+// dataAt gets the value at the dot-separated path in data, or null if
+// a field on the path is null.
+func (c *Client) dataAt(data json.RawMessage, path string) (json.RawMessage, error) {
+	for _, name := range strings.Split(path, ".") {
+		if len(data) == 0 || string(data) == "null" {
+			return data, nil
+		}
+		var fields map[string]json.RawMessage
+		if err := c.decode(data, &fields); err != nil {
+			return nil, err
+		}
+		if data = fields[name]; data == nil {
+			return nil, errors.Errorf("graphql: no %s in response", path)
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,44 @@
+package graphql_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestRunPath(t *testing.T) {
+	RegisterTestingT(t)
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, body)
+	}))
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL)
+	req := graphql.NewRequest(`{ organization { repositories { nodes { name } } } }`)
+
+	body = `{"data":{"organization":{"repositories":{"nodes":[{"name":"a"},{"name":"b"}]}}}}`
+	var repos []struct {
+		Name string
+	}
+	Expect(client.RunPath(context.Background(), req, "organization.repositories.nodes", &repos)).Should(Succeed())
+	Expect(repos).Should(HaveLen(2))
+	Expect(repos[1].Name).Should(Equal("b"))
+
+	body = `{"data":{"organization":null}}`
+	repos = nil
+	Expect(client.RunPath(context.Background(), req, "organization.repositories.nodes", &repos)).Should(Succeed())
+	Expect(repos).Should(BeNil())
+
+	body = `{"data":{"organization":{}}}`
+	err := client.RunPath(context.Background(), req, "organization.repositories.nodes", &repos)
+	Expect(err).Should(MatchError("graphql: no organization.repositories.nodes in response"))
+
+	body = `{"data":null,"errors":[{"message":"not found"}]}`
+	err = client.RunPath(context.Background(), req, "organization.repositories.nodes", &repos)
+	Expect(err).Should(MatchError("graphql: not found"))
+}
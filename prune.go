@@ -0,0 +1,103 @@
+package graphql
+
+import (
+	"reflect"
+)
+
+// PruningReport lists the fields a query requests that the response
+// destination never uses, so they can be removed from the query.
+type PruningReport struct {
+	// Operation is the request's MetricsName.
+	Operation string
+	// Unused are the response paths of the unused fields, such as
+	// "user.friends".
+	Unused []string
+}
+
+// WithPruningReport sets a function called after each successful Run
+// with the fields the query requested that the response value has
+// nowhere to put. Fields decoded into maps, interfaces or types with
+// their own UnmarshalJSON are treated as used.
+// It parses every query, so it is meant for development and testing.
+//  NewClient(endpoint, WithPruningReport(func(r graphql.PruningReport) {
+//      if len(r.Unused) > 0 {
+//          log.Printf("%s: unused fields %v", r.Operation, r.Unused)
+//      }
+//  }))
+func WithPruningReport(fn func(PruningReport)) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.pruningReport = fn
+	})
+}
+
+// reportPruning calls the client's pruning report function with the
+// fields of req that resp doesn't use.
+func (c *Client) reportPruning(req *Request, resp interface{}) {
+	if c.pruningReport == nil || resp == nil {
+		return
+	}
+	doc, err := parseDocument(req.Query)
+	if err != nil {
+		return
+	}
+	p := &pruner{
+		fragments: make(map[string]*fragmentDef, len(doc.fragments)),
+		spread:    make(map[string]bool),
+		matching:  c.walker.matching,
+	}
+	for _, frag := range doc.fragments {
+		p.fragments[frag.name] = frag
+	}
+	for _, op := range doc.operations {
+		if req.OperationName != "" && op.name != req.OperationName {
+			continue
+		}
+		p.selections(op.selections, reflect.TypeOf(resp), "")
+	}
+	defer c.recoverHook("pruning report", nil)
+	c.pruningReport(PruningReport{Operation: req.MetricsName(), Unused: p.unused})
+}
+
+type pruner struct {
+	fragments map[string]*fragmentDef
+	spread    map[string]bool
+	unused    []string
+	// matching is how the client matches response keys to fields.
+	matching FieldMatching
+}
+
+func (p *pruner) selections(selections []selection, t reflect.Type, path string) {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || reflect.PtrTo(t).Implements(unmarshalerType) {
+		return
+	}
+	fields := cachedFields(t)
+	for _, sel := range selections {
+		switch sel := sel.(type) {
+		case *field:
+			if sel.name == "__typename" {
+				continue
+			}
+			key := sel.responseKey()
+			sf := p.matching.field(fields, key)
+			if sf == nil {
+				p.unused = append(p.unused, joinPath(path, key))
+				continue
+			}
+			if len(sel.selections) > 0 {
+				p.selections(sel.selections, sf.typ, joinPath(path, key))
+			}
+		case *inlineFragment:
+			p.selections(sel.selections, t, path)
+		case *fragmentSpread:
+			frag, ok := p.fragments[sel.name]
+			if !ok || p.spread[sel.name+"@"+path] {
+				continue
+			}
+			p.spread[sel.name+"@"+path] = true
+			p.selections(frag.selections, t, path)
+		}
+	}
+}
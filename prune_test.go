@@ -0,0 +1,52 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestPruningReport(t *testing.T) {
+	RegisterTestingT(t)
+	var report graphql.PruningReport
+	client := bodyClient(`{"data":{}}`, graphql.WithPruningReport(func(r graphql.PruningReport) {
+		report = r
+	}))
+	var resp struct {
+		User *struct {
+			Name    string
+			Friends []struct {
+				ID string `json:"id"`
+			}
+			Extra json.RawMessage `json:"extra"`
+		} `json:"user"`
+		Meta map[string]interface{} `json:"meta"`
+	}
+	req := graphql.NewRequest(`query GetUser {
+		user(id: 1) {
+			__typename
+			name
+			email
+			friends { id avatar: avatarUrl }
+			extra { a b }
+			...Details
+		}
+		meta { everything }
+		total: count
+	}
+	fragment Details on User { name createdAt }`)
+	err := client.Run(context.Background(), req, &resp)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(report).Should(Equal(graphql.PruningReport{
+		Operation: "GetUser",
+		Unused: []string{
+			"user.email",
+			"user.friends.avatar",
+			"user.createdAt",
+			"total",
+		},
+	}))
+}
@@ -0,0 +1,268 @@
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Redacted replaces the values of redacted headers and variables.
+const Redacted = "[REDACTED]"
+
+// DefaultRedactionDeny are the name fragments redacted when
+// Redaction.Deny is nil.
+var DefaultRedactionDeny = []string{
+	"token", "secret", "password", "passwd", "authorization",
+	"cookie", "apikey", "api_key", "api-key", "credential", "signature",
+}
+
+// Redaction decides which headers, variables and response fields may
+// appear in logs, traces and debug output. Names are matched case-insensitively.
+// A name is shown if it is allow-listed; otherwise it is redacted if it
+// contains a Deny fragment or Strict is set.
+type Redaction struct {
+	// AllowHeaders are header names that are always shown.
+	AllowHeaders []string
+	// AllowVariables are variable names, at any depth, that are
+	// always shown.
+	AllowVariables []string
+	// Deny are name fragments that cause a header or variable to be
+	// redacted. If nil, DefaultRedactionDeny is used.
+	Deny []string
+	// Strict redacts every name that is not allow-listed.
+	Strict bool
+	// Fields are response fields whose values are masked: field names,
+	// matched at any depth, or paths such as data.users[*].email, as
+	// described for IgnorePath. Unlike headers and variables, response
+	// values are only masked when listed here.
+	Fields []string
+
+	// masks are the compiled Fields, set by WithRedaction.
+	masks *fieldMasks
+}
+
+// WithRedaction sets the rules for which headers and variables the
+// client may expose in logs, traces and debug output. Without it, names
+// matching DefaultRedactionDeny are redacted. The client keeps a copy of
+// r, so changing r afterwards doesn't change the client's rules.
+//  NewClient(endpoint, WithRedaction(&Redaction{
+//      AllowHeaders: []string{"X-Request-Id"},
+//      Strict:       true,
+//  }))
+func WithRedaction(r *Redaction) ClientOption {
+	return ClientOption(func(client *Client) {
+		copied := *r
+		copied.masks = compileFieldMasks(r.Fields)
+		client.redaction = &copied
+	})
+}
+
+// redactor gets the client's Redaction, or the default.
+func (c *Client) redactor() *Redaction {
+	if c.redaction == nil {
+		return &Redaction{}
+	}
+	return c.redaction
+}
+
+// Header gets a copy of h with redacted values replaced.
+func (r *Redaction) Header(h http.Header) http.Header {
+	out := make(http.Header, len(h))
+	for name, values := range h {
+		if r.redacts(name, r.AllowHeaders) {
+			out[name] = []string{Redacted}
+			continue
+		}
+		out[name] = append([]string(nil), values...)
+	}
+	return out
+}
+
+// Variables gets a copy of vars with redacted values replaced.
+// Values are copied through JSON, so nested objects are redacted too.
+func (r *Redaction) Variables(vars map[string]interface{}) map[string]interface{} {
+	if vars == nil {
+		return nil
+	}
+	b, err := json.Marshal(vars)
+	if err != nil {
+		return map[string]interface{}{Redacted: err.Error()}
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return map[string]interface{}{Redacted: err.Error()}
+	}
+	r.redactValue(out)
+	return out
+}
+
+func (r *Redaction) redactValue(v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for name, value := range v {
+			if r.redacts(name, r.AllowVariables) {
+				v[name] = Redacted
+				continue
+			}
+			r.redactValue(value)
+		}
+	case []interface{}:
+		for _, value := range v {
+			r.redactValue(value)
+		}
+	}
+}
+
+// URL gets raw with its user information, and the values of redacted
+// query parameters, replaced. URLs that can't be parsed are replaced
+// whole.
+func (r *Redaction) URL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return Redacted
+	}
+	var userinfo string
+	if u.User != nil {
+		userinfo = Redacted + "@"
+		u.User = nil
+	}
+	if u.RawQuery != "" {
+		q := u.Query()
+		names := make([]string, 0, len(q))
+		for name := range q {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var params []string
+		for _, name := range names {
+			for _, value := range q[name] {
+				if r.redacts(name, nil) {
+					value = Redacted
+				} else {
+					value = url.QueryEscape(value)
+				}
+				params = append(params, url.QueryEscape(name)+"="+value)
+			}
+		}
+		u.RawQuery = strings.Join(params, "&")
+	}
+	s := u.String()
+	if userinfo != "" && u.Scheme != "" {
+		s = u.Scheme + "://" + userinfo + strings.TrimPrefix(s, u.Scheme+"://")
+	}
+	return s
+}
+
+// Response gets a copy of a response body with the values of masked
+// fields replaced. Bodies that aren't JSON are returned unchanged.
+func (r *Redaction) Response(body []byte) []byte {
+	if len(r.Fields) == 0 {
+		return body
+	}
+	v, err := decodeJSON(body)
+	if err != nil {
+		return body
+	}
+	masked, err := json.Marshal(r.fieldMasks().mask("", v))
+	if err != nil {
+		return body
+	}
+	return masked
+}
+
+// fieldMasks are the compiled Fields of a Redaction: field names, and
+// patterns for paths.
+type fieldMasks struct {
+	names    []string
+	patterns []*regexp.Regexp
+}
+
+func compileFieldMasks(fields []string) *fieldMasks {
+	m := &fieldMasks{}
+	for _, field := range fields {
+		if strings.ContainsAny(field, ".[") {
+			m.patterns = append(m.patterns, pathPattern(field))
+			continue
+		}
+		m.names = append(m.names, field)
+	}
+	return m
+}
+
+// fieldMasks gets the compiled Fields: those compiled by WithRedaction,
+// or, for a Redaction used on its own, compiled now.
+func (r *Redaction) fieldMasks() *fieldMasks {
+	if r.masks != nil {
+		return r.masks
+	}
+	return compileFieldMasks(r.Fields)
+}
+
+func (m *fieldMasks) mask(path string, v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for name, value := range v {
+			p := joinPath(path, name)
+			if value != nil && m.masks(p) {
+				v[name] = Redacted
+				continue
+			}
+			v[name] = m.mask(p, value)
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = m.mask(path+"["+strconv.Itoa(i)+"]", value)
+		}
+	}
+	return v
+}
+
+// masks reports whether the response value at path, or one containing
+// it, is masked.
+func (m *fieldMasks) masks(path string) bool {
+	for _, pattern := range m.patterns {
+		if pattern.MatchString(path) {
+			return true
+		}
+	}
+	if len(m.names) == 0 {
+		return false
+	}
+	keys := strings.FieldsFunc(path, func(c rune) bool {
+		return c == '.' || c == '[' || c == ']'
+	})
+	for _, name := range m.names {
+		for _, key := range keys {
+			if strings.EqualFold(key, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *Redaction) redacts(name string, allow []string) bool {
+	for _, a := range allow {
+		if strings.EqualFold(a, name) {
+			return false
+		}
+	}
+	if r.Strict {
+		return true
+	}
+	deny := r.Deny
+	if deny == nil {
+		deny = DefaultRedactionDeny
+	}
+	lower := strings.ToLower(name)
+	for _, d := range deny {
+		if strings.Contains(lower, strings.ToLower(d)) {
+			return true
+		}
+	}
+	return false
+}
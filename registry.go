@@ -0,0 +1,116 @@
+package graphql
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// RegistryConfig describes a set of named clients. It can be
+// unmarshalled from JSON or YAML:
+//  clients:
+//    github:
+//      endpoint: https://api.github.com/graphql
+//      headers:
+//        Authorization: bearer abc123
+type RegistryConfig struct {
+	Clients map[string]ClientConfig `json:"clients" yaml:"clients"`
+}
+
+// LoadRegistryConfig reads a RegistryConfig in JSON from r.
+func LoadRegistryConfig(r io.Reader) (RegistryConfig, error) {
+	var cfg RegistryConfig
+	if err := json.NewDecoder(r).Decode(&cfg); err != nil {
+		return cfg, errors.Wrap(err, "decoding registry config")
+	}
+	return cfg, nil
+}
+
+// RegistryConfigFromEnv reads a RegistryConfig from environment
+// variables. Each client is configured as described for
+// ClientConfigFromEnv, with the prefix PREFIX_NAME, and must have
+// an endpoint:
+//  GRAPHQL_GITHUB_ENDPOINT=https://api.github.com/graphql
+//  GRAPHQL_GITHUB_HEADER_ACCEPT=application/json
+// Client names are lower case, and can't contain underscores, so that
+// variables such as GRAPHQL_GITHUB_HEADER_X_ENDPOINT aren't taken for
+// the endpoints of other clients.
+func RegistryConfigFromEnv(prefix string) RegistryConfig {
+	cfg := RegistryConfig{Clients: make(map[string]ClientConfig)}
+	prefix = strings.ToUpper(prefix) + "_"
+	for _, kv := range os.Environ() {
+		key := strings.SplitN(kv, "=", 2)[0]
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		// the name is the first word after the prefix
+		rest := strings.TrimPrefix(key, prefix)
+		i := strings.Index(rest, "_")
+		if i <= 0 || rest[i+1:] != "ENDPOINT" {
+			continue
+		}
+		name := rest[:i]
+		cfg.Clients[strings.ToLower(name)] = ClientConfigFromEnv(prefix + name)
+	}
+	return cfg
+}
+
+// Registry holds named clients, for applications that talk to several
+// GraphQL servers. It is safe for concurrent use.
+type Registry struct {
+	mu      sync.RWMutex
+	clients map[string]*Client
+}
+
+// NewRegistry makes a Registry with a client for each entry in the
+// config. opts are applied to every client after its config.
+func NewRegistry(cfg RegistryConfig, opts ...ClientOption) (*Registry, error) {
+	r := &Registry{clients: make(map[string]*Client)}
+	for name, clientConfig := range cfg.Clients {
+		client, err := NewClientFromConfig(clientConfig, opts...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "client %q", name)
+		}
+		r.clients[name] = client
+	}
+	return r, nil
+}
+
+// Register adds a client to the registry, replacing any with the
+// same name.
+func (r *Registry) Register(name string, client *Client) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.clients == nil {
+		r.clients = make(map[string]*Client)
+	}
+	r.clients[name] = client
+}
+
+// Client gets the named client.
+func (r *Registry) Client(name string) (*Client, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	client, ok := r.clients[name]
+	if !ok {
+		return nil, errors.Errorf("graphql: no client named %q", name)
+	}
+	return client, nil
+}
+
+// Names gets the names of the clients in the registry, sorted.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.clients))
+	for name := range r.clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
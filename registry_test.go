@@ -0,0 +1,63 @@
+package graphql_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestRegistry(t *testing.T) {
+	RegisterTestingT(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{"token":"`+r.Header.Get("Authorization")+`"}}`)
+	}))
+	defer srv.Close()
+
+	cfg, err := graphql.LoadRegistryConfig(strings.NewReader(`{
+		"clients": {
+			"users": {"endpoint": "` + srv.URL + `", "headers": {"Authorization": "users-token"}},
+			"orders": {"endpoint": "` + srv.URL + `"}
+		}
+	}`))
+	Expect(err).ShouldNot(HaveOccurred())
+	registry, err := graphql.NewRegistry(cfg)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(registry.Names()).Should(Equal([]string{"orders", "users"}))
+
+	client, err := registry.Client("users")
+	Expect(err).ShouldNot(HaveOccurred())
+	var resp struct {
+		Token string
+	}
+	err = client.Run(context.Background(), graphql.NewRequest(`{ token }`), &resp)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(resp.Token).Should(Equal("users-token"))
+
+	_, err = registry.Client("missing")
+	Expect(err).Should(HaveOccurred())
+}
+
+func TestRegistryConfigFromEnv(t *testing.T) {
+	RegisterTestingT(t)
+	os.Setenv("GQLTEST_GITHUB_ENDPOINT", "https://api.github.com/graphql")
+	os.Setenv("GQLTEST_GITHUB_HEADER_X_API_KEY", "abc")
+	os.Setenv("GQLTEST_GITHUB_HEADER_X_ENDPOINT", "internal")
+	defer os.Unsetenv("GQLTEST_GITHUB_ENDPOINT")
+	defer os.Unsetenv("GQLTEST_GITHUB_HEADER_X_API_KEY")
+	defer os.Unsetenv("GQLTEST_GITHUB_HEADER_X_ENDPOINT")
+
+	cfg := graphql.RegistryConfigFromEnv("gqltest")
+	Expect(cfg.Clients).Should(Equal(map[string]graphql.ClientConfig{
+		"github": {
+			Endpoint: "https://api.github.com/graphql",
+			Headers:  map[string]string{"X-Api-Key": "abc", "X-Endpoint": "internal"},
+		},
+	}))
+}
@@ -0,0 +1,79 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// Result is the outcome of running a request, with metadata about
+// the call.
+type Result[T any] struct {
+	// Data is the decoded data field of the response.
+	Data T
+	// Errors are the errors in the response. Data may still hold
+	// partial results.
+	Errors Errors
+	// FieldErrors are the errors that have a path, keyed by the path of
+	// the field they relate to, as Errors.ByField.
+	FieldErrors map[string]Errors
+	// Warnings are conditions that didn't fail the call, such as
+	// partial data or warnings from the server.
+	Warnings []Warning
+	// Truncated are the lists cut short by WithListLimit.
+	Truncated []Truncation
+	// HTTPStatus is the status code of the HTTP response.
+	HTTPStatus int
+	// Header is the header of the HTTP response.
+	Header http.Header
+	// Duration is the time taken to get and read the response.
+	Duration time.Duration
+	// Attempts is the number of HTTP requests made.
+	Attempts int
+	// FromCache is whether the response was served by an HTTP cache
+	// rather than the server: it has an Age header, as caches add, or
+	// an X-From-Cache header, as caching transports such as httpcache
+	// add. Queries sent with GET may be cached by proxies and CDNs.
+	FromCache bool
+	// Stats are the sizes and timings of the request.
+	Stats RequestStats
+}
+
+// Do runs req with the client and decodes the response into a Result.
+// Unlike Run, errors in the GraphQL response don't fail the call; they
+// are in Result.Errors alongside any data. The error is for failures to
+// send the request or read the response.
+//  result, err := graphql.Do[UserData](ctx, client, req)
+func Do[T any](ctx context.Context, c *Client, req *Request) (*Result[T], error) {
+	ctx, endTask := c.traceTask(ctx, req)
+	defer endTask()
+	res, err := c.do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result[T]{
+		Errors:      res.errors,
+		FieldErrors: res.errors.ByField(),
+		Truncated:   res.truncations,
+		HTTPStatus:  res.status,
+		Header:      res.header,
+		Duration:    res.duration,
+		Attempts:    res.attempts,
+		FromCache:   res.stats.FromCache,
+	}
+	result.Warnings, err = c.handleResponse(ctx, req, res, &result.Data, true, func(data json.RawMessage) ([]Warning, error) {
+		return c.decodeResult(res.req, data, &result.Data)
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Stats = res.stats
+	return result, nil
+}
+
+// fromCache reports whether a response with the header was served by an
+// HTTP cache.
+func fromCache(header http.Header) bool {
+	return header.Get("Age") != "" || header.Get("X-From-Cache") != ""
+}
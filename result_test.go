@@ -0,0 +1,89 @@
+package graphql_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestDoResult(t *testing.T) {
+	RegisterTestingT(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{
+			"data": {"users": [{"name": "Mat"}, null]},
+			"errors": [{"message": "not found", "path": ["users", 1]}]
+		}`)
+	}))
+	defer srv.Close()
+
+	type userData struct {
+		Users []*struct {
+			Name string
+		}
+	}
+	client := graphql.NewClient(srv.URL)
+	result, err := graphql.Do[userData](context.Background(), client, graphql.NewRequest(`{ users { name } }`))
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(result.HTTPStatus).Should(Equal(http.StatusOK))
+	Expect(result.Attempts).Should(Equal(1))
+	Expect(result.Duration).Should(BeNumerically(">", 0))
+	Expect(result.Data.Users).Should(HaveLen(2))
+	Expect(result.Data.Users[0].Name).Should(Equal("Mat"))
+	Expect(result.Errors).Should(HaveLen(1))
+	Expect(result.Errors[0].Path).Should(Equal([]interface{}{"users", float64(1)}))
+}
+
+func TestDoResultFromCache(t *testing.T) {
+	RegisterTestingT(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("cached") != "" {
+			w.Header().Set("Age", "30")
+		}
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	var stats []graphql.RequestStats
+	report := graphql.WithStatsReport(func(s graphql.RequestStats) {
+		stats = append(stats, s)
+	})
+	req := graphql.NewRequest(`{ users { name } }`)
+
+	result, err := graphql.Do[map[string]interface{}](context.Background(), graphql.NewClient(srv.URL), req)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(result.FromCache).Should(BeFalse())
+
+	cached := graphql.NewClient(srv.URL+"?cached=1", report)
+	result, err = graphql.Do[map[string]interface{}](context.Background(), cached, req)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(result.FromCache).Should(BeTrue())
+	Expect(result.Stats.FromCache).Should(BeTrue())
+	Expect(cached.Run(context.Background(), req, nil)).Should(Succeed())
+	// Do and Run both report stats
+	Expect(stats).Should(HaveLen(2))
+	Expect(stats[0].FromCache).Should(BeTrue())
+	Expect(stats[1].FromCache).Should(BeTrue())
+}
+
+func TestDoHandlesResponsesAsRun(t *testing.T) {
+	RegisterTestingT(t)
+	var shapes []graphql.ShapeReport
+	var stats []graphql.RequestStats
+	client := bodyClient(`{"data":{"user":{"id":"1","createdAt":"2020-05-17T10:30:00Z"}}}`,
+		graphql.WithSchema(loadSchema()), graphql.WithTypedScalars(nil),
+		graphql.WithShapeReport(func(r graphql.ShapeReport) { shapes = append(shapes, r) }),
+		graphql.WithStatsReport(func(s graphql.RequestStats) { stats = append(stats, s) }))
+	req := graphql.NewRequest(`{ user(id: 1) { id createdAt } }`)
+
+	result, err := graphql.Do[map[string]interface{}](context.Background(), client, req)
+	Expect(err).ShouldNot(HaveOccurred())
+	user := result.Data["user"].(map[string]interface{})
+	Expect(user["createdAt"]).Should(Equal(time.Date(2020, 5, 17, 10, 30, 0, 0, time.UTC)))
+	Expect(shapes).Should(HaveLen(1))
+	Expect(stats).Should(HaveLen(1))
+}
@@ -0,0 +1,48 @@
+package graphql
+
+import "context"
+
+// RequestOption changes a request for one call.
+type RequestOption func(req *Request)
+
+// UseMetricsName sets the request's metrics name, as Request.SetMetricsName.
+func UseMetricsName(name string) RequestOption {
+	return func(req *Request) {
+		req.SetMetricsName(name)
+	}
+}
+
+// UseIdempotencyKey sets the request's idempotency key, as
+// Request.SetIdempotencyKey.
+func UseIdempotencyKey(key string) RequestOption {
+	return func(req *Request) {
+		req.SetIdempotencyKey(key)
+	}
+}
+
+// UseRoute sets the endpoint the request is routed to, as
+// Request.SetRoute.
+func UseRoute(route Route) RequestOption {
+	return func(req *Request) {
+		req.SetRoute(route)
+	}
+}
+
+// RunFunc gets a function that runs req as Run does, for fanning out
+// calls with an errgroup.Group or similar. The options are applied to a
+// copy of req, so one request can be run with different options.
+//  g, ctx := errgroup.WithContext(ctx)
+//  g.Go(client.RunFunc(ctx, userReq, &user))
+//  g.Go(client.RunFunc(ctx, ordersReq, &orders, graphql.UseRoute(graphql.RoutePrimary)))
+//  err := g.Wait()
+func (c *Client) RunFunc(ctx context.Context, req *Request, resp interface{}, opts ...RequestOption) func() error {
+	if len(opts) > 0 {
+		req = req.clone()
+		for _, opt := range opts {
+			opt(req)
+		}
+	}
+	return func() error {
+		return c.Run(ctx, req, resp)
+	}
+}
@@ -0,0 +1,51 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestRunFunc(t *testing.T) {
+	RegisterTestingT(t)
+	var (
+		mu   sync.Mutex
+		keys []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Variables map[string]interface{}
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(b, &body)
+		mu.Lock()
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		mu.Unlock()
+		io.WriteString(w, `{"data":{"id":"`+body.Variables["id"].(string)+`"}}`)
+	}))
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL)
+	ctx := context.Background()
+
+	req := graphql.NewRequest(`mutation Touch($id: ID!) { id }`)
+	req.Var("id", "1")
+	var a, b struct{ ID string }
+	run := client.RunFunc(ctx, req, &a, graphql.UseIdempotencyKey("key-1"), graphql.UseMetricsName("touch"))
+	Expect(a.ID).Should(BeEmpty())
+	Expect(run()).Should(Succeed())
+	Expect(a.ID).Should(Equal("1"))
+	Expect(req.IdempotencyKey()).Should(BeEmpty())
+	Expect(req.MetricsName()).Should(Equal("Touch"))
+
+	Expect(client.RunFunc(ctx, req, &b)()).Should(Succeed())
+	Expect(b.ID).Should(Equal("1"))
+	Expect(keys).Should(Equal([]string{"key-1", ""}))
+}
@@ -0,0 +1,561 @@
+package graphql
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ScalarEncoder encodes a Go value sent in variables into the value
+// sent for a GraphQL scalar, such as a string or number.
+type ScalarEncoder func(v interface{}) (interface{}, error)
+
+// WithScalarEncoder sets how variable values of the same type as example
+// are encoded, wherever they appear in the variables: directly, in
+// lists and maps, in Inputs, or in struct fields.
+//  NewClient(endpoint, WithScalarEncoder(money.Amount{}, func(v interface{}) (interface{}, error) {
+//      return v.(money.Amount).String(), nil
+//  }))
+func WithScalarEncoder(example interface{}, enc ScalarEncoder) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.scalars.set(reflect.TypeOf(example), enc)
+	})
+}
+
+// WithVariableTimeFormat sets how time.Time values in variables are
+// encoded. TimeRFC3339 and TimeISO8601 encode RFC 3339 strings with
+// nanoseconds, as encoding/json does; TimeDate encodes the date only,
+// and TimeUnix and TimeUnixMillis encode numbers.
+//  NewClient(endpoint, WithVariableTimeFormat(TimeUnixMillis))
+func WithVariableTimeFormat(format TimeFormat) ClientOption {
+	return WithScalarEncoder(time.Time{}, func(v interface{}) (interface{}, error) {
+		return encodeTime(v.(time.Time), format)
+	})
+}
+
+func encodeTime(t time.Time, format TimeFormat) (interface{}, error) {
+	switch format {
+	case "", TimeRFC3339, TimeISO8601:
+		return t.Format(time.RFC3339Nano), nil
+	case TimeDate:
+		return t.Format(dateLayout), nil
+	case TimeUnix:
+		return t.Unix(), nil
+	case TimeUnixMillis:
+		return t.UnixNano() / int64(time.Millisecond), nil
+	}
+	return nil, errors.Errorf("graphql: unknown time format %q", string(format))
+}
+
+// BytesEncoding is an encoding of []byte values in variables.
+type BytesEncoding string
+
+// Byte slice encodings.
+const (
+	// BytesBase64 encodes standard base64 strings, as encoding/json does
+	// without an encoding set.
+	BytesBase64 BytesEncoding = "base64"
+	// BytesBase64URL encodes unpadded URL-safe base64 strings.
+	BytesBase64URL BytesEncoding = "base64url"
+	// BytesHex encodes hexadecimal strings.
+	BytesHex BytesEncoding = "hex"
+)
+
+// WithBytesEncoding sets how []byte values in variables are encoded.
+//  NewClient(endpoint, WithBytesEncoding(BytesHex))
+func WithBytesEncoding(encoding BytesEncoding) ClientOption {
+	return WithScalarEncoder([]byte(nil), func(v interface{}) (interface{}, error) {
+		b := v.([]byte)
+		if b == nil {
+			return nil, nil
+		}
+		switch encoding {
+		case BytesBase64:
+			return base64.StdEncoding.EncodeToString(b), nil
+		case BytesBase64URL:
+			return base64.RawURLEncoding.EncodeToString(b), nil
+		case BytesHex:
+			return hex.EncodeToString(b), nil
+		}
+		return nil, errors.Errorf("graphql: unknown bytes encoding %q", string(encoding))
+	})
+}
+
+// BigNumberEncoding is an encoding of math/big values in variables.
+type BigNumberEncoding string
+
+// Big number encodings.
+const (
+	// BigNumberString encodes decimal strings, which every GraphQL server
+	// can read without losing precision.
+	BigNumberString BigNumberEncoding = "string"
+	// BigNumberNumber encodes JSON numbers.
+	BigNumberNumber BigNumberEncoding = "number"
+)
+
+// WithBigNumberEncoding sets how *big.Int, *big.Float and *big.Rat
+// values in variables are encoded. Rats are encoded as decimals, rounded
+// to 20 places if they have no exact decimal form. Without it, they are
+// encoded as encoding/json encodes them: Ints as numbers, and Floats and
+// Rats as strings, Rats as fractions.
+//  NewClient(endpoint, WithBigNumberEncoding(BigNumberNumber))
+func WithBigNumberEncoding(encoding BigNumberEncoding) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.scalars.setBigNumbers(encoding)
+	})
+}
+
+func (r *scalarRegistry) setBigNumbers(encoding BigNumberEncoding) {
+	encoder := func(text func(v interface{}) string) ScalarEncoder {
+		return func(v interface{}) (interface{}, error) {
+			if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+				return nil, nil
+			}
+			s := text(v)
+			switch encoding {
+			case BigNumberString:
+				return s, nil
+			case BigNumberNumber:
+				return json.Number(s), nil
+			}
+			return nil, errors.Errorf("graphql: unknown big number encoding %q", string(encoding))
+		}
+	}
+	r.set(reflect.TypeOf(&big.Int{}), encoder(func(v interface{}) string {
+		return v.(*big.Int).String()
+	}))
+	r.set(reflect.TypeOf(&big.Float{}), encoder(func(v interface{}) string {
+		return v.(*big.Float).Text('f', -1)
+	}))
+	r.set(reflect.TypeOf(&big.Rat{}), encoder(func(v interface{}) string {
+		rat := v.(*big.Rat)
+		if rat.IsInt() {
+			return rat.Num().String()
+		}
+		s := rat.FloatString(20)
+		s = strings.TrimRight(s, "0")
+		return strings.TrimSuffix(s, ".")
+	}))
+}
+
+// scalarRegistry holds the encoders for variable values by type.
+type scalarRegistry struct {
+	encoders map[reflect.Type]ScalarEncoder
+
+	mu sync.Mutex
+	// needs caches whether values of a type can contain values with
+	// encoders.
+	needs map[reflect.Type]bool
+}
+
+func (r *scalarRegistry) set(t reflect.Type, enc ScalarEncoder) {
+	if r.encoders == nil {
+		r.encoders = make(map[reflect.Type]ScalarEncoder)
+	}
+	r.encoders[t] = enc
+}
+
+// encodeVariables gets the request with its variables encoded by the
+// client's scalar encoders.
+func (c *Client) encodeVariables(req *Request) (*Request, error) {
+	if len(c.scalars.encoders) == 0 || len(req.Variables) == 0 {
+		return req, nil
+	}
+	out := req.clone()
+	for name, v := range req.Variables {
+		ev, err := c.scalars.encode(reflect.ValueOf(v))
+		if err != nil {
+			return nil, errors.Wrapf(err, "encoding variable %q", name)
+		}
+		out.Variables[name] = ev
+	}
+	return out, nil
+}
+
+var (
+	inputType     = reflect.TypeOf(&Input{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// encode converts v to a value that encodes to the same JSON as v,
+// except that values with encoders are replaced by their encodings.
+// Values that can't contain values with encoders are returned as-is.
+func (r *scalarRegistry) encode(v reflect.Value) (interface{}, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+	t := v.Type()
+	if enc, ok := r.encoders[t]; ok {
+		return enc(v.Interface())
+	}
+	if t == inputType {
+		if v.IsNil() {
+			return nil, nil
+		}
+		return r.encode(reflect.ValueOf(v.Interface().(*Input).fields))
+	}
+	if !r.need(t) {
+		// encoding/json calls pointer methods of addressable values
+		if t.Kind() != reflect.Ptr && v.CanAddr() && reflect.PtrTo(t).Implements(marshalerType) {
+			return v.Addr().Interface(), nil
+		}
+		if t.Kind() != reflect.Ptr && v.CanAddr() && reflect.PtrTo(t).Implements(textMarshalerType) {
+			return v.Addr().Interface(), nil
+		}
+		return v.Interface(), nil
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return r.encode(v.Elem())
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			ev, err := r.encode(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			key, err := mapKey(iter.Key())
+			if err != nil {
+				return nil, err
+			}
+			m[key] = ev
+		}
+		return m, nil
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && v.IsNil() {
+			return nil, nil
+		}
+		list := make([]interface{}, v.Len())
+		for i := range list {
+			ev, err := r.encode(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			list[i] = ev
+		}
+		return list, nil
+	case reflect.Struct:
+		m := make(map[string]interface{})
+		var err error
+		for _, f := range encodeFields(t) {
+			fv, ok := fieldByIndex(v, f.index)
+			if !ok || (f.omitEmpty && isEmptyValue(fv)) {
+				continue
+			}
+			if f.quoted {
+				m[f.name], err = quote(fv)
+				if err != nil {
+					return nil, err
+				}
+				continue
+			}
+			ev, err := r.encode(fv)
+			if err != nil {
+				return nil, err
+			}
+			m[f.name] = ev
+		}
+		return m, nil
+	}
+	return v.Interface(), nil
+}
+
+// mapKey gets the string a map key is encoded as, as encoding/json does.
+func mapKey(key reflect.Value) (string, error) {
+	if key.Kind() == reflect.String {
+		return key.String(), nil
+	}
+	if tm, ok := key.Interface().(encoding.TextMarshaler); ok {
+		if key.Kind() == reflect.Ptr && key.IsNil() {
+			return "", nil
+		}
+		b, err := tm.MarshalText()
+		return string(b), err
+	}
+	return fmt.Sprint(key.Interface()), nil
+}
+
+// quote gets the value of a field with the string option: its JSON
+// encoding, as a string.
+func quote(v reflect.Value) (interface{}, error) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// marshals reports whether encoding/json encodes values of type t with
+// their own MarshalJSON or MarshalText methods.
+func marshals(t reflect.Type) bool {
+	pt := reflect.PtrTo(t)
+	return t.Implements(marshalerType) || t.Implements(textMarshalerType) ||
+		pt.Implements(marshalerType) || pt.Implements(textMarshalerType)
+}
+
+// need reports whether values of type t can contain values with
+// encoders.
+func (r *scalarRegistry) need(t reflect.Type) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.needs == nil {
+		r.needs = make(map[reflect.Type]bool)
+	}
+	need, _ := r.needLocked(t, make(map[reflect.Type]bool))
+	// with nothing further up being checked, the answer is final
+	r.needs[t] = need
+	return need
+}
+
+// needLocked is need. visiting holds the types being checked further up,
+// which are taken not to need encoding, so that recursive types
+// terminate. Answers that depend on that are not final, and aren't
+// cached; final reports whether the answer is.
+func (r *scalarRegistry) needLocked(t reflect.Type, visiting map[reflect.Type]bool) (need, final bool) {
+	if need, ok := r.needs[t]; ok {
+		return need, true
+	}
+	if visiting[t] {
+		return false, false
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+	final = true
+	if _, ok := r.encoders[t]; ok || t == inputType {
+		need = true
+	} else {
+		switch t.Kind() {
+		case reflect.Interface:
+			need = true
+		case reflect.Ptr:
+			need, final = r.needLocked(t.Elem(), visiting)
+		case reflect.Slice, reflect.Array, reflect.Map:
+			// encoding/json encodes marshalers themselves
+			if !marshals(t) {
+				need, final = r.needLocked(t.Elem(), visiting)
+			}
+		case reflect.Struct:
+			if marshals(t) {
+				break
+			}
+			for _, f := range encodeFields(t) {
+				if f.quoted {
+					continue
+				}
+				fieldNeed, fieldFinal := r.needLocked(t.FieldByIndex(f.index).Type, visiting)
+				if fieldNeed {
+					need = true
+					break
+				}
+				final = final && fieldFinal
+			}
+		}
+	}
+	// a type that needs encoding does whatever is assumed of the others
+	if need || final {
+		r.needs[t] = need
+		return need, true
+	}
+	return false, false
+}
+
+// encodeField is a struct field as encoding/json encodes it.
+type encodeField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+	// quoted is whether the field has the string option, which encodes
+	// its JSON as a string.
+	quoted bool
+	// tagged is whether the name is from the field's tag.
+	tagged bool
+}
+
+var encodeFieldsCache sync.Map // map[reflect.Type][]encodeField
+
+// encodeFields gets the fields of the struct type t that encoding/json
+// encodes, including those of embedded structs, following its rules for
+// fields of the same name.
+func encodeFields(t reflect.Type) []encodeField {
+	if fields, ok := encodeFieldsCache.Load(t); ok {
+		return fields.([]encodeField)
+	}
+	fields := dominantFields(jsonFields(t))
+	encodeFieldsCache.Store(t, fields)
+	return fields
+}
+
+// jsonFields gets every field encoding/json might encode from the
+// struct type t, searching embedded structs breadth first. Fields with
+// the same name at the same depth because a struct is embedded more
+// than once are listed twice, so that they cancel out.
+func jsonFields(t reflect.Type) []encodeField {
+	type embedded struct {
+		typ   reflect.Type
+		index []int
+	}
+	var fields []encodeField
+	next := []embedded{{typ: t}}
+	var count, nextCount map[reflect.Type]int
+	visited := make(map[reflect.Type]bool)
+	for len(next) > 0 {
+		current := next
+		next = nil
+		count, nextCount = nextCount, make(map[reflect.Type]int)
+		for _, e := range current {
+			if visited[e.typ] {
+				continue
+			}
+			visited[e.typ] = true
+			for i := 0; i < e.typ.NumField(); i++ {
+				sf := e.typ.Field(i)
+				ft := sf.Type
+				if ft.Name() == "" && ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+				if sf.Anonymous {
+					if sf.PkgPath != "" && ft.Kind() != reflect.Struct {
+						continue
+					}
+				} else if sf.PkgPath != "" {
+					continue // unexported
+				}
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				opts := strings.Split(tag, ",")
+				name := opts[0]
+				index := make([]int, len(e.index)+1)
+				copy(index, e.index)
+				index[len(e.index)] = i
+				if name == "" && sf.Anonymous && ft.Kind() == reflect.Struct {
+					nextCount[ft]++
+					if nextCount[ft] == 1 {
+						next = append(next, embedded{typ: ft, index: index})
+					}
+					continue
+				}
+				f := encodeField{name: name, index: index, tagged: name != ""}
+				if name == "" {
+					f.name = sf.Name
+				}
+				for _, opt := range opts[1:] {
+					switch opt {
+					case "omitempty":
+						f.omitEmpty = true
+					case "string":
+						switch ft.Kind() {
+						case reflect.Bool,
+							reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+							reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+							reflect.Float32, reflect.Float64,
+							reflect.String:
+							f.quoted = true
+						}
+					}
+				}
+				fields = append(fields, f)
+				if count[e.typ] > 1 {
+					fields = append(fields, f)
+				}
+			}
+		}
+	}
+	return fields
+}
+
+// dominantFields keeps, of the fields with each name, the one
+// encoding/json encodes: the shallowest, or the tagged one of the
+// shallowest. Names with no single such field are dropped. The fields
+// are returned in the order of the struct.
+func dominantFields(fields []encodeField) []encodeField {
+	sort.SliceStable(fields, func(i, j int) bool {
+		a, b := fields[i], fields[j]
+		if a.name != b.name {
+			return a.name < b.name
+		}
+		if len(a.index) != len(b.index) {
+			return len(a.index) < len(b.index)
+		}
+		return a.tagged && !b.tagged
+	})
+	var out []encodeField
+	for i := 0; i < len(fields); {
+		j := i + 1
+		for j < len(fields) && fields[j].name == fields[i].name {
+			j++
+		}
+		same := fields[i:j]
+		if len(same) == 1 || len(same[0].index) < len(same[1].index) || same[0].tagged != same[1].tagged {
+			out = append(out, same[0])
+		}
+		i = j
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i].index, out[j].index
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	return out
+}
+
+// fieldByIndex gets the field of struct v, reporting false if it is in
+// a nil embedded pointer.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,297 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+// variablesServer records the variables of the last request it received.
+func variablesServer(vars *map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphql.Request
+		json.NewDecoder(r.Body).Decode(&req)
+		*vars = req.Variables
+		io.WriteString(w, `{"data":{}}`)
+	}))
+}
+
+func TestVariableTimeFormat(t *testing.T) {
+	RegisterTestingT(t)
+	var vars map[string]interface{}
+	srv := variablesServer(&vars)
+	defer srv.Close()
+	when := time.Date(2020, 5, 17, 10, 30, 0, 0, time.UTC)
+	type window struct {
+		From  time.Time  `json:"from"`
+		To    *time.Time `json:"to,omitempty"`
+		Label string     `json:"label"`
+	}
+
+	for _, test := range []struct {
+		format graphql.TimeFormat
+		want   interface{}
+	}{
+		{graphql.TimeRFC3339, "2020-05-17T10:30:00Z"},
+		{graphql.TimeDate, "2020-05-17"},
+		{graphql.TimeUnix, float64(1589711400)},
+		{graphql.TimeUnixMillis, float64(1589711400000)},
+	} {
+		client := graphql.NewClient(srv.URL, graphql.WithVariableTimeFormat(test.format))
+		req := graphql.NewRequest(`query ($at: DateTime, $window: Window, $input: EventInput) { events { id } }`)
+		req.Var("at", when)
+		req.Var("window", window{From: when, Label: "may"})
+		req.Var("input", graphql.NewInput("EventInput").Set("times", []time.Time{when}))
+		err := client.Run(context.Background(), req, nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(vars).Should(Equal(map[string]interface{}{
+			"at":     test.want,
+			"window": map[string]interface{}{"from": test.want, "label": "may"},
+			"input":  map[string]interface{}{"times": []interface{}{test.want}},
+		}), string(test.format))
+		Expect(req.Variables["at"]).Should(Equal(when))
+	}
+
+	client := graphql.NewClient(srv.URL, graphql.WithVariableTimeFormat("fortnights"))
+	req := graphql.NewRequest(`query ($at: DateTime) { events { id } }`)
+	req.Var("at", when)
+	err := client.Run(context.Background(), req, nil)
+	Expect(err).Should(MatchError(`encoding variable "at": graphql: unknown time format "fortnights"`))
+}
+
+func TestDecodeTimeDate(t *testing.T) {
+	RegisterTestingT(t)
+	var resp struct {
+		Day time.Time
+	}
+	client := bodyClient(`{"data":{"day":"2020-05-17"}}`, graphql.WithTimeFormat(graphql.TimeDate))
+	err := client.Run(context.Background(), graphql.NewRequest(`{ day }`), &resp)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(resp.Day).Should(Equal(time.Date(2020, 5, 17, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestBytesAndBigNumberVariables(t *testing.T) {
+	RegisterTestingT(t)
+	var vars map[string]interface{}
+	srv := variablesServer(&vars)
+	defer srv.Close()
+	n, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	req := graphql.NewRequest(`query ($data: Bytes, $n: BigInt, $f: BigDecimal, $r: BigDecimal, $raw: JSON) { a }`)
+	req.Var("data", []byte{0xfb, 0xff})
+	req.Var("n", n)
+	req.Var("f", big.NewFloat(1.5))
+	req.Var("r", big.NewRat(1, 3))
+	req.Var("raw", json.RawMessage(`{"x":1}`))
+
+	client := graphql.NewClient(srv.URL)
+	err := client.Run(context.Background(), req, nil)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(vars).Should(Equal(map[string]interface{}{
+		"data": "+/8=",
+		"n":    1.2345678901234568e29,
+		"f":    "1.5",
+		"r":    "1/3",
+		"raw":  map[string]interface{}{"x": float64(1)},
+	}))
+
+	client = graphql.NewClient(srv.URL, graphql.WithBigNumberEncoding(graphql.BigNumberString))
+	err = client.Run(context.Background(), req, nil)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(vars["n"]).Should(Equal("123456789012345678901234567890"))
+	Expect(vars["f"]).Should(Equal("1.5"))
+	Expect(vars["r"]).Should(Equal("0.33333333333333333333"))
+
+	client = graphql.NewClient(srv.URL,
+		graphql.WithBytesEncoding(graphql.BytesHex),
+		graphql.WithBigNumberEncoding(graphql.BigNumberNumber),
+	)
+	req.Variables["r"] = big.NewRat(3, 4)
+	err = client.Run(context.Background(), req, nil)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(vars["data"]).Should(Equal("fbff"))
+	Expect(vars["n"]).Should(BeNumerically("==", 1.2345678901234568e29))
+	Expect(vars["r"]).Should(Equal(0.75))
+
+	client = graphql.NewClient(srv.URL, graphql.WithBytesEncoding(graphql.BytesBase64URL))
+	err = client.Run(context.Background(), req, nil)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(vars["data"]).Should(Equal("-_8"))
+}
+
+// recursive types for TestVariableTimeFormatRecursive
+type timedA struct {
+	B  *timedB   `json:"b,omitempty"`
+	At time.Time `json:"at"`
+}
+
+type timedB struct {
+	A *timedA `json:"a,omitempty"`
+}
+
+// stamp is encoded as text, whatever its fields.
+type stamp struct {
+	At time.Time
+}
+
+func (s stamp) MarshalText() ([]byte, error) {
+	return []byte("stamp"), nil
+}
+
+func TestVariableTimeFormatRecursive(t *testing.T) {
+	RegisterTestingT(t)
+	var vars map[string]interface{}
+	srv := variablesServer(&vars)
+	defer srv.Close()
+	when := time.Date(2020, 5, 17, 10, 30, 0, 0, time.UTC)
+	client := graphql.NewClient(srv.URL, graphql.WithVariableTimeFormat(graphql.TimeUnix))
+	req := graphql.NewRequest(`query ($v: Input) { events { id } }`)
+	// checking A first checks B while A is being checked
+	req.Var("v", timedA{At: when})
+	Expect(client.Run(context.Background(), req, nil)).Should(Succeed())
+	Expect(vars["v"]).Should(Equal(map[string]interface{}{"at": float64(1589711400)}))
+
+	req.Var("v", timedB{A: &timedA{At: when}})
+	Expect(client.Run(context.Background(), req, nil)).Should(Succeed())
+	Expect(vars["v"]).Should(Equal(map[string]interface{}{"a": map[string]interface{}{"at": float64(1589711400)}}))
+
+	req.Var("v", stamp{At: when})
+	Expect(client.Run(context.Background(), req, nil)).Should(Succeed())
+	Expect(vars["v"]).Should(Equal("stamp"))
+}
+
+// rawVariablesServer records the encoded variables of the last request
+// it received.
+func rawVariablesServer(vars *json.RawMessage) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Variables json.RawMessage `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		*vars = req.Variables
+		io.WriteString(w, `{"data":{}}`)
+	}))
+}
+
+// customTags encodes as a string, whatever its items.
+type customTags []interface{}
+
+func (customTags) MarshalJSON() ([]byte, error) {
+	return []byte(`"custom"`), nil
+}
+
+type quotedInput struct {
+	ID    int    `json:"id,string"`
+	Extra string `json:"extra"`
+}
+
+func TestDefaultVariablesEncoding(t *testing.T) {
+	RegisterTestingT(t)
+	var vars json.RawMessage
+	srv := rawVariablesServer(&vars)
+	defer srv.Close()
+	req := graphql.NewRequest(`query ($in: Input, $tags: Tags, $data: Bytes) { a }`)
+	req.Var("in", quotedInput{ID: 5, Extra: "x"})
+	req.Var("tags", customTags{1})
+	req.Var("data", []byte{0xfb, 0xff})
+
+	// without encoders, variables are sent as encoding/json encodes them
+	client := graphql.NewClient(srv.URL)
+	Expect(client.Run(context.Background(), req, nil)).Should(Succeed())
+	want, err := json.Marshal(req.Variables)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(string(vars)).Should(Equal(string(want)))
+}
+
+// types for TestVariablesEncodingMatchesEncodingJSON
+
+type nameA struct {
+	Name string
+	Both string
+	At   time.Time
+}
+
+type nameB struct {
+	Name string
+	Both string `json:"Both"`
+}
+
+type outer struct {
+	nameA
+	*nameB
+	Name    string   `json:"name"`
+	ID      int      `json:"id,string"`
+	Ratio   *float64 `json:"ratio,string,omitempty"`
+	Label   string   `json:"label,string"`
+	Skipped string   `json:"-"`
+	Empty   string   `json:",omitempty"`
+	At      time.Time
+	Tags    customTags
+	Counts  countMap
+	Ref     refMarshaler
+	Keys    map[textKey]time.Time
+}
+
+// countMap encodes as its size.
+type countMap map[string]interface{}
+
+func (m countMap) MarshalJSON() ([]byte, error) {
+	return json.Marshal(len(m))
+}
+
+// refMarshaler encodes as a reference, with a pointer receiver.
+type refMarshaler struct {
+	At time.Time
+}
+
+func (r *refMarshaler) MarshalJSON() ([]byte, error) {
+	return []byte(`"ref"`), nil
+}
+
+type textKey struct {
+	k string
+}
+
+func (k textKey) MarshalText() ([]byte, error) {
+	return []byte("key-" + k.k), nil
+}
+
+func TestVariablesEncodingMatchesEncodingJSON(t *testing.T) {
+	RegisterTestingT(t)
+	var vars json.RawMessage
+	srv := rawVariablesServer(&vars)
+	defer srv.Close()
+	when := time.Date(2020, 5, 17, 10, 30, 0, 0, time.UTC)
+	ratio := 0.5
+	v := &outer{
+		nameA:  nameA{Name: "a", Both: "a", At: when},
+		nameB:  &nameB{Name: "b", Both: "b"},
+		Name:   "outer",
+		ID:     5,
+		Ratio:  &ratio,
+		Label:  "x",
+		At:     when,
+		Tags:   customTags{when},
+		Counts: countMap{"at": when},
+		Ref:    refMarshaler{At: when},
+		Keys:   map[textKey]time.Time{{"a"}: when},
+	}
+	req := graphql.NewRequest(`query ($in: Input, $list: [Input]) { a }`)
+	req.Var("in", v)
+	req.Var("list", []interface{}{*v, map[string]interface{}{"at": when}})
+
+	// the time format matches encoding/json's, so that the variables are
+	// encoded by the client's encoders but should come out the same
+	client := graphql.NewClient(srv.URL, graphql.WithVariableTimeFormat(graphql.TimeRFC3339))
+	Expect(client.Run(context.Background(), req, nil)).Should(Succeed())
+	want, err := json.Marshal(req.Variables)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(string(vars)).Should(MatchJSON(want))
+}
@@ -0,0 +1,269 @@
+package graphql
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+)
+
+// TypeKind is the kind of a schema type, such as OBJECT or NON_NULL.
+type TypeKind string
+
+// Type kinds, as named by introspection.
+const (
+	KindScalar      TypeKind = "SCALAR"
+	KindObject      TypeKind = "OBJECT"
+	KindInterface   TypeKind = "INTERFACE"
+	KindUnion       TypeKind = "UNION"
+	KindEnum        TypeKind = "ENUM"
+	KindInputObject TypeKind = "INPUT_OBJECT"
+	KindList        TypeKind = "LIST"
+	KindNonNull     TypeKind = "NON_NULL"
+)
+
+// Schema describes the types of a GraphQL service.
+// It is read from the result of an introspection query, and encodes back
+// to the same form, so snapshots can be vendored as JSON files.
+type Schema struct {
+	// QueryType, MutationType and SubscriptionType are the names of the
+	// root operation types; MutationType and SubscriptionType are empty
+	// if the service doesn't support them.
+	QueryType        string
+	MutationType     string
+	SubscriptionType string
+	Types            []*SchemaType
+
+	index map[string]*SchemaType
+}
+
+// SchemaType is a named type in a Schema.
+type SchemaType struct {
+	Kind          TypeKind       `json:"kind"`
+	Name          string         `json:"name"`
+	Description   string         `json:"description,omitempty"`
+	Fields        []*SchemaField `json:"fields"`
+	InputFields   []*InputValue  `json:"inputFields"`
+	Interfaces    []*TypeRef     `json:"interfaces"`
+	PossibleTypes []*TypeRef     `json:"possibleTypes"`
+	EnumValues    []*EnumValue   `json:"enumValues"`
+}
+
+// SchemaField is a field of an object or interface type.
+type SchemaField struct {
+	Name              string        `json:"name"`
+	Description       string        `json:"description,omitempty"`
+	Args              []*InputValue `json:"args"`
+	Type              *TypeRef      `json:"type"`
+	IsDeprecated      bool          `json:"isDeprecated"`
+	DeprecationReason string        `json:"deprecationReason,omitempty"`
+}
+
+// InputValue is an argument, or a field of an input object type.
+type InputValue struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Type        *TypeRef `json:"type"`
+	// DefaultValue is the default in GraphQL syntax, or nil if there
+	// is none.
+	DefaultValue *string `json:"defaultValue"`
+}
+
+// EnumValue is a value of an enum type.
+type EnumValue struct {
+	Name              string `json:"name"`
+	Description       string `json:"description,omitempty"`
+	IsDeprecated      bool   `json:"isDeprecated"`
+	DeprecationReason string `json:"deprecationReason,omitempty"`
+}
+
+// TypeRef is a reference to a type; a named type, or a list or non-null
+// wrapper of another TypeRef.
+type TypeRef struct {
+	Kind   TypeKind `json:"kind"`
+	Name   string   `json:"name,omitempty"`
+	OfType *TypeRef `json:"ofType,omitempty"`
+}
+
+// String gets the type in GraphQL syntax, such as [ID!]!.
+func (t *TypeRef) String() string {
+	switch t.Kind {
+	case KindList:
+		return "[" + t.OfType.String() + "]"
+	case KindNonNull:
+		return t.OfType.String() + "!"
+	}
+	return t.Name
+}
+
+// NamedType gets the name of the type, without list and non-null
+// wrappers.
+func (t *TypeRef) NamedType() string {
+	for t.OfType != nil {
+		t = t.OfType
+	}
+	return t.Name
+}
+
+// ParseSchema reads a Schema from the JSON result of an introspection
+// query. The result may be the whole response, or just its data.
+func ParseSchema(b []byte) (*Schema, error) {
+	var result struct {
+		Data struct {
+			Schema *Schema `json:"__schema"`
+		} `json:"data"`
+		Schema *Schema `json:"__schema"`
+	}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, errors.Wrap(err, "graphql: decoding schema")
+	}
+	schema := result.Schema
+	if schema == nil {
+		schema = result.Data.Schema
+	}
+	if schema == nil {
+		return nil, errors.New("graphql: no __schema in introspection result")
+	}
+	return schema, nil
+}
+
+// LoadSchema reads a Schema from the JSON result of an introspection
+// query.
+func LoadSchema(r io.Reader) (*Schema, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "graphql: reading schema")
+	}
+	return ParseSchema(b)
+}
+
+type introspectionSchema struct {
+	QueryType        *introspectionName `json:"queryType"`
+	MutationType     *introspectionName `json:"mutationType"`
+	SubscriptionType *introspectionName `json:"subscriptionType"`
+	Types            []*SchemaType      `json:"types"`
+}
+
+type introspectionName struct {
+	Name string `json:"name"`
+}
+
+func newIntrospectionName(name string) *introspectionName {
+	if name == "" {
+		return nil
+	}
+	return &introspectionName{Name: name}
+}
+
+// UnmarshalJSON reads the __schema object of an introspection result.
+func (s *Schema) UnmarshalJSON(b []byte) error {
+	var in introspectionSchema
+	if err := json.Unmarshal(b, &in); err != nil {
+		return err
+	}
+	*s = Schema{Types: in.Types}
+	if in.QueryType != nil {
+		s.QueryType = in.QueryType.Name
+	}
+	if in.MutationType != nil {
+		s.MutationType = in.MutationType.Name
+	}
+	if in.SubscriptionType != nil {
+		s.SubscriptionType = in.SubscriptionType.Name
+	}
+	s.index = make(map[string]*SchemaType, len(s.Types))
+	for _, t := range s.Types {
+		s.index[t.Name] = t
+	}
+	return nil
+}
+
+// MarshalJSON writes the __schema object of an introspection result.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	return json.Marshal(introspectionSchema{
+		QueryType:        newIntrospectionName(s.QueryType),
+		MutationType:     newIntrospectionName(s.MutationType),
+		SubscriptionType: newIntrospectionName(s.SubscriptionType),
+		Types:            s.Types,
+	})
+}
+
+// Type gets the named type, or nil if the schema has no such type.
+func (s *Schema) Type(name string) *SchemaType {
+	if s.index != nil {
+		return s.index[name]
+	}
+	for _, t := range s.Types {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// RootType gets the root type for the operation type, such as
+// "mutation", or nil if the service doesn't support it.
+func (s *Schema) RootType(opType string) *SchemaType {
+	switch opType {
+	case "query":
+		return s.Type(s.QueryType)
+	case "mutation":
+		return s.Type(s.MutationType)
+	case "subscription":
+		return s.Type(s.SubscriptionType)
+	}
+	return nil
+}
+
+// Field gets the named field, or nil if the type has no such field.
+func (t *SchemaType) Field(name string) *SchemaField {
+	for _, f := range t.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// InputField gets the named input field, or nil if the type has no
+// such field.
+func (t *SchemaType) InputField(name string) *InputValue {
+	return findInputValue(t.InputFields, name)
+}
+
+// EnumValue gets the named enum value, or nil if the type has no such
+// value.
+func (t *SchemaType) EnumValue(name string) *EnumValue {
+	for _, v := range t.EnumValues {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// Arg gets the named argument, or nil if the field has no such argument.
+func (f *SchemaField) Arg(name string) *InputValue {
+	return findInputValue(f.Args, name)
+}
+
+func findInputValue(values []*InputValue, name string) *InputValue {
+	for _, v := range values {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// isComposite reports whether fields can be selected on the type.
+func (t *SchemaType) isComposite() bool {
+	return t.Kind == KindObject || t.Kind == KindInterface || t.Kind == KindUnion
+}
+
+// isInput reports whether the type can be used for variables and
+// arguments.
+func (t *SchemaType) isInput() bool {
+	return t.Kind == KindScalar || t.Kind == KindEnum || t.Kind == KindInputObject
+}
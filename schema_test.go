@@ -0,0 +1,91 @@
+package graphql_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func loadSchema() *graphql.Schema {
+	b, err := ioutil.ReadFile("testdata/schema.json")
+	Expect(err).ShouldNot(HaveOccurred())
+	schema, err := graphql.ParseSchema(b)
+	Expect(err).ShouldNot(HaveOccurred())
+	return schema
+}
+
+func TestParseSchema(t *testing.T) {
+	RegisterTestingT(t)
+	schema := loadSchema()
+	Expect(schema.QueryType).Should(Equal("Query"))
+	Expect(schema.MutationType).Should(Equal("Mutation"))
+	Expect(schema.SubscriptionType).Should(BeEmpty())
+	Expect(schema.RootType("subscription")).Should(BeNil())
+
+	users := schema.Type("Query").Field("users")
+	Expect(users.Type.String()).Should(Equal("[User!]!"))
+	Expect(users.Type.NamedType()).Should(Equal("User"))
+	Expect(*users.Arg("first").DefaultValue).Should(Equal("10"))
+	Expect(schema.Type("User").Field("email").DeprecationReason).Should(Equal("Use emails."))
+
+	b, err := json.Marshal(map[string]interface{}{"__schema": schema})
+	Expect(err).ShouldNot(HaveOccurred())
+	again, err := graphql.ParseSchema(b)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(again.Type("Role").EnumValue("ADMIN")).ShouldNot(BeNil())
+	Expect(again.MutationType).Should(Equal("Mutation"))
+
+	_, err = graphql.ParseSchema([]byte(`{"data":{}}`))
+	Expect(err).Should(HaveOccurred())
+}
+
+func TestSchemaValidate(t *testing.T) {
+	RegisterTestingT(t)
+	schema := loadSchema()
+
+	errs := schema.Validate(`
+		query GetUser($id: ID!, $role: Role) {
+			user(id: $id) { ...UserFields friends { __typename } }
+			users(role: $role) { id }
+			search(filter: {role: ADMIN}) { ... on User { name } }
+		}
+		fragment UserFields on User { id name @include(if: true) }
+		mutation { createUser(input: {name: "Mat"}) { id } }
+	`)
+	Expect(errs).Should(BeEmpty())
+
+	errs = schema.Validate(`query GetUser($id: ID, $unused: Int) {
+	user(id: $id) { nickname }
+	users(first: "ten", role: OWNER) { ...Missing }
+	createdAt
+}`)
+	Expect(errs).Should(Equal(graphql.Errors{
+		{Message: `Variable "$id" of type "ID" used in position expecting type "ID!"`, Locations: []graphql.Location{{Line: 2, Column: 2}}},
+		{Message: `Cannot query field "nickname" on type "User"`, Locations: []graphql.Location{{Line: 2, Column: 18}}},
+		{Message: `Int cannot represent value: "ten"`, Locations: []graphql.Location{{Line: 3, Column: 2}}},
+		{Message: `Value "OWNER" does not exist in "Role" enum`, Locations: []graphql.Location{{Line: 3, Column: 2}}},
+		{Message: `Unknown fragment "Missing"`, Locations: []graphql.Location{{Line: 3, Column: 37}}},
+		{Message: `Cannot query field "createdAt" on type "Query"`, Locations: []graphql.Location{{Line: 4, Column: 2}}},
+		{Message: `Variable "$unused" is never used in operation "GetUser"`, Locations: []graphql.Location{{Line: 1, Column: 24}}},
+	}))
+
+	errs = schema.Validate(`mutation { createUser(input: {nickname: "m"}) }`)
+	Expect(errs).Should(HaveLen(2))
+	Expect(errs[0].Message).Should(Equal(`Field "CreateUserInput.name" of required type "String!" was not provided`))
+	Expect(errs[1].Message).Should(Equal(`Field "createUser" of type "User" must have a selection of subfields`))
+
+	errs = schema.Validate(`{ user(id: 1) { ...UserFields } }`, `fragment UserFields on User { id age }`)
+	Expect(errs).Should(Equal(graphql.Errors{
+		{Message: `Cannot query field "age" on type "User" (in fragment "UserFields")`},
+	}))
+
+	errs = schema.Validate(`subscription { users { id } }`)
+	Expect(errs).Should(HaveLen(1))
+	Expect(errs.Error()).Should(Equal("graphql: Schema does not support subscription operations"))
+
+	errs = schema.Validate(`{ user(id: 1) {`)
+	Expect(errs).Should(HaveLen(1))
+}
@@ -0,0 +1,324 @@
+package graphql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// schemaRetryInterval is the longest a SchemaCache waits to introspect
+// an endpoint again after failing to.
+const schemaRetryInterval = time.Minute
+
+// schemaRefreshTimeout is how long a client waits for the stale schema
+// it refreshes in the background.
+const schemaRefreshTimeout = time.Minute
+
+// SchemaCache holds the introspected schemas of endpoints, so that
+// clients validating and coercing against the schema, and tools linting
+// or completing documents, share one introspection of each endpoint
+// instead of each making their own. Schemas older than the TTL are
+// revalidated with the ETag the server gave them, if any, so an
+// unchanged schema isn't sent again. It is safe for concurrent use.
+//  cache := graphql.NewSchemaCache(time.Hour, dir)
+//  users := graphql.NewClient(endpoint, graphql.WithSchemaCache(cache))
+type SchemaCache struct {
+	// TTL is how long a schema is used before it is revalidated.
+	TTL time.Duration
+	// Dir is the directory schemas are also kept in, so that they
+	// outlive the process; if it is "", they are only kept in memory.
+	Dir string
+	// Clock is the clock schemas are aged by; the system clock if nil.
+	Clock Clock
+
+	mu      sync.Mutex
+	entries map[string]*schemaCacheEntry
+}
+
+// schemaCacheEntry is the cached schema of an endpoint. Its fields are
+// guarded by the cache's mu; fetch is held while introspecting, so an
+// endpoint is introspected once however many callers need it.
+type schemaCacheEntry struct {
+	fetch   sync.Mutex
+	loaded  bool
+	schema  *Schema
+	etag    string
+	fetched time.Time
+	failed  time.Time
+	// refreshing is set while a stale schema is refreshed in the
+	// background.
+	refreshing bool
+}
+
+// NewSchemaCache makes a SchemaCache that revalidates schemas after ttl,
+// and keeps them in dir, if it isn't "".
+func NewSchemaCache(ttl time.Duration, dir string) *SchemaCache {
+	return &SchemaCache{
+		TTL:     ttl,
+		Dir:     dir,
+		entries: make(map[string]*schemaCacheEntry),
+	}
+}
+
+// Schema gets the schema of the client's endpoint, introspecting it with
+// the client if it isn't cached or is older than the TTL. If a stale
+// schema can't be revalidated, the error is returned with it.
+func (s *SchemaCache) Schema(ctx context.Context, client *Client) (*Schema, error) {
+	e := s.entry(client.endpoint)
+	e.fetch.Lock()
+	defer e.fetch.Unlock()
+	s.mu.Lock()
+	schema, etag, fetched := e.schema, e.etag, e.fetched
+	s.mu.Unlock()
+	if schema != nil && s.now().Sub(fetched) < s.TTL {
+		return schema, nil
+	}
+	req := NewRequest(IntrospectionQuery)
+	if schema != nil && etag != "" {
+		req.Header = http.Header{"If-None-Match": []string{etag}}
+	}
+	res, err := Do[struct {
+		Schema *Schema `json:"__schema"`
+	}](ctx, client, req)
+	if se, ok := errors.Cause(err).(*StatusError); ok && se.StatusCode == http.StatusNotModified && schema != nil {
+		s.store(client.endpoint, e, schema, etag)
+		return schema, nil
+	}
+	if err == nil && len(res.Errors) > 0 {
+		err = res.Errors[0]
+	} else if err == nil && res.Data.Schema == nil {
+		err = errors.New("graphql: no __schema in introspection result")
+	}
+	if err != nil {
+		s.mu.Lock()
+		e.failed = s.now()
+		s.mu.Unlock()
+		return schema, errors.Wrap(err, "introspecting schema")
+	}
+	s.store(client.endpoint, e, res.Data.Schema, res.Header.Get("ETag"))
+	return res.Data.Schema, nil
+}
+
+// Invalidate marks the cached schema of the endpoint stale, so that the
+// next call to Schema revalidates it. Clients using the cache keep using
+// the schema until it has been revalidated.
+func (s *SchemaCache) Invalidate(endpoint string) {
+	e := s.entry(endpoint)
+	s.mu.Lock()
+	e.fetched, e.failed = time.Time{}, time.Time{}
+	s.mu.Unlock()
+	if path := s.path(endpoint); path != "" {
+		os.Chtimes(path, time.Unix(0, 0), time.Unix(0, 0))
+	}
+}
+
+// cached gets the cached schema of the endpoint without introspecting
+// it, or nil if there is none.
+func (s *SchemaCache) cached(endpoint string) *Schema {
+	e := s.entry(endpoint)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return e.schema
+}
+
+// stale reports whether the schema of the endpoint should be fetched:
+// it is missing or older than the TTL, isn't being refreshed, and the
+// last failure to fetch it wasn't recent. If there is a schema to use
+// meanwhile, cached is set, and the caller is taken to be refreshing it
+// until it calls refreshed.
+func (s *SchemaCache) stale(endpoint string) (stale, cached bool) {
+	e := s.entry(endpoint)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	retry := s.TTL
+	if retry > schemaRetryInterval || retry <= 0 {
+		retry = schemaRetryInterval
+	}
+	if e.refreshing || !e.failed.IsZero() && now.Sub(e.failed) < retry {
+		return false, false
+	}
+	if e.schema == nil {
+		return true, false
+	}
+	if now.Sub(e.fetched) < s.TTL {
+		return false, false
+	}
+	e.refreshing = true
+	return true, true
+}
+
+// refreshed ends the refresh of the schema of the endpoint taken on by
+// a call to stale.
+func (s *SchemaCache) refreshed(endpoint string) {
+	e := s.entry(endpoint)
+	s.mu.Lock()
+	e.refreshing = false
+	s.mu.Unlock()
+}
+
+// entry gets the entry of the endpoint, loading it from Dir the first
+// time.
+func (s *SchemaCache) entry(endpoint string) *schemaCacheEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.entries == nil {
+		s.entries = make(map[string]*schemaCacheEntry)
+	}
+	e, ok := s.entries[endpoint]
+	if !ok {
+		e = &schemaCacheEntry{}
+		s.entries[endpoint] = e
+	}
+	if !e.loaded {
+		e.loaded = true
+		s.load(endpoint, e)
+	}
+	return e
+}
+
+// schemaFile is the form schemas are kept in on disk: an introspection
+// result, which ParseSchema reads, with the schema's ETag.
+type schemaFile struct {
+	ETag string `json:"etag,omitempty"`
+	Data struct {
+		Schema *Schema `json:"__schema"`
+	} `json:"data"`
+}
+
+// load reads the entry of the endpoint from Dir. The modification time
+// of the file is when the schema was fetched.
+func (s *SchemaCache) load(endpoint string, e *schemaCacheEntry) {
+	path := s.path(endpoint)
+	if path == "" {
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+	var f schemaFile
+	if json.Unmarshal(b, &f) != nil || f.Data.Schema == nil {
+		return
+	}
+	e.schema, e.etag, e.fetched = f.Data.Schema, f.ETag, info.ModTime()
+}
+
+// store sets the schema of the endpoint, fetched now, and writes it to
+// Dir. A failure to write it only costs an introspection later.
+func (s *SchemaCache) store(endpoint string, e *schemaCacheEntry, schema *Schema, etag string) {
+	s.mu.Lock()
+	now := s.now()
+	e.schema, e.etag, e.fetched, e.failed = schema, etag, now, time.Time{}
+	s.mu.Unlock()
+	path := s.path(endpoint)
+	if path == "" {
+		return
+	}
+	var f schemaFile
+	f.ETag = etag
+	f.Data.Schema = schema
+	b, err := json.Marshal(f)
+	if err != nil || os.MkdirAll(s.Dir, 0700) != nil {
+		return
+	}
+	// written whole and renamed, so readers never see part of a schema
+	tmp, err := ioutil.TempFile(s.Dir, ".schema")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(b)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		os.Chtimes(tmp.Name(), now, now)
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// path gets the file the schema of the endpoint is kept in, or "" if
+// schemas are only kept in memory.
+func (s *SchemaCache) path(endpoint string) string {
+	if s.Dir == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(endpoint))
+	return filepath.Join(s.Dir, hex.EncodeToString(sum[:8])+".json")
+}
+
+func (s *SchemaCache) now() time.Time {
+	if s.Clock == nil {
+		return time.Now()
+	}
+	return s.Clock.Now()
+}
+
+// WithSchemaCache makes the client get its schema from the cache, for
+// validating and coercing variables as WithSchema does. The schema is
+// introspected before a request when it is missing, and revalidated in
+// the background when it is older than the cache's TTL, the stale
+// schema being used meanwhile. Requests aren't failed by errors doing
+// so, and go unchecked until there is a schema. A schema given with
+// WithSchema takes precedence.
+//  NewClient(endpoint, WithSchemaCache(cache))
+func WithSchemaCache(cache *SchemaCache) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.schemaCache = cache
+	})
+}
+
+// schemaRefreshKey marks the context of the introspection made to
+// refresh the client's schema, so that it doesn't refresh it again.
+type schemaRefreshKey struct{}
+
+// refreshSchema fetches the client's schema into its cache, if it has
+// one and the schema is stale. A missing schema is fetched before the
+// request is sent; a stale one is refreshed in the background, on a
+// context that keeps the values of ctx but isn't cancelled with it, and
+// used until it has been.
+func (c *Client) refreshSchema(ctx context.Context) {
+	if c.schema != nil || c.schemaCache == nil || ctx.Value(schemaRefreshKey{}) != nil {
+		return
+	}
+	stale, cached := c.schemaCache.stale(c.endpoint)
+	if !stale {
+		return
+	}
+	ctx = context.WithValue(ctx, schemaRefreshKey{}, true)
+	if !cached {
+		c.schemaCache.Schema(ctx, c)
+		return
+	}
+	go func() {
+		defer c.schemaCache.refreshed(c.endpoint)
+		ctx, cancel := context.WithTimeout(detachedContext{Context: context.Background(), parent: ctx}, schemaRefreshTimeout)
+		defer cancel()
+		c.schemaCache.Schema(ctx, c)
+	}()
+}
+
+// currentSchema gets the schema requests are checked against: the one
+// given with WithSchema, or the one cached for the endpoint.
+func (c *Client) currentSchema() *Schema {
+	if c.schema != nil || c.schemaCache == nil {
+		return c.schema
+	}
+	return c.schemaCache.cached(c.endpoint)
+}
@@ -0,0 +1,165 @@
+package graphql_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/joefitzgerald/graphql"
+	"github.com/joefitzgerald/graphql/graphqltest"
+	. "github.com/onsi/gomega"
+)
+
+// schemaServer serves testdata/schema.json with the ETag "v1",
+// answering revalidations with 304 Not Modified, and other requests
+// with empty data. It counts the introspections and revalidations.
+func schemaServer(introspections, revalidations *int32) *httptest.Server {
+	schema, err := ioutil.ReadFile("testdata/schema.json")
+	Expect(err).ShouldNot(HaveOccurred())
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		switch {
+		case !strings.Contains(string(b), "__schema"):
+			w.Write([]byte(`{"data":{}}`))
+		case r.Header.Get("If-None-Match") == `"v1"`:
+			atomic.AddInt32(revalidations, 1)
+			w.WriteHeader(http.StatusNotModified)
+		default:
+			atomic.AddInt32(introspections, 1)
+			w.Header().Set("ETag", `"v1"`)
+			w.Write(schema)
+		}
+	}))
+}
+
+func TestSchemaCache(t *testing.T) {
+	RegisterTestingT(t)
+	var introspections, revalidations int32
+	srv := schemaServer(&introspections, &revalidations)
+	defer srv.Close()
+	dir, err := ioutil.TempDir("", "graphql")
+	Expect(err).ShouldNot(HaveOccurred())
+	defer os.RemoveAll(dir)
+	clock := graphqltest.NewClock(time.Now())
+	cache := graphql.NewSchemaCache(time.Hour, dir)
+	cache.Clock = clock
+	client := graphql.NewClient(srv.URL)
+	ctx := context.Background()
+
+	schema, err := cache.Schema(ctx, client)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(schema.Type("CreateUserInput")).ShouldNot(BeNil())
+	cache.Schema(ctx, client)
+	Expect(introspections).Should(BeEquivalentTo(1))
+
+	// stale schemas are revalidated with their ETag
+	clock.Advance(2 * time.Hour)
+	revalidated, err := cache.Schema(ctx, client)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(revalidated).Should(BeIdenticalTo(schema))
+	Expect(introspections).Should(BeEquivalentTo(1))
+	Expect(revalidations).Should(BeEquivalentTo(1))
+
+	// another cache reads the schema kept on disk
+	other := graphql.NewSchemaCache(time.Hour, dir)
+	other.Clock = clock
+	schema, err = other.Schema(ctx, client)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(schema.Type("CreateUserInput")).ShouldNot(BeNil())
+	Expect(introspections).Should(BeEquivalentTo(1))
+	Expect(revalidations).Should(BeEquivalentTo(1))
+
+	// invalidated schemas are revalidated, by this cache and by caches
+	// reading them from disk
+	other.Invalidate(srv.URL)
+	Expect(other.Schema(ctx, client)).Should(BeIdenticalTo(schema))
+	Expect(revalidations).Should(BeEquivalentTo(2))
+	other.Invalidate(srv.URL)
+	third := graphql.NewSchemaCache(time.Hour, dir)
+	third.Clock = clock
+	third.Schema(ctx, client)
+	Expect(introspections).Should(BeEquivalentTo(1))
+	Expect(revalidations).Should(BeEquivalentTo(3))
+
+	_, err = cache.Schema(ctx, graphql.NewClient("http://127.0.0.1:1"))
+	Expect(err).Should(MatchError(ContainSubstring("introspecting schema: ")))
+}
+
+func TestWithSchemaCache(t *testing.T) {
+	RegisterTestingT(t)
+	var introspections, revalidations int32
+	srv := schemaServer(&introspections, &revalidations)
+	defer srv.Close()
+	cache := graphql.NewSchemaCache(time.Hour, "")
+	users := graphql.NewClient(srv.URL, graphql.WithSchemaCache(cache))
+	admin := graphql.NewClient(srv.URL, graphql.WithSchemaCache(cache))
+	req := graphql.NewRequest(`mutation ($input: CreateUserInput!) { createUser(input: $input) { id } }`)
+	req.Var("input", graphql.NewInput("UserFilter").Set("name", "Mat"))
+
+	err := users.Run(context.Background(), req, nil)
+	Expect(err).Should(MatchError("graphql: invalid input: $input: expected CreateUserInput, got UserFilter"))
+	err = admin.Run(context.Background(), req, nil)
+	Expect(err).Should(MatchError("graphql: invalid input: $input: expected CreateUserInput, got UserFilter"))
+	Expect(introspections).Should(BeEquivalentTo(1))
+
+	req.Var("input", graphql.NewInput("CreateUserInput").Set("name", "Mat"))
+	Expect(users.Run(context.Background(), req, nil)).Should(Succeed())
+
+	// requests go unchecked when the schema can't be introspected
+	var disabled int32
+	closed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		if strings.Contains(string(b), "__schema") {
+			atomic.AddInt32(&disabled, 1)
+			w.Write([]byte(`{"errors":[{"message":"introspection is disabled"}]}`))
+			return
+		}
+		w.Write([]byte(`{"data":{}}`))
+	}))
+	defer closed.Close()
+	client := graphql.NewClient(closed.URL, graphql.WithSchemaCache(cache))
+	Expect(client.Run(context.Background(), req, nil)).Should(Succeed())
+	Expect(client.Run(context.Background(), req, nil)).Should(Succeed())
+	Expect(disabled).Should(BeEquivalentTo(1))
+}
+
+func TestWithSchemaCacheRefreshesInBackground(t *testing.T) {
+	RegisterTestingT(t)
+	var introspections, revalidations int32
+	release := make(chan struct{})
+	srv := schemaServer(&introspections, &revalidations)
+	defer srv.Close()
+	// revalidations wait until released
+	transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		if r.Header.Get("If-None-Match") != "" {
+			<-release
+		}
+		return http.DefaultTransport.RoundTrip(r)
+	})
+	clock := graphqltest.NewClock(time.Now())
+	cache := graphql.NewSchemaCache(time.Hour, "")
+	cache.Clock = clock
+	client := graphql.NewClient(srv.URL,
+		graphql.WithSchemaCache(cache),
+		graphql.WithHTTPClient(&http.Client{Transport: transport}),
+	)
+	req := graphql.NewRequest(`mutation ($input: CreateUserInput!) { createUser(input: $input) { id } }`)
+	req.Var("input", graphql.NewInput("UserFilter").Set("name", "Mat"))
+	invalid := "graphql: invalid input: $input: expected CreateUserInput, got UserFilter"
+	Expect(client.Run(context.Background(), req, nil)).Should(MatchError(invalid))
+
+	// the stale schema is used while it is revalidated
+	clock.Advance(2 * time.Hour)
+	Expect(client.Run(context.Background(), req, nil)).Should(MatchError(invalid))
+	Expect(client.Run(context.Background(), req, nil)).Should(MatchError(invalid))
+	close(release)
+	Eventually(func() int32 { return atomic.LoadInt32(&revalidations) }).Should(BeEquivalentTo(1))
+	Consistently(func() int32 { return atomic.LoadInt32(&revalidations) }, 50*time.Millisecond).Should(BeEquivalentTo(1))
+	Expect(atomic.LoadInt32(&introspections)).Should(BeEquivalentTo(1))
+}
@@ -0,0 +1,234 @@
+package graphql
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ChangeSeverity is how a schema change affects existing operations.
+type ChangeSeverity string
+
+// Change severities.
+const (
+	// ChangeBreaking changes can make existing operations fail.
+	ChangeBreaking ChangeSeverity = "BREAKING"
+	// ChangeDangerous changes are valid for existing operations, but may
+	// surprise clients, such as a new enum value.
+	ChangeDangerous ChangeSeverity = "DANGEROUS"
+	// ChangeSafe changes can't affect existing operations.
+	ChangeSafe ChangeSeverity = "SAFE"
+)
+
+// SchemaChange is a difference between two versions of a schema.
+type SchemaChange struct {
+	Severity ChangeSeverity
+	// Path is the schema coordinate of the change, such as "User.name"
+	// or "Query.user(id:)".
+	Path string
+	// Message describes the change.
+	Message string
+}
+
+func (c SchemaChange) String() string {
+	return fmt.Sprintf("%s %s: %s", c.Severity, c.Path, c.Message)
+}
+
+// HasBreakingChanges reports whether any of the changes are breaking.
+func HasBreakingChanges(changes []SchemaChange) bool {
+	for _, c := range changes {
+		if c.Severity == ChangeBreaking {
+			return true
+		}
+	}
+	return false
+}
+
+// DiffSchemas gets the changes made to a schema between the before and
+// after versions, sorted by path. Introspection types, whose names begin with
+// "__", are ignored.
+func DiffSchemas(before, after *Schema) []SchemaChange {
+	d := &schemaDiff{}
+	d.root("query", before.QueryType, after.QueryType)
+	d.root("mutation", before.MutationType, after.MutationType)
+	d.root("subscription", before.SubscriptionType, after.SubscriptionType)
+	for _, ot := range before.Types {
+		if isIntrospectionType(ot.Name) {
+			continue
+		}
+		nt := after.Type(ot.Name)
+		if nt == nil {
+			d.add(ChangeBreaking, ot.Name, "type was removed")
+			continue
+		}
+		d.typ(ot, nt)
+	}
+	for _, nt := range after.Types {
+		if !isIntrospectionType(nt.Name) && before.Type(nt.Name) == nil {
+			d.add(ChangeSafe, nt.Name, "type was added")
+		}
+	}
+	sort.SliceStable(d.changes, func(i, j int) bool {
+		return d.changes[i].Path < d.changes[j].Path
+	})
+	return d.changes
+}
+
+type schemaDiff struct {
+	changes []SchemaChange
+}
+
+func (d *schemaDiff) add(severity ChangeSeverity, path, format string, args ...interface{}) {
+	d.changes = append(d.changes, SchemaChange{
+		Severity: severity,
+		Path:     path,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (d *schemaDiff) root(opType, before, after string) {
+	switch {
+	case before == after:
+	case after == "":
+		d.add(ChangeBreaking, "schema", "%s operations are no longer supported", opType)
+	case before == "":
+		d.add(ChangeSafe, "schema", "%s operations are now supported", opType)
+	default:
+		d.add(ChangeBreaking, "schema", "%s root type changed from %s to %s", opType, before, after)
+	}
+}
+
+func (d *schemaDiff) typ(before, after *SchemaType) {
+	if before.Kind != after.Kind {
+		d.add(ChangeBreaking, before.Name, "kind changed from %s to %s", before.Kind, after.Kind)
+		return
+	}
+	for _, of := range before.Fields {
+		path := before.Name + "." + of.Name
+		nf := after.Field(of.Name)
+		if nf == nil {
+			d.add(ChangeBreaking, path, "field was removed")
+			continue
+		}
+		if of.Type.String() != nf.Type.String() {
+			severity := ChangeBreaking
+			if outputTypeSafe(of.Type, nf.Type) {
+				severity = ChangeSafe
+			}
+			d.add(severity, path, "type changed from %s to %s", of.Type, nf.Type)
+		}
+		if !of.IsDeprecated && nf.IsDeprecated {
+			d.add(ChangeSafe, path, "field was deprecated: %s", nf.DeprecationReason)
+		}
+		d.inputValues(path, "argument", of.Args, nf.Args)
+	}
+	for _, nf := range after.Fields {
+		if before.Field(nf.Name) == nil {
+			d.add(ChangeSafe, before.Name+"."+nf.Name, "field was added")
+		}
+	}
+	d.inputValues(before.Name, "input field", before.InputFields, after.InputFields)
+	for _, ov := range before.EnumValues {
+		nv := after.EnumValue(ov.Name)
+		if nv == nil {
+			d.add(ChangeBreaking, before.Name+"."+ov.Name, "enum value was removed")
+			continue
+		}
+		if !ov.IsDeprecated && nv.IsDeprecated {
+			d.add(ChangeSafe, before.Name+"."+ov.Name, "enum value was deprecated: %s", nv.DeprecationReason)
+		}
+	}
+	for _, nv := range after.EnumValues {
+		if before.EnumValue(nv.Name) == nil {
+			d.add(ChangeDangerous, before.Name+"."+nv.Name, "enum value was added")
+		}
+	}
+	d.members(before.Name, "member", before.PossibleTypes, after.PossibleTypes)
+	d.members(before.Name, "interface", before.Interfaces, after.Interfaces)
+}
+
+// inputValues compares the arguments of a field, or the fields of an
+// input object.
+func (d *schemaDiff) inputValues(parent, what string, before, after []*InputValue) {
+	path := func(name string) string {
+		if what == "argument" {
+			return parent + "(" + name + ":)"
+		}
+		return parent + "." + name
+	}
+	for _, ov := range before {
+		nv := findInputValue(after, ov.Name)
+		if nv == nil {
+			d.add(ChangeBreaking, path(ov.Name), "%s was removed", what)
+			continue
+		}
+		if ov.Type.String() != nv.Type.String() {
+			severity := ChangeBreaking
+			if inputTypeSafe(ov.Type, nv.Type) {
+				severity = ChangeSafe
+			}
+			d.add(severity, path(ov.Name), "type changed from %s to %s", ov.Type, nv.Type)
+		}
+		if defaultString(ov.DefaultValue) != defaultString(nv.DefaultValue) {
+			d.add(ChangeDangerous, path(ov.Name), "default changed from %s to %s", defaultString(ov.DefaultValue), defaultString(nv.DefaultValue))
+		}
+	}
+	for _, nv := range after {
+		if findInputValue(before, nv.Name) != nil {
+			continue
+		}
+		if nv.Type.Kind == KindNonNull && nv.DefaultValue == nil {
+			d.add(ChangeBreaking, path(nv.Name), "required %s was added", what)
+		} else {
+			d.add(ChangeSafe, path(nv.Name), "%s was added", what)
+		}
+	}
+}
+
+// members compares the possible types of a union, or the interfaces of
+// an object.
+func (d *schemaDiff) members(parent, what string, before, after []*TypeRef) {
+	has := func(refs []*TypeRef, name string) bool {
+		for _, r := range refs {
+			if r.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+	for _, o := range before {
+		if !has(after, o.Name) {
+			d.add(ChangeBreaking, parent, "%s %s was removed", what, o.Name)
+		}
+	}
+	for _, n := range after {
+		if !has(before, n.Name) {
+			d.add(ChangeDangerous, parent, "%s %s was added", what, n.Name)
+		}
+	}
+}
+
+// outputTypeSafe reports whether changing a field's type is safe for
+// clients; only making it non-null is.
+func outputTypeSafe(before, after *TypeRef) bool {
+	if after.Kind == KindNonNull && before.Kind != KindNonNull {
+		return after.OfType.String() == before.String()
+	}
+	return false
+}
+
+// inputTypeSafe reports whether changing an input's type is safe for
+// clients; only making it nullable is.
+func inputTypeSafe(before, after *TypeRef) bool {
+	return outputTypeSafe(after, before)
+}
+
+func defaultString(v *string) string {
+	if v == nil {
+		return "none"
+	}
+	return *v
+}
+
+func isIntrospectionType(name string) bool {
+	return len(name) > 1 && name[:2] == "__"
+}
@@ -0,0 +1,38 @@
+package graphql_test
+
+import (
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestDiffSchemas(t *testing.T) {
+	RegisterTestingT(t)
+	before := loadSchema()
+	Expect(graphql.DiffSchemas(before, loadSchema())).Should(BeEmpty())
+
+	after := loadSchema()
+	user := after.Type("User")
+	user.Fields = user.Fields[1:] // remove id
+	user.Field("name").Type = &graphql.TypeRef{Kind: graphql.KindNonNull, OfType: user.Field("name").Type}
+	role := after.Type("Role")
+	role.EnumValues = append(role.EnumValues, &graphql.EnumValue{Name: "OWNER"})
+	users := after.Type("Query").Field("users")
+	users.Args = append(users.Args, &graphql.InputValue{
+		Name: "org",
+		Type: &graphql.TypeRef{Kind: graphql.KindNonNull, OfType: &graphql.TypeRef{Kind: graphql.KindScalar, Name: "ID"}},
+	})
+	after.MutationType = ""
+
+	changes := graphql.DiffSchemas(before, after)
+	Expect(changes).Should(Equal([]graphql.SchemaChange{
+		{Severity: graphql.ChangeBreaking, Path: "Query.users(org:)", Message: "required argument was added"},
+		{Severity: graphql.ChangeDangerous, Path: "Role.OWNER", Message: "enum value was added"},
+		{Severity: graphql.ChangeBreaking, Path: "User.id", Message: "field was removed"},
+		{Severity: graphql.ChangeSafe, Path: "User.name", Message: "type changed from String to String!"},
+		{Severity: graphql.ChangeBreaking, Path: "schema", Message: "mutation operations are no longer supported"},
+	}))
+	Expect(graphql.HasBreakingChanges(changes)).Should(BeTrue())
+	Expect(changes[0].String()).Should(Equal("BREAKING Query.users(org:): required argument was added"))
+}
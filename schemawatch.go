@@ -0,0 +1,153 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SchemaChangeReport is the changes to the schema of an endpoint found
+// by a SchemaWatcher.
+type SchemaChangeReport struct {
+	Endpoint string
+	// Time is when the changed schema was introspected.
+	Time    time.Time
+	Changes []SchemaChange
+	// Breaking is whether any of the changes are breaking.
+	Breaking bool
+}
+
+// SchemaWatcher introspects the endpoints of clients and compares each
+// schema with the one it saw last, so that teams are told when upstream
+// schemas change. The changes found in a check of all the endpoints are
+// passed to the notify function together. It is safe for concurrent
+// use.
+//  watcher := graphql.NewSchemaWatcher(graphql.SchemaWebhook(hookURL, nil), users, billing)
+//  go watcher.Run(ctx, 10*time.Minute, func(err error) { log.Print(err) })
+type SchemaWatcher struct {
+	// Clock is the clock reports are timed with; the system clock if nil.
+	Clock Clock
+
+	clients []*Client
+	notify  func(reports []SchemaChangeReport) error
+
+	mu        sync.Mutex
+	snapshots map[string]*Schema
+}
+
+// NewSchemaWatcher makes a SchemaWatcher of the endpoints of clients,
+// that calls notify with the changes found by each check.
+func NewSchemaWatcher(notify func(reports []SchemaChangeReport) error, clients ...*Client) *SchemaWatcher {
+	return &SchemaWatcher{
+		clients:   clients,
+		notify:    notify,
+		snapshots: make(map[string]*Schema),
+	}
+}
+
+// SetSnapshot sets the schema the next introspection of the endpoint is
+// compared with, such as a vendored snapshot. Otherwise the first check
+// of an endpoint only records its schema.
+func (w *SchemaWatcher) SetSnapshot(endpoint string, schema *Schema) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.snapshots[endpoint] = schema
+}
+
+// Check introspects each endpoint, and if any of their schemas have
+// changed, calls notify with the changes. Endpoints that can't be
+// introspected keep their snapshots; the error says which they are,
+// after the others are checked.
+func (w *SchemaWatcher) Check(ctx context.Context) error {
+	var reports []SchemaChangeReport
+	var failed []string
+	for _, c := range w.clients {
+		schema, err := c.Introspect(ctx)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", c.endpoint, err))
+			continue
+		}
+		w.mu.Lock()
+		last := w.snapshots[c.endpoint]
+		w.snapshots[c.endpoint] = schema
+		w.mu.Unlock()
+		if last == nil {
+			continue
+		}
+		if changes := DiffSchemas(last, schema); len(changes) > 0 {
+			reports = append(reports, SchemaChangeReport{
+				Endpoint: c.endpoint,
+				Time:     w.now(),
+				Changes:  changes,
+				Breaking: HasBreakingChanges(changes),
+			})
+		}
+	}
+	if len(reports) > 0 {
+		if err := w.notify(reports); err != nil {
+			return errors.Wrap(err, "graphql: notifying schema changes")
+		}
+	}
+	if len(failed) > 0 {
+		return errors.Errorf("graphql: introspecting %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// Run checks the endpoints now, and then every interval, until ctx is
+// done. Errors from checks are passed to onError, if it isn't nil.
+func (w *SchemaWatcher) Run(ctx context.Context, interval time.Duration, onError func(error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := w.Check(ctx); err != nil && onError != nil && ctx.Err() == nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *SchemaWatcher) now() time.Time {
+	if w.Clock == nil {
+		return time.Now()
+	}
+	return w.Clock.Now()
+}
+
+// SchemaWebhook makes a SchemaWatcher notify function that posts the
+// reports as a JSON array to url, with httpClient, or
+// http.DefaultClient if it is nil.
+func SchemaWebhook(url string, httpClient *http.Client) func(reports []SchemaChangeReport) error {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return func(reports []SchemaChangeReport) error {
+		b, err := json.Marshal(reports)
+		if err != nil {
+			return err
+		}
+		res, err := httpClient.Post(url, "application/json", bytes.NewReader(b))
+		if err != nil {
+			return err
+		}
+		defer res.Body.Close()
+		io.Copy(ioutil.Discard, res.Body)
+		if res.StatusCode/100 != 2 {
+			return errors.Errorf("webhook responded %s", res.Status)
+		}
+		return nil
+	}
+}
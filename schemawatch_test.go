@@ -0,0 +1,71 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestSchemaWatcher(t *testing.T) {
+	RegisterTestingT(t)
+	vendored, err := ioutil.ReadFile("testdata/schema.json")
+	Expect(err).ShouldNot(HaveOccurred())
+	live := string(vendored)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		w.Write([]byte(live))
+	}))
+	defer srv.Close()
+	var notified [][]graphql.SchemaChangeReport
+	watcher := graphql.NewSchemaWatcher(func(reports []graphql.SchemaChangeReport) error {
+		notified = append(notified, reports)
+		return nil
+	}, graphql.NewClient(srv.URL), graphql.NewClient("http://127.0.0.1:1"))
+	watcher.Clock = fixedClock{}
+
+	err = watcher.Check(context.Background())
+	Expect(err).Should(MatchError(ContainSubstring("graphql: introspecting http://127.0.0.1:1: ")))
+	Expect(notified).Should(BeEmpty())
+
+	live = strings.Replace(live, `"name": "nickname"`, `"name": "nick"`, 1)
+	watcher.Check(context.Background())
+	Expect(notified).Should(HaveLen(1))
+	report := notified[0][0]
+	Expect(report.Endpoint).Should(Equal(srv.URL))
+	Expect(report.Time).Should(Equal(fixedClock{}.Now()))
+	Expect(report.Breaking).Should(BeTrue())
+	Expect(report.Changes).Should(ContainElement(graphql.SchemaChange{
+		Severity: graphql.ChangeBreaking, Path: "CreateUserInput.nickname", Message: "input field was removed",
+	}))
+
+	// unchanged schemas aren't reported
+	watcher.Check(context.Background())
+	Expect(notified).Should(HaveLen(1))
+}
+
+func TestSchemaWebhook(t *testing.T) {
+	RegisterTestingT(t)
+	var got []graphql.SchemaChangeReport
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Expect(r.Header.Get("Content-Type")).Should(Equal("application/json"))
+		Expect(json.NewDecoder(r.Body).Decode(&got)).Should(Succeed())
+		if got[0].Endpoint == "fail" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+	notify := graphql.SchemaWebhook(srv.URL, nil)
+	reports := []graphql.SchemaChangeReport{{Endpoint: "users", Changes: []graphql.SchemaChange{{Severity: graphql.ChangeSafe, Path: "User.nick", Message: "field was added"}}}}
+	Expect(notify(reports)).Should(Succeed())
+	Expect(got).Should(HaveLen(1))
+	Expect(got[0].Changes[0].Path).Should(Equal("User.nick"))
+
+	Expect(notify([]graphql.SchemaChangeReport{{Endpoint: "fail"}})).Should(MatchError("webhook responded 502 Bad Gateway"))
+}
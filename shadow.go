@@ -0,0 +1,134 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// shadowTimeout limits the time taken by shadow requests, which don't
+// have the caller's context.
+const shadowTimeout = 30 * time.Second
+
+// ShadowReport describes a query that was mirrored to the shadow
+// endpoint.
+type ShadowReport struct {
+	// Request is the request that was mirrored.
+	Request *Request
+	// Latency and ShadowLatency are the times taken by the primary and
+	// shadow endpoints.
+	Latency       time.Duration
+	ShadowLatency time.Duration
+	// Differences describe where the shadow response differed from the
+	// primary response, such as `data.user.name: "Mat" != "Matt"`.
+	Differences []string
+	// Err is the error from the shadow request, if it failed.
+	Err error
+}
+
+// WithShadow mirrors a fraction of queries to a second endpoint, to
+// validate it before moving traffic to it. samplingRate is the fraction
+// of queries mirrored, between 0 and 1. Shadow requests are sent in the
+// background after the primary response is received; their results are
+// only passed to the function set with WithShadowReport.
+// Queries in batches are mirrored one at a time. Mutations are never
+// mirrored.
+//  NewClient(endpoint,
+//      WithShadow(newGatewayEndpoint, 0.05),
+//      WithShadowReport(recordShadowDiffs),
+//  )
+func WithShadow(endpoint string, samplingRate float64) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.shadowEndpoint = endpoint
+		client.shadowRate = samplingRate
+	})
+}
+
+// WithShadowReport sets the function called with the result of each
+// shadow request. It is called from a separate goroutine.
+func WithShadowReport(fn func(ShadowReport)) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.shadowReport = fn
+	})
+}
+
+// withoutShadow turns off shadowing, routing, capture and health
+// tracking, for the shadow client itself.
+func withoutShadow() ClientOption {
+	return ClientOption(func(client *Client) {
+		client.shadowEndpoint = ""
+		client.endpointResolver = nil
+		client.readEndpoints = nil
+		client.captureSink = nil
+		client.health = nil
+	})
+}
+
+// shadow mirrors req to the shadow endpoint if it is sampled, comparing
+// the result with the primary response.
+func (c *Client) shadow(req *Request, primary *response) {
+	if c.shadowClient == nil || c.sample() >= c.shadowRate {
+		return
+	}
+	if op, err := req.Operation(); err != nil || op.Type != "query" {
+		return
+	}
+	req = req.clone()
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shadowTimeout)
+		defer cancel()
+		report := ShadowReport{Request: req, Latency: primary.duration}
+		start := c.now()
+		res, err := c.shadowClient.do(ctx, req)
+		report.ShadowLatency = c.since(start)
+		if err != nil {
+			report.Err = err
+		} else {
+			report.Differences, report.Err = diffResponses(primary, res, c.redactor())
+		}
+		if c.shadowReport != nil {
+			defer c.recoverHook("shadow report", nil)
+			c.shadowReport(report)
+		}
+	}()
+}
+
+// diffResponses compares the data and errors of two responses, masking
+// the values of fields masked by r.
+func diffResponses(a, b *response, r *Redaction) ([]string, error) {
+	encode := func(res *response) ([]byte, error) {
+		return json.Marshal(struct {
+			Data   json.RawMessage `json:"data,omitempty"`
+			Errors Errors          `json:"errors,omitempty"`
+		}{res.data, res.errors})
+	}
+	ja, err := encode(a)
+	if err != nil {
+		return nil, err
+	}
+	jb, err := encode(b)
+	if err != nil {
+		return nil, err
+	}
+	diffs, err := Diff(ja, jb)
+	if err != nil {
+		return nil, err
+	}
+	masks := r.fieldMasks()
+	var out []string
+	for _, d := range diffs {
+		if masks.masks(d.Path) {
+			d.A, d.B = maskedValue(d.A), maskedValue(d.B)
+		}
+		out = append(out, d.String())
+	}
+	return out, nil
+}
+
+// maskedValue replaces a masked value, keeping missing values missing.
+func maskedValue(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	return Redacted
+}
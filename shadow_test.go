@@ -0,0 +1,60 @@
+package graphql_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestShadow(t *testing.T) {
+	RegisterTestingT(t)
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{"user":{"name":"Mat","age":30}}}`)
+	}))
+	defer primary.Close()
+	shadowCalls := 0
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		shadowCalls++
+		io.WriteString(w, `{"data":{"user":{"age":30.0,"name":"Matt"}}}`)
+	}))
+	defer shadow.Close()
+
+	reports := make(chan graphql.ShadowReport, 1)
+	client := graphql.NewClient(primary.URL,
+		graphql.WithShadow(shadow.URL, 1),
+		graphql.WithShadowReport(func(r graphql.ShadowReport) {
+			reports <- r
+		}),
+	)
+	var resp map[string]interface{}
+	err := client.Run(context.Background(), graphql.NewRequest(`query { user { name age } }`), &resp)
+	Expect(err).ShouldNot(HaveOccurred())
+
+	var report graphql.ShadowReport
+	Eventually(reports, time.Second).Should(Receive(&report))
+	Expect(report.Err).ShouldNot(HaveOccurred())
+	Expect(report.Differences).Should(Equal([]string{`data.user.name: "Mat" != "Matt"`}))
+
+	err = client.Run(context.Background(), graphql.NewRequest(`mutation { deleteUser }`), &resp)
+	Expect(err).ShouldNot(HaveOccurred())
+	Consistently(reports, 100*time.Millisecond).ShouldNot(Receive())
+	Expect(shadowCalls).Should(Equal(1))
+
+	client = graphql.NewClient(primary.URL,
+		graphql.WithShadow(shadow.URL, 1),
+		graphql.WithShadowReport(func(r graphql.ShadowReport) {
+			reports <- r
+		}),
+		graphql.WithRedaction(&graphql.Redaction{Fields: []string{"name"}}),
+	)
+	err = client.Run(context.Background(), graphql.NewRequest(`query { user { name age } }`), &resp)
+	Expect(err).ShouldNot(HaveOccurred())
+	Eventually(reports, time.Second).Should(Receive(&report))
+	Expect(report.Differences).Should(Equal([]string{`data.user.name: "[REDACTED]" != "[REDACTED]"`}))
+}
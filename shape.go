@@ -0,0 +1,103 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// ShapeReport describes the size and shape of the data in a response,
+// for finding queries that fetch more than they need.
+type ShapeReport struct {
+	// Operation is the request's MetricsName.
+	Operation string
+	// Fields describe each top-level field of the data, sorted by name.
+	Fields []FieldShape
+}
+
+// FieldShape describes the value of a top-level response field.
+type FieldShape struct {
+	// Field is the response key of the field.
+	Field string
+	// Bytes is the size of the encoded value.
+	Bytes int
+	// Objects is the number of objects in the value, including itself.
+	Objects int
+	// ListItems is the number of items in all lists in the value, and
+	// MaxListLength is the length of the longest list.
+	ListItems     int
+	MaxListLength int
+}
+
+// WithShapeReport sets a function called with the shape of the data in
+// each response, to feed metrics about the size of each field.
+//  NewClient(endpoint, WithShapeReport(func(r graphql.ShapeReport) {
+//      for _, f := range r.Fields {
+//          fieldBytes.WithLabelValues(r.Operation, f.Field).Observe(float64(f.Bytes))
+//      }
+//  }))
+func WithShapeReport(fn func(ShapeReport)) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.shapeReport = fn
+	})
+}
+
+// reportShape calls the client's shape report function with the shape
+// of data.
+func (c *Client) reportShape(req *Request, data json.RawMessage) {
+	if c.shapeReport == nil {
+		return
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil || fields == nil {
+		return
+	}
+	report := ShapeReport{Operation: req.MetricsName()}
+	for name, value := range fields {
+		report.Fields = append(report.Fields, measureShape(name, value))
+	}
+	sort.Slice(report.Fields, func(i, j int) bool {
+		return report.Fields[i].Field < report.Fields[j].Field
+	})
+	defer c.recoverHook("shape report", nil)
+	c.shapeReport(report)
+}
+
+// measureShape counts the objects and list items in a JSON value.
+func measureShape(name string, value json.RawMessage) FieldShape {
+	shape := FieldShape{Field: name, Bytes: len(value)}
+	d := json.NewDecoder(bytes.NewReader(value))
+	// lists holds the item counts of the open lists; -1 for objects
+	var lists []int
+	expectKey := false
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return shape
+		}
+		if _, ok := tok.(string); ok && expectKey {
+			// object keys aren't values
+			expectKey = false
+			continue
+		}
+		if n := len(lists); n > 0 && lists[n-1] >= 0 && tok != json.Delim(']') {
+			lists[n-1]++
+			shape.ListItems++
+		}
+		switch tok {
+		case json.Delim('{'):
+			shape.Objects++
+			lists = append(lists, -1)
+		case json.Delim('['):
+			lists = append(lists, 0)
+		case json.Delim(']'):
+			if n := lists[len(lists)-1]; n > shape.MaxListLength {
+				shape.MaxListLength = n
+			}
+			lists = lists[:len(lists)-1]
+		case json.Delim('}'):
+			lists = lists[:len(lists)-1]
+		}
+		expectKey = len(lists) > 0 && lists[len(lists)-1] < 0
+	}
+}
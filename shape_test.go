@@ -0,0 +1,29 @@
+package graphql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestShapeReport(t *testing.T) {
+	RegisterTestingT(t)
+	var report graphql.ShapeReport
+	client := bodyClient(`{"data":{
+		"viewer": {"name": "Mat", "tags": ["a", "b"]},
+		"users": [{"id": 1, "friends": [{"id": 2}, {"id": 3}, {"id": 4}]}, {"id": 5, "friends": []}],
+		"count": 2
+	}}`, graphql.WithShapeReport(func(r graphql.ShapeReport) {
+		report = r
+	}))
+	err := client.Run(context.Background(), graphql.NewRequest(`query Feed { viewer { name tags } users { id friends { id } } count }`), nil)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(report.Operation).Should(Equal("Feed"))
+	Expect(report.Fields).Should(Equal([]graphql.FieldShape{
+		{Field: "count", Bytes: 1},
+		{Field: "users", Bytes: 83, Objects: 5, ListItems: 5, MaxListLength: 3},
+		{Field: "viewer", Bytes: 35, Objects: 1, ListItems: 2, MaxListLength: 2},
+	}))
+}
@@ -0,0 +1,57 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// RequestTooLargeError is returned when a request is larger than the
+// client's limits, before it is sent.
+type RequestTooLargeError struct {
+	// Part is "query" or "variables".
+	Part string
+	// Size is the size of the part in bytes, and Limit its limit.
+	Size  int64
+	Limit int64
+}
+
+func (e *RequestTooLargeError) Error() string {
+	return fmt.Sprintf("graphql: %s is %d bytes, over the limit of %d", e.Part, e.Size, e.Limit)
+}
+
+// WithMaxQuerySize limits the size of query documents in bytes. Larger
+// requests fail with a *RequestTooLargeError without being sent, which
+// is clearer than the errors from gateways that truncate large bodies.
+//  NewClient(endpoint, WithMaxQuerySize(256<<10))
+func WithMaxQuerySize(size int64) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.maxQuerySize = size
+	})
+}
+
+// WithMaxVariablesSize limits the size of the JSON encoded variables of
+// requests in bytes. Larger requests fail with a *RequestTooLargeError
+// without being sent.
+//  NewClient(endpoint, WithMaxVariablesSize(1<<20))
+func WithMaxVariablesSize(size int64) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.maxVariablesSize = size
+	})
+}
+
+// checkSize checks the request against the client's size limits.
+func (c *Client) checkSize(req *Request) error {
+	if c.maxQuerySize > 0 && int64(len(req.Query)) > c.maxQuerySize {
+		return &RequestTooLargeError{Part: "query", Size: int64(len(req.Query)), Limit: c.maxQuerySize}
+	}
+	if c.maxVariablesSize > 0 && len(req.Variables) > 0 {
+		b, err := json.Marshal(req.Variables)
+		if err != nil {
+			return err
+		}
+		if int64(len(b)) > c.maxVariablesSize {
+			return &RequestTooLargeError{Part: "variables", Size: int64(len(b)), Limit: c.maxVariablesSize}
+		}
+	}
+	return nil
+}
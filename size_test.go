@@ -0,0 +1,30 @@
+package graphql_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestRequestSizeLimits(t *testing.T) {
+	RegisterTestingT(t)
+	client := bodyClient(`{"data":{}}`,
+		graphql.WithMaxQuerySize(20),
+		graphql.WithMaxVariablesSize(16),
+	)
+	ctx := context.Background()
+
+	err := client.Run(ctx, graphql.NewRequest(`{ user { name } }`), nil)
+	Expect(err).ShouldNot(HaveOccurred())
+
+	err = client.Run(ctx, graphql.NewRequest(`{ user { name email } }`), nil)
+	Expect(err).Should(Equal(&graphql.RequestTooLargeError{Part: "query", Size: 23, Limit: 20}))
+
+	req := graphql.NewRequest(`{ a }`)
+	req.Var("s", strings.Repeat("x", 10))
+	err = client.Run(ctx, req, nil)
+	Expect(err).Should(MatchError("graphql: variables is 18 bytes, over the limit of 16"))
+}
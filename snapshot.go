@@ -0,0 +1,144 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Snapshot keeps the result of a query in memory, refreshing it
+// periodically, for slowly changing reference data that would otherwise
+// be fetched for every use.
+//  countries := graphql.NewSnapshot[CountryData](client, req, 10*time.Minute)
+//  if err := countries.Start(ctx); err != nil {
+//      return err
+//  }
+//  data := countries.Load()
+type Snapshot[T any] struct {
+	client   *Client
+	req      *Request
+	interval time.Duration
+
+	value atomic.Value // *T
+
+	mu       sync.Mutex
+	data     json.RawMessage
+	err      error
+	onChange []func(data *T)
+	// started and finished number the last refresh started and the
+	// last whose result was kept.
+	started, finished uint64
+}
+
+// NewSnapshot makes a Snapshot of the result of req, refreshed every
+// interval once started.
+func NewSnapshot[T any](client *Client, req *Request, interval time.Duration) *Snapshot[T] {
+	return &Snapshot[T]{
+		client:   client,
+		req:      req,
+		interval: interval,
+	}
+}
+
+// OnChange adds a function called with the new data each time a refresh
+// gets a result that differs from the last. It must be called before
+// Start.
+func (s *Snapshot[T]) OnChange(f func(data *T)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onChange = append(s.onChange, f)
+}
+
+// Start loads the snapshot, then refreshes it in the background every
+// interval, by the client's clock if it is a TickingClock, until ctx is
+// done. If the first load fails, the error is returned and the snapshot
+// is not refreshed.
+func (s *Snapshot[T]) Start(ctx context.Context) error {
+	if err := s.Refresh(ctx); err != nil {
+		return err
+	}
+	ticks, stop := s.client.tick(s.interval)
+	go func() {
+		defer stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticks:
+				s.Refresh(ctx)
+			}
+		}
+	}()
+	return nil
+}
+
+// Load gets the current data, or nil if the snapshot hasn't been loaded.
+// The data is shared, so it must not be modified.
+func (s *Snapshot[T]) Load() *T {
+	data, _ := s.value.Load().(*T)
+	return data
+}
+
+// Err gets the error from the last refresh, or nil if it succeeded. The
+// snapshot keeps its data when a refresh fails.
+func (s *Snapshot[T]) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Refresh runs the query now, replacing the data if the result has
+// changed. The query is run, and OnChange functions called, without
+// holding the snapshot's lock, so they may call its methods. When
+// refreshes overlap, the result of the last one started is kept.
+func (s *Snapshot[T]) Refresh(ctx context.Context) error {
+	s.mu.Lock()
+	s.started++
+	seq := s.started
+	s.mu.Unlock()
+	raw, data, err := s.fetch(ctx)
+	s.mu.Lock()
+	if seq < s.finished {
+		// a later refresh has finished, and its result stands
+		s.mu.Unlock()
+		return err
+	}
+	s.finished = seq
+	s.err = err
+	var onChange []func(data *T)
+	if err == nil && (s.data == nil || !bytes.Equal(s.data, raw)) {
+		s.data = raw
+		s.value.Store(data)
+		onChange = s.onChange
+	}
+	s.mu.Unlock()
+	for _, f := range onChange {
+		s.client.callHook("snapshot change callback", func() error {
+			f(data)
+			return nil
+		})
+	}
+	return err
+}
+
+// fetch runs the query, getting its data both as sent and decoded. The
+// response is handled as Run handles it.
+func (s *Snapshot[T]) fetch(ctx context.Context) (json.RawMessage, *T, error) {
+	res, err := s.client.do(ctx, s.req)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "refreshing snapshot")
+	}
+	data := new(T)
+	_, err = s.client.handleResponse(ctx, s.req, res, data, false, func(raw json.RawMessage) ([]Warning, error) {
+		return s.client.decodeResult(res.req, raw, data)
+	})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "refreshing snapshot")
+	}
+	return res.data, data, nil
+}
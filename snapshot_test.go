@@ -0,0 +1,143 @@
+package graphql_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/joefitzgerald/graphql"
+	"github.com/joefitzgerald/graphql/graphqltest"
+	. "github.com/onsi/gomega"
+)
+
+func TestSnapshot(t *testing.T) {
+	RegisterTestingT(t)
+	var version int32 = 1
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		switch atomic.LoadInt32(&version) {
+		case 1:
+			io.WriteString(w, `{"data":{"version":1}}`)
+		case 2:
+			io.WriteString(w, `{"data":{"version":2}}`)
+		default:
+			io.WriteString(w, `{"errors":[{"message":"down"}]}`)
+		}
+	}))
+	defer srv.Close()
+	type data struct {
+		Version int
+	}
+	snapshot := graphql.NewSnapshot[data](graphql.NewClient(srv.URL), graphql.NewRequest(`{ version }`), time.Hour)
+	Expect(snapshot.Load()).Should(BeNil())
+	var changes []int
+	snapshot.OnChange(func(d *data) {
+		changes = append(changes, d.Version)
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	Expect(snapshot.Start(ctx)).Should(Succeed())
+	first := snapshot.Load()
+	Expect(first.Version).Should(Equal(1))
+
+	Expect(snapshot.Refresh(ctx)).Should(Succeed())
+	Expect(snapshot.Load()).Should(BeIdenticalTo(first))
+
+	atomic.StoreInt32(&version, 2)
+	Expect(snapshot.Refresh(ctx)).Should(Succeed())
+	Expect(snapshot.Load().Version).Should(Equal(2))
+	Expect(changes).Should(Equal([]int{1, 2}))
+
+	atomic.StoreInt32(&version, 3)
+	Expect(snapshot.Refresh(ctx)).Should(MatchError("refreshing snapshot: graphql: down"))
+	Expect(snapshot.Err()).Should(HaveOccurred())
+	Expect(snapshot.Load().Version).Should(Equal(2))
+	Expect(atomic.LoadInt32(&calls)).Should(BeEquivalentTo(4))
+}
+
+func TestSnapshotRefreshes(t *testing.T) {
+	RegisterTestingT(t)
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	snapshot := graphql.NewSnapshot[map[string]interface{}](graphql.NewClient(srv.URL), graphql.NewRequest(`{ a }`), 10*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	Expect(snapshot.Start(ctx)).Should(Succeed())
+	Eventually(func() int32 { return atomic.LoadInt32(&calls) }).Should(BeNumerically(">=", 3))
+}
+
+func TestSnapshotRefreshUnlocked(t *testing.T) {
+	RegisterTestingT(t)
+	release := make(chan struct{})
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) > 1 {
+			<-release
+		}
+		io.WriteString(w, fmt.Sprintf(`{"data":{"version":%d}}`, atomic.LoadInt32(&calls)))
+	}))
+	defer srv.Close()
+	type data struct {
+		Version int
+	}
+	snapshot := graphql.NewSnapshot[data](graphql.NewClient(srv.URL), graphql.NewRequest(`{ version }`), time.Hour)
+	var errs []error
+	snapshot.OnChange(func(d *data) {
+		// callbacks may use the snapshot
+		errs = append(errs, snapshot.Err())
+	})
+	Expect(snapshot.Refresh(context.Background())).Should(Succeed())
+
+	done := make(chan error)
+	go func() {
+		done <- snapshot.Refresh(context.Background())
+	}()
+	Eventually(func() int32 { return atomic.LoadInt32(&calls) }).Should(BeEquivalentTo(2))
+	// the snapshot can be read while a refresh waits on the server
+	Expect(snapshot.Err()).ShouldNot(HaveOccurred())
+	Expect(snapshot.Load().Version).Should(Equal(1))
+	close(release)
+	Expect(<-done).Should(Succeed())
+	Expect(snapshot.Load().Version).Should(Equal(2))
+	Expect(errs).Should(Equal([]error{nil, nil}))
+}
+
+func TestSnapshotClock(t *testing.T) {
+	RegisterTestingT(t)
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		io.WriteString(w, `{"data":{"a":1}}`)
+	}))
+	defer srv.Close()
+	clock := graphqltest.NewClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	var stats int32
+	client := graphql.NewClient(srv.URL, graphql.WithClock(clock), graphql.WithStatsReport(func(graphql.RequestStats) {
+		atomic.AddInt32(&stats, 1)
+	}))
+	snapshot := graphql.NewSnapshot[map[string]interface{}](client, graphql.NewRequest(`{ a }`), time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	Expect(snapshot.Start(ctx)).Should(Succeed())
+	Expect(atomic.LoadInt32(&calls)).Should(BeEquivalentTo(1))
+	// refreshes are handled as calls to Run are
+	Expect(atomic.LoadInt32(&stats)).Should(BeEquivalentTo(1))
+
+	// the snapshot is refreshed by the client's clock
+	clock.Advance(59 * time.Minute)
+	Consistently(func() int32 { return atomic.LoadInt32(&calls) }, 50*time.Millisecond).Should(BeEquivalentTo(1))
+	clock.Advance(time.Minute)
+	Eventually(func() int32 { return atomic.LoadInt32(&calls) }).Should(BeEquivalentTo(2))
+	Eventually(func() int32 { return atomic.LoadInt32(&stats) }).Should(BeEquivalentTo(2))
+}
@@ -0,0 +1,109 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Media types defined by the GraphQL-over-HTTP specification.
+const (
+	MediaTypeJSON            = "application/json"
+	MediaTypeGraphQLResponse = "application/graphql-response+json"
+)
+
+// specAccept is the Accept header sent in spec compliance mode,
+// preferring the GraphQL response media type.
+const specAccept = MediaTypeGraphQLResponse + ";charset=utf-8, " + MediaTypeJSON + ";charset=utf-8;q=0.9"
+
+// StatusError is returned when the server responds with an HTTP status
+// that doesn't carry a GraphQL response.
+type StatusError struct {
+	StatusCode int
+	Status     string
+	// Body is the response body.
+	Body []byte
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("graphql: server returned %s", e.Status)
+}
+
+// WithSpecCompliance makes the client follow the GraphQL-over-HTTP
+// specification: it accepts the application/graphql-response+json media
+// type, interprets HTTP status codes according to the response media type,
+// and rejects responses that are not well-formed GraphQL responses.
+//  NewClient(endpoint, WithSpecCompliance())
+func WithSpecCompliance() ClientOption {
+	return ClientOption(func(client *Client) {
+		client.specCompliance = true
+	})
+}
+
+// checkSpecResponse checks res and its body against the
+// GraphQL-over-HTTP specification.
+// Responses of type application/graphql-response+json carry a GraphQL
+// response whatever the status. Responses of type application/json only
+// do for 2xx statuses.
+func checkSpecResponse(res *http.Response, mediaType string, body []byte) error {
+	ok := res.StatusCode >= 200 && res.StatusCode < 300
+	switch {
+	case mediaType == MediaTypeGraphQLResponse:
+	case mediaType == MediaTypeJSON && ok:
+	case ok:
+		return &DecodeError{Offset: -1, Reason: fmt.Sprintf("unexpected content type %q", res.Header.Get("Content-Type"))}
+	default:
+		return &StatusError{StatusCode: res.StatusCode, Status: res.Status, Body: body}
+	}
+	if err := checkResponseShape(body); err != nil {
+		if !ok {
+			return &StatusError{StatusCode: res.StatusCode, Status: res.Status, Body: body}
+		}
+		return err
+	}
+	return nil
+}
+
+// checkStatusResponse checks a non-2xx response outside spec compliance
+// mode. Gateways often reject requests with 4xx statuses and GraphQL error
+// bodies, so the body is decoded when it is a well-formed GraphQL response
+// with errors, of a JSON media type; anything else, such as an HTML error
+// page, or data without errors, is reported as a StatusError.
+func checkStatusResponse(res *http.Response, mediaType string, body []byte) error {
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return nil
+	}
+	switch mediaType {
+	case MediaTypeGraphQLResponse, MediaTypeJSON, "":
+		var graphResponse struct {
+			Errors []json.RawMessage
+		}
+		if checkResponseShape(body) == nil && json.Unmarshal(body, &graphResponse) == nil && len(graphResponse.Errors) > 0 {
+			return nil
+		}
+	}
+	return &StatusError{StatusCode: res.StatusCode, Status: res.Status, Body: body}
+}
+
+// checkResponseShape checks that body is a well-formed GraphQL response:
+// an object with data, or a non-empty errors list, or both.
+func checkResponseShape(body []byte) error {
+	var shape map[string]json.RawMessage
+	if err := json.Unmarshal(body, &shape); err != nil {
+		return &DecodeError{Offset: -1, Reason: "response is not a JSON object", Err: err}
+	}
+	data, hasData := shape["data"]
+	errs, hasErrors := shape["errors"]
+	if !hasData && !hasErrors {
+		return &DecodeError{Offset: -1, Reason: "response has neither data nor errors"}
+	}
+	if hasErrors {
+		var list []json.RawMessage
+		if err := json.Unmarshal(errs, &list); err != nil || len(list) == 0 {
+			return &DecodeError{Offset: -1, Reason: "response errors must be a non-empty list"}
+		}
+	} else if string(data) == "null" {
+		return &DecodeError{Offset: -1, Reason: "response has null data and no errors"}
+	}
+	return nil
+}
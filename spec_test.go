@@ -0,0 +1,100 @@
+package graphql_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestSpecCompliance(t *testing.T) {
+	RegisterTestingT(t)
+	var (
+		status      int
+		contentType string
+		body        string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Expect(r.Header.Get("Accept")).Should(HavePrefix(graphql.MediaTypeGraphQLResponse))
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL, graphql.WithSpecCompliance())
+	run := func() error {
+		var resp map[string]interface{}
+		return client.Run(context.Background(), graphql.NewRequest(`{ value }`), &resp)
+	}
+
+	status, contentType, body = http.StatusOK, graphql.MediaTypeGraphQLResponse, `{"data":{"value":1}}`
+	Expect(run()).ShouldNot(HaveOccurred())
+
+	status, contentType, body = http.StatusBadRequest, graphql.MediaTypeGraphQLResponse, `{"errors":[{"message":"Cannot query field"}]}`
+	err := run()
+	Expect(err).Should(MatchError("graphql: Cannot query field"))
+
+	status, contentType, body = http.StatusBadGateway, graphql.MediaTypeJSON, `{"errors":[{"message":"upstream"}]}`
+	err = run()
+	statusErr, ok := err.(*graphql.StatusError)
+	Expect(ok).Should(BeTrue())
+	Expect(statusErr.StatusCode).Should(Equal(http.StatusBadGateway))
+
+	status, contentType, body = http.StatusOK, graphql.MediaTypeJSON, `{"errors":[]}`
+	_, ok = run().(*graphql.DecodeError)
+	Expect(ok).Should(BeTrue())
+
+	status, contentType, body = http.StatusOK, "text/html", `<html></html>`
+	_, ok = run().(*graphql.DecodeError)
+	Expect(ok).Should(BeTrue())
+}
+
+func TestErrorStatus(t *testing.T) {
+	RegisterTestingT(t)
+	var (
+		status      int
+		contentType string
+		body        string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL)
+	run := func() error {
+		var resp map[string]interface{}
+		return client.Run(context.Background(), graphql.NewRequest(`{ value }`), &resp)
+	}
+
+	status, contentType, body = http.StatusBadRequest, graphql.MediaTypeJSON, `{"errors":[{"message":"Cannot query field"}]}`
+	Expect(run()).Should(MatchError("graphql: Cannot query field"))
+
+	status, contentType, body = http.StatusUnauthorized, graphql.MediaTypeGraphQLResponse+"; charset=utf-8", `{"errors":[{"message":"unauthenticated"}]}`
+	Expect(run()).Should(MatchError("graphql: unauthenticated"))
+
+	status, contentType, body = http.StatusBadGateway, "text/html", `<html>Bad Gateway</html>`
+	err := run()
+	statusErr, ok := err.(*graphql.StatusError)
+	Expect(ok).Should(BeTrue())
+	Expect(statusErr.StatusCode).Should(Equal(http.StatusBadGateway))
+	Expect(string(statusErr.Body)).Should(Equal(`<html>Bad Gateway</html>`))
+
+	status, contentType, body = http.StatusServiceUnavailable, graphql.MediaTypeJSON, `{"message":"overloaded"}`
+	_, ok = run().(*graphql.StatusError)
+	Expect(ok).Should(BeTrue())
+
+	// data without errors isn't a success with an error status
+	status, contentType, body = http.StatusInternalServerError, graphql.MediaTypeJSON, `{"data":{"value":"stale"}}`
+	statusErr, ok = run().(*graphql.StatusError)
+	Expect(ok).Should(BeTrue())
+	Expect(statusErr.StatusCode).Should(Equal(http.StatusInternalServerError))
+
+	status, contentType, body = http.StatusBadRequest, graphql.MediaTypeJSON, `{"data":{"value":"partial"},"errors":[{"message":"invalid"}]}`
+	Expect(run()).Should(MatchError("graphql: invalid"))
+}
@@ -0,0 +1,107 @@
+package graphql
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RequestStats are the sizes and timings of a request, to measure the
+// effect of features such as compression and persisted queries.
+type RequestStats struct {
+	// Operation is the request's MetricsName.
+	Operation string
+	// RootFields are the request's RootFields, if there is a stats
+	// report function.
+	RootFields []string
+	// RequestBytes is the size of the encoded request body.
+	RequestBytes int
+	// ResponseWireBytes is the size of the response body as received,
+	// and ResponseBytes its size after decompression.
+	ResponseWireBytes int
+	ResponseBytes     int
+	// DecodeTime is the time taken to decode the response.
+	DecodeTime time.Duration
+	// LatencyBudget is the latency budget sent with the request, and
+	// BudgetOutcome whether the server honored it; see WithLatencyBudget.
+	LatencyBudget time.Duration
+	BudgetOutcome BudgetOutcome
+	// FromCache is whether the response was served by an HTTP cache,
+	// as Result.FromCache.
+	FromCache bool
+}
+
+// WithStatsReport sets a function called with the RequestStats of each
+// request, including each request in a batch, to feed metrics. The
+// sizes of a batched request are those of its own parts of the batch,
+// and its wire size isn't measured.
+//  NewClient(endpoint, WithStatsReport(func(stats RequestStats) {
+//      responseBytes.WithLabelValues(stats.Operation).Observe(float64(stats.ResponseWireBytes))
+//  }))
+func WithStatsReport(report func(RequestStats)) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.statsReport = report
+	})
+}
+
+// reportStats calls the stats report function, if there is one.
+func (c *Client) reportStats(stats RequestStats) {
+	if c.statsReport == nil {
+		return
+	}
+	defer c.recoverHook("stats report", nil)
+	c.statsReport(stats)
+}
+
+// acceptGzip asks for a gzip compressed response, as http.Transport
+// does unless compression is turned off, but so that the client sees the
+// compressed size.
+func (c *Client) acceptGzip(r *http.Request) {
+	if r.Header.Get("Accept-Encoding") != "" {
+		return
+	}
+	transport := c.httpClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if t, ok := transport.(*http.Transport); ok && t.DisableCompression {
+		return
+	}
+	r.Header.Set("Accept-Encoding", "gzip")
+}
+
+// readResponse reads the body of res, decompressing it if needed, and
+// records its sizes in stats.
+func (c *Client) readResponse(res *http.Response, stats *RequestStats) ([]byte, error) {
+	wire := &countingReader{r: res.Body}
+	var r io.Reader = wire
+	if res.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(wire)
+		if err != nil {
+			return nil, errors.Wrap(err, "decompressing body")
+		}
+		defer gz.Close()
+		r = gz
+	}
+	body, err := c.readBody(r)
+	if err != nil {
+		return nil, err
+	}
+	stats.ResponseWireBytes = wire.n
+	stats.ResponseBytes = len(body)
+	return body, nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += n
+	return n, err
+}
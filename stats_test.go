@@ -0,0 +1,56 @@
+package graphql_test
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestRequestStats(t *testing.T) {
+	RegisterTestingT(t)
+	body := `{"data":{"items":["` + strings.Repeat("a", 1000) + `"]}}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			io.WriteString(w, body)
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		io.WriteString(gz, body)
+		gz.Close()
+	}))
+	defer srv.Close()
+	var reports []graphql.RequestStats
+	client := graphql.NewClient(srv.URL, graphql.WithStatsReport(func(stats graphql.RequestStats) {
+		reports = append(reports, stats)
+	}))
+	req := graphql.NewRequest(`query Items { items }`)
+	var resp struct {
+		Items []string
+	}
+	Expect(client.Run(context.Background(), req, &resp)).Should(Succeed())
+	Expect(resp.Items[0]).Should(HaveLen(1000))
+	Expect(reports).Should(HaveLen(1))
+	stats := reports[0]
+	Expect(stats.Operation).Should(Equal("Items"))
+	Expect(stats.RootFields).Should(Equal([]string{"items"}))
+	Expect(stats.RequestBytes).Should(Equal(len(`{"query":"query Items { items }"}`)))
+	Expect(stats.ResponseBytes).Should(Equal(len(body)))
+	Expect(stats.ResponseWireBytes).Should(BeNumerically(">", 0))
+	Expect(stats.ResponseWireBytes).Should(BeNumerically("<", 100))
+	Expect(stats.DecodeTime).Should(BeNumerically(">", 0))
+
+	result, err := graphql.Do[map[string]interface{}](context.Background(), client, req)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(result.Stats.ResponseBytes).Should(Equal(len(body)))
+
+	err = graphql.NewClient(srv.URL, graphql.WithMaxResponseSize(500)).Run(context.Background(), req, nil)
+	Expect(err).Should(BeAssignableToTypeOf(&graphql.DecodeError{}))
+}
@@ -0,0 +1,250 @@
+package graphql
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// RunTo runs req as Run does, but writes the data of the response to w
+// as it is read, without decoding or holding it in memory, for proxying
+// and exporting large results. The data is written as the server sent
+// it; it isn't passed through response plugins or handlers, and the
+// response size limit doesn't apply to it. Null data isn't written.
+//
+// Errors in the response are returned after the data is written, so w
+// may hold partial data.
+//  err := client.RunTo(ctx, req, f)
+func (c *Client) RunTo(ctx context.Context, req *Request, w io.Writer) error {
+	ctx = context.WithValue(ctx, streamKey{}, w)
+	res, err := c.do(ctx, req)
+	if err != nil {
+		return err
+	}
+	_, err = c.handleResponse(ctx, req, res, nil, false, nil)
+	return err
+}
+
+type streamKey struct{}
+
+// streamTo gets the writer the data of the response is streamed to, if
+// the request is run with RunTo.
+func streamTo(ctx context.Context) io.Writer {
+	w, _ := ctx.Value(streamKey{}).(io.Writer)
+	return w
+}
+
+// streamResponse copies the data of the body of res to w, and gets the
+// rest of the response, with data replaced by {}, or null if it was
+// null, for decoding.
+func (c *Client) streamResponse(res *http.Response, stats *RequestStats, w io.Writer) (string, []byte, error) {
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	wire := &countingReader{r: res.Body}
+	var r io.Reader = wire
+	if res.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(wire)
+		if err != nil {
+			return "", nil, errors.Wrap(err, "decompressing body")
+		}
+		defer gz.Close()
+		r = gz
+	}
+	counted := &countingReader{r: r}
+	br := bufio.NewReader(counted)
+	c.stripStreamPrefix(br)
+	s := &streamScanner{r: br}
+	body, err := s.envelope(w)
+	if err != nil {
+		return "", nil, err
+	}
+	stats.ResponseWireBytes = wire.n
+	stats.ResponseBytes = counted.n
+	return mediaType, body, nil
+}
+
+// streamScanner reads a GraphQL response object, copying its data.
+type streamScanner struct {
+	r      *bufio.Reader
+	offset int64
+}
+
+func (s *streamScanner) readByte() (byte, error) {
+	b, err := s.r.ReadByte()
+	if err == io.EOF {
+		return 0, s.errorf("unexpected end of response")
+	}
+	if err != nil {
+		return 0, err
+	}
+	s.offset++
+	return b, nil
+}
+
+// next reads the next byte that isn't white space.
+func (s *streamScanner) next() (byte, error) {
+	for {
+		b, err := s.readByte()
+		if err != nil {
+			return 0, err
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return b, nil
+	}
+}
+
+func (s *streamScanner) errorf(format string, args ...interface{}) error {
+	return &DecodeError{Offset: s.offset, Reason: fmt.Sprintf(format, args...)}
+}
+
+// envelope reads the response object, writing its data to w, and gets
+// the rest of it.
+func (s *streamScanner) envelope(w io.Writer) ([]byte, error) {
+	if b, err := s.next(); err != nil {
+		return nil, err
+	} else if b != '{' {
+		return nil, s.errorf("response is not a JSON object")
+	}
+	rest := make(map[string]json.RawMessage)
+	for {
+		b, err := s.next()
+		if err != nil {
+			return nil, err
+		}
+		if b == '}' && len(rest) == 0 {
+			break
+		}
+		if b != '"' {
+			return nil, s.errorf("expected object key")
+		}
+		var raw bytes.Buffer
+		raw.WriteByte(b)
+		if err := s.copyString(&raw); err != nil {
+			return nil, err
+		}
+		var key string
+		if err := json.Unmarshal(raw.Bytes(), &key); err != nil {
+			return nil, s.errorf("invalid object key")
+		}
+		if b, err := s.next(); err != nil {
+			return nil, err
+		} else if b != ':' {
+			return nil, s.errorf("expected : after object key")
+		}
+		first, err := s.next()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case key == "data" && first != 'n':
+			rest[key] = json.RawMessage("{}")
+			err = s.copyData(first, w)
+		case key == "data":
+			// null data isn't written, so that requests that are sent
+			// again, such as persisted queries, don't write it first
+			rest[key] = json.RawMessage("null")
+			err = s.copyValue(first, &bytes.Buffer{})
+		default:
+			var value bytes.Buffer
+			err = s.copyValue(first, &value)
+			rest[key] = value.Bytes()
+		}
+		if err != nil {
+			return nil, err
+		}
+		b, err = s.next()
+		if err != nil {
+			return nil, err
+		}
+		if b == '}' {
+			break
+		}
+		if b != ',' {
+			return nil, s.errorf("expected , or } after object value")
+		}
+	}
+	return json.Marshal(rest)
+}
+
+// copyData copies the data value starting with first to w.
+func (s *streamScanner) copyData(first byte, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if err := s.copyValue(first, bw); err != nil {
+		return err
+	}
+	return errors.Wrap(bw.Flush(), "graphql: writing data")
+}
+
+// copyValue copies the JSON value starting with first to w.
+func (s *streamScanner) copyValue(first byte, w io.ByteWriter) error {
+	depth := 0
+	b := first
+	for {
+		w.WriteByte(b)
+		switch b {
+		case '"':
+			if err := s.copyString(w); err != nil {
+				return err
+			}
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+		}
+		if depth < 0 {
+			return s.errorf("unbalanced %c", b)
+		}
+		if depth == 0 {
+			if b == '"' || b == '}' || b == ']' {
+				return nil
+			}
+			// a literal or number ends before the next delimiter
+			next, err := s.r.Peek(1)
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			switch next[0] {
+			case ' ', '\t', '\r', '\n', ',', '}', ']':
+				return nil
+			}
+		}
+		var err error
+		if b, err = s.readByte(); err != nil {
+			return err
+		}
+	}
+}
+
+// copyString copies the rest of a string, after its opening quote, to w.
+func (s *streamScanner) copyString(w io.ByteWriter) error {
+	for {
+		b, err := s.readByte()
+		if err != nil {
+			return err
+		}
+		w.WriteByte(b)
+		switch b {
+		case '\\':
+			b, err := s.readByte()
+			if err != nil {
+				return err
+			}
+			w.WriteByte(b)
+		case '"':
+			return nil
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package graphql_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestRunTo(t *testing.T) {
+	RegisterTestingT(t)
+	var body string
+	var compress bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if compress {
+			w.Header().Set("Content-Encoding", "gzip")
+			gz := gzip.NewWriter(w)
+			defer gz.Close()
+			io.WriteString(gz, body)
+			return
+		}
+		io.WriteString(w, body)
+	}))
+	defer srv.Close()
+	var stats graphql.RequestStats
+	client := graphql.NewClient(srv.URL, graphql.WithStatsReport(func(s graphql.RequestStats) {
+		stats = s
+	}))
+	req := graphql.NewRequest(`{ users { name } }`)
+
+	body = `{"extensions":{"cost":1}, "data" : {"users":[{"name":"a \"}"},{"name":"b"}],"n":[1,2.5e3,true,null]} }`
+	var buf bytes.Buffer
+	Expect(client.RunTo(context.Background(), req, &buf)).Should(Succeed())
+	Expect(buf.String()).Should(Equal(`{"users":[{"name":"a \"}"},{"name":"b"}],"n":[1,2.5e3,true,null]}`))
+	Expect(stats.ResponseBytes).Should(Equal(len(body)))
+
+	compress = true
+	buf.Reset()
+	Expect(client.RunTo(context.Background(), req, &buf)).Should(Succeed())
+	Expect(buf.String()).Should(Equal(`{"users":[{"name":"a \"}"},{"name":"b"}],"n":[1,2.5e3,true,null]}`))
+	compress = false
+
+	body = `{"data":{"users":[null]},"errors":[{"message":"not found","path":["users",0]}]}`
+	buf.Reset()
+	Expect(client.RunTo(context.Background(), req, &buf)).Should(MatchError("graphql: not found"))
+	Expect(buf.String()).Should(Equal(`{"users":[null]}`))
+
+	body = `{"errors":[{"message":"denied"}],"data":null}`
+	buf.Reset()
+	Expect(client.RunTo(context.Background(), req, &buf)).Should(MatchError("graphql: denied"))
+	Expect(buf.String()).Should(BeEmpty())
+
+	body = `{"data":{"users":[`
+	err := client.RunTo(context.Background(), req, &buf)
+	Expect(err).Should(BeAssignableToTypeOf(&graphql.DecodeError{}))
+}
+
+func TestRunToResponsePrefixes(t *testing.T) {
+	RegisterTestingT(t)
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, body)
+	}))
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL, graphql.WithResponsePrefixes())
+	req := graphql.NewRequest(`{ value }`)
+
+	body = ")]}'\n" + `{"data":{"value":"guarded"}}`
+	var buf bytes.Buffer
+	Expect(client.RunTo(context.Background(), req, &buf)).Should(Succeed())
+	Expect(buf.String()).Should(Equal(`{"value":"guarded"}`))
+
+	body = ` while(1);{"data":{"value":"loop"}}`
+	buf.Reset()
+	Expect(client.RunTo(context.Background(), req, &buf)).Should(Succeed())
+	Expect(buf.String()).Should(Equal(`{"value":"loop"}`))
+
+	body = `{"data":{"value":"plain"}}`
+	buf.Reset()
+	Expect(client.RunTo(context.Background(), req, &buf)).Should(Succeed())
+	Expect(buf.String()).Should(Equal(`{"value":"plain"}`))
+}
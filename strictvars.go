@@ -0,0 +1,50 @@
+package graphql
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// WithStrictVariables makes requests fail before they are sent if they
+// set variables the operation doesn't declare, which servers ignore, to
+// catch variables left behind when queries are edited.
+//  NewClient(endpoint, WithStrictVariables())
+func WithStrictVariables() ClientOption {
+	return ClientOption(func(client *Client) {
+		client.strictVariables = true
+	})
+}
+
+// checkUndeclaredVariables checks that the operation declares every
+// variable of the prepared request, if the client is strict about them.
+// Documents that can't be parsed are left for the server to reject.
+func (c *Client) checkUndeclaredVariables(req *Request) error {
+	if !c.strictVariables || len(req.Variables) == 0 {
+		return nil
+	}
+	doc, err := parseDocument(req.Query)
+	if err != nil {
+		return nil
+	}
+	op, err := doc.operation(req.OperationName)
+	if err != nil {
+		return nil
+	}
+	declared := make(map[string]bool, len(op.varDefs))
+	for _, vd := range op.varDefs {
+		declared[vd.name] = true
+	}
+	var undeclared []string
+	for name := range req.Variables {
+		if !declared[name] {
+			undeclared = append(undeclared, "$"+name)
+		}
+	}
+	if len(undeclared) == 0 {
+		return nil
+	}
+	sort.Strings(undeclared)
+	return errors.Errorf("graphql: variables not declared by the operation: %s", strings.Join(undeclared, ", "))
+}
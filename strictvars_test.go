@@ -0,0 +1,41 @@
+package graphql_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestStrictVariables(t *testing.T) {
+	RegisterTestingT(t)
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		io.WriteString(w, `{"data":{"user":null}}`)
+	}))
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL,
+		graphql.WithStrictVariables(),
+		graphql.WithDefaultVars(map[string]interface{}{"locale": "en"}),
+	)
+
+	req := graphql.NewRequest(`query User($id: ID!) { user(id: $id) { name } }`)
+	req.Var("id", "1")
+	Expect(client.Run(context.Background(), req, nil)).Should(Succeed())
+	Expect(calls).Should(Equal(1))
+
+	req.Var("userId", "1")
+	req.Var("expand", true)
+	err := client.Run(context.Background(), req, nil)
+	Expect(err).Should(MatchError("graphql: variables not declared by the operation: $expand, $userId"))
+	Expect(calls).Should(Equal(1))
+
+	err = graphql.NewClient(srv.URL).Run(context.Background(), req, nil)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(calls).Should(Equal(2))
+}
@@ -0,0 +1,338 @@
+package graphql
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// StripClientDirectives makes a Plugin that removes Apollo client-only
+// directives from documents before they are sent, for servers that reject
+// them as unknown. Fields marked @client are resolved locally, so they
+// are removed along with their selections; @connection directives are
+// dropped, keeping the field. Fields and fragments left with no
+// selections are removed too, as are variables only the removed fields
+// used, and their values.
+//  NewClient(endpoint, WithPlugin(StripClientDirectives()))
+func StripClientDirectives() Plugin {
+	return PluginFuncs{
+		Request: func(req *Request) error {
+			return stripRequest(req, []string{"client"}, []string{"connection"})
+		},
+	}
+}
+
+// StripDirectives makes a Plugin that removes the named directives from
+// documents before they are sent, keeping the fields they are applied to.
+// Variables only the directives used are removed, with their values.
+func StripDirectives(names ...string) Plugin {
+	return PluginFuncs{
+		Request: func(req *Request) error {
+			return stripRequest(req, nil, names)
+		},
+	}
+}
+
+// span is a range of bytes in a document.
+type span struct {
+	start, end int
+}
+
+// stripRequest strips the directives from the query of req, as
+// stripDirectives does, deleting the values of the variables removed.
+func stripRequest(req *Request, dropSelections, dropDirectives []string) error {
+	query, removed, err := stripDirectives(req.Query, dropSelections, dropDirectives)
+	if err != nil {
+		return err
+	}
+	req.Query = query
+	for _, name := range removed {
+		delete(req.Variables, name)
+	}
+	return nil
+}
+
+// stripDirectives removes selections carrying any of the dropSelections
+// directives, and occurrences of the dropDirectives directives.
+// Fields left with no selections are removed too, and so are fragments,
+// with their spreads. Variable definitions only the removed parts used
+// are removed, and their names returned.
+func stripDirectives(query string, dropSelections, dropDirectives []string) (string, []string, error) {
+	if !containsDirective(query, dropSelections) && !containsDirective(query, dropDirectives) {
+		return query, nil, nil
+	}
+	doc, err := parseDocument(query)
+	if err != nil {
+		return "", nil, err
+	}
+	s := &stripper{dropSelections: dropSelections, dropDirectives: dropDirectives, empty: make(map[string]bool)}
+	// removing a fragment removes its spreads, which may leave other
+	// fragments empty in turn
+	for changed := true; changed; {
+		changed = false
+		for _, frag := range doc.fragments {
+			if !s.empty[frag.name] && s.selections(frag.selections) == 0 {
+				s.empty[frag.name] = true
+				changed = true
+			}
+		}
+	}
+	s.cuts = nil
+	for _, op := range doc.operations {
+		s.directives(op.directives)
+		for _, v := range op.varDefs {
+			s.directives(v.directives)
+		}
+		if s.selections(op.selections) == 0 {
+			name := op.name
+			if name == "" {
+				name = op.opType
+			}
+			return "", nil, errors.Errorf("graphql: stripping directives leaves %s with no selections", name)
+		}
+	}
+	for _, frag := range doc.fragments {
+		if s.empty[frag.name] {
+			s.cuts = append(s.cuts, span{frag.start, frag.end})
+			continue
+		}
+		s.directives(frag.directives)
+		s.selections(frag.selections)
+	}
+	return pruneVariables(doc, cut(query, s.cuts))
+}
+
+// pruneVariables removes the variable definitions of the operations of
+// the stripped query that were used in the original document but no
+// longer are, returning the names of the variables no operation
+// declares any more.
+func pruneVariables(original *document, query string) (string, []string, error) {
+	doc, err := parseDocument(query)
+	if err != nil {
+		return "", nil, err
+	}
+	var cuts []span
+	declared := make(map[string]bool)
+	var pruned []string
+	for i, op := range doc.operations {
+		before := variablesUsed(original, original.operations[i])
+		after := variablesUsed(doc, op)
+		var opCuts []span
+		if len(op.varDefs) > 0 {
+			open := op.varDefsStart
+			// definitions are cut with the separator before them, or,
+			// up to the first one kept, after them
+			prev, kept := open+1, false
+			for _, v := range op.varDefs {
+				if before[v.name] && !after[v.name] {
+					opCuts = append(opCuts, span{prev, v.end})
+					pruned = append(pruned, v.name)
+				} else {
+					if !kept && len(opCuts) > 0 {
+						opCuts = append(opCuts, span{open + 1, v.start})
+					}
+					kept = true
+					declared[v.name] = true
+				}
+				prev = v.end
+			}
+			if !kept && len(opCuts) > 0 {
+				// an empty list of definitions isn't allowed, so the
+				// parentheses go too
+				opCuts = []span{{open, op.varDefsEnd + 1}}
+			}
+		}
+		cuts = append(cuts, opCuts...)
+	}
+	var removed []string
+	for _, name := range pruned {
+		if !declared[name] {
+			removed = append(removed, name)
+		}
+	}
+	return cut(query, cuts), removed, nil
+}
+
+// variablesUsed gets the names of the variables used by the operation,
+// including in the fragments it spreads.
+func variablesUsed(doc *document, op *operationDef) map[string]bool {
+	u := &variableUses{
+		fragments: make(map[string]*fragmentDef, len(doc.fragments)),
+		spread:    make(map[string]bool),
+		used:      make(map[string]bool),
+	}
+	for _, frag := range doc.fragments {
+		u.fragments[frag.name] = frag
+	}
+	u.directives(op.directives)
+	u.selections(op.selections)
+	return u.used
+}
+
+type variableUses struct {
+	fragments map[string]*fragmentDef
+	spread    map[string]bool
+	used      map[string]bool
+}
+
+func (u *variableUses) selections(selections []selection) {
+	for _, sel := range selections {
+		switch sel := sel.(type) {
+		case *field:
+			u.arguments(sel.args)
+			u.directives(sel.directives)
+			u.selections(sel.selections)
+		case *inlineFragment:
+			u.directives(sel.directives)
+			u.selections(sel.selections)
+		case *fragmentSpread:
+			u.directives(sel.directives)
+			frag := u.fragments[sel.name]
+			if frag == nil || u.spread[sel.name] {
+				continue
+			}
+			u.spread[sel.name] = true
+			u.directives(frag.directives)
+			u.selections(frag.selections)
+		}
+	}
+}
+
+func (u *variableUses) directives(directives []*directive) {
+	for _, d := range directives {
+		u.arguments(d.args)
+	}
+}
+
+func (u *variableUses) arguments(args []*argument) {
+	for _, arg := range args {
+		u.value(arg.value)
+	}
+}
+
+func (u *variableUses) value(v *value) {
+	if v == nil {
+		return
+	}
+	switch v.kind {
+	case valueVariable:
+		u.used[v.raw] = true
+	case valueList:
+		for _, item := range v.list {
+			u.value(item)
+		}
+	case valueObject:
+		u.arguments(v.fields)
+	}
+}
+
+type stripper struct {
+	dropSelections []string
+	dropDirectives []string
+	// empty holds the fragments left with no selections, whose spreads
+	// are removed.
+	empty map[string]bool
+	cuts  []span
+}
+
+// selections strips the selections, returning how many remain.
+func (s *stripper) selections(selections []selection) int {
+	remaining := 0
+	for _, sel := range selections {
+		var directives []*directive
+		var children []selection
+		switch sel := sel.(type) {
+		case *field:
+			directives, children = sel.directives, sel.selections
+		case *inlineFragment:
+			directives, children = sel.directives, sel.selections
+		case *fragmentSpread:
+			directives = sel.directives
+			if s.empty[sel.name] {
+				s.cuts = append(s.cuts, spanOf(sel))
+				continue
+			}
+		}
+		if hasDirective(directives, s.dropSelections) {
+			s.cuts = append(s.cuts, spanOf(sel))
+			continue
+		}
+		if len(children) > 0 {
+			before := len(s.cuts)
+			if s.selections(children) == 0 {
+				// discard the cuts inside the removed selection
+				s.cuts = append(s.cuts[:before], spanOf(sel))
+				continue
+			}
+		}
+		s.directives(directives)
+		remaining++
+	}
+	return remaining
+}
+
+func (s *stripper) directives(directives []*directive) {
+	for _, d := range directives {
+		if containsString(s.dropDirectives, d.name) {
+			s.cuts = append(s.cuts, span{d.start, d.end})
+		}
+	}
+}
+
+func spanOf(sel selection) span {
+	start, end := sel.span()
+	return span{start, end}
+}
+
+// cut removes the spans from s.
+func cut(s string, spans []span) string {
+	if len(spans) == 0 {
+		return s
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	var b strings.Builder
+	pos := 0
+	for _, sp := range spans {
+		if sp.start < pos {
+			// spans inside or overlapping one already cut
+			if sp.end > pos {
+				pos = sp.end
+			}
+			continue
+		}
+		b.WriteString(s[pos:sp.start])
+		pos = sp.end
+	}
+	b.WriteString(s[pos:])
+	return b.String()
+}
+
+func hasDirective(directives []*directive, names []string) bool {
+	for _, d := range directives {
+		if containsString(names, d.name) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsDirective is a quick check for whether the query might use
+// any of the directives, to avoid parsing documents that don't.
+func containsDirective(query string, names []string) bool {
+	for _, name := range names {
+		if strings.Contains(query, "@"+name) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
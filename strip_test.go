@@ -0,0 +1,125 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestStripClientDirectives(t *testing.T) {
+	RegisterTestingT(t)
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphql.Request
+		json.NewDecoder(r.Body).Decode(&req)
+		query = req.Query
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	client := graphql.NewClient(srv.URL, graphql.WithPlugin(graphql.StripClientDirectives()))
+	req := graphql.NewRequest(`query Feed($type: String) {
+		feed(type: $type) @connection(key: "feed", filter: ["type"]) {
+			id
+			isLiked @client
+			local @client { a b }
+		}
+		settings { theme @client }
+	}`)
+	err := client.Run(context.Background(), req, nil)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(strings.Join(strings.Fields(query), " ")).Should(Equal(`query Feed($type: String) { feed(type: $type) { id } }`))
+}
+
+func TestStripDirectives(t *testing.T) {
+	RegisterTestingT(t)
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphql.Request
+		json.NewDecoder(r.Body).Decode(&req)
+		query = req.Query
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	client := graphql.NewClient(srv.URL, graphql.WithPlugin(graphql.StripDirectives("internal")))
+	err := client.Run(context.Background(), graphql.NewRequest(`{ a @internal(x: 1) b }`), nil)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(query).Should(Equal(`{ a  b }`))
+}
+
+func TestStripClientDirectivesEmptySelections(t *testing.T) {
+	RegisterTestingT(t)
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphql.Request
+		json.NewDecoder(r.Body).Decode(&req)
+		query = req.Query
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL, graphql.WithPlugin(graphql.StripClientDirectives()))
+
+	// fragments with only client fields are removed with their spreads,
+	// and so are fields left with only those spreads
+	req := graphql.NewRequest(`query { user { id ...Local } settings { ...Local } }
+	fragment Local on User { isLiked @client theme @client }`)
+	Expect(client.Run(context.Background(), req, nil)).Should(Succeed())
+	Expect(strings.Join(strings.Fields(query), " ")).Should(Equal(`query { user { id } }`))
+
+	req = graphql.NewRequest(`{ a { b @client } c { d } }`)
+	Expect(client.Run(context.Background(), req, nil)).Should(Succeed())
+	Expect(strings.Join(strings.Fields(query), " ")).Should(Equal(`{ c { d } }`))
+
+	// operations can't be left empty
+	req = graphql.NewRequest(`query Local { isLoggedIn @client }`)
+	Expect(client.Run(context.Background(), req, nil)).Should(MatchError(ContainSubstring("leaves Local with no selections")))
+}
+
+func TestStripClientDirectivesUnusedVariables(t *testing.T) {
+	RegisterTestingT(t)
+	var received graphql.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = graphql.Request{}
+		json.NewDecoder(r.Body).Decode(&received)
+		io.WriteString(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL, graphql.WithPlugin(graphql.StripClientDirectives()), graphql.WithStrictVariables())
+
+	req := graphql.NewRequest(`query Feed($id: ID!, $draft: Boolean, $size: Int) {
+		feed(id: $id) { id preview(size: $size) @client }
+		drafts(include: $draft) @client { id }
+	}`)
+	req.Var("id", "1")
+	req.Var("draft", true)
+	req.Var("size", 10)
+	Expect(client.Run(context.Background(), req, nil)).Should(Succeed())
+	Expect(strings.Join(strings.Fields(received.Query), " ")).Should(Equal(`query Feed($id: ID!) { feed(id: $id) { id } }`))
+	Expect(received.Variables).Should(Equal(map[string]interface{}{"id": "1"}))
+	// the request is left as it was
+	Expect(req.Variables).Should(HaveLen(3))
+
+	req = graphql.NewRequest(`query Feed($a: Int, $b: Int, $c: Int) { feed(c: $c) { id x(a: $a, b: $b) @client } }`)
+	Expect(client.Run(context.Background(), req, nil)).Should(Succeed())
+	Expect(strings.Join(strings.Fields(received.Query), " ")).Should(Equal(`query Feed($c: Int) { feed(c: $c) { id } }`))
+
+	// parentheses in comments aren't taken for those of the definitions
+	req = graphql.NewRequest("query Q # (comment\n($b: Int) { z @client { w(b: $b) } x }")
+	req.Var("b", 1)
+	Expect(client.Run(context.Background(), req, nil)).Should(Succeed())
+	Expect(received.Query).Should(Equal("query Q # (comment\n {  x }"))
+
+	req = graphql.NewRequest(`query Local($id: ID!) { feed { id } item(id: $id) @client { id } }`)
+	req.Var("id", "1")
+	Expect(client.Run(context.Background(), req, nil)).Should(Succeed())
+	Expect(strings.Join(strings.Fields(received.Query), " ")).Should(Equal(`query Local { feed { id } }`))
+	Expect(received.Variables).Should(BeEmpty())
+}
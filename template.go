@@ -0,0 +1,120 @@
+package graphql
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Template is a query document with placeholders, for the rare cases
+// where the document itself must vary, such as choosing fields or
+// fragments at run time. Placeholders are written ${name}.
+//  tmpl := graphql.MustParseTemplate(`query { user(id: 1) { ${fields} ...${fragment} } }`)
+//  req, err := tmpl.Request(map[string]interface{}{
+//      "fields":   []string{"id", "name"},
+//      "fragment": "UserDetails",
+//  })
+// Placeholders can only be filled with GraphQL names, so a template can't
+// be made to run arbitrary GraphQL, even with values from user input.
+// Pass data as variables instead.
+type Template struct {
+	// parts alternates between literal text and placeholder names,
+	// starting with text.
+	parts []string
+}
+
+// ParseTemplate parses a query template.
+func ParseTemplate(src string) (*Template, error) {
+	t := &Template{}
+	for {
+		i := strings.Index(src, "${")
+		if i < 0 {
+			t.parts = append(t.parts, src)
+			return t, nil
+		}
+		end := strings.IndexByte(src[i:], '}')
+		if end < 0 {
+			return nil, errors.New("graphql: unterminated placeholder in template")
+		}
+		name := strings.TrimSpace(src[i+2 : i+end])
+		if !isName(name) {
+			return nil, errors.Errorf("graphql: bad placeholder %q in template", src[i:i+end+1])
+		}
+		t.parts = append(t.parts, src[:i], name)
+		src = src[i+end+1:]
+	}
+}
+
+// MustParseTemplate is like ParseTemplate but panics if the template
+// can't be parsed. It is for templates in package variables.
+func MustParseTemplate(src string) *Template {
+	t, err := ParseTemplate(src)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Execute fills the placeholders with values, and checks that the result
+// is a valid query document. Each value must be a string holding a
+// GraphQL name, or a []string of names, which are separated by spaces.
+func (t *Template) Execute(values map[string]interface{}) (string, error) {
+	var buf bytes.Buffer
+	for i, part := range t.parts {
+		if i%2 == 0 {
+			buf.WriteString(part)
+			continue
+		}
+		v, ok := values[part]
+		if !ok {
+			return "", errors.Errorf("graphql: no value for template placeholder %q", part)
+		}
+		switch v := v.(type) {
+		case string:
+			if !isName(v) {
+				return "", errors.Errorf("graphql: value %q for template placeholder %q is not a GraphQL name", v, part)
+			}
+			buf.WriteString(v)
+		case []string:
+			for j, name := range v {
+				if !isName(name) {
+					return "", errors.Errorf("graphql: value %q for template placeholder %q is not a GraphQL name", name, part)
+				}
+				if j > 0 {
+					buf.WriteByte(' ')
+				}
+				buf.WriteString(name)
+			}
+		default:
+			return "", errors.Errorf("graphql: value for template placeholder %q is a %T, not a string or []string", part, v)
+		}
+	}
+	query := buf.String()
+	if _, err := parseDocument(query); err != nil {
+		return "", errors.Wrap(err, "graphql: executing template")
+	}
+	return query, nil
+}
+
+// Request executes the template and makes a Request from it.
+func (t *Template) Request(values map[string]interface{}) (*Request, error) {
+	query, err := t.Execute(values)
+	if err != nil {
+		return nil, err
+	}
+	return NewRequest(query), nil
+}
+
+// isName reports whether s is a GraphQL name.
+func isName(s string) bool {
+	if s == "" || !isNameStart(s[0]) {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		if !isNameContinue(s[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,40 @@
+package graphql_test
+
+import (
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestTemplate(t *testing.T) {
+	RegisterTestingT(t)
+	tmpl, err := graphql.ParseTemplate(`query { user(id: 1) { ${fields} ...${ fragment } } } fragment Details on User { email }`)
+	Expect(err).ShouldNot(HaveOccurred())
+
+	req, err := tmpl.Request(map[string]interface{}{
+		"fields":   []string{"id", "name"},
+		"fragment": "Details",
+	})
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(req.Query).Should(Equal(`query { user(id: 1) { id name ...Details } } fragment Details on User { email }`))
+
+	_, err = tmpl.Execute(map[string]interface{}{
+		"fields":   []string{"id", "name } deleteUser(id: 1) {"},
+		"fragment": "Details",
+	})
+	Expect(err).Should(MatchError(`graphql: value "name } deleteUser(id: 1) {" for template placeholder "fields" is not a GraphQL name`))
+
+	_, err = tmpl.Execute(map[string]interface{}{"fields": "id"})
+	Expect(err).Should(MatchError(`graphql: no value for template placeholder "fragment"`))
+
+	_, err = tmpl.Execute(map[string]interface{}{"fields": 1, "fragment": "Details"})
+	Expect(err).Should(MatchError(`graphql: value for template placeholder "fields" is a int, not a string or []string`))
+
+	_, err = tmpl.Execute(map[string]interface{}{"fields": []string{"query"}, "fragment": "on"})
+	Expect(err).Should(MatchError(ContainSubstring("graphql: executing template")))
+
+	_, err = graphql.ParseTemplate(`{ ${a b} }`)
+	Expect(err).Should(MatchError(`graphql: bad placeholder "${a b}" in template`))
+	Expect(func() { graphql.MustParseTemplate(`{ ${a `) }).Should(Panic())
+}
@@ -0,0 +1,119 @@
+package graphql
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// TimeFormat is an encoding of timestamps, in responses or variables.
+type TimeFormat string
+
+// Time formats for decoding into time.Time.
+const (
+	// TimeRFC3339 decodes RFC 3339 strings, as encoding/json does.
+	TimeRFC3339 TimeFormat = "rfc3339"
+	// TimeISO8601 decodes ISO 8601 strings, including those without
+	// a time zone (taken as UTC) and dates without a time.
+	TimeISO8601 TimeFormat = "iso8601"
+	// TimeUnix decodes numbers of seconds since the Unix epoch.
+	TimeUnix TimeFormat = "unix"
+	// TimeUnixMillis decodes numbers of milliseconds since the Unix epoch.
+	TimeUnixMillis TimeFormat = "unixmillis"
+	// TimeDate decodes dates without a time, such as "2006-01-02".
+	TimeDate TimeFormat = "date"
+)
+
+// dateLayout is the layout of TimeDate values.
+const dateLayout = "2006-01-02"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// iso8601Layouts are the ISO 8601 forms accepted by TimeISO8601.
+var iso8601Layouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04",
+	"20060102T150405Z0700",
+	"20060102T150405",
+	"2006-01-02",
+	"20060102",
+}
+
+// WithTimeFormat sets how response values are decoded into time.Time
+// fields. A field can override the client's format with a tag:
+//  type Event struct {
+//      Start time.Time `graphql:"time=unixmillis"`
+//  }
+func WithTimeFormat(format TimeFormat) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.walker.timeFormat = format
+	})
+}
+
+// decodeTime converts v, encoded in the format, to an RFC 3339 string
+// that time.Time can unmarshal.
+func decodeTime(v interface{}, format TimeFormat, path string) (interface{}, error) {
+	var t time.Time
+	switch format {
+	case "", TimeRFC3339:
+		return v, nil
+	case TimeISO8601:
+		s, ok := v.(string)
+		if !ok {
+			return nil, timeError(v, format, path)
+		}
+		var err error
+		for _, layout := range iso8601Layouts {
+			if t, err = time.Parse(layout, s); err == nil {
+				break
+			}
+		}
+		if err != nil {
+			return nil, timeError(v, format, path)
+		}
+	case TimeDate:
+		s, ok := v.(string)
+		if !ok {
+			return nil, timeError(v, format, path)
+		}
+		var err error
+		if t, err = time.Parse(dateLayout, s); err != nil {
+			return nil, timeError(v, format, path)
+		}
+	case TimeUnix, TimeUnixMillis:
+		var n json.Number
+		switch v := v.(type) {
+		case json.Number:
+			n = v
+		case string:
+			n = json.Number(v)
+		default:
+			return nil, timeError(v, format, path)
+		}
+		i, err := strconv.ParseInt(string(n), 10, 64)
+		if err != nil {
+			return nil, timeError(v, format, path)
+		}
+		if format == TimeUnix {
+			t = time.Unix(i, 0)
+		} else {
+			t = time.Unix(0, i*int64(time.Millisecond))
+		}
+		t = t.UTC()
+	default:
+		return nil, &DecodeError{Offset: -1, Reason: "unknown time format " + strconv.Quote(string(format))}
+	}
+	return t.Format(time.RFC3339Nano), nil
+}
+
+func timeError(v interface{}, format TimeFormat, path string) error {
+	b, _ := json.Marshal(v)
+	return &DecodeError{
+		Offset: -1,
+		Reason: "cannot decode " + string(b) + " as " + string(format) + " time at data" + path,
+	}
+}
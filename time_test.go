@@ -0,0 +1,59 @@
+package graphql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestTimeFormat(t *testing.T) {
+	RegisterTestingT(t)
+	ctx := context.Background()
+	body := `{"data":{"created":"2018-03-01T10:20:30","updated":1519899630000,"events":[{"at":"20180301"}]}}`
+	client := bodyClient(body, graphql.WithTimeFormat(graphql.TimeISO8601))
+
+	var resp struct {
+		Created time.Time
+		Updated *time.Time `graphql:"time=unixmillis"`
+		Events  []struct {
+			At time.Time
+		}
+	}
+	err := client.Run(ctx, graphql.NewRequest(`{}`), &resp)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(resp.Created).Should(Equal(time.Date(2018, 3, 1, 10, 20, 30, 0, time.UTC)))
+	Expect(resp.Updated.Equal(resp.Created)).Should(BeTrue())
+	Expect(resp.Events[0].At).Should(Equal(time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC)))
+
+	err = bodyClient(`{"data":{"created":"yesterday"}}`, graphql.WithTimeFormat(graphql.TimeISO8601)).Run(ctx, graphql.NewRequest(`{}`), &resp)
+	_, ok := err.(*graphql.DecodeError)
+	Expect(ok).Should(BeTrue())
+	Expect(err.Error()).Should(ContainSubstring("data.created"))
+}
+
+// recursive types for TestTimeTagRecursive
+type eventNode struct {
+	Parent *eventParent
+	At     time.Time `graphql:"time=unix"`
+}
+
+type eventParent struct {
+	Node *eventNode
+}
+
+func TestTimeTagRecursive(t *testing.T) {
+	RegisterTestingT(t)
+	ctx := context.Background()
+	var node eventNode
+	err := bodyClient(`{"data":{"at":1519899630}}`).Run(ctx, graphql.NewRequest(`{}`), &node)
+	Expect(err).ShouldNot(HaveOccurred())
+
+	// eventParent was checked while eventNode was being checked
+	var parent eventParent
+	err = bodyClient(`{"data":{"node":{"at":1519899630}}}`).Run(ctx, graphql.NewRequest(`{}`), &parent)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(parent.Node.At.Unix()).Should(Equal(int64(1519899630)))
+}
@@ -0,0 +1,40 @@
+package graphql
+
+import (
+	"context"
+	"runtime/trace"
+)
+
+// WithRuntimeTrace runs each request in a runtime/trace task, logging
+// the operation name, with regions for sending the request and decoding
+// the response, so that calls show up in go tool trace. It costs little
+// when no trace is being taken.
+//  NewClient(endpoint, WithRuntimeTrace())
+func WithRuntimeTrace() ClientOption {
+	return ClientOption(func(client *Client) {
+		client.runtimeTrace = true
+	})
+}
+
+type traceTaskKey struct{}
+
+// traceTask starts the task of a request, unless the client doesn't
+// trace requests or ctx is already in the request's task, and gets the
+// context in the task and a function to end it.
+func (c *Client) traceTask(ctx context.Context, req *Request) (context.Context, func()) {
+	if !c.runtimeTrace || ctx.Value(traceTaskKey{}) != nil {
+		return ctx, func() {}
+	}
+	ctx, task := trace.NewTask(ctx, "graphql.Run")
+	trace.Log(ctx, "operation", req.MetricsName())
+	return context.WithValue(ctx, traceTaskKey{}, true), task.End
+}
+
+// traceRegion starts a region of the request's task, and gets a function
+// to end it.
+func (c *Client) traceRegion(ctx context.Context, name string) func() {
+	if !c.runtimeTrace {
+		return func() {}
+	}
+	return trace.StartRegion(ctx, name).End
+}
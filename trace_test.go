@@ -0,0 +1,36 @@
+package graphql_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime/trace"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestWithRuntimeTrace(t *testing.T) {
+	RegisterTestingT(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{"ok":true}}`)
+	}))
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL, graphql.WithRuntimeTrace())
+
+	var buf bytes.Buffer
+	if err := trace.Start(&buf); err != nil {
+		t.Skipf("tracing is unavailable: %v", err)
+	}
+	var resp map[string]interface{}
+	err := client.Run(context.Background(), graphql.NewRequest(`query TracedUsers { ok }`), &resp)
+	trace.Stop()
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(resp).Should(HaveKeyWithValue("ok", true))
+	for _, s := range []string{"graphql.Run", "operation", "TracedUsers", "graphql.send", "graphql.decode", "graphql.decodeResult"} {
+		Expect(buf.String()).Should(ContainSubstring(s))
+	}
+}
@@ -0,0 +1,174 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+// Transport is how the client sends requests over HTTP.
+type Transport int
+
+// Transports.
+const (
+	// TransportPOST sends every request in the body of a POST request.
+	TransportPOST Transport = iota
+	// TransportGET sends queries in the URL of a GET request, so that
+	// HTTP caches can cache them, if the URL is short enough. Other
+	// requests are posted.
+	TransportGET
+	// TransportPersisted sends automatic persisted queries: the hash of
+	// the query, and the query itself only if the server asks for it.
+	// Queries are sent with GET if the URL is short enough.
+	TransportPersisted
+	// TransportAuto picks the best transport the server supports, as
+	// found with Client.Capabilities: persisted queries if the server
+	// supports them, and GET for queries if the server accepts it.
+	// Requests are posted if the server can't be probed.
+	TransportAuto
+)
+
+// maxGETLength is the longest URL sent in a GET request; longer
+// requests are posted, since servers and proxies may reject long URLs.
+const maxGETLength = 2048
+
+// WithTransport sets how the client sends requests. The default is
+// TransportPOST. Batches are always posted.
+//  NewClient(endpoint, WithTransport(TransportAuto))
+func WithTransport(transport Transport) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.transport = transport
+	})
+}
+
+// transportPlan is how a request is sent.
+type transportPlan struct {
+	// get is whether the request may be sent with GET.
+	get bool
+	// persisted is whether the query is sent as a persisted query.
+	persisted bool
+}
+
+// plan decides how to send the prepared request.
+func (c *Client) plan(ctx context.Context, req *Request) transportPlan {
+	if c.transport == TransportPOST {
+		return transportPlan{}
+	}
+	op, err := req.Operation()
+	query := err == nil && op.Type == "query"
+	if c.transport == TransportAuto {
+		caps, err := c.Capabilities(ctx)
+		if err != nil {
+			return transportPlan{}
+		}
+		return transportPlan{get: query && caps.GET, persisted: caps.PersistedQueries}
+	}
+	return transportPlan{get: query, persisted: c.transport == TransportPersisted}
+}
+
+// send sends the prepared request, encoded as b, with the client's
+// transport.
+func (c *Client) send(ctx context.Context, req *Request, b []byte, header http.Header, stats *RequestStats) (*http.Response, string, []byte, error) {
+	plan := c.plan(ctx, req)
+	if !plan.get && !plan.persisted {
+		return c.post(ctx, b, header, stats)
+	}
+	if !plan.persisted {
+		return c.sendAs(ctx, req, plan.get, true, false, header, stats)
+	}
+	res, mediaType, body, err := c.sendAs(ctx, req, plan.get, false, true, header, stats)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	var graphResponse struct {
+		Errors Errors
+	}
+	json.Unmarshal(body, &graphResponse)
+	for _, e := range graphResponse.Errors {
+		switch {
+		case persistedQueryNotFound(e):
+			return c.sendAs(ctx, req, plan.get, true, true, header, stats)
+		case e.Message == "PersistedQueryNotSupported" || e.Extensions["code"] == "PERSISTED_QUERY_NOT_SUPPORTED":
+			return c.sendAs(ctx, req, plan.get, true, false, header, stats)
+		}
+	}
+	return res, mediaType, body, nil
+}
+
+// persistedQueryNotFound reports whether e is the error servers return
+// for persisted queries they don't have.
+func persistedQueryNotFound(e Error) bool {
+	return e.Message == "PersistedQueryNotFound" || e.Extensions["code"] == "PERSISTED_QUERY_NOT_FOUND"
+}
+
+// sendAs sends the prepared request with GET, if get is set and the URL
+// is short enough, or else POST, with the query if withQuery is set and
+// its hash if hash is set.
+func (c *Client) sendAs(ctx context.Context, req *Request, get, withQuery, hash bool, header http.Header, stats *RequestStats) (*http.Response, string, []byte, error) {
+	payload := struct {
+		OperationName string                 `json:"operationName,omitempty"`
+		Query         string                 `json:"query,omitempty"`
+		Variables     map[string]interface{} `json:"variables,omitempty"`
+		Extensions    map[string]interface{} `json:"extensions,omitempty"`
+	}{
+		OperationName: req.OperationName,
+		Variables:     req.Variables,
+	}
+	if withQuery {
+		payload.Query = req.Query
+	}
+	if hash {
+		payload.Extensions = map[string]interface{}{
+			"persistedQuery": map[string]interface{}{
+				"version":    1,
+				"sha256Hash": documentInfo(req.Query).hash,
+			},
+		}
+	}
+	if get {
+		endpoint, err := c.resolveEndpoint(ctx)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		q := u.Query()
+		if payload.OperationName != "" {
+			q.Set("operationName", payload.OperationName)
+		}
+		if payload.Query != "" {
+			q.Set("query", payload.Query)
+		}
+		if len(payload.Variables) > 0 {
+			b, err := json.Marshal(payload.Variables)
+			if err != nil {
+				return nil, "", nil, err
+			}
+			q.Set("variables", string(b))
+		}
+		if payload.Extensions != nil {
+			b, err := json.Marshal(payload.Extensions)
+			if err != nil {
+				return nil, "", nil, err
+			}
+			q.Set("extensions", string(b))
+		}
+		u.RawQuery = q.Encode()
+		if len(u.String()) <= maxGETLength {
+			r, err := http.NewRequest(http.MethodGet, u.String(), nil)
+			if err != nil {
+				return nil, "", nil, err
+			}
+			stats.RequestBytes = len(u.RawQuery)
+			return c.roundTrip(ctx, r, header, stats)
+		}
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return c.post(ctx, b, header, stats)
+}
@@ -0,0 +1,109 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+// apqServer serves GET and POST requests, supporting automatic persisted
+// queries, and records the method and whether a query was sent for
+// each request.
+func apqServer(calls *[]string) *httptest.Server {
+	store := make(map[string]string)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query      string
+			Extensions struct {
+				PersistedQuery *struct {
+					SHA256Hash string `json:"sha256Hash"`
+				} `json:"persistedQuery"`
+			}
+		}
+		if r.Method == http.MethodGet {
+			req.Query = r.URL.Query().Get("query")
+			json.Unmarshal([]byte(r.URL.Query().Get("extensions")), &req.Extensions)
+		} else {
+			b, _ := ioutil.ReadAll(r.Body)
+			json.Unmarshal(b, &req)
+		}
+		call := r.Method
+		if req.Query != "" {
+			call += " query"
+		}
+		if pq := req.Extensions.PersistedQuery; pq != nil {
+			call += " hash"
+			if req.Query == "" {
+				if req.Query = store[pq.SHA256Hash]; req.Query == "" {
+					*calls = append(*calls, call)
+					io.WriteString(w, `{"errors":[{"message":"PersistedQueryNotFound"}]}`)
+					return
+				}
+			}
+			store[pq.SHA256Hash] = req.Query
+		}
+		*calls = append(*calls, call)
+		io.WriteString(w, `{"data":{"ok":true}}`)
+	}))
+}
+
+func TestTransport(t *testing.T) {
+	RegisterTestingT(t)
+	var calls []string
+	srv := apqServer(&calls)
+	defer srv.Close()
+	ctx := context.Background()
+	var resp map[string]interface{}
+
+	client := graphql.NewClient(srv.URL, graphql.WithTransport(graphql.TransportGET))
+	Expect(client.Run(ctx, graphql.NewRequest(`query Ok { ok }`), &resp)).Should(Succeed())
+	Expect(client.Run(ctx, graphql.NewRequest(`mutation Ok { ok }`), &resp)).Should(Succeed())
+	long := graphql.NewRequest(`query Ok { ok }` + strings.Repeat(" ", 3000))
+	Expect(client.Run(ctx, long, &resp)).Should(Succeed())
+	Expect(calls).Should(Equal([]string{"GET query", "POST query", "POST query"}))
+
+	calls = nil
+	client = graphql.NewClient(srv.URL, graphql.WithTransport(graphql.TransportPersisted))
+	Expect(client.Run(ctx, graphql.NewRequest(`query Ok { ok }`), &resp)).Should(Succeed())
+	Expect(client.Run(ctx, graphql.NewRequest(`query Ok { ok }`), &resp)).Should(Succeed())
+	Expect(client.Run(ctx, graphql.NewRequest(`mutation Ok { ok }`), &resp)).Should(Succeed())
+	Expect(resp["ok"]).Should(BeTrue())
+	Expect(calls).Should(Equal([]string{"GET hash", "GET query hash", "GET hash", "POST hash", "POST query hash"}))
+}
+
+func TestTransportAuto(t *testing.T) {
+	RegisterTestingT(t)
+	var methods []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		io.WriteString(w, `{"data":{"ok":true}}`)
+	}))
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL, graphql.WithTransport(graphql.TransportAuto))
+	var resp map[string]interface{}
+	Expect(client.Run(context.Background(), graphql.NewRequest(`query Ok { ok }`), &resp)).Should(Succeed())
+	caps, err := client.Capabilities(context.Background())
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(caps.GET).Should(BeFalse())
+	Expect(methods[0]).Should(Equal(http.MethodGet))
+	Expect(methods[len(methods)-1]).Should(Equal(http.MethodPost))
+
+	var calls []string
+	apq := apqServer(&calls)
+	defer apq.Close()
+	client = graphql.NewClient(apq.URL, graphql.WithTransport(graphql.TransportAuto))
+	Expect(client.Run(context.Background(), graphql.NewRequest(`query Ok { ok }`), &resp)).Should(Succeed())
+	Expect(calls[len(calls)-2:]).Should(Equal([]string{"GET hash", "GET query hash"}))
+}
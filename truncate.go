@@ -0,0 +1,173 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Truncation is a list in a response that was cut short by
+// WithListLimit.
+type Truncation struct {
+	// Path is the path of the list in the response.
+	Path []interface{}
+	// Kept is the number of elements kept, and so the index of the first
+	// element dropped, of the Total in the response.
+	Kept  int
+	Total int
+	// Cursor is the cursor of the last edge kept, if the list is the
+	// edges of a connection, to fetch the rest from with after.
+	Cursor string
+}
+
+func (t Truncation) String() string {
+	s := fmt.Sprintf("list truncated to %d of %d elements", t.Kept, t.Total)
+	if t.Cursor != "" {
+		s += fmt.Sprintf("; continue after cursor %q", t.Cursor)
+	}
+	return s
+}
+
+// WithListLimit keeps at most maxElements elements, and at most about
+// maxBytes bytes of encoded elements, of each list in the data decoded
+// into results, for consumers that can't hold unexpectedly large results.
+// Zero means no limit. The first element of a list is always kept. Lists
+// that are cut short are given in Result.Truncated, and as
+// WarningTruncated warnings, so the rest can be fetched later.
+//
+// Lists are trimmed after the response body has been read, so this
+// bounds the results the caller decodes and keeps, not the memory used
+// to read the response; use WithMaxResponseSize to bound that.
+//  NewClient(endpoint, WithListLimit(1000, 1<<20))
+func WithListLimit(maxElements, maxBytes int) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.maxListElements = maxElements
+		client.maxListBytes = maxBytes
+	})
+}
+
+// truncateLists gets data with its lists cut to the client's limits.
+func (c *Client) truncateLists(data json.RawMessage) (json.RawMessage, []Truncation, error) {
+	if c.maxListElements <= 0 && c.maxListBytes <= 0 || len(data) == 0 {
+		return data, nil, nil
+	}
+	t := &truncator{
+		d:           json.NewDecoder(bytes.NewReader(data)),
+		maxElements: c.maxListElements,
+		maxBytes:    c.maxListBytes,
+	}
+	t.d.UseNumber()
+	if err := t.value(nil); err != nil {
+		return nil, nil, &DecodeError{Offset: t.d.InputOffset(), Reason: err.Error(), Err: err}
+	}
+	return t.out.Bytes(), t.truncations, nil
+}
+
+// truncator copies a JSON value token by token, cutting its lists short.
+type truncator struct {
+	d           *json.Decoder
+	out         bytes.Buffer
+	maxElements int
+	maxBytes    int
+	truncations []Truncation
+}
+
+// value copies the next value, at path.
+func (t *truncator) value(path []interface{}) error {
+	tok, err := t.d.Token()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case json.Delim('{'):
+		t.out.WriteByte('{')
+		for i := 0; t.d.More(); i++ {
+			key, err := t.d.Token()
+			if err != nil {
+				return err
+			}
+			if i > 0 {
+				t.out.WriteByte(',')
+			}
+			b, _ := json.Marshal(key)
+			t.out.Write(b)
+			t.out.WriteByte(':')
+			if err := t.value(append(path, key)); err != nil {
+				return err
+			}
+		}
+		t.out.WriteByte('}')
+		_, err = t.d.Token()
+		return err
+	case json.Delim('['):
+		return t.list(path)
+	}
+	b, err := json.Marshal(tok)
+	if err != nil {
+		return err
+	}
+	t.out.Write(b)
+	return nil
+}
+
+// list copies the rest of the list at path, after its [.
+func (t *truncator) list(path []interface{}) error {
+	t.out.WriteByte('[')
+	start := t.out.Len()
+	var cursor string
+	i := 0
+	for ; t.d.More(); i++ {
+		if t.maxElements > 0 && i >= t.maxElements || t.maxBytes > 0 && i > 0 && t.out.Len()-start >= t.maxBytes {
+			total := i
+			for ; t.d.More(); total++ {
+				if err := t.skip(); err != nil {
+					return err
+				}
+			}
+			t.truncations = append(t.truncations, Truncation{
+				Path:   append([]interface{}(nil), path...),
+				Kept:   i,
+				Total:  total,
+				Cursor: cursor,
+			})
+			break
+		}
+		if i > 0 {
+			t.out.WriteByte(',')
+		}
+		elem := t.out.Len()
+		if err := t.value(append(path, i)); err != nil {
+			return err
+		}
+		if len(path) > 0 && path[len(path)-1] == "edges" {
+			var edge struct {
+				Cursor string `json:"cursor"`
+			}
+			json.Unmarshal(t.out.Bytes()[elem:], &edge)
+			cursor = edge.Cursor
+		}
+	}
+	t.out.WriteByte(']')
+	_, err := t.d.Token()
+	return err
+}
+
+// skip reads the next value without copying it.
+func (t *truncator) skip() error {
+	depth := 0
+	for {
+		tok, err := t.d.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
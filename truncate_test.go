@@ -0,0 +1,77 @@
+package graphql_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestWithListLimit(t *testing.T) {
+	RegisterTestingT(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data":{
+			"tags": ["a", "b", "c", "d"],
+			"users": {"edges": [
+				{"cursor": "c1", "node": {"name": "a", "roles": [1, 2, 3]}},
+				{"cursor": "c2", "node": {"name": "b", "roles": []}},
+				{"cursor": "c3", "node": {"name": "c", "roles": [{"x": [1]}]}}
+			]},
+			"blob": ["xxxxxxxxxx", "yyyyyyyyyy", "zzzzzzzzzz"]
+		}}`)
+	}))
+	defer srv.Close()
+	var warnings []graphql.Warning
+	client := graphql.NewClient(srv.URL, graphql.WithListLimit(2, 0), graphql.WithWarningHandler(func(req *graphql.Request, w graphql.Warning) {
+		warnings = append(warnings, w)
+	}))
+	type data struct {
+		Tags  []string
+		Users struct {
+			Edges []struct {
+				Cursor string
+				Node   struct {
+					Name  string
+					Roles []int
+				}
+			}
+		}
+		Blob []string
+	}
+
+	result, err := graphql.Do[data](context.Background(), client, graphql.NewRequest(`{ tags users { edges { cursor node { name roles } } } blob }`))
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(result.Data.Tags).Should(Equal([]string{"a", "b"}))
+	Expect(result.Data.Users.Edges).Should(HaveLen(2))
+	Expect(result.Data.Users.Edges[0].Node.Roles).Should(Equal([]int{1, 2}))
+	Expect(result.Data.Blob).Should(HaveLen(2))
+	Expect(result.Truncated).Should(Equal([]graphql.Truncation{
+		{Path: []interface{}{"tags"}, Kept: 2, Total: 4},
+		{Path: []interface{}{"users", "edges", 0, "node", "roles"}, Kept: 2, Total: 3},
+		{Path: []interface{}{"users", "edges"}, Kept: 2, Total: 3, Cursor: "c2"},
+		{Path: []interface{}{"blob"}, Kept: 2, Total: 3},
+	}))
+	Expect(result.Warnings).Should(ContainElement(graphql.Warning{
+		Kind:    graphql.WarningTruncated,
+		Message: `list truncated to 2 of 3 elements; continue after cursor "c2"`,
+		Path:    []interface{}{"users", "edges"},
+	}))
+
+	warnings = nil
+	var resp data
+	Expect(client.Run(context.Background(), graphql.NewRequest(`{ tags }`), &resp)).Should(Succeed())
+	Expect(resp.Tags).Should(HaveLen(2))
+	Expect(warnings).Should(HaveLen(4))
+
+	// elements are kept until the list has at least maxBytes
+	client = graphql.NewClient(srv.URL, graphql.WithListLimit(0, 20))
+	result, err = graphql.Do[data](context.Background(), client, graphql.NewRequest(`{ blob }`))
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(result.Data.Tags).Should(HaveLen(4))
+	Expect(result.Data.Users.Edges).Should(HaveLen(1))
+	Expect(result.Data.Blob).Should(Equal([]string{"xxxxxxxxxx", "yyyyyyyyyy"}))
+}
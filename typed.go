@@ -0,0 +1,307 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ScalarDecoder converts a scalar value, as decoded from JSON with
+// numbers kept as json.Number, into a Go value.
+type ScalarDecoder func(v interface{}) (interface{}, error)
+
+// DefaultScalarDecoders are the decoders used by WithTypedScalars when
+// none are given. They decode Int and Long to int64, Float to float64,
+// BigInt to *big.Int, and DateTime and Date to time.Time.
+var DefaultScalarDecoders = map[string]ScalarDecoder{
+	"Int":      decodeInt64,
+	"Long":     decodeInt64,
+	"Float":    decodeFloat64,
+	"BigInt":   decodeBigInt,
+	"DateTime": decodeDateTime,
+	"Date":     decodeDate,
+}
+
+// WithTypedScalars makes responses decoded into a map[string]interface{}
+// or interface{} hold Go types for scalar fields, rather than strings and
+// float64s, so dynamic consumers needn't parse them. It uses the client's
+// schema (see WithSchema) to find the type of each field in the query.
+// Fragments on the members of unions and interfaces are only applied to
+// objects whose __typename is selected and matches.
+// decoders maps scalar type names to decoders; if nil,
+// DefaultScalarDecoders is used. Fields of other types decode as usual.
+//  NewClient(endpoint, WithSchema(schema), WithTypedScalars(nil))
+func WithTypedScalars(decoders map[string]ScalarDecoder) ClientOption {
+	return ClientOption(func(client *Client) {
+		if decoders == nil {
+			decoders = DefaultScalarDecoders
+		}
+		client.scalarDecoders = decoders
+	})
+}
+
+// decodeResult decodes the data of the response to req into resp,
+// with typed scalars if resp is dynamic and the client uses them, and
+// gets warnings about optional fields that couldn't be decoded.
+func (c *Client) decodeResult(req *Request, data json.RawMessage, resp interface{}) ([]Warning, error) {
+	schema := c.currentSchema()
+	if c.scalarDecoders == nil || schema == nil || len(data) == 0 {
+		return c.decodeDataWarnings(data, resp)
+	}
+	var set func(v interface{})
+	switch resp := resp.(type) {
+	case *map[string]interface{}:
+		set = func(v interface{}) {
+			m, _ := v.(map[string]interface{})
+			*resp = m
+		}
+	case *interface{}:
+		set = func(v interface{}) { *resp = v }
+	default:
+		return c.decodeDataWarnings(data, resp)
+	}
+	doc, err := parseDocument(req.Query)
+	if err != nil {
+		return c.decodeDataWarnings(data, resp)
+	}
+	if err := checkJSON(data, c.maxDepth); err != nil {
+		return nil, err
+	}
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return nil, &DecodeError{Offset: -1, Reason: err.Error(), Err: err}
+	}
+	td := &typedDecoder{
+		schema:    schema,
+		decoders:  c.scalarDecoders,
+		fragments: make(map[string]*fragmentDef),
+	}
+	for _, frag := range doc.fragments {
+		td.fragments[frag.name] = frag
+	}
+	for _, op := range doc.operations {
+		if req.OperationName != "" && op.name != req.OperationName {
+			continue
+		}
+		if root := schema.RootType(op.opType); root != nil {
+			if err := td.selections(v, root, op.selections, ""); err != nil {
+				return nil, err
+			}
+		}
+	}
+	set(plainNumbers(v))
+	return nil, nil
+}
+
+type typedDecoder struct {
+	schema    *Schema
+	decoders  map[string]ScalarDecoder
+	fragments map[string]*fragmentDef
+}
+
+func (td *typedDecoder) selections(v interface{}, parent *SchemaType, selections []selection, path string) error {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	for _, sel := range selections {
+		switch sel := sel.(type) {
+		case *field:
+			def := parent.Field(sel.name)
+			key := sel.responseKey()
+			value, ok := obj[key]
+			if def == nil || !ok {
+				continue
+			}
+			converted, err := td.value(value, def.Type, sel.selections, joinPath(path, key))
+			if err != nil {
+				return err
+			}
+			obj[key] = converted
+		case *inlineFragment:
+			t := td.fragmentType(obj, parent, sel.typeCondition)
+			if t == nil {
+				continue
+			}
+			if err := td.selections(obj, t, sel.selections, path); err != nil {
+				return err
+			}
+		case *fragmentSpread:
+			frag, ok := td.fragments[sel.name]
+			if !ok {
+				continue
+			}
+			t := td.fragmentType(obj, parent, frag.typeCondition)
+			if t == nil {
+				continue
+			}
+			if err := td.selections(obj, t, frag.selections, path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fragmentType gets the type of a fragment on the type condition if it
+// applies to obj, of the parent type, or nil if it doesn't: if obj's
+// __typename is the type, or one of its possible types. Without a
+// __typename, only fragments on the parent type, or on abstract types of
+// an object parent, are known to apply.
+func (td *typedDecoder) fragmentType(obj map[string]interface{}, parent *SchemaType, condition string) *SchemaType {
+	if condition == "" || condition == parent.Name {
+		return parent
+	}
+	t := td.schema.Type(condition)
+	if t == nil {
+		return nil
+	}
+	typename, _ := obj["__typename"].(string)
+	if typename == "" && parent.Kind == KindObject {
+		typename = parent.Name
+	}
+	if typename == "" {
+		return nil
+	}
+	if typename == t.Name {
+		return t
+	}
+	for _, possible := range t.PossibleTypes {
+		if possible.Name == typename {
+			return t
+		}
+	}
+	return nil
+}
+
+func (td *typedDecoder) value(v interface{}, t *TypeRef, selections []selection, path string) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch t.Kind {
+	case KindNonNull:
+		return td.value(v, t.OfType, selections, path)
+	case KindList:
+		list, ok := v.([]interface{})
+		if !ok {
+			return v, nil
+		}
+		for i := range list {
+			converted, err := td.value(list[i], t.OfType, selections, path+"["+strconv.Itoa(i)+"]")
+			if err != nil {
+				return nil, err
+			}
+			list[i] = converted
+		}
+		return list, nil
+	}
+	named := td.schema.Type(t.Name)
+	if named == nil {
+		return v, nil
+	}
+	if named.isComposite() {
+		return v, td.selections(v, named, selections, path)
+	}
+	dec, ok := td.decoders[named.Name]
+	if !ok {
+		return v, nil
+	}
+	switch v.(type) {
+	case string, json.Number, bool:
+	default:
+		// already converted by an earlier selection of the field
+		return v, nil
+	}
+	converted, err := dec(v)
+	if err != nil {
+		return nil, &DecodeError{Offset: -1, Reason: "cannot decode " + named.Name + " at data." + path, Err: err}
+	}
+	return converted, nil
+}
+
+// plainNumbers replaces the json.Numbers left in v with float64s, as
+// encoding/json decodes them.
+func plainNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = plainNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = plainNumbers(item)
+		}
+	}
+	return v
+}
+
+func scalarNumber(v interface{}) (json.Number, error) {
+	switch v := v.(type) {
+	case json.Number:
+		return v, nil
+	case string:
+		return json.Number(v), nil
+	}
+	return "", errors.Errorf("%v is not a number", v)
+}
+
+func decodeInt64(v interface{}) (interface{}, error) {
+	n, err := scalarNumber(v)
+	if err != nil {
+		return nil, err
+	}
+	return n.Int64()
+}
+
+func decodeFloat64(v interface{}) (interface{}, error) {
+	n, err := scalarNumber(v)
+	if err != nil {
+		return nil, err
+	}
+	return n.Float64()
+}
+
+func decodeBigInt(v interface{}) (interface{}, error) {
+	n, err := scalarNumber(v)
+	if err != nil {
+		return nil, err
+	}
+	i, ok := new(big.Int).SetString(string(n), 10)
+	if !ok {
+		return nil, errors.Errorf("%q is not an integer", string(n))
+	}
+	return i, nil
+}
+
+func decodeDateTime(v interface{}) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, errors.Errorf("%v is not a string", v)
+	}
+	var err error
+	for _, layout := range iso8601Layouts {
+		var t time.Time
+		if t, err = time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return nil, err
+}
+
+func decodeDate(v interface{}) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, errors.Errorf("%v is not a string", v)
+	}
+	return time.Parse(dateLayout, strings.TrimSpace(s))
+}
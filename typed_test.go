@@ -0,0 +1,98 @@
+package graphql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestTypedScalars(t *testing.T) {
+	RegisterTestingT(t)
+	client := bodyClient(`{"data":{
+		"user": {"id": "1", "createdAt": "2020-05-17T10:30:00Z", "friends": [{"createdAt": "2021-01-02T00:00:00Z"}]},
+		"users": [{"id": "2", "role": "ADMIN"}],
+		"search": [{"__typename": "User", "created": "2019-01-01T00:00:00Z"}],
+		"extra": 1
+	}}`, graphql.WithSchema(loadSchema()), graphql.WithTypedScalars(nil))
+	req := graphql.NewRequest(`query {
+		user(id: 1) { id createdAt friends { ...Created } }
+		users { id role }
+		search { __typename ... on User { created: createdAt } }
+	}
+	fragment Created on User { createdAt }`)
+
+	var resp map[string]interface{}
+	err := client.Run(context.Background(), req, &resp)
+	Expect(err).ShouldNot(HaveOccurred())
+	user := resp["user"].(map[string]interface{})
+	Expect(user["id"]).Should(Equal("1"))
+	Expect(user["createdAt"]).Should(Equal(time.Date(2020, 5, 17, 10, 30, 0, 0, time.UTC)))
+	friend := user["friends"].([]interface{})[0].(map[string]interface{})
+	Expect(friend["createdAt"]).Should(BeAssignableToTypeOf(time.Time{}))
+	Expect(resp["users"]).Should(Equal([]interface{}{map[string]interface{}{"id": "2", "role": "ADMIN"}}))
+	result := resp["search"].([]interface{})[0].(map[string]interface{})
+	Expect(result["created"]).Should(BeAssignableToTypeOf(time.Time{}))
+	Expect(resp["extra"]).Should(Equal(float64(1)))
+
+	// structs decode as usual
+	var typed struct {
+		User struct {
+			CreatedAt time.Time
+		}
+	}
+	err = client.Run(context.Background(), req, &typed)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(typed.User.CreatedAt.Year()).Should(Equal(2020))
+
+	client = bodyClient(`{"data":{"users":[{"id":"1","createdAt":"yesterday"}]}}`,
+		graphql.WithSchema(loadSchema()), graphql.WithTypedScalars(nil))
+	err = client.Run(context.Background(), graphql.NewRequest(`{ users { createdAt } }`), &resp)
+	Expect(err).Should(MatchError(ContainSubstring("cannot decode DateTime at data.users[0].createdAt")))
+}
+
+func TestTypedScalarsUnion(t *testing.T) {
+	RegisterTestingT(t)
+	named := func(kind, name string) string {
+		return `{"kind":"` + kind + `","name":"` + name + `"}`
+	}
+	field := func(name, typ string) string {
+		return `{"name":"` + name + `","args":[],"type":` + typ + `}`
+	}
+	schema, err := graphql.ParseSchema([]byte(`{"__schema":{"queryType":{"name":"Query"},"types":[
+		{"kind":"OBJECT","name":"Query","fields":[` + field("search", `{"kind":"LIST","ofType":`+named("UNION", "SearchResult")+`}`) + `]},
+		{"kind":"UNION","name":"SearchResult","possibleTypes":[` + named("OBJECT", "User") + `,` + named("OBJECT", "Post") + `]},
+		{"kind":"OBJECT","name":"User","fields":[` + field("joined", named("SCALAR", "DateTime")) + `]},
+		{"kind":"OBJECT","name":"Post","fields":[` + field("posted", named("SCALAR", "String")) + `]},
+		{"kind":"SCALAR","name":"DateTime"},
+		{"kind":"SCALAR","name":"String"}
+	]}}`))
+	Expect(err).ShouldNot(HaveOccurred())
+	client := bodyClient(`{"data":{"search":[
+		{"__typename": "User", "at": "2019-01-01T00:00:00Z"},
+		{"__typename": "Post", "at": "last week"}
+	]}}`, graphql.WithSchema(schema), graphql.WithTypedScalars(nil))
+	req := graphql.NewRequest(`{
+		search { __typename ... on User { at: joined } ...PostFields }
+	}
+	fragment PostFields on Post { at: posted }`)
+
+	var resp map[string]interface{}
+	Expect(client.Run(context.Background(), req, &resp)).Should(Succeed())
+	results := resp["search"].([]interface{})
+	Expect(results[0].(map[string]interface{})["at"]).Should(Equal(time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)))
+	Expect(results[1].(map[string]interface{})["at"]).Should(Equal("last week"))
+
+	// types are mapped from the document as sent, here by a plugin
+	client = bodyClient(`{"data":{"search":[{"__typename": "User", "at": "2019-01-01T00:00:00Z"}]}}`,
+		graphql.WithSchema(schema), graphql.WithTypedScalars(nil),
+		graphql.WithPlugin(graphql.PluginFuncs{Request: func(req *graphql.Request) error {
+			req.Query = `{ search { __typename ... on User { at: joined } } }`
+			return nil
+		}}))
+	Expect(client.Run(context.Background(), graphql.NewRequest(`{ search { __typename } }`), &resp)).Should(Succeed())
+	results = resp["search"].([]interface{})
+	Expect(results[0].(map[string]interface{})["at"]).Should(BeAssignableToTypeOf(time.Time{}))
+}
@@ -0,0 +1,426 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Validate checks a query document against the schema, the way a server
+// does before executing it: that the fields, arguments, types, fragments
+// and variables it uses exist and fit together. It returns nil if the
+// document is valid.
+//
+// Fragments are documents of shared fragment definitions that the query
+// may use. Only the fragments used are checked, and errors in them are
+// reported without locations, since they are in another document.
+//
+// Directives are not checked, since introspection doesn't describe
+// where they may be used.
+func (s *Schema) Validate(query string, fragments ...string) Errors {
+	return s.check(query, fragments).errs
+}
+
+// check checks a query document and its shared fragments against
+// the schema.
+func (s *Schema) check(query string, fragments []string) *validator {
+	doc, err := parseDocument(query)
+	if err != nil {
+		return &validator{errs: Errors{{Message: strings.TrimPrefix(err.Error(), "graphql: ")}}}
+	}
+	v := &validator{
+		schema:    s,
+		src:       query,
+		fragments: make(map[string]*fragmentDef),
+		external:  make(map[*fragmentDef]bool),
+		seen:      make(map[string]bool),
+	}
+	for _, frag := range doc.fragments {
+		if _, ok := v.fragments[frag.name]; ok {
+			v.errorf(frag.start, "There can be only one fragment named %q", frag.name)
+			continue
+		}
+		v.fragments[frag.name] = frag
+	}
+	for _, src := range fragments {
+		shared, err := parseDocument(src)
+		if err != nil {
+			return &validator{errs: Errors{{Message: "fragments: " + strings.TrimPrefix(err.Error(), "graphql: ")}}}
+		}
+		for _, frag := range shared.fragments {
+			if _, ok := v.fragments[frag.name]; !ok {
+				v.fragments[frag.name] = frag
+				v.external[frag] = true
+			}
+		}
+	}
+	used := make(map[string]bool)
+	for _, op := range doc.operations {
+		v.operation(op)
+		for name := range v.spread {
+			used[name] = true
+		}
+	}
+	// fragments no operation uses are still checked, since they may be
+	// used by operations in other documents
+	v.op = nil
+	for _, frag := range doc.fragments {
+		if !used[frag.name] {
+			v.spread = map[string]bool{frag.name: true}
+			v.fragment(frag)
+		}
+	}
+	return v
+}
+
+type validator struct {
+	schema    *Schema
+	src       string
+	fragments map[string]*fragmentDef
+	external  map[*fragmentDef]bool
+	errs      Errors
+	seen      map[string]bool
+	// deprecations are the uses of deprecated fields and enum values.
+	deprecations []Deprecation
+
+	// op is the operation being checked, or nil when checking a fragment
+	// on its own.
+	op     *operationDef
+	vars   map[string]*varDef
+	used   map[string]bool
+	spread map[string]bool
+	// inExternal is the name of the fragment from another document
+	// being checked.
+	inExternal string
+}
+
+func (v *validator) errorf(offset int, format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	var locations []Location
+	if v.inExternal != "" {
+		msg += fmt.Sprintf(" (in fragment %q)", v.inExternal)
+	} else {
+		locations = []Location{location(v.src, offset)}
+	}
+	key := fmt.Sprintf("%v:%s", locations, msg)
+	if v.seen[key] {
+		return
+	}
+	v.seen[key] = true
+	v.errs = append(v.errs, Error{Message: msg, Locations: locations})
+}
+
+// location gets the line and column of the byte offset in src.
+func location(src string, offset int) Location {
+	before := src[:offset]
+	line := strings.Count(before, "\n") + 1
+	column := offset - strings.LastIndex(before, "\n")
+	return Location{Line: line, Column: column}
+}
+
+func (v *validator) operation(op *operationDef) {
+	v.op = op
+	v.vars = make(map[string]*varDef)
+	v.used = make(map[string]bool)
+	v.spread = make(map[string]bool)
+	root := v.schema.RootType(op.opType)
+	if root == nil {
+		v.errorf(op.start, "Schema does not support %s operations", op.opType)
+		return
+	}
+	for _, vd := range op.varDefs {
+		if _, ok := v.vars[vd.name]; ok {
+			v.errorf(vd.start, "There can be only one variable named \"$%s\"", vd.name)
+			continue
+		}
+		v.vars[vd.name] = vd
+		t := v.schema.Type(namedType(vd.typ))
+		switch {
+		case t == nil:
+			v.errorf(vd.start, "Unknown type %q", namedType(vd.typ))
+		case !t.isInput():
+			v.errorf(vd.start, "Variable \"$%s\" cannot be non-input type %q", vd.name, vd.typ)
+		}
+	}
+	v.selections(root, op.selections)
+	for _, vd := range op.varDefs {
+		if !v.used[vd.name] {
+			v.errorf(vd.start, "Variable \"$%s\" is never used%s", vd.name, v.inOperation())
+		}
+	}
+}
+
+func (v *validator) inOperation() string {
+	if v.op.name == "" {
+		return ""
+	}
+	return fmt.Sprintf(" in operation %q", v.op.name)
+}
+
+func (v *validator) fragment(frag *fragmentDef) {
+	if v.external[frag] {
+		defer func(name string) { v.inExternal = name }(v.inExternal)
+		v.inExternal = frag.name
+	}
+	t := v.schema.Type(frag.typeCondition)
+	switch {
+	case t == nil:
+		v.errorf(frag.start, "Unknown type %q", frag.typeCondition)
+	case !t.isComposite():
+		v.errorf(frag.start, "Fragment %q cannot condition on non composite type %q", frag.name, frag.typeCondition)
+	default:
+		v.directives(frag.directives, frag.start)
+		v.selections(t, frag.selections)
+	}
+}
+
+func (v *validator) selections(parent *SchemaType, selections []selection) {
+	for _, sel := range selections {
+		switch sel := sel.(type) {
+		case *field:
+			v.field(parent, sel)
+		case *inlineFragment:
+			v.directives(sel.directives, sel.start)
+			t := parent
+			if sel.typeCondition != "" {
+				t = v.schema.Type(sel.typeCondition)
+				if t == nil {
+					v.errorf(sel.start, "Unknown type %q", sel.typeCondition)
+					continue
+				}
+				if !t.isComposite() {
+					v.errorf(sel.start, "Fragment cannot condition on non composite type %q", sel.typeCondition)
+					continue
+				}
+			}
+			v.selections(t, sel.selections)
+		case *fragmentSpread:
+			v.directives(sel.directives, sel.start)
+			frag, ok := v.fragments[sel.name]
+			if !ok {
+				v.errorf(sel.start, "Unknown fragment %q", sel.name)
+				continue
+			}
+			if v.spread[sel.name] {
+				continue
+			}
+			v.spread[sel.name] = true
+			v.fragment(frag)
+		}
+	}
+}
+
+func (v *validator) field(parent *SchemaType, f *field) {
+	v.directives(f.directives, f.start)
+	switch {
+	case f.name == "__typename":
+		return
+	case (f.name == "__schema" || f.name == "__type") && parent.Name == v.schema.QueryType:
+		return
+	}
+	def := parent.Field(f.name)
+	if def == nil {
+		v.errorf(f.start, "Cannot query field %q on type %q", f.name, parent.Name)
+		return
+	}
+	if def.IsDeprecated {
+		v.deprecated(f.start, parent.Name+"."+f.name, def.DeprecationReason)
+	}
+	given := make(map[string]bool)
+	for _, arg := range f.args {
+		given[arg.name] = true
+		ad := def.Arg(arg.name)
+		if ad == nil {
+			v.errorf(f.start, "Unknown argument %q on field \"%s.%s\"", arg.name, parent.Name, f.name)
+			continue
+		}
+		v.value(arg.value, ad.Type, ad.DefaultValue != nil, f.start)
+	}
+	for _, ad := range def.Args {
+		if ad.Type.Kind == KindNonNull && ad.DefaultValue == nil && !given[ad.Name] {
+			v.errorf(f.start, "Field \"%s.%s\" argument %q of type %q is required, but it was not provided", parent.Name, f.name, ad.Name, ad.Type)
+		}
+	}
+	t := v.schema.Type(def.Type.NamedType())
+	if t == nil {
+		return
+	}
+	switch {
+	case t.isComposite() && len(f.selections) == 0:
+		v.errorf(f.start, "Field %q of type %q must have a selection of subfields", f.name, def.Type)
+	case !t.isComposite() && len(f.selections) > 0:
+		v.errorf(f.start, "Field %q must not have a selection since type %q has no subfields", f.name, def.Type)
+	case t.isComposite():
+		v.selections(t, f.selections)
+	}
+}
+
+// directives checks the variables used by directive arguments.
+func (v *validator) directives(directives []*directive, offset int) {
+	for _, d := range directives {
+		for _, arg := range d.args {
+			v.value(arg.value, nil, false, offset)
+		}
+	}
+}
+
+// value checks that val fits the type t. If t is nil, only its
+// variables are checked. hasDefault is whether the location val is in
+// has a default, which lets nullable variables be used for non-null
+// types.
+func (v *validator) value(val *value, t *TypeRef, hasDefault bool, offset int) {
+	if val.kind == valueVariable {
+		v.variable(val.raw, t, hasDefault, offset)
+		return
+	}
+	if t == nil {
+		for _, item := range val.list {
+			v.value(item, nil, false, offset)
+		}
+		for _, f := range val.fields {
+			v.value(f.value, nil, false, offset)
+		}
+		return
+	}
+	if val.kind == valueNull {
+		if t.Kind == KindNonNull {
+			v.errorf(offset, "Expected value of type %q, found null", t)
+		}
+		return
+	}
+	if t.Kind == KindNonNull {
+		t = t.OfType
+	}
+	if t.Kind == KindList {
+		if val.kind != valueList {
+			// a single value is coerced to a list of one
+			v.value(val, t.OfType, false, offset)
+			return
+		}
+		for _, item := range val.list {
+			v.value(item, t.OfType, false, offset)
+		}
+		return
+	}
+	named := v.schema.Type(t.Name)
+	if named == nil {
+		return
+	}
+	switch named.Kind {
+	case KindEnum:
+		if val.kind != valueEnum {
+			v.errorf(offset, "Enum %q cannot represent non-enum value: %s", named.Name, describeValue(val))
+		} else if ev := named.EnumValue(val.raw); ev == nil {
+			v.errorf(offset, "Value %q does not exist in %q enum", val.raw, named.Name)
+		} else if ev.IsDeprecated {
+			v.deprecated(offset, named.Name+"."+ev.Name, ev.DeprecationReason)
+		}
+	case KindInputObject:
+		if val.kind != valueObject {
+			v.errorf(offset, "Expected value of type %q, found %s", named.Name, describeValue(val))
+			return
+		}
+		given := make(map[string]bool)
+		for _, f := range val.fields {
+			given[f.name] = true
+			fd := named.InputField(f.name)
+			if fd == nil {
+				v.errorf(offset, "Field %q is not defined by type %q", f.name, named.Name)
+				v.value(f.value, nil, false, offset)
+				continue
+			}
+			v.value(f.value, fd.Type, fd.DefaultValue != nil, offset)
+		}
+		for _, fd := range named.InputFields {
+			if fd.Type.Kind == KindNonNull && fd.DefaultValue == nil && !given[fd.Name] {
+				v.errorf(offset, "Field \"%s.%s\" of required type %q was not provided", named.Name, fd.Name, fd.Type)
+			}
+		}
+	case KindScalar:
+		if !scalarAccepts(named.Name, val.kind) {
+			v.errorf(offset, "%s cannot represent value: %s", named.Name, describeValue(val))
+		}
+	}
+}
+
+func (v *validator) variable(name string, t *TypeRef, hasDefault bool, offset int) {
+	if v.op == nil {
+		return
+	}
+	v.used[name] = true
+	vd, ok := v.vars[name]
+	if !ok {
+		if v.op.name == "" {
+			v.errorf(offset, "Variable \"$%s\" is not defined", name)
+		} else {
+			v.errorf(offset, "Variable \"$%s\" is not defined by operation %q", name, v.op.name)
+		}
+		return
+	}
+	if t == nil {
+		return
+	}
+	if hasDefault || vd.defaultValue != nil {
+		if t.Kind == KindNonNull {
+			t = t.OfType
+		}
+	}
+	if !typeAllowed(vd.typ, t) {
+		v.errorf(offset, "Variable \"$%s\" of type %q used in position expecting type %q", name, vd.typ, t)
+	}
+}
+
+// typeAllowed reports whether a variable of type vt can be used where
+// a value of type t is expected.
+func typeAllowed(vt *typeRef, t *TypeRef) bool {
+	if t.Kind == KindNonNull {
+		if !vt.nonNull {
+			return false
+		}
+		return typeAllowed(&typeRef{name: vt.name, elem: vt.elem}, t.OfType)
+	}
+	if vt.nonNull {
+		return typeAllowed(&typeRef{name: vt.name, elem: vt.elem}, t)
+	}
+	if t.Kind == KindList {
+		return vt.elem != nil && typeAllowed(vt.elem, t.OfType)
+	}
+	return vt.elem == nil && vt.name == t.Name
+}
+
+// scalarAccepts reports whether a literal of the kind is valid for the
+// built-in scalar type. Custom scalars accept anything.
+func scalarAccepts(scalar string, kind valueKind) bool {
+	switch scalar {
+	case "Int":
+		return kind == valueInt
+	case "Float":
+		return kind == valueInt || kind == valueFloat
+	case "String":
+		return kind == valueString || kind == valueBlockString
+	case "Boolean":
+		return kind == valueBoolean
+	case "ID":
+		return kind == valueString || kind == valueBlockString || kind == valueInt
+	}
+	return true
+}
+
+func namedType(t *typeRef) string {
+	for t.elem != nil {
+		t = t.elem
+	}
+	return t.name
+}
+
+// describeValue describes a literal value in error messages.
+func describeValue(val *value) string {
+	switch val.kind {
+	case valueList:
+		return "a list"
+	case valueObject:
+		return "an object"
+	case valueVariable:
+		return "$" + val.raw
+	}
+	return val.raw
+}
@@ -0,0 +1,314 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// walker rewrites a decoded JSON value so that it unmarshals into a
+// destination type the way the client's decode options require. It walks
+// the value and the type together, applying the rules of encoding/json
+// for matching object keys to struct fields.
+type walker struct {
+	timeFormat TimeFormat
+	flatten    bool
+	enums      map[reflect.Type]enumFallback
+	matching   FieldMatching
+	// warnings are about optional fields left zero by a walk.
+	warnings []Warning
+}
+
+// active reports whether the walker needs to run for values decoded
+// into t.
+func (w *walker) active(t reflect.Type) bool {
+	return w.timeFormat != "" || w.flatten || len(w.enums) > 0 || w.matching != MatchFieldsDefault || typeHasTags(t)
+}
+
+// decodeData unmarshals the data payload into resp, rewriting it first
+// if the decode options require it.
+func (c *Client) decodeData(data json.RawMessage, resp interface{}) error {
+	_, err := c.decodeDataWarnings(data, resp)
+	return err
+}
+
+// decodeDataWarnings is decodeData, also getting warnings about optional
+// fields that were left zero because they couldn't be decoded.
+func (c *Client) decodeDataWarnings(data json.RawMessage, resp interface{}) ([]Warning, error) {
+	if resp == nil || len(data) == 0 {
+		return nil, nil
+	}
+	t := reflect.TypeOf(resp)
+	if !c.walker.active(t) {
+		return nil, c.decode(data, resp)
+	}
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return nil, &DecodeError{Offset: -1, Reason: err.Error(), Err: err}
+	}
+	// a copy of the walker holds the warnings of this walk
+	w := c.walker
+	v, err := w.walk(v, t, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, &DecodeError{Offset: -1, Reason: err.Error(), Err: err}
+	}
+	return w.warnings, c.decode(b, resp)
+}
+
+// walk rewrites v for decoding into t. tag holds the options of the
+// struct field being decoded, and path the location of v in the data.
+func (w *walker) walk(v interface{}, t reflect.Type, tag tagOptions, path string) (interface{}, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if v == nil {
+		return nil, nil
+	}
+	if t == timeType {
+		format := w.timeFormat
+		if f, ok := tag["time"]; ok {
+			format = TimeFormat(f)
+		}
+		return decodeTime(v, format, path)
+	}
+	if e, ok := w.enums[t]; ok {
+		return e.value(v), nil
+	}
+	if reflect.PtrTo(t).Implements(unmarshalerType) {
+		return v, nil
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return v, nil
+		}
+		fields := cachedFields(t)
+		// keys matched other than as encoding/json would are renamed
+		// after the walk, and unmatched ones dropped
+		var renamed map[string]string
+		if w.matching != MatchFieldsDefault {
+			renamed = make(map[string]string)
+		}
+		for key, value := range obj {
+			f := w.field(fields, key)
+			if f == nil {
+				if w.matching != MatchFieldsDefault {
+					renamed[key] = ""
+				}
+				continue
+			}
+			rewritten, err := w.walk(value, f.typ, f.tag, path+"."+key)
+			if _, ok := f.tag["optional"]; ok {
+				rewritten, err = w.optional(rewritten, f.typ, path+"."+key, err), nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			obj[key] = rewritten
+			if w.matching != MatchFieldsDefault && key != f.name {
+				renamed[key] = f.name
+			}
+		}
+		for key, name := range renamed {
+			if name != "" {
+				obj[name] = obj[key]
+			}
+			delete(obj, key)
+		}
+		return obj, nil
+	case reflect.Slice, reflect.Array:
+		if obj, ok := v.(map[string]interface{}); ok && t.Kind() == reflect.Slice && w.flattens(tag) {
+			if nodes, ok := flattenConnection(obj); ok {
+				v = nodes
+			}
+		}
+		list, ok := v.([]interface{})
+		if !ok {
+			return v, nil
+		}
+		for i := range list {
+			rewritten, err := w.walk(list[i], t.Elem(), tag, path+"["+strconv.Itoa(i)+"]")
+			if err != nil {
+				return nil, err
+			}
+			list[i] = rewritten
+		}
+		return list, nil
+	case reflect.Map:
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return v, nil
+		}
+		for key, value := range obj {
+			rewritten, err := w.walk(value, t.Elem(), tag, path+"."+key)
+			if err != nil {
+				return nil, err
+			}
+			obj[key] = rewritten
+		}
+		return obj, nil
+	}
+	return v, nil
+}
+
+// flattens reports whether connections are flattened into slice fields
+// with the tag.
+func (w *walker) flattens(tag tagOptions) bool {
+	_, ok := tag["flatten"]
+	return ok || w.flatten
+}
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// tagOptions are the comma separated options of a `graphql` struct tag.
+// Options may have a value, as in `graphql:"time=unixmillis"`.
+type tagOptions map[string]string
+
+func parseTag(tag string) tagOptions {
+	if tag == "" {
+		return nil
+	}
+	opts := make(tagOptions)
+	for _, opt := range strings.Split(tag, ",") {
+		opt = strings.TrimSpace(opt)
+		if opt == "" {
+			continue
+		}
+		kv := strings.SplitN(opt, "=", 2)
+		if len(kv) == 2 {
+			opts[kv[0]] = kv[1]
+		} else {
+			opts[kv[0]] = ""
+		}
+	}
+	return opts
+}
+
+// structField is a struct field that can be decoded from a JSON object key.
+type structField struct {
+	name string
+	typ  reflect.Type
+	tag  tagOptions
+}
+
+type structFields []structField
+
+// match finds the field an object key decodes into, preferring an exact
+// match over a case-insensitive one, as encoding/json does.
+func (fields structFields) match(key string) *structField {
+	var fold *structField
+	for i := range fields {
+		if fields[i].name == key {
+			return &fields[i]
+		}
+		if fold == nil && strings.EqualFold(fields[i].name, key) {
+			fold = &fields[i]
+		}
+	}
+	return fold
+}
+
+var fieldCache sync.Map // map[reflect.Type]structFields
+
+// cachedFields gets the decodable fields of the struct type t,
+// including the promoted fields of embedded structs.
+func cachedFields(t reflect.Type) structFields {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.(structFields)
+	}
+	var fields structFields
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		jsonTag := sf.Tag.Get("json")
+		if jsonTag == "-" {
+			continue
+		}
+		name := strings.Split(jsonTag, ",")[0]
+		ft := sf.Type
+		if sf.Anonymous && name == "" {
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				fields = append(fields, cachedFields(ft)...)
+				continue
+			}
+		}
+		if sf.PkgPath != "" {
+			continue // unexported
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, structField{
+			name: name,
+			typ:  sf.Type,
+			tag:  parseTag(sf.Tag.Get("graphql")),
+		})
+	}
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+var tagCache sync.Map // map[reflect.Type]bool
+
+// typeHasTags reports whether t contains struct fields with
+// `graphql` tags.
+func typeHasTags(t reflect.Type) bool {
+	if has, ok := tagCache.Load(t); ok {
+		return has.(bool)
+	}
+	has, _ := typeHasTagsVisiting(t, make(map[reflect.Type]bool))
+	// with nothing further up being checked, the answer is final
+	tagCache.Store(t, has)
+	return has
+}
+
+// typeHasTagsVisiting is typeHasTags. visiting holds the types being
+// checked further up, which are taken to have no tags, so that recursive
+// types terminate. Answers that depend on that are not final, and aren't
+// cached; final reports whether the answer is.
+func typeHasTagsVisiting(t reflect.Type, visiting map[reflect.Type]bool) (has, final bool) {
+	if has, ok := tagCache.Load(t); ok {
+		return has.(bool), true
+	}
+	if visiting[t] {
+		return false, false
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+	final = true
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		has, final = typeHasTagsVisiting(t.Elem(), visiting)
+	case reflect.Struct:
+		for _, f := range cachedFields(t) {
+			if f.tag != nil {
+				has = true
+				break
+			}
+			fieldHas, fieldFinal := typeHasTagsVisiting(f.typ, visiting)
+			if fieldHas {
+				has = true
+				break
+			}
+			final = final && fieldFinal
+		}
+	}
+	// a type with tags has them whatever is assumed of the others
+	if has || final {
+		tagCache.Store(t, has)
+		return has, true
+	}
+	return false, false
+}
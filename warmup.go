@@ -0,0 +1,95 @@
+package graphql
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// warmupTimeout is how long a warmup waits for the server.
+const warmupTimeout = 30 * time.Second
+
+// warmupState tracks warmups of a client's connection.
+type warmupState struct {
+	mu   sync.Mutex
+	done bool
+	call *warmupCall
+}
+
+// warmupCall is a warmup in progress.
+type warmupCall struct {
+	done chan struct{}
+	err  error
+}
+
+// Warmup opens a connection to the server ahead of the first request, so
+// that request doesn't wait for DNS resolution and the TCP and TLS
+// handshakes. It sends a HEAD request to the endpoint with the client's
+// headers; any HTTP response counts as success, and the connection is
+// kept for reuse by the client's HTTP transport.
+//
+// Warmup is safe to call concurrently: callers share one warmup, and once
+// a warmup has succeeded, later calls return immediately. A failed warmup
+// is tried again by the next call. The warmup runs on a context that
+// keeps the values of ctx but isn't cancelled with it, for at most 30
+// seconds, so a caller that stops waiting doesn't fail the others.
+//  go client.Warmup(ctx)
+func (c *Client) Warmup(ctx context.Context) error {
+	w := &c.warmup
+	w.mu.Lock()
+	if w.done {
+		w.mu.Unlock()
+		return nil
+	}
+	call := w.call
+	if call == nil {
+		call = &warmupCall{done: make(chan struct{})}
+		w.call = call
+		ctx := detachedContext{Context: context.Background(), parent: ctx}
+		go func() {
+			ctx, cancel := context.WithTimeout(ctx, warmupTimeout)
+			defer cancel()
+			call.err = c.warm(ctx)
+			w.mu.Lock()
+			w.done = call.err == nil
+			w.call = nil
+			w.mu.Unlock()
+			close(call.done)
+		}()
+	}
+	w.mu.Unlock()
+	select {
+	case <-call.done:
+		return call.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *Client) warm(ctx context.Context) error {
+	endpoint, err := c.resolveEndpoint(ctx)
+	if err != nil {
+		return err
+	}
+	r, err := http.NewRequest(http.MethodHead, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	for name, values := range c.header {
+		r.Header[name] = values
+	}
+	if err := c.applyCredentials(r); err != nil {
+		return err
+	}
+	res, err := c.httpClient.Do(r.WithContext(ctx))
+	if err != nil {
+		return errors.Wrap(err, "warming up connection")
+	}
+	io.Copy(ioutil.Discard, res.Body)
+	return res.Body.Close()
+}
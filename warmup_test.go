@@ -0,0 +1,75 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestWarmup(t *testing.T) {
+	RegisterTestingT(t)
+	var heads int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Expect(r.Method).Should(Equal(http.MethodHead))
+		Expect(r.Header.Get("Authorization")).Should(Equal("Bearer abc"))
+		atomic.AddInt32(&heads, 1)
+		<-release
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL, graphql.WithHeader("Authorization", "Bearer abc"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Expect(client.Warmup(context.Background())).Should(Succeed())
+		}()
+	}
+	Eventually(func() int32 { return atomic.LoadInt32(&heads) }).Should(BeEquivalentTo(1))
+	close(release)
+	wg.Wait()
+
+	Expect(client.Warmup(context.Background())).Should(Succeed())
+	Expect(atomic.LoadInt32(&heads)).Should(BeEquivalentTo(1))
+
+	down := graphql.NewClient("http://127.0.0.1:1")
+	Expect(down.Warmup(context.Background())).Should(MatchError(ContainSubstring("warming up connection")))
+}
+
+func TestWarmupOutlivesCaller(t *testing.T) {
+	RegisterTestingT(t)
+	var heads int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&heads, 1)
+		<-release
+	}))
+	defer srv.Close()
+	client := graphql.NewClient(srv.URL)
+
+	// the first caller starts the warmup, then stops waiting for it
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error)
+	go func() {
+		first <- client.Warmup(ctx)
+	}()
+	Eventually(func() int32 { return atomic.LoadInt32(&heads) }).Should(BeEquivalentTo(1))
+	second := make(chan error)
+	go func() {
+		second <- client.Warmup(context.Background())
+	}()
+	cancel()
+	Expect(<-first).Should(Equal(context.Canceled))
+	close(release)
+	Expect(<-second).Should(Succeed())
+	Expect(atomic.LoadInt32(&heads)).Should(BeEquivalentTo(1))
+}
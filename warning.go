@@ -0,0 +1,107 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// WarningKind is the kind of a Warning.
+type WarningKind string
+
+// Warning kinds.
+const (
+	// WarningPartialData is for responses with both data and errors,
+	// from Do; Run fails such calls.
+	WarningPartialData WarningKind = "partial_data"
+	// WarningServer is for warnings the server sent in the "warnings"
+	// extension of the response.
+	WarningServer WarningKind = "server"
+	// WarningOptionalField is for optional fields, tagged
+	// `graphql:"optional"` for best-effort data such as enrichments,
+	// that are left zero because the response has errors at or under
+	// them, or they can't be decoded; Run doesn't fail for those.
+	//  type User struct {
+	//      Name       string
+	//      Reputation *Reputation `graphql:"optional"`
+	//  }
+	WarningOptionalField WarningKind = "optional_field"
+	// WarningTruncated is for lists cut short by WithListLimit.
+	WarningTruncated WarningKind = "truncated"
+)
+
+// Warning is a condition that doesn't fail a call but that callers may
+// want to know about.
+type Warning struct {
+	Kind    WarningKind
+	Message string
+	// Path is the path of the response field the warning relates to,
+	// if any.
+	Path []interface{}
+}
+
+func (w Warning) String() string {
+	if len(w.Path) == 0 {
+		return fmt.Sprintf("%s: %s", w.Kind, w.Message)
+	}
+	return fmt.Sprintf("%s: %s (at %v)", w.Kind, w.Message, w.Path)
+}
+
+// WithWarningHandler sets a function called with each warning about a
+// response, including the responses to requests in batches.
+//  NewClient(endpoint, WithWarningHandler(func(req *Request, w Warning) {
+//      log.Printf("graphql: %s: %s", req.MetricsName(), w)
+//  }))
+func WithWarningHandler(handler func(req *Request, w Warning)) ClientOption {
+	return ClientOption(func(client *Client) {
+		client.warningHandler = handler
+	})
+}
+
+// warnings gets the warnings about the response to req, passing them to
+// the client's handler. Errors in responses with data are only warnings
+// if partial is set; Run fails such calls instead.
+func (c *Client) warnings(req *Request, res *response, partial bool) []Warning {
+	var warnings []Warning
+	if partial && len(res.errors) > 0 && len(res.data) > 0 && !bytes.Equal(res.data, []byte("null")) {
+		for _, err := range res.errors {
+			warnings = append(warnings, Warning{Kind: WarningPartialData, Message: err.Message, Path: err.Path})
+		}
+	}
+	if len(res.extensions) > 0 {
+		var extensions struct {
+			Warnings []json.RawMessage `json:"warnings"`
+		}
+		json.Unmarshal(res.extensions, &extensions)
+		for _, raw := range extensions.Warnings {
+			// warnings are either strings or objects like errors
+			var w struct {
+				Message string        `json:"message"`
+				Path    []interface{} `json:"path"`
+			}
+			if json.Unmarshal(raw, &w.Message) != nil && json.Unmarshal(raw, &w) != nil {
+				continue
+			}
+			warnings = append(warnings, Warning{Kind: WarningServer, Message: w.Message, Path: w.Path})
+		}
+	}
+	for _, t := range res.truncations {
+		warnings = append(warnings, Warning{Kind: WarningTruncated, Message: t.String(), Path: t.Path})
+	}
+	c.handleWarnings(req, warnings)
+	return warnings
+}
+
+// handleWarnings passes the warnings about the response to req to the
+// client's handler.
+func (c *Client) handleWarnings(req *Request, warnings []Warning) {
+	if c.warningHandler == nil {
+		return
+	}
+	for _, w := range warnings {
+		c.callHook("warning handler", func() error {
+			c.warningHandler(req, w)
+			return nil
+		})
+	}
+}
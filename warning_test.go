@@ -0,0 +1,39 @@
+package graphql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/joefitzgerald/graphql"
+	. "github.com/onsi/gomega"
+)
+
+func TestWarnings(t *testing.T) {
+	RegisterTestingT(t)
+	var handled []graphql.Warning
+	handler := graphql.WithWarningHandler(func(req *graphql.Request, w graphql.Warning) {
+		handled = append(handled, w)
+	})
+	body := `{
+		"data": {"user": {"name": "Mat", "email": null}},
+		"errors": [{"message": "email unavailable", "path": ["user", "email"]}],
+		"extensions": {"warnings": ["slow resolver", {"message": "field is deprecated", "path": ["user", "name"]}]}
+	}`
+	client := bodyClient(body, handler)
+	ctx := context.Background()
+
+	result, err := graphql.Do[map[string]interface{}](ctx, client, graphql.NewRequest(`{ user { name email } }`))
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(result.Warnings).Should(Equal([]graphql.Warning{
+		{Kind: graphql.WarningPartialData, Message: "email unavailable", Path: []interface{}{"user", "email"}},
+		{Kind: graphql.WarningServer, Message: "slow resolver"},
+		{Kind: graphql.WarningServer, Message: "field is deprecated", Path: []interface{}{"user", "name"}},
+	}))
+	Expect(handled).Should(Equal(result.Warnings))
+	Expect(result.Warnings[0].String()).Should(Equal("partial_data: email unavailable (at [user email])"))
+
+	handled = nil
+	err = client.Run(ctx, graphql.NewRequest(`{ user { name email } }`), nil)
+	Expect(err).Should(MatchError("graphql: email unavailable"))
+	Expect(handled).Should(Equal(result.Warnings[1:]))
+}